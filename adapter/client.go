@@ -0,0 +1,201 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opencensus.io/plugin/ochttp"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/source"
+	"knative.dev/pkg/tracing/propagation/tracecontextb3"
+)
+
+// specVersion is the CloudEvents spec version of the events sent.
+const specVersion = "1.0"
+
+// Event is a CloudEvent to be sent to the sink.
+type Event struct {
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	DataSchema      string
+	Data            []byte
+
+	// Extensions are the extension attributes of the event.
+	Extensions map[string]string
+}
+
+// Client sends events to the sink of an adapter.
+type Client interface {
+	// Send sends the event, returning an error unless the sink accepted it.
+	Send(ctx context.Context, event Event) error
+}
+
+// ClientConfig configures a Client created by NewClient.
+type ClientConfig struct {
+	// Target is the URI the events are sent to.
+	Target string
+
+	// Overrides are applied to every event sent. Their subject and
+	// dataschema extensions set the corresponding attributes.
+	Overrides *duckv1.CloudEventOverrides
+
+	// Reporter, when set, reports the events sent.
+	Reporter source.StatsReporter
+
+	// Env tags the reported metrics.
+	Env *EnvConfig
+
+	// Transport is the transport used to send events. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+type client struct {
+	target    string
+	overrides *duckv1.CloudEventOverrides
+	reporter  source.StatsReporter
+	env       *EnvConfig
+	http      *http.Client
+}
+
+// NewClient creates a Client sending events in the binary HTTP mode of
+// CloudEvents, with the trace context propagated.
+func NewClient(cfg ClientConfig) (Client, error) {
+	if cfg.Target == "" {
+		return nil, errors.New("the target of the client must be set")
+	}
+	if err := cfg.Overrides.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid CloudEvent overrides: %w", err)
+	}
+	transport := cfg.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	env := cfg.Env
+	if env == nil {
+		env = &EnvConfig{}
+	}
+	return &client{
+		target:    cfg.Target,
+		overrides: cfg.Overrides,
+		reporter:  cfg.Reporter,
+		env:       env,
+		http: &http.Client{
+			Transport: &ochttp.Transport{
+				Base:        transport,
+				Propagation: tracecontextb3.TraceContextEgress,
+			},
+		},
+	}, nil
+}
+
+// Send implements Client.
+func (c *client) Send(ctx context.Context, event Event) error {
+	event = applyOverrides(event, c.overrides)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.target, bytes.NewReader(event.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Ce-Specversion", specVersion)
+	req.Header.Set("Ce-Id", event.ID)
+	req.Header.Set("Ce-Source", event.Source)
+	req.Header.Set("Ce-Type", event.Type)
+	if event.Subject != "" {
+		req.Header.Set("Ce-Subject", event.Subject)
+	}
+	if !event.Time.IsZero() {
+		req.Header.Set("Ce-Time", event.Time.UTC().Format(time.RFC3339Nano))
+	}
+	if event.DataContentType != "" {
+		req.Header.Set("Content-Type", event.DataContentType)
+	}
+	if event.DataSchema != "" {
+		req.Header.Set("Ce-Dataschema", event.DataSchema)
+	}
+	for k, v := range event.Extensions {
+		req.Header.Set("Ce-"+strings.ToLower(k), v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.report(event, 0, err)
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = fmt.Errorf("the sink responded with status %d", resp.StatusCode)
+	}
+	c.report(event, resp.StatusCode, err)
+	return err
+}
+
+// applyOverrides returns the event with the overrides applied, without
+// modifying the extensions of the given event.
+func applyOverrides(event Event, overrides *duckv1.CloudEventOverrides) Event {
+	if overrides == nil || len(overrides.Extensions) == 0 {
+		return event
+	}
+	ext := make(map[string]string, len(event.Extensions)+len(overrides.Extensions))
+	for k, v := range event.Extensions {
+		ext[k] = v
+	}
+	for k, v := range overrides.Extensions {
+		switch k {
+		case "subject":
+			event.Subject = v
+		case "dataschema":
+			event.DataSchema = v
+		default:
+			ext[k] = v
+		}
+	}
+	event.Extensions = ext
+	return event
+}
+
+func (c *client) report(event Event, code int, err error) {
+	if c.reporter == nil {
+		return
+	}
+	args := &source.ReportArgs{
+		Namespace:     c.env.Namespace,
+		EventType:     event.Type,
+		EventSource:   event.Source,
+		Name:          c.env.Name,
+		ResourceGroup: c.env.ResourceGroup,
+	}
+	if err != nil {
+		var netErr net.Error
+		args.Error = err.Error()
+		args.Timeout = errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+	}
+	c.reporter.ReportEventCount(args, code)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/environment"
+	"knative.dev/pkg/source"
+)
+
+type fakeReporter struct {
+	args  []*source.ReportArgs
+	codes []int
+}
+
+func (r *fakeReporter) ReportEventCount(args *source.ReportArgs, code int) error {
+	r.args = append(r.args, args)
+	r.codes = append(r.codes, code)
+	return nil
+}
+
+func TestClientSend(t *testing.T) {
+	var (
+		gotHeader http.Header
+		gotBody   string
+		status    = http.StatusAccepted
+	)
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(status)
+	}))
+	defer sink.Close()
+
+	reporter := &fakeReporter{}
+	c, err := NewClient(ClientConfig{
+		Target: sink.URL,
+		Overrides: &duckv1.CloudEventOverrides{Extensions: map[string]string{
+			"env":        "prod",
+			"subject":    "overridden",
+			"dataschema": "https://schemas.example.com/ping",
+		}},
+		Reporter: reporter,
+		Env:      &EnvConfig{Config: environment.Config{Namespace: "ns"}, Name: "ping", ResourceGroup: "pingsources.sources.knative.dev"},
+	})
+	if err != nil {
+		t.Fatal("NewClient() =", err)
+	}
+
+	event := Event{
+		ID:              "1",
+		Source:          "/ping",
+		Type:            "dev.knative.ping",
+		Time:            time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		DataContentType: "application/json",
+		Data:            []byte(`{"hello":"world"}`),
+		Extensions:      map[string]string{"env": "dev", "team": "a"},
+	}
+	if err := c.Send(context.Background(), event); err != nil {
+		t.Fatal("Send() =", err)
+	}
+
+	for k, want := range map[string]string{
+		"Ce-Specversion": "1.0",
+		"Ce-Id":          "1",
+		"Ce-Source":      "/ping",
+		"Ce-Type":        "dev.knative.ping",
+		"Ce-Time":        "2020-01-02T03:04:05Z",
+		"Ce-Env":         "prod",
+		"Ce-Team":        "a",
+		"Ce-Subject":     "overridden",
+		"Ce-Dataschema":  "https://schemas.example.com/ping",
+		"Content-Type":   "application/json",
+	} {
+		if got := gotHeader.Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+	if gotBody != `{"hello":"world"}` {
+		t.Errorf("Body = %s", gotBody)
+	}
+	if event.Extensions["env"] != "dev" {
+		t.Error("Send() modified the extensions of the event")
+	}
+
+	status = http.StatusBadRequest
+	if err := c.Send(context.Background(), event); err == nil {
+		t.Error("Send() = nil, wanted an error for a rejected event")
+	}
+
+	wantArgs := &source.ReportArgs{
+		Namespace:     "ns",
+		EventType:     "dev.knative.ping",
+		EventSource:   "/ping",
+		Name:          "ping",
+		ResourceGroup: "pingsources.sources.knative.dev",
+	}
+	if !cmp.Equal(reporter.args[0], wantArgs) {
+		t.Errorf("Reported args (-want, +got) = %s", cmp.Diff(wantArgs, reporter.args[0]))
+	}
+	if got, want := reporter.codes, []int{http.StatusAccepted, http.StatusBadRequest}; !cmp.Equal(got, want) {
+		t.Errorf("Reported codes = %v, want %v", got, want)
+	}
+	if reporter.args[1].Error == "" {
+		t.Error("The rejected event was reported without an error")
+	}
+}
+
+func TestNewClientWithoutTarget(t *testing.T) {
+	if _, err := NewClient(ClientConfig{}); err == nil {
+		t.Error("NewClient() = nil, wanted an error")
+	}
+}
+
+func TestNewClientInvalidOverrides(t *testing.T) {
+	_, err := NewClient(ClientConfig{
+		Target:    "http://sink.ns.svc.cluster.local",
+		Overrides: &duckv1.CloudEventOverrides{Extensions: map[string]string{"type": "spoofed"}},
+	})
+	if err == nil {
+		t.Error("NewClient() = nil, wanted an error")
+	}
+}
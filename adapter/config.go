@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/environment"
+)
+
+// EnvConfig is the configuration every adapter reads from its environment,
+// as set up by the source reconcilers. Adapters needing more configuration
+// embed it in their own struct, and read the rest with envconfig tags too.
+type EnvConfig struct {
+	// Config holds the namespace, sink and observability configuration
+	// shared with controllers.
+	environment.Config
+
+	// Name is the name of the source.
+	Name string `envconfig:"NAME" default:"adapter"`
+
+	// ResourceGroup is the group.resource of the source, e.g.
+	// pingsources.sources.knative.dev, used to tag the metrics.
+	ResourceGroup string `envconfig:"K_RESOURCE_GROUP"`
+
+	// CEOverrides is the JSON encoded duckv1.CloudEventOverrides applied
+	// to the outbound events.
+	CEOverrides string `envconfig:"K_CE_OVERRIDES"`
+}
+
+// EnvConfigAccessor gives access to the EnvConfig of an adapter's
+// environment.
+type EnvConfigAccessor interface {
+	// GetEnvConfig returns the EnvConfig.
+	GetEnvConfig() *EnvConfig
+}
+
+// EnvConfigConstructor returns an empty EnvConfigAccessor to be filled in
+// from the environment.
+type EnvConfigConstructor func() EnvConfigAccessor
+
+// Check that EnvConfig implements EnvConfigAccessor.
+var _ EnvConfigAccessor = (*EnvConfig)(nil)
+
+// GetEnvConfig implements EnvConfigAccessor.
+func (e *EnvConfig) GetEnvConfig() *EnvConfig {
+	return e
+}
+
+// GetCloudEventOverrides parses the CloudEventOverrides, if any.
+func (e *EnvConfig) GetCloudEventOverrides() (*duckv1.CloudEventOverrides, error) {
+	if e.CEOverrides == "" {
+		return nil, nil
+	}
+	overrides := &duckv1.CloudEventOverrides{}
+	if err := json.Unmarshal([]byte(e.CEOverrides), overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse K_CE_OVERRIDES: %w", err)
+	}
+	return overrides, nil
+}
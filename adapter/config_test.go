@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type myEnvConfig struct {
+	EnvConfig
+
+	Schedule string `envconfig:"SCHEDULE" required:"true"`
+}
+
+func TestEnvConfig(t *testing.T) {
+	for k, v := range map[string]string{
+		"NAMESPACE":      "ns",
+		"SINK_URI":       "http://legacy.ns.svc.cluster.local",
+		"K_CE_OVERRIDES": `{"extensions": {"env": "prod"}}`,
+		"SCHEDULE":       "* * * * *",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	var env myEnvConfig
+	if err := envconfig.Process("", &env); err != nil {
+		t.Fatal("Process() =", err)
+	}
+	if got, want := env.Schedule, "* * * * *"; got != want {
+		t.Errorf("Schedule = %q, want %q", got, want)
+	}
+	if got, want := env.GetEnvConfig().Name, "adapter"; got != want {
+		t.Errorf("Name = %q, want %q", got, want)
+	}
+	if got, want := env.GetSink(), "http://legacy.ns.svc.cluster.local"; got != want {
+		t.Errorf("GetSink() = %q, want %q", got, want)
+	}
+	env.Sink = "http://sink.ns.svc.cluster.local"
+	if got, want := env.GetSink(), "http://sink.ns.svc.cluster.local"; got != want {
+		t.Errorf("GetSink() = %q, want %q", got, want)
+	}
+
+	overrides, err := env.GetCloudEventOverrides()
+	if err != nil {
+		t.Fatal("GetCloudEventOverrides() =", err)
+	}
+	if got, want := overrides.Extensions["env"], "prod"; got != want {
+		t.Errorf("Extensions[env] = %q, want %q", got, want)
+	}
+
+	env.CEOverrides = "{"
+	if _, err := env.GetCloudEventOverrides(); err == nil {
+		t.Error("GetCloudEventOverrides() = nil, wanted an error")
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/environment"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/signals"
+	"knative.dev/pkg/source"
+	"knative.dev/pkg/tracing"
+	tracingconfig "knative.dev/pkg/tracing/config"
+)
+
+// Adapter is a receive adapter, turning some external activity into
+// events sent through its Client.
+type Adapter interface {
+	// Start runs the adapter until the context is cancelled.
+	Start(ctx context.Context) error
+}
+
+// AdapterConstructor creates an Adapter from its environment, sending
+// events through the given Client.
+type AdapterConstructor func(ctx context.Context, env EnvConfigAccessor, client Client) Adapter
+
+// Main runs the generic main flow of receive adapters with a new context.
+func Main(component string, ector EnvConfigConstructor, ctor AdapterConstructor) {
+	MainWithContext(signals.NewContext(), component, ector, ctor)
+}
+
+// MainWithContext parses the adapter's environment, sets up its logging,
+// metrics and tracing, and creates a Client sending to its sink, then runs
+// the adapter until the context is cancelled.
+func MainWithContext(ctx context.Context, component string, ector EnvConfigConstructor, ctor AdapterConstructor) {
+	accessor := ector()
+	if err := environment.Process(accessor); err != nil {
+		log.Fatal("Error processing the environment: ", err)
+	}
+	env := accessor.GetEnvConfig()
+
+	// Report errors parsing the logging config once the logger is set up,
+	// using the defaults in the meantime.
+	loggingConfig, loggingErr := logging.JsonToLoggingConfig(env.LoggingConfigJSON)
+	if loggingErr != nil {
+		loggingConfig, _ = logging.NewConfigFromMap(nil)
+	}
+	logger, _ := logging.NewLoggerFromConfig(loggingConfig, component)
+	defer flush(logger)
+	ctx = logging.WithLogger(ctx, logger)
+	if loggingErr != nil {
+		logger.Warnw("Failed to parse the logging config, using the defaults", zap.Error(loggingErr))
+	}
+
+	if metricsConfig, err := metrics.JsonToMetricsOptions(env.MetricsConfigJSON); err != nil {
+		logger.Warnw("Failed to parse the metrics config, metrics are disabled", zap.Error(err))
+	} else if err := metrics.UpdateExporter(ctx, *metricsConfig, logger); err != nil {
+		logger.Errorw("Failed to create the metrics exporter", zap.Error(err))
+	}
+
+	tracer := tracing.NewOpenCensusTracer(tracing.WithExporter(component, logger))
+	defer tracer.Finish()
+	if tracingConfig, err := tracingconfig.JsonToTracingConfig(env.TracingConfigJSON); err != nil {
+		logger.Warnw("Failed to parse the tracing config, tracing is disabled", zap.Error(err))
+	} else if err := tracer.ApplyConfig(tracingConfig); err != nil {
+		logger.Errorw("Failed to set up tracing", zap.Error(err))
+	}
+
+	overrides, err := env.GetCloudEventOverrides()
+	if err != nil {
+		logger.Fatalw("Error parsing the CloudEvent overrides", zap.Error(err))
+	}
+	reporter, err := source.NewStatsReporter()
+	if err != nil {
+		logger.Fatalw("Error creating the stats reporter", zap.Error(err))
+	}
+	client, err := NewClient(ClientConfig{
+		Target:    env.GetSink(),
+		Overrides: overrides,
+		Reporter:  reporter,
+		Env:       env,
+	})
+	if err != nil {
+		logger.Fatalw("Error creating the client", zap.Error(err))
+	}
+
+	logger.Info("Starting the adapter")
+	if err := ctor(ctx, accessor, client).Start(ctx); err != nil {
+		logger.Errorw("The adapter failed", zap.Error(err))
+	}
+}
+
+// flushTimeout bounds how long a last Prometheus scrape is waited for on
+// shutdown, well within the default termination grace period of pods.
+const flushTimeout = 15 * time.Second
+
+func flush(logger *zap.SugaredLogger) {
+	logger.Sync()
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	metrics.ShutdownExporter(ctx, metrics.ReportingPeriod())
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package duck
+
+import (
+	"context"
+	"reflect"
+)
+
+// informerFactoryKey associates an InformerFactory with the Go type of the
+// duck type it produces informers for.
+type informerFactoryKey struct {
+	t reflect.Type
+}
+
+// WithInformerFactory associates the InformerFactory producing informers
+// for the given duck type, e.g. &duckv1.Addressable{}, with the context.
+func WithInformerFactory(ctx context.Context, duckType interface{}, dif InformerFactory) context.Context {
+	return context.WithValue(ctx, informerFactoryKey{t: reflect.TypeOf(duckType)}, dif)
+}
+
+// GetInformerFactory returns the InformerFactory associated with the given
+// duck type, e.g. &duckv1.Addressable{}, or nil if there is none.
+func GetInformerFactory(ctx context.Context, duckType interface{}) InformerFactory {
+	dif, _ := ctx.Value(informerFactoryKey{t: reflect.TypeOf(duckType)}).(InformerFactory)
+	return dif
+}
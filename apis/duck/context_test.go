@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package duck
+
+import (
+	"context"
+	"testing"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestInformerFactoryContext(t *testing.T) {
+	ctx := context.Background()
+	if dif := GetInformerFactory(ctx, &duckv1.Addressable{}); dif != nil {
+		t.Errorf("GetInformerFactory() = %v, wanted nil", dif)
+	}
+
+	want := &CachedInformerFactory{}
+	ctx = WithInformerFactory(ctx, &duckv1.AddressableType{}, want)
+	if got := GetInformerFactory(ctx, &duckv1.AddressableType{}); got != want {
+		t.Errorf("GetInformerFactory() = %v, wanted %v", got, want)
+	}
+	if dif := GetInformerFactory(ctx, &duckv1.Source{}); dif != nil {
+		t.Errorf("GetInformerFactory(Source) = %v, wanted nil", dif)
+	}
+}
@@ -40,6 +40,9 @@ type TypedInformerFactory struct {
 	Type         apis.Listable
 	ResyncPeriod time.Duration
 	StopChannel  <-chan struct{}
+
+	// Namespace, when set, scopes the informers to a single namespace.
+	Namespace string
 }
 
 // Check that TypedInformerFactory implements InformerFactory.
@@ -49,14 +52,18 @@ var _ InformerFactory = (*TypedInformerFactory)(nil)
 func (dif *TypedInformerFactory) Get(ctx context.Context, gvr schema.GroupVersionResource) (cache.SharedIndexInformer, cache.GenericLister, error) {
 	// Avoid error cases, like the GVR does not exist.
 	// It is not a full check. Some RBACs might sneak by, but the window is very small.
-	if _, err := dif.Client.Resource(gvr).List(ctx, metav1.ListOptions{}); err != nil {
+	var resource dynamic.ResourceInterface = dif.Client.Resource(gvr)
+	if dif.Namespace != "" {
+		resource = dif.Client.Resource(gvr).Namespace(dif.Namespace)
+	}
+	if _, err := resource.List(ctx, metav1.ListOptions{}); err != nil {
 		return nil, nil, err
 	}
 
 	listObj := dif.Type.GetListType()
 	lw := &cache.ListWatch{
-		ListFunc:  asStructuredLister(ctx, dif.Client.Resource(gvr).List, listObj),
-		WatchFunc: AsStructuredWatcher(ctx, dif.Client.Resource(gvr).Watch, dif.Type),
+		ListFunc:  asStructuredLister(ctx, resource.List, listObj),
+		WatchFunc: AsStructuredWatcher(ctx, resource.Watch, dif.Type),
 	}
 	inf := cache.NewSharedIndexInformer(lw, dif.Type, dif.ResyncPeriod, cache.Indexers{
 		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
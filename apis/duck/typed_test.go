@@ -96,6 +96,50 @@ func TestSimpleList(t *testing.T) {
 	// TODO(mattmoor): Access through informer
 }
 
+func TestNamespacedList(t *testing.T) {
+	scheme := runtime.NewScheme()
+	AddToScheme(scheme)
+	duckv1alpha1.AddToScheme(scheme)
+
+	var objs []runtime.Object
+	for _, namespace := range []string{"foo", "other"} {
+		objs = append(objs, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "pkg.knative.dev/v2",
+				"kind":       "Resource",
+				"metadata": map[string]interface{}{
+					"namespace": namespace,
+					"name":      "bar",
+				},
+			},
+		})
+	}
+	client := fake.NewSimpleDynamicClient(scheme, objs...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tif := &duck.TypedInformerFactory{
+		Client:       client,
+		Type:         &duckv1alpha1.AddressableType{},
+		ResyncPeriod: 1 * time.Second,
+		StopChannel:  ctx.Done(),
+		Namespace:    "foo",
+	}
+
+	_, lister, err := tif.Get(ctx, SchemeGroupVersion.WithResource("resources"))
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+
+	if _, err := lister.ByNamespace("foo").Get("bar"); err != nil {
+		t.Error("Get(foo/bar) =", err)
+	}
+	if _, err := lister.ByNamespace("other").Get("bar"); err == nil {
+		t.Error("Get(other/bar) succeeded, but the informer is scoped to foo")
+	}
+}
+
 func TestInvalidResource(t *testing.T) {
 	client := &invalidResourceClient{}
 	stopCh := make(chan struct{})
@@ -18,6 +18,7 @@ package v1
 
 import (
 	"context"
+	"crypto/x509"
 
 	"knative.dev/pkg/apis"
 )
@@ -31,6 +32,11 @@ type Destination struct {
 	// URI can be an absolute URL(non-empty scheme and non-empty host) pointing to the target or a relative URI. Relative URIs will be resolved using the base URI retrieved from Ref.
 	// +optional
 	URI *apis.URL `json:"uri,omitempty"`
+
+	// CACerts are the Certification Authority (CA) certificates in PEM format
+	// that the destination's server certificates are expected to be signed by.
+	// +optional
+	CACerts *string `json:"CACerts,omitempty"`
 }
 
 // Validate the Destination has all the necessary fields and check the
@@ -50,6 +56,10 @@ func ValidateDestination(ctx context.Context, dest Destination) *apis.FieldError
 		return apis.ErrGeneric("expected at least one, got none", "ref", "uri")
 	}
 
+	if dest.CACerts != nil && !x509.NewCertPool().AppendCertsFromPEM([]byte(*dest.CACerts)) {
+		return apis.ErrInvalidValue("not a PEM encoded certificate bundle", "CACerts")
+	}
+
 	if ref != nil && uri != nil && uri.URL().IsAbs() {
 		return apis.ErrGeneric("Absolute URI is not allowed when Ref or [apiVersion, kind, name] is present", "[apiVersion, kind, name]", "ref", "uri")
 	}
@@ -35,6 +35,19 @@ const (
 func TestValidateDestination(t *testing.T) {
 	ctx := context.Background()
 
+	caCerts := `-----BEGIN CERTIFICATE-----
+MIIBhzCCAS2gAwIBAgIUGk/YWeXlDJfJMO+TgwyQZ8F6GF0wCgYIKoZIzj0EAwIw
+GTEXMBUGA1UEAwwOY2EuZXhhbXBsZS5jb20wHhcNMjYxMDE3MDEzMzE1WhcNMzYx
+MDE0MDEzMzE1WjAZMRcwFQYDVQQDDA5jYS5leGFtcGxlLmNvbTBZMBMGByqGSM49
+AgEGCCqGSM49AwEHA0IABCnlrtAPuosMky9PT3KxDTjAdmEofNLmH25ckN8rLaSc
+HYqrM639AKznYQ7NDGoRxL01hjI1E5nLDDM4qvMSH8CjUzBRMB0GA1UdDgQWBBR3
+FZYVOc6nBQUoZESNbg7PlkiGdjAfBgNVHSMEGDAWgBR3FZYVOc6nBQUoZESNbg7P
+lkiGdjAPBgNVHRMBAf8EBTADAQH/MAoGCCqGSM49BAMCA0gAMEUCIBJMFA7em1Hj
+FCUWQd8LHe02LYbkb5GecsGOpBjRlc8cAiEAwW5sNqty07dWTG2SOvcU7Kd0K0cJ
+j6YnPVC99L5Q//0=
+-----END CERTIFICATE-----`
+	badCACerts := "not a certificate"
+
 	validRef := KReference{
 		Kind:       kind,
 		APIVersion: apiVersion,
@@ -117,6 +130,17 @@ func TestValidateDestination(t *testing.T) {
 			},
 			Ref: &validRef,
 		},
+	}, "valid, uri with CA certs": {
+		dest: &Destination{
+			URI:     &apis.URL{Scheme: "https", Host: "host"},
+			CACerts: &caCerts,
+		},
+	}, "invalid, CA certs are not PEM": {
+		dest: &Destination{
+			URI:     &apis.URL{Scheme: "https", Host: "host"},
+			CACerts: &badCACerts,
+		},
+		want: "invalid value: not a PEM encoded certificate bundle: CACerts",
 	}}
 
 	for name, tc := range tests {
@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	"context"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -64,6 +65,49 @@ type CloudEventOverrides struct {
 	Extensions map[string]string `json:"extensions,omitempty"`
 }
 
+// reservedAttributes are the CloudEvents context attributes that identify an
+// event, and so cannot be overridden.
+var reservedAttributes = map[string]bool{
+	"id":              true,
+	"source":          true,
+	"specversion":     true,
+	"type":            true,
+	"time":            true,
+	"datacontenttype": true,
+	"data":            true,
+}
+
+// Validate checks that the extension names are valid CloudEvents attribute
+// names, lower-case letters and digits only, and that they do not override
+// the attributes identifying the event. The subject and dataschema
+// attributes may be overridden.
+func (ceOverrides *CloudEventOverrides) Validate(ctx context.Context) *apis.FieldError {
+	if ceOverrides == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	for key := range ceOverrides.Extensions {
+		switch {
+		case key == "":
+			errs = errs.Also(apis.ErrInvalidKeyName(key, "extensions", "keys must not be empty"))
+		case !isAttributeName(key):
+			errs = errs.Also(apis.ErrInvalidKeyName(key, "extensions", "keys must only contain lower-case letters and digits"))
+		case reservedAttributes[key]:
+			errs = errs.Also(apis.ErrInvalidKeyName(key, "extensions", "keys must not override reserved attributes"))
+		}
+	}
+	return errs
+}
+
+func isAttributeName(key string) bool {
+	for _, r := range key {
+		if !('a' <= r && r <= 'z') && !('0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
 // SourceStatus shows how we expect folks to embed Addressable in
 // their Status field.
 type SourceStatus struct {
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/pkg/apis"
+)
+
+func TestCloudEventOverridesValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides *CloudEventOverrides
+		want      *apis.FieldError
+	}{{
+		name: "nil",
+	}, {
+		name: "valid",
+		overrides: &CloudEventOverrides{Extensions: map[string]string{
+			"env":        "prod",
+			"region2":    "us",
+			"subject":    "overridden",
+			"dataschema": "https://schemas.example.com/ping",
+		}},
+	}, {
+		name:      "empty key",
+		overrides: &CloudEventOverrides{Extensions: map[string]string{"": "empty"}},
+		want:      apis.ErrInvalidKeyName("", "extensions", "keys must not be empty"),
+	}, {
+		name:      "invalid characters",
+		overrides: &CloudEventOverrides{Extensions: map[string]string{"Team-Name": "a"}},
+		want:      apis.ErrInvalidKeyName("Team-Name", "extensions", "keys must only contain lower-case letters and digits"),
+	}, {
+		name:      "reserved attribute",
+		overrides: &CloudEventOverrides{Extensions: map[string]string{"source": "/spoofed"}},
+		want:      apis.ErrInvalidKeyName("source", "extensions", "keys must not override reserved attributes"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.overrides.Validate(context.Background())
+			if !cmp.Equal(test.want.Error(), got.Error()) {
+				t.Errorf("Validate() (-want, +got) = %s", cmp.Diff(test.want.Error(), got.Error()))
+			}
+		})
+	}
+}
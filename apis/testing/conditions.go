@@ -18,6 +18,8 @@ package testing
 import (
 	"fmt"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/test"
@@ -35,6 +37,18 @@ func CheckCondition(a apis.ConditionAccessor, c apis.ConditionType, cs corev1.Co
 	return nil
 }
 
+// CheckConditionWithReason checks if condition `c` on `cc` has value `cs`
+// and reason `reason`.
+func CheckConditionWithReason(a apis.ConditionAccessor, c apis.ConditionType, cs corev1.ConditionStatus, reason string) error {
+	if err := CheckCondition(a, c, cs); err != nil {
+		return err
+	}
+	if cond := a.GetCondition(c); cond.Reason != reason {
+		return fmt.Errorf("condition(%v).Reason = %q, wanted: %q", c, cond.Reason, reason)
+	}
+	return nil
+}
+
 // CheckConditionOngoing checks if the condition is in state `Unknown`.
 func CheckConditionOngoing(a apis.ConditionAccessor, c apis.ConditionType, t test.T) {
 	t.Helper()
@@ -58,3 +72,59 @@ func CheckConditionSucceeded(a apis.ConditionAccessor, c apis.ConditionType, t t
 		t.Error(err)
 	}
 }
+
+// AssertConditionTrue fails the test if condition `c` on `a` is not `True`.
+func AssertConditionTrue(t test.T, a apis.ConditionAccessor, c apis.ConditionType) {
+	t.Helper()
+	CheckConditionSucceeded(a, c, t)
+}
+
+// AssertConditionFalseWithReason fails the test if condition `c` on `a` is
+// not `False` with the given reason.
+func AssertConditionFalseWithReason(t test.T, a apis.ConditionAccessor, c apis.ConditionType, reason string) {
+	t.Helper()
+	if err := CheckConditionWithReason(a, c, corev1.ConditionFalse, reason); err != nil {
+		t.Error(err)
+	}
+}
+
+// AssertConditionUnknownWithReason fails the test if condition `c` on `a`
+// is not `Unknown` with the given reason.
+func AssertConditionUnknownWithReason(t test.T, a apis.ConditionAccessor, c apis.ConditionType, reason string) {
+	t.Helper()
+	if err := CheckConditionWithReason(a, c, corev1.ConditionUnknown, reason); err != nil {
+		t.Error(err)
+	}
+}
+
+// AssertReadyTrue fails the test if the Ready condition on `a` is not `True`.
+func AssertReadyTrue(t test.T, a apis.ConditionAccessor) {
+	t.Helper()
+	AssertConditionTrue(t, a, apis.ConditionReady)
+}
+
+// AssertReadyFalseWithReason fails the test if the Ready condition on `a`
+// is not `False` with the given reason.
+func AssertReadyFalseWithReason(t test.T, a apis.ConditionAccessor, reason string) {
+	t.Helper()
+	AssertConditionFalseWithReason(t, a, apis.ConditionReady, reason)
+}
+
+// AssertReadyUnknownWithReason fails the test if the Ready condition on `a`
+// is not `Unknown` with the given reason.
+func AssertReadyUnknownWithReason(t test.T, a apis.ConditionAccessor, reason string) {
+	t.Helper()
+	AssertConditionUnknownWithReason(t, a, apis.ConditionReady, reason)
+}
+
+// IgnoreLastTransitionTime is a cmp.Option ignoring the volatile
+// LastTransitionTime of conditions.
+var IgnoreLastTransitionTime = cmpopts.IgnoreFields(apis.Condition{}, "LastTransitionTime")
+
+// ConditionsDiff returns the diff between the want and got conditions,
+// ignoring their LastTransitionTime and order, or "" if they match.
+func ConditionsDiff(want, got apis.Conditions) string {
+	return cmp.Diff(want, got, IgnoreLastTransitionTime,
+		cmpopts.SortSlices(func(a, b apis.Condition) bool { return a.Type < b.Type }),
+		cmpopts.EquateEmpty())
+}
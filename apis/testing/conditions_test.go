@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// fakeT records the errors reported by the assertions.
+type fakeT struct {
+	*testing.T
+	errors int
+}
+
+func (f *fakeT) Error(args ...interface{}) {
+	f.errors++
+}
+
+func TestAssertReady(t *testing.T) {
+	status := &duckv1.Status{
+		Conditions: duckv1.Conditions{{
+			Type:   apis.ConditionReady,
+			Status: corev1.ConditionUnknown,
+			Reason: "Deploying",
+		}, {
+			Type:   "Foo",
+			Status: corev1.ConditionTrue,
+		}, {
+			Type:   "Bar",
+			Status: corev1.ConditionFalse,
+			Reason: "Broken",
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		assert     func(*fakeT)
+		wantErrors int
+	}{{
+		name:   "ready unknown with reason",
+		assert: func(f *fakeT) { AssertReadyUnknownWithReason(f, status, "Deploying") },
+	}, {
+		name:       "ready unknown with another reason",
+		assert:     func(f *fakeT) { AssertReadyUnknownWithReason(f, status, "Failed") },
+		wantErrors: 1,
+	}, {
+		name:       "ready true",
+		assert:     func(f *fakeT) { AssertReadyTrue(f, status) },
+		wantErrors: 1,
+	}, {
+		name:   "condition true",
+		assert: func(f *fakeT) { AssertConditionTrue(f, status, "Foo") },
+	}, {
+		name:   "condition false with reason",
+		assert: func(f *fakeT) { AssertConditionFalseWithReason(f, status, "Bar", "Broken") },
+	}, {
+		name:       "missing condition",
+		assert:     func(f *fakeT) { AssertConditionTrue(f, status, "Baz") },
+		wantErrors: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := &fakeT{T: t}
+			test.assert(f)
+			if f.errors != test.wantErrors {
+				t.Errorf("Errors = %d, want %d", f.errors, test.wantErrors)
+			}
+		})
+	}
+}
+
+func TestConditionsDiff(t *testing.T) {
+	want := apis.Conditions{{
+		Type:   apis.ConditionReady,
+		Status: corev1.ConditionTrue,
+	}, {
+		Type:   "Foo",
+		Status: corev1.ConditionTrue,
+	}}
+	got := apis.Conditions{{
+		Type:               "Foo",
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: apis.VolatileTime{Inner: metav1.Now()},
+	}, {
+		Type:   apis.ConditionReady,
+		Status: corev1.ConditionTrue,
+	}}
+
+	if diff := ConditionsDiff(want, got); diff != "" {
+		t.Error("ConditionsDiff() (-want, +got):", diff)
+	}
+
+	got[0].Status = corev1.ConditionFalse
+	if diff := ConditionsDiff(want, got); diff == "" {
+		t.Error("ConditionsDiff() = \"\", wanted a diff")
+	}
+}
@@ -0,0 +1,40 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changeset
+
+import "runtime/debug"
+
+// readBuildInfo is overridden in tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// revisionFromBuildInfo returns the first 7 digits of the vcs.revision
+// build setting, if the binary was built with VCS stamping.
+func revisionFromBuildInfo() (string, bool) {
+	bi, ok := readBuildInfo()
+	if !ok {
+		return "", false
+	}
+	for _, s := range bi.Settings {
+		if s.Key == "vcs.revision" && commitIDRE.MatchString(s.Value) {
+			return s.Value[:7], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,69 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changeset
+
+import (
+	"os"
+	"runtime/debug"
+	"testing"
+)
+
+func TestBuildInfoFallback(t *testing.T) {
+	defer func() { readBuildInfo = debug.ReadBuildInfo }()
+	os.Unsetenv(koDataPathEnvName)
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Settings: []debug.BuildSetting{{
+			Key:   "vcs.revision",
+			Value: "a2d1bdfe929516d7da141aef68631a7ee6941b2d",
+		}}}, true
+	}
+	got, err := Get()
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if got != testCommitID {
+		t.Errorf("Get() = %s, want %s", got, testCommitID)
+	}
+	if got := Version(); got != testCommitID {
+		t.Errorf("Version() = %s, want %s", got, testCommitID)
+	}
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	if _, err := Get(); err == nil {
+		t.Error("Get() = nil, wanted an error without KO_DATA_PATH nor build info")
+	}
+	if got := Version(); got != Unknown {
+		t.Errorf("Version() = %s, want %s", got, Unknown)
+	}
+
+	// KO_DATA_PATH takes precedence.
+	os.Setenv(koDataPathEnvName, "testdata")
+	defer os.Unsetenv(koDataPathEnvName)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Settings: []debug.BuildSetting{{
+			Key:   "vcs.revision",
+			Value: "ffffffffffffffffffffffffffffffffffffffff",
+		}}}, true
+	}
+	if got, _ := Get(); got != testCommitID {
+		t.Errorf("Get() = %s, want %s", got, testCommitID)
+	}
+}
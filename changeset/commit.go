@@ -32,9 +32,34 @@ const (
 
 var commitIDRE = regexp.MustCompile(`^[a-f0-9]{40}$`)
 
+// Unknown is returned by Version when the commit ID can't be determined.
+const Unknown = "unknown"
+
 // Get tries to fetch the first 7 digitals of GitHub commit ID from HEAD file in
-// KO_DATA_PATH. If it fails, it returns the error it gets.
+// KO_DATA_PATH. If that fails, it falls back to the VCS revision stamped into
+// the binary by the go tool. If both fail, it returns the error it got from
+// KO_DATA_PATH.
 func Get() (string, error) {
+	commitID, err := getFromKoData()
+	if err != nil {
+		if rev, ok := revisionFromBuildInfo(); ok {
+			return rev, nil
+		}
+		return "", err
+	}
+	return commitID, nil
+}
+
+// Version returns the commit ID the binary was built from, as returned
+// by Get, or Unknown. It is meant for --version flags and log fields.
+func Version() string {
+	if commitID, err := Get(); err == nil {
+		return commitID
+	}
+	return Unknown
+}
+
+func getFromKoData() (string, error) {
 	data, err := readFileFromKoData(commitIDFile)
 	if err != nil {
 		return "", err
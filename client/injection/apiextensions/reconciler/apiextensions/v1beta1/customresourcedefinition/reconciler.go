@@ -114,6 +114,10 @@ type reconcilerImpl struct {
 	// skipStatusUpdates configures whether or not this reconciler automatically updates
 	// the status of the reconciled resource.
 	skipStatusUpdates bool
+
+	// patchStatus configures whether status changes are written as a merge
+	// patch of the changed fields, rather than a full status update.
+	patchStatus bool
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -169,6 +173,9 @@ func NewReconciler(ctx context.Context, logger *zap.SugaredLogger, client client
 		if opts.SkipStatusUpdates {
 			rec.skipStatusUpdates = true
 		}
+		if opts.PatchStatus {
+			rec.patchStatus = true
+		}
 	}
 
 	return rec
@@ -300,6 +307,9 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 }
 
 func (r *reconcilerImpl) updateStatus(ctx context.Context, existing *v1beta1.CustomResourceDefinition, desired *v1beta1.CustomResourceDefinition) error {
+	if r.patchStatus {
+		return r.patchStatusChanges(ctx, existing, desired)
+	}
 	existing = existing.DeepCopy()
 	return reconciler.RetryUpdateConflicts(func(attempts int) (err error) {
 		// The first iteration tries to use the injectionInformer's state, subsequent attempts fetch the latest state via API.
@@ -331,6 +341,21 @@ func (r *reconcilerImpl) updateStatus(ctx context.Context, existing *v1beta1.Cus
 	})
 }
 
+// patchStatusChanges writes the changes from the status of existing to the
+// status of desired as a merge patch to the status subresource.
+func (r *reconcilerImpl) patchStatusChanges(ctx context.Context, existing *v1beta1.CustomResourceDefinition, desired *v1beta1.CustomResourceDefinition) error {
+	patch, err := reconciler.StatusMergePatch(existing.Status, desired.Status)
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Debug("Patching status with: ", string(patch))
+
+	patcher := r.Client.ApiextensionsV1beta1().CustomResourceDefinitions()
+
+	_, err = patcher.Patch(ctx, desired.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	return err
+}
+
 // updateFinalizersFiltered will update the Finalizers of the resource.
 // TODO: this method could be generic and sync all finalizers. For now it only
 // updates defaultFinalizerName or its override.
@@ -44,9 +44,11 @@ func WithDuck(ctx context.Context) context.Context {
 			Type:         (&v1.Source{}).GetFullType(),
 			ResyncPeriod: controller.GetResyncPeriod(ctx),
 			StopChannel:  ctx.Done(),
+			Namespace:    injection.GetNamespaceScope(ctx),
 		},
 	}
-	return context.WithValue(ctx, Key{}, dif)
+	ctx = context.WithValue(ctx, Key{}, dif)
+	return duck.WithInformerFactory(ctx, &v1.Source{}, dif)
 }
 
 // Get extracts the typed informer from the context.
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	listersappsv1 "k8s.io/client-go/listers/apps/v1"
+	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/reconciler"
+)
+
+// scaler sets the replicas of the status of the deployments.
+type scaler struct{}
+
+func (scaler) ReconcileKind(_ context.Context, d *appsv1.Deployment) reconciler.Event {
+	d.Status.Replicas = 3
+	return nil
+}
+
+func TestReconcilePatchStatus(t *testing.T) {
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"},
+		Status:     appsv1.DeploymentStatus{Replicas: 1, ReadyReplicas: 1},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(d); err != nil {
+		t.Fatal("indexer.Add() =", err)
+	}
+	client := fake.NewSimpleClientset(d)
+	var patches []clientgotesting.PatchAction
+	client.PrependReactor("patch", "deployments", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		patches = append(patches, action.(clientgotesting.PatchAction))
+		return true, nil, nil
+	})
+
+	ctx := logtesting.TestContextWithLogger(t)
+	r := NewReconciler(ctx, logtesting.TestLogger(t), client, listersappsv1.NewDeploymentLister(indexer),
+		record.NewFakeRecorder(10), scaler{}, controller.Options{PatchStatus: true})
+	if err := r.(reconciler.LeaderAware).Promote(reconciler.UniversalBucket(), func(reconciler.Bucket, types.NamespacedName) {}); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	if err := r.Reconcile(ctx, "ns/name"); err != nil {
+		t.Fatal("Reconcile() =", err)
+	}
+
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("Unexpected update of %s", action.GetResource().Resource)
+		}
+	}
+	if len(patches) != 1 {
+		t.Fatalf("Got %d patches, want 1", len(patches))
+	}
+	p := patches[0]
+	if got, want := p.GetSubresource(), "status"; got != want {
+		t.Errorf("Patched subresource = %q, want %q", got, want)
+	}
+	if got, want := p.GetPatchType(), types.MergePatchType; got != want {
+		t.Errorf("Patch type = %q, want %q", got, want)
+	}
+	// Only the changed fields are patched.
+	if got, want := string(p.GetPatch()), `{"status":{"replicas":3}}`; got != want {
+		t.Errorf("Patch = %s, want %s", got, want)
+	}
+}
@@ -113,6 +113,10 @@ type reconcilerImpl struct {
 	// skipStatusUpdates configures whether or not this reconciler automatically updates
 	// the status of the reconciled resource.
 	skipStatusUpdates bool
+
+	// patchStatus configures whether status changes are written as a merge
+	// patch of the changed fields, rather than a full status update.
+	patchStatus bool
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -168,6 +172,9 @@ func NewReconciler(ctx context.Context, logger *zap.SugaredLogger, client kubern
 		if opts.SkipStatusUpdates {
 			rec.skipStatusUpdates = true
 		}
+		if opts.PatchStatus {
+			rec.patchStatus = true
+		}
 	}
 
 	return rec
@@ -299,6 +306,9 @@ func (r *reconcilerImpl) Reconcile(ctx context.Context, key string) error {
 }
 
 func (r *reconcilerImpl) updateStatus(ctx context.Context, existing *v1.Namespace, desired *v1.Namespace) error {
+	if r.patchStatus {
+		return r.patchStatusChanges(ctx, existing, desired)
+	}
 	existing = existing.DeepCopy()
 	return reconciler.RetryUpdateConflicts(func(attempts int) (err error) {
 		// The first iteration tries to use the injectionInformer's state, subsequent attempts fetch the latest state via API.
@@ -330,6 +340,21 @@ func (r *reconcilerImpl) updateStatus(ctx context.Context, existing *v1.Namespac
 	})
 }
 
+// patchStatusChanges writes the changes from the status of existing to the
+// status of desired as a merge patch to the status subresource.
+func (r *reconcilerImpl) patchStatusChanges(ctx context.Context, existing *v1.Namespace, desired *v1.Namespace) error {
+	patch, err := reconciler.StatusMergePatch(existing.Status, desired.Status)
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Debug("Patching status with: ", string(patch))
+
+	patcher := r.Client.CoreV1().Namespaces()
+
+	_, err = patcher.Patch(ctx, desired.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	return err
+}
+
 // updateFinalizersFiltered will update the Finalizers of the resource.
 // TODO: this method could be generic and sync all finalizers. For now it only
 // updates defaultFinalizerName or its override.
@@ -60,6 +60,9 @@ func Validate(genericArgs *args.GeneratorArgs) error {
 	if len(customArgs.ExternalVersionsInformersPackage) == 0 {
 		return fmt.Errorf("external versions informers package cannot be empty")
 	}
+	if len(customArgs.ForceKinds) != 0 && len(customArgs.ListersPackage) == 0 {
+		return fmt.Errorf("listers package cannot be empty when forcing reconciler kinds")
+	}
 
 	return nil
 }
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package args
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		custom  CustomArgs
+		wantErr bool
+	}{{
+		name:   "valid",
+		output: "example.com/client/injection",
+		custom: CustomArgs{
+			VersionedClientSetPackage:        "example.com/client/clientset/versioned",
+			ExternalVersionsInformersPackage: "example.com/client/informers/externalversions",
+		},
+	}, {
+		name:   "valid with forced kinds",
+		output: "example.com/client/injection",
+		custom: CustomArgs{
+			VersionedClientSetPackage:        "example.com/client/clientset/versioned",
+			ExternalVersionsInformersPackage: "example.com/client/informers/externalversions",
+			ListersPackage:                   "example.com/client/listers",
+			ForceKinds:                       "Foo,Bar",
+		},
+	}, {
+		name: "missing output package",
+		custom: CustomArgs{
+			VersionedClientSetPackage:        "example.com/client/clientset/versioned",
+			ExternalVersionsInformersPackage: "example.com/client/informers/externalversions",
+		},
+		wantErr: true,
+	}, {
+		name:   "missing clientset package",
+		output: "example.com/client/injection",
+		custom: CustomArgs{
+			ExternalVersionsInformersPackage: "example.com/client/informers/externalversions",
+		},
+		wantErr: true,
+	}, {
+		name:   "missing informers package",
+		output: "example.com/client/injection",
+		custom: CustomArgs{
+			VersionedClientSetPackage: "example.com/client/clientset/versioned",
+		},
+		wantErr: true,
+	}, {
+		name:   "forced kinds without listers",
+		output: "example.com/client/injection",
+		custom: CustomArgs{
+			VersionedClientSetPackage:        "example.com/client/clientset/versioned",
+			ExternalVersionsInformersPackage: "example.com/client/informers/externalversions",
+			ForceKinds:                       "Foo",
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			genericArgs, customArgs := NewDefaults()
+			genericArgs.OutputPackagePath = test.output
+			*customArgs = test.custom
+			if err := Validate(genericArgs); (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -82,10 +82,12 @@ func (g *duckGenerator) GenerateType(c *generator.Context, t *types.Type, w io.W
 		"version":                   namer.IC(g.groupVersion.Version.String()),
 		"injectionRegisterDuck":     c.Universe.Type(types.Name{Package: "knative.dev/pkg/injection", Name: "Default.RegisterDuck"}),
 		"getResyncPeriod":           c.Universe.Type(types.Name{Package: "knative.dev/pkg/controller", Name: "GetResyncPeriod"}),
+		"getNamespaceScope":         c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "GetNamespaceScope"}),
 		"dynamicGet":                c.Universe.Type(types.Name{Package: "knative.dev/pkg/injection/clients/dynamicclient", Name: "Get"}),
 		"duckTypedInformerFactory":  c.Universe.Type(types.Name{Package: "knative.dev/pkg/apis/duck", Name: "TypedInformerFactory"}),
 		"duckCachedInformerFactory": c.Universe.Type(types.Name{Package: "knative.dev/pkg/apis/duck", Name: "CachedInformerFactory"}),
 		"duckInformerFactory":       c.Universe.Type(types.Name{Package: "knative.dev/pkg/apis/duck", Name: "InformerFactory"}),
+		"duckWithInformerFactory":   c.Universe.Function(types.Name{Package: "knative.dev/pkg/apis/duck", Name: "WithInformerFactory"}),
 		"loggingFromContext": c.Universe.Function(types.Name{
 			Package: "knative.dev/pkg/logging",
 			Name:    "FromContext",
@@ -117,9 +119,11 @@ func WithDuck(ctx {{.contextContext|raw}}) {{.contextContext|raw}} {
 			Type:         (&{{.type|raw}}{}).GetFullType(),
 			ResyncPeriod: {{.getResyncPeriod|raw}}(ctx),
 			StopChannel:  ctx.Done(),
+			Namespace:    {{.getNamespaceScope|raw}}(ctx),
 		},
 	}
-	return context.WithValue(ctx, Key{}, dif)
+	ctx = context.WithValue(ctx, Key{}, dif)
+	return {{.duckWithInformerFactory|raw}}(ctx, &{{.type|raw}}{}, dif)
 }
 
 // Get extracts the typed informer from the context.
@@ -158,7 +158,7 @@ func (t Tags) NeedsReconciler(kind *types.Type, args *informergenargs.CustomArgs
 	// Overrides
 	kinds := strings.Split(args.ForceKinds, ",")
 	for _, k := range kinds {
-		if kind.Name.Name == k {
+		if kind.Name.Name == strings.TrimSpace(k) {
 			klog.V(5).Infof("Kind %s was forced to generate reconciler.", k)
 			return true
 		}
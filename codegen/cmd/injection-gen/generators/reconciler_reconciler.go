@@ -104,6 +104,7 @@ func (g *reconcilerReconcilerGenerator) GenerateType(c *generator.Context, t *ty
 		"reconcilerEvent":                c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "Event"}),
 		"reconcilerReconcilerEvent":      c.Universe.Type(types.Name{Package: "knative.dev/pkg/reconciler", Name: "ReconcilerEvent"}),
 		"reconcilerRetryUpdateConflicts": c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "RetryUpdateConflicts"}),
+		"reconcilerStatusMergePatch":     c.Universe.Function(types.Name{Package: "knative.dev/pkg/reconciler", Name: "StatusMergePatch"}),
 		"reconcilerConfigStore":          c.Universe.Type(types.Name{Name: "ConfigStore", Package: "knative.dev/pkg/reconciler"}),
 		// Deps
 		"clientsetInterface": c.Universe.Type(types.Name{Name: "Interface", Package: g.clientsetPkg}),
@@ -289,6 +290,10 @@ type reconcilerImpl struct {
 	// the status of the reconciled resource.
 	skipStatusUpdates bool
 
+	// patchStatus configures whether status changes are written as a merge
+	// patch of the changed fields, rather than a full status update.
+	patchStatus bool
+
 	{{if .hasClass}}
 	// classValue is the resource annotation[{{ .class }}] instance value this reconciler instance filters on.
 	classValue string
@@ -351,6 +356,9 @@ func NewReconciler(ctx {{.contextContext|raw}}, logger *{{.zapSugaredLogger|raw}
 		if opts.SkipStatusUpdates {
 			rec.skipStatusUpdates = true
 		}
+		if opts.PatchStatus {
+			rec.patchStatus = true
+		}
 	}
 
 	return rec
@@ -507,6 +515,9 @@ func (r *reconcilerImpl) Reconcile(ctx {{.contextContext|raw}}, key string) erro
 
 var reconcilerStatusFactory = `
 func (r *reconcilerImpl) updateStatus(ctx {{.contextContext|raw}}, existing *{{.type|raw}}, desired *{{.type|raw}}) error {
+	if r.patchStatus {
+		return r.patchStatusChanges(ctx, existing, desired)
+	}
 	existing = existing.DeepCopy()
 	return {{.reconcilerRetryUpdateConflicts|raw}}(func(attempts int) (err error) {
 		// The first iteration tries to use the injectionInformer's state, subsequent attempts fetch the latest state via API.
@@ -542,6 +553,24 @@ func (r *reconcilerImpl) updateStatus(ctx {{.contextContext|raw}}, existing *{{.
 		return err
 	})
 }
+
+// patchStatusChanges writes the changes from the status of existing to the
+// status of desired as a merge patch to the status subresource.
+func (r *reconcilerImpl) patchStatusChanges(ctx {{.contextContext|raw}}, existing *{{.type|raw}}, desired *{{.type|raw}}) error {
+	patch, err := {{.reconcilerStatusMergePatch|raw}}(existing.Status, desired.Status)
+	if err != nil {
+		return err
+	}
+	{{.loggingFromContext|raw}}(ctx).Debug("Patching status with: ", string(patch))
+
+	{{if .nonNamespaced}}
+	patcher := r.Client.{{.group}}{{.version}}().{{.type|apiGroup}}()
+	{{else}}
+	patcher := r.Client.{{.group}}{{.version}}().{{.type|apiGroup}}(desired.Namespace)
+	{{end}}
+	_, err = patcher.Patch(ctx, desired.Name, {{.typesMergePatchType|raw}}, patch, {{.metav1PatchOptions|raw}}{}, "status")
+	return err
+}
 `
 
 var reconcilerFinalizerFactory = `
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	clientgentypes "k8s.io/code-generator/cmd/client-gen/types"
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/types"
+)
+
+func TestReconcilerReconcilerGenerator(t *testing.T) {
+	tests := []struct {
+		name          string
+		typ           types.Name
+		group         string
+		nonNamespaced bool
+	}{{
+		name:  "deployment",
+		typ:   types.Name{Package: "k8s.io/api/apps/v1", Name: "Deployment"},
+		group: "apps",
+	}, {
+		name:          "namespace",
+		typ:           types.Name{Package: "k8s.io/api/core/v1", Name: "Namespace"},
+		group:         "core",
+		nonNamespaced: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &generator.Context{
+				Namers:   NameSystems(),
+				Universe: types.Universe{},
+			}
+			typ := c.Universe.Type(test.typ)
+			g := &reconcilerReconcilerGenerator{
+				outputPackage:  "knative.dev/pkg/client/injection/kube/reconciler/" + test.group + "/v1/" + test.name,
+				imports:        generator.NewImportTracker(),
+				typeToGenerate: typ,
+				clientsetPkg:   "k8s.io/client-go/kubernetes",
+				listerName:     typ.Name.Name + "Lister",
+				listerPkg:      "k8s.io/client-go/listers/" + test.group + "/v1",
+				groupGoName:    test.group,
+				groupVersion:   clientgentypes.GroupVersion{Group: clientgentypes.Group(test.group), Version: "v1"},
+				nonNamespaced:  test.nonNamespaced,
+			}
+			for name, n := range g.Namers(c) {
+				c.Namers[name] = n
+			}
+
+			var buf bytes.Buffer
+			if err := g.GenerateType(c, typ, &buf); err != nil {
+				t.Fatal("GenerateType() =", err)
+			}
+			got, err := format.Source(buf.Bytes())
+			if err != nil {
+				t.Fatal("format.Source() =", err)
+			}
+
+			want, err := ioutil.ReadFile(filepath.Join("testdata", test.name+"_reconciler.golden"))
+			if err != nil {
+				t.Fatal("Failed to load the golden output:", err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Error("GenerateType() (-want, +got):", diff)
+			}
+		})
+	}
+}
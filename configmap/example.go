@@ -21,6 +21,8 @@ import (
 	"hash/crc32"
 	"regexp"
 	"strings"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -42,3 +44,28 @@ var (
 func Checksum(value string) string {
 	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(sequentialNewlines.ReplaceAllString(strings.TrimSpace(value), `\n`))))
 }
+
+// ExampleModified returns whether the example block of the ConfigMap no longer
+// matches its checksum annotation. This typically means that a setting was
+// edited in the example rather than at the top-level of the data, where it
+// would have an effect. ConfigMaps without the annotation are never reported.
+func ExampleModified(configMap *corev1.ConfigMap) bool {
+	example, hasExample := configMap.Data[ExampleKey]
+	checksum, hasChecksum := configMap.Annotations[ExampleChecksumAnnotation]
+	return hasExample && hasChecksum && checksum != Checksum(example)
+}
+
+// WithoutExample returns the data with the example block stripped, for it
+// to be parsed. The data passed in is not modified.
+func WithoutExample(data map[string]string) map[string]string {
+	if _, ok := data[ExampleKey]; !ok {
+		return data
+	}
+	ret := make(map[string]string, len(data)-1)
+	for k, v := range data {
+		if k != ExampleKey {
+			ret[k] = v
+		}
+	}
+	return ret
+}
@@ -16,7 +16,13 @@ limitations under the License.
 
 package configmap
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 func TestChecksum(t *testing.T) {
 	tests := []struct {
@@ -51,3 +57,60 @@ func TestChecksum(t *testing.T) {
 		}
 	}
 }
+
+func TestExampleModified(t *testing.T) {
+	const example = "# The example.\nfoo: bar"
+	tests := []struct {
+		name        string
+		data        map[string]string
+		annotations map[string]string
+		want        bool
+	}{{
+		name: "no example",
+		data: map[string]string{"foo": "bar"},
+		annotations: map[string]string{
+			ExampleChecksumAnnotation: Checksum(example),
+		},
+	}, {
+		name: "no checksum",
+		data: map[string]string{ExampleKey: example},
+	}, {
+		name: "pristine",
+		data: map[string]string{ExampleKey: example},
+		annotations: map[string]string{
+			ExampleChecksumAnnotation: Checksum(example),
+		},
+	}, {
+		name: "edited",
+		data: map[string]string{ExampleKey: example + "\nfoo: baz"},
+		annotations: map[string]string{
+			ExampleChecksumAnnotation: Checksum(example),
+		},
+		want: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+				Data:       test.data,
+			}
+			if got := ExampleModified(cm); got != test.want {
+				t.Errorf("ExampleModified() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWithoutExample(t *testing.T) {
+	data := map[string]string{
+		ExampleKey: "foo: bar",
+		"foo":      "baz",
+	}
+	if got, want := WithoutExample(data), map[string]string{"foo": "baz"}; !cmp.Equal(got, want) {
+		t.Errorf("WithoutExample() = %v, want %v", got, want)
+	}
+	if _, ok := data[ExampleKey]; !ok {
+		t.Error("WithoutExample() modified the data passed in")
+	}
+}
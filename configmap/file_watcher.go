@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// dataDir is the symlink through which Kubernetes atomically swaps the
+// contents of ConfigMap volumes.
+const dataDir = "..data"
+
+// NewFileWatcher returns a FileWatcher for the ConfigMaps of the given
+// namespace mounted at the given paths, keyed by ConfigMap name, which
+// polls them for changes with the given period.
+func NewFileWatcher(namespace string, period time.Duration, mounts map[string]string) *FileWatcher {
+	return &FileWatcher{
+		ManualWatcher: ManualWatcher{Namespace: namespace},
+		period:        period,
+		mounts:        mounts,
+		data:          make(map[string]map[string]string, len(mounts)),
+	}
+}
+
+// FileWatcher is a Watcher that reads ConfigMaps from the volumes they are
+// mounted at, for components that cannot watch them through the API.
+type FileWatcher struct {
+	period time.Duration
+	mounts map[string]string
+
+	// data holds the last observed data of each watched ConfigMap.
+	// It is only accessed by Start and the polling loop it launches.
+	data map[string]map[string]string
+
+	// Embedding this struct allows us to reuse the logic
+	// of registering and notifying observers.
+	ManualWatcher
+}
+
+// Asserts that FileWatcher implements Watcher.
+var _ Watcher = (*FileWatcher)(nil)
+
+// Start implements Watcher. It fails if any of the watched ConfigMaps has
+// no mount path, or cannot be read.
+func (w *FileWatcher) Start(stopCh <-chan struct{}) error {
+	if w.period <= 0 {
+		return errors.New("polling period must be positive")
+	}
+
+	w.m.RLock()
+	names := make([]string, 0, len(w.observers))
+	for name := range w.observers {
+		names = append(names, name)
+	}
+	w.m.RUnlock()
+
+	for _, name := range names {
+		if _, ok := w.mounts[name]; !ok {
+			return fmt.Errorf("no mount path for ConfigMap %q", name)
+		}
+		if err := w.poll(name); err != nil {
+			return fmt.Errorf("failed to load ConfigMap %q: %w", name, err)
+		}
+	}
+
+	go wait.Until(func() {
+		for _, name := range names {
+			// Transient read errors keep the last observed state,
+			// the next poll tries again.
+			w.poll(name)
+		}
+	}, w.period, stopCh)
+	return nil
+}
+
+// poll reads the mounted ConfigMap and notifies the observers if its data
+// changed since the last time it was read.
+func (w *FileWatcher) poll(name string) error {
+	data, err := loadMount(w.mounts[name])
+	if err != nil {
+		return err
+	}
+	if last, ok := w.data[name]; ok && reflect.DeepEqual(last, data) {
+		return nil
+	}
+	w.data[name] = data
+	w.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: w.Namespace,
+			Name:      name,
+		},
+		Data: data,
+	})
+	return nil
+}
+
+// loadMount reads the data of a ConfigMap volume. When the volume is
+// managed by Kubernetes, the data is read from the directory the "..data"
+// symlink points to, so that a concurrent update cannot be half-read.
+func loadMount(dir string) (map[string]string, error) {
+	if resolved, err := filepath.EvalSymlinks(filepath.Join(dir, dataDir)); err == nil {
+		dir = resolved
+	}
+	return Load(dir)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// writeVolume lays out data the way Kubernetes does for ConfigMap volumes:
+// in a timestamped directory, which the "..data" symlink is atomically
+// swapped to, and which the keys link to through it.
+func writeVolume(t *testing.T, dir, version string, data map[string]string) {
+	t.Helper()
+	ts := filepath.Join(dir, "..ts-"+version)
+	if err := os.Mkdir(ts, 0700); err != nil {
+		t.Fatal("Mkdir() =", err)
+	}
+	for k, v := range data {
+		if err := ioutil.WriteFile(filepath.Join(ts, k), []byte(v), 0600); err != nil {
+			t.Fatal("WriteFile() =", err)
+		}
+		link := filepath.Join(dir, k)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join(dataDir, k), link); err != nil {
+				t.Fatal("Symlink() =", err)
+			}
+		}
+	}
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(ts), tmp); err != nil {
+		t.Fatal("Symlink() =", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, dataDir)); err != nil {
+		t.Fatal("Rename() =", err)
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-watcher")
+	if err != nil {
+		t.Fatal("TempDir() =", err)
+	}
+	defer os.RemoveAll(dir)
+	writeVolume(t, dir, "1", map[string]string{"foo": "bar"})
+
+	seen := make(chan *corev1.ConfigMap, 10)
+	w := NewFileWatcher("ns", 10*time.Millisecond, map[string]string{"config-test": dir})
+	w.Watch("config-test", func(cm *corev1.ConfigMap) {
+		seen <- cm
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err != nil {
+		t.Fatal("Start() =", err)
+	}
+
+	select {
+	case cm := <-seen:
+		if cm.Namespace != "ns" || cm.Name != "config-test" {
+			t.Errorf("Observed %s/%s, want: ns/config-test", cm.Namespace, cm.Name)
+		}
+		if want := map[string]string{"foo": "bar"}; !cmp.Equal(cm.Data, want) {
+			t.Errorf("Data = %v, want: %v", cm.Data, want)
+		}
+	default:
+		t.Fatal("The initial state was not observed by Start")
+	}
+
+	writeVolume(t, dir, "2", map[string]string{"foo": "baz"})
+	var got *corev1.ConfigMap
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		select {
+		case got = <-seen:
+			return true, nil
+		default:
+			return false, nil
+		}
+	}); err != nil {
+		t.Fatal("The update was not observed")
+	}
+	if want := map[string]string{"foo": "baz"}; !cmp.Equal(got.Data, want) {
+		t.Errorf("Data = %v, want: %v", got.Data, want)
+	}
+
+	// Unchanged data is not observed again.
+	time.Sleep(50 * time.Millisecond)
+	if len(seen) != 0 {
+		t.Errorf("Observed %d more times, want: 0", len(seen))
+	}
+}
+
+func TestFileWatcherErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		period time.Duration
+		mounts map[string]string
+	}{{
+		name:   "no mount",
+		period: time.Second,
+		mounts: map[string]string{},
+	}, {
+		name:   "missing mount",
+		period: time.Second,
+		mounts: map[string]string{"config-test": "/does/not/exist"},
+	}, {
+		name:   "bad period",
+		mounts: map[string]string{"config-test": os.TempDir()},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := NewFileWatcher("ns", test.period, test.mounts)
+			w.Watch("config-test", func(*corev1.ConfigMap) {})
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			if err := w.Start(stopCh); err == nil {
+				t.Error("Start() = nil, wanted an error")
+			}
+		})
+	}
+}
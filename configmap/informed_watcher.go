@@ -199,7 +199,15 @@ func (i *InformedWatcher) updateConfigMapEvent(o, n interface{}) {
 }
 
 func (i *InformedWatcher) deleteConfigMapEvent(obj interface{}) {
-	configMap := obj.(*corev1.ConfigMap)
+	// The informer may have missed the deletion, in which case it hands us
+	// the last state it knew of, wrapped in a tombstone.
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	configMap, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
 	if def, ok := i.defaults[configMap.Name]; ok {
 		i.OnChange(def)
 	}
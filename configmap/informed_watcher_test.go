@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 )
 
 type counter struct {
@@ -439,6 +440,49 @@ func TestDefaultConfigMapDeleted(t *testing.T) {
 	}
 }
 
+func TestDefaultConfigMapDeletedTombstone(t *testing.T) {
+	defaultFooCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+		},
+		Data: map[string]string{
+			"default": "from code",
+		},
+	}
+	fooCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+		},
+		Data: map[string]string{
+			"from": "k8s",
+		},
+	}
+
+	cmw := NewInformedWatcher(fakekubeclientset.NewSimpleClientset(), "default")
+	foo1 := &counter{name: "foo1"}
+	cmw.WatchWithDefault(*defaultFooCM, foo1.callback)
+
+	// A deletion the informer only learnt about on relist reverts to the default.
+	cmw.deleteConfigMapEvent(cache.DeletedFinalStateUnknown{
+		Key: "default/foo",
+		Obj: fooCM,
+	})
+	// Tombstones of anything else are ignored.
+	cmw.deleteConfigMapEvent(cache.DeletedFinalStateUnknown{
+		Key: "default/bar",
+		Obj: "not a ConfigMap",
+	})
+
+	if got, want := foo1.count(), 1; got != want {
+		t.Fatalf("foo1.count = %v, want %d", got, want)
+	}
+	if got, want := foo1.cfg[0].Data, defaultFooCM.Data; !equality.Semantic.DeepEqual(want, got) {
+		t.Errorf("config seen should have been '%v', actually '%v'", want, got)
+	}
+}
+
 func TestWatchWithDefaultAfterStart(t *testing.T) {
 	defaultFooCM := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
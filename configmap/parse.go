@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -47,7 +48,9 @@ func AsBool(key string, target *bool) ParseFunc {
 		if raw, ok := data[key]; ok {
 			val, err := strconv.ParseBool(raw)
 			*target = val // If err != nil — this is always false.
-			return err
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %w", key, err)
+			}
 		}
 		return nil
 	}
@@ -67,6 +70,20 @@ func AsInt32(key string, target *int32) ParseFunc {
 	}
 }
 
+// AsInt parses the value at key as an int into the target, if it exists.
+func AsInt(key string, target *int) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %w", key, err)
+			}
+			*target = val
+		}
+		return nil
+	}
+}
+
 // AsInt64 parses the value at key as an int64 into the target, if it exists.
 func AsInt64(key string, target *int64) ParseFunc {
 	return func(data map[string]string) error {
@@ -124,10 +141,17 @@ func AsDuration(key string, target *time.Duration) ParseFunc {
 }
 
 // AsStringSet parses the value at key as a sets.String (split by ',') into the target, if it exists.
+// Whitespace around the elements is trimmed, and empty elements are dropped.
 func AsStringSet(key string, target *sets.String) ParseFunc {
 	return func(data map[string]string) error {
 		if raw, ok := data[key]; ok {
-			*target = sets.NewString(strings.Split(raw, ",")...)
+			set := sets.NewString()
+			for _, s := range strings.Split(raw, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					set.Insert(s)
+				}
+			}
+			*target = set
 		}
 		return nil
 	}
@@ -190,11 +214,13 @@ func AsNamespacedName(key string, target *types.NamespacedName) ParseFunc {
 }
 
 // Parse parses the given map using the parser functions passed in.
+// All the parsers are run, and their errors are aggregated.
 func Parse(data map[string]string, parsers ...ParseFunc) error {
+	var errs []error
 	for _, parse := range parsers {
 		if err := parse(data); err != nil {
-			return err
+			errs = append(errs, err)
 		}
 	}
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
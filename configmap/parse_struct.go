@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// structTag is the struct tag AsStruct maps the fields by.
+const structTag = "configmap"
+
+// AsStruct parses the values of the keys named by the `configmap` tags of
+// the fields of the struct pointed to by target into those fields, if they
+// exist. The fields may have any of the types supported by the other
+// parse functions of this package, or be structs whose tag is a prefix
+// for the keys of their own fields. String fields accept a `oneof` option
+// restricting their values, e.g.:
+//
+//	type Config struct {
+//		Timeout time.Duration `configmap:"timeout"`
+//		Mode    string        `configmap:"mode,oneof=fast|safe"`
+//		Scaler  struct {
+//			Min int32 `configmap:"min"`
+//		} `configmap:"scaler."`
+//	}
+//
+// maps the "timeout", "mode" and "scaler.min" keys. Fields without the tag
+// are left untouched.
+func AsStruct(target interface{}) ParseFunc {
+	return func(data map[string]string) error {
+		v := reflect.ValueOf(target)
+		if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("target must be a pointer to a struct, was: %T", target)
+		}
+		parsers, err := structParsers("", v.Elem())
+		if err != nil {
+			return err
+		}
+		return Parse(data, parsers...)
+	}
+}
+
+// structParsers returns the parse functions for the tagged fields of the
+// struct, with the keys prefixed by prefix.
+func structParsers(prefix string, v reflect.Value) ([]ParseFunc, error) {
+	var parsers []ParseFunc
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag, ok := field.Tag.Lookup(structTag)
+		if !ok || field.PkgPath != "" {
+			// Untagged or unexported.
+			continue
+		}
+		name, opts := splitTag(tag)
+		key := prefix + name
+
+		switch p := v.Field(i).Addr().Interface().(type) {
+		case *string:
+			parsers = append(parsers, AsString(key, p))
+			if oneOf, ok := opts["oneof"]; ok {
+				parsers = append(parsers, OneOf(key, strings.Split(oneOf, "|")...))
+			}
+		case *bool:
+			parsers = append(parsers, AsBool(key, p))
+		case *int:
+			parsers = append(parsers, AsInt(key, p))
+		case *int32:
+			parsers = append(parsers, AsInt32(key, p))
+		case *int64:
+			parsers = append(parsers, AsInt64(key, p))
+		case *uint32:
+			parsers = append(parsers, AsUint32(key, p))
+		case *float64:
+			parsers = append(parsers, AsFloat64(key, p))
+		case *time.Duration:
+			parsers = append(parsers, AsDuration(key, p))
+		case *sets.String:
+			parsers = append(parsers, AsStringSet(key, p))
+		case **resource.Quantity:
+			parsers = append(parsers, AsQuantity(key, p))
+		case *types.NamespacedName:
+			parsers = append(parsers, AsNamespacedName(key, p))
+		case **types.NamespacedName:
+			parsers = append(parsers, AsOptionalNamespacedName(key, p))
+		default:
+			// Structs without tagged fields, e.g. resource.Quantity, would
+			// silently never be set.
+			if field.Type.Kind() != reflect.Struct || !hasTaggedFields(field.Type) {
+				return nil, fmt.Errorf("field %s has unsupported type %v", field.Name, field.Type)
+			}
+			nested, err := structParsers(key, v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			parsers = append(parsers, nested...)
+		}
+	}
+	return parsers, nil
+}
+
+// hasTaggedFields returns whether the struct type has exported fields with
+// the configmap tag.
+func hasTaggedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup(structTag); ok && t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTag splits a tag into the key name and its comma-separated options.
+func splitTag(tag string) (string, map[string]string) {
+	parts := strings.Split(tag, ",")
+	opts := make(map[string]string, len(parts)-1)
+	for _, opt := range parts[1:] {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+		} else {
+			opts[kv[0]] = ""
+		}
+	}
+	return parts[0], opts
+}
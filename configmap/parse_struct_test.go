@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type nestedTestConfig struct {
+	Min int32 `configmap:"min"`
+	Max int32 `configmap:"max"`
+}
+
+type structTestConfig struct {
+	Str      string                `configmap:"str"`
+	Mode     string                `configmap:"mode,oneof=fast|safe"`
+	Toggle   bool                  `configmap:"toggle"`
+	Int      int                   `configmap:"int"`
+	I64      int64                 `configmap:"i64"`
+	U32      uint32                `configmap:"u32"`
+	F64      float64               `configmap:"f64"`
+	Dur      time.Duration         `configmap:"dur"`
+	Set      sets.String           `configmap:"set"`
+	Qua      *resource.Quantity    `configmap:"qua"`
+	NSN      types.NamespacedName  `configmap:"nsn"`
+	OptNSN   *types.NamespacedName `configmap:"opt-nsn"`
+	Scaler   nestedTestConfig      `configmap:"scaler."`
+	Untagged string
+}
+
+func TestAsStruct(t *testing.T) {
+	fiveHundredM := resource.MustParse("500m")
+	tests := []struct {
+		name    string
+		conf    structTestConfig
+		data    map[string]string
+		want    structTestConfig
+		wantErr bool
+	}{{
+		name: "all good",
+		conf: structTestConfig{Untagged: "kept"},
+		data: map[string]string{
+			"str":        "foo",
+			"mode":       "safe",
+			"toggle":     "true",
+			"int":        "7",
+			"i64":        "2",
+			"u32":        "3",
+			"f64":        "1.5",
+			"dur":        "1m",
+			"set":        "a,b",
+			"qua":        "500m",
+			"nsn":        "ns/name",
+			"opt-nsn":    "other-ns/other-name",
+			"scaler.min": "1",
+			"scaler.max": "10",
+			"Untagged":   "ignored",
+		},
+		want: structTestConfig{
+			Str:      "foo",
+			Mode:     "safe",
+			Toggle:   true,
+			Int:      7,
+			I64:      2,
+			U32:      3,
+			F64:      1.5,
+			Dur:      time.Minute,
+			Set:      sets.NewString("a", "b"),
+			Qua:      &fiveHundredM,
+			NSN:      types.NamespacedName{Namespace: "ns", Name: "name"},
+			OptNSN:   &types.NamespacedName{Namespace: "other-ns", Name: "other-name"},
+			Scaler:   nestedTestConfig{Min: 1, Max: 10},
+			Untagged: "kept",
+		},
+	}, {
+		name: "defaults are kept",
+		conf: structTestConfig{Mode: "fast", Dur: time.Second},
+		data: map[string]string{},
+		want: structTestConfig{Mode: "fast", Dur: time.Second},
+	}, {
+		name: "not one of",
+		data: map[string]string{
+			"mode": "reckless",
+		},
+		want: structTestConfig{
+			Mode: "reckless",
+		},
+		wantErr: true,
+	}, {
+		name: "bad int",
+		data: map[string]string{
+			"int": "seven",
+		},
+		wantErr: true,
+	}, {
+		name: "nested error",
+		data: map[string]string{
+			"scaler.min": "many",
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Parse(test.data, AsStruct(&test.conf))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Parse() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if !cmp.Equal(test.conf, test.want) {
+				t.Errorf("parsed (-want, +got): %s", cmp.Diff(test.want, test.conf))
+			}
+		})
+	}
+}
+
+func TestAsStructBadTarget(t *testing.T) {
+	var unsupported struct {
+		Ints []int `configmap:"ints"`
+	}
+	var quantity struct {
+		Memory resource.Quantity `configmap:"memory"`
+	}
+	for name, target := range map[string]interface{}{
+		"not a pointer":       structTestConfig{},
+		"not a struct":        new(string),
+		"unsupported type":    &unsupported,
+		"struct without tags": &quantity,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := Parse(nil, AsStruct(target)); err == nil {
+				t.Error("Parse() = nil, wanted an error")
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package configmap
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 type testConfig struct {
 	str    string
 	toggle bool
+	i      int
 	i32    int32
 	i64    int64
 	u32    uint32
@@ -54,6 +56,7 @@ func TestParse(t *testing.T) {
 		data: map[string]string{
 			"test-string":   "foo.bar",
 			"test-bool":     "true",
+			"test-int":      "4",
 			"test-int32":    "1",
 			"test-int64":    "2",
 			"test-uint32":   "3",
@@ -68,6 +71,7 @@ func TestParse(t *testing.T) {
 		want: testConfig{
 			str:    "foo.bar",
 			toggle: true,
+			i:      4,
 			i32:    1,
 			i64:    2,
 			u32:    3,
@@ -104,12 +108,37 @@ func TestParse(t *testing.T) {
 			dur:    time.Minute,
 			qua:    &fiveHundredM,
 		},
+	}, {
+		name: "string set is trimmed",
+		data: map[string]string{
+			"test-set": " a, b ,,c ",
+		},
+		want: testConfig{
+			set: sets.NewString("a", "b", "c"),
+		},
+	}, {
+		name: "errors do not stop parsing",
+		data: map[string]string{
+			"test-int32":    "foo",
+			"test-duration": "1m",
+			"test-quantity": "foo",
+		},
+		want: testConfig{
+			dur: time.Minute,
+		},
+		expectErr: true,
 	}, {
 		name: "junk bool fails",
 		data: map[string]string{
 			"test-bool": "foo",
 		},
 		expectErr: true,
+	}, {
+		name: "int error",
+		data: map[string]string{
+			"test-int": "foo",
+		},
+		expectErr: true,
 	}, {
 		name: "int32 error",
 		data: map[string]string{
@@ -165,6 +194,7 @@ func TestParse(t *testing.T) {
 			if err := Parse(test.data,
 				AsString("test-string", &test.conf.str),
 				AsBool("test-bool", &test.conf.toggle),
+				AsInt("test-int", &test.conf.i),
 				AsInt32("test-int32", &test.conf.i32),
 				AsInt64("test-int64", &test.conf.i64),
 				AsUint32("test-uint32", &test.conf.u32),
@@ -184,3 +214,25 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAggregatesErrors(t *testing.T) {
+	var (
+		i32 int32
+		dur time.Duration
+	)
+	err := Parse(map[string]string{
+		"test-int32":    "foo",
+		"test-duration": "bar",
+	},
+		AsInt32("test-int32", &i32),
+		AsDuration("test-duration", &dur),
+	)
+	if err == nil {
+		t.Fatal("Parse() = nil, wanted an error")
+	}
+	for _, key := range []string{"test-int32", "test-duration"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Parse() = %v, wanted it to mention %q", err, key)
+		}
+	}
+}
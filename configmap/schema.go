@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Schema describes the data of a ConfigMap, so that it can be defaulted and
+// validated before it reaches its observers.
+type Schema struct {
+	// Defaults holds the values of the keys absent from the ConfigMap.
+	Defaults map[string]string
+
+	// Required lists the keys the ConfigMap must hold, after defaulting.
+	Required []string
+
+	// Validators are run against the data, after defaulting. Any of the
+	// parse functions of this package can be used, as well as OneOf and
+	// InRange.
+	Validators []ParseFunc
+}
+
+// Apply returns a copy of the ConfigMap with the defaults of the schema
+// applied, or an error if it doesn't conform to the schema.
+func (s *Schema) Apply(configMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	cm := configMap.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = make(map[string]string, len(s.Defaults))
+	}
+	for k, v := range s.Defaults {
+		if _, ok := cm.Data[k]; !ok {
+			cm.Data[k] = v
+		}
+	}
+
+	var errs []error
+	for _, k := range s.Required {
+		if _, ok := cm.Data[k]; !ok {
+			errs = append(errs, fmt.Errorf("missing required key %q", k))
+		}
+	}
+	if err := Parse(cm.Data, s.Validators...); err != nil {
+		errs = append(errs, err)
+	}
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		return nil, fmt.Errorf("ConfigMap %q is invalid: %w", cm.Name, err)
+	}
+	return cm, nil
+}
+
+// WithSchema returns an Observer that applies the schema to the ConfigMaps
+// it observes before passing them on to the given observers. ConfigMaps that
+// don't conform to the schema are reported to onError instead, so that the
+// observers keep acting on the last valid configuration.
+func WithSchema(schema Schema, onError func(*corev1.ConfigMap, error), o ...Observer) Observer {
+	return func(configMap *corev1.ConfigMap) {
+		cm, err := schema.Apply(configMap)
+		if err != nil {
+			onError(configMap, err)
+			return
+		}
+		for _, observer := range o {
+			observer(cm)
+		}
+	}
+}
+
+// OneOf validates that the value at key, if it exists, is one of allowed.
+func OneOf(key string, allowed ...string) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok && !sets.NewString(allowed...).Has(raw) {
+			return fmt.Errorf("failed to parse %q: %q is not one of %v", key, raw, allowed)
+		}
+		return nil
+	}
+}
+
+// InRange validates that the value at key, if it exists, is a number
+// between min and max, inclusive.
+func InRange(key string, min, max float64) ParseFunc {
+	return func(data map[string]string) error {
+		raw, ok := data[key]
+		if !ok {
+			return nil
+		}
+		val, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse %q: %w", key, err)
+		}
+		if val < min || val > max {
+			return fmt.Errorf("failed to parse %q: %v is not within [%v, %v]", key, val, min, max)
+		}
+		return nil
+	}
+}
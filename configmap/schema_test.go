@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSchemaApply(t *testing.T) {
+	schema := Schema{
+		Defaults: map[string]string{"mode": "fast", "replicas": "1"},
+		Required: []string{"name"},
+		Validators: []ParseFunc{
+			OneOf("mode", "fast", "slow"),
+			InRange("replicas", 1, 10),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    map[string]string
+		wantErr bool
+	}{{
+		name: "defaults applied",
+		data: map[string]string{"name": "foo"},
+		want: map[string]string{"name": "foo", "mode": "fast", "replicas": "1"},
+	}, {
+		name: "values kept",
+		data: map[string]string{"name": "foo", "mode": "slow", "replicas": "10"},
+		want: map[string]string{"name": "foo", "mode": "slow", "replicas": "10"},
+	}, {
+		name:    "missing required key",
+		data:    map[string]string{"mode": "slow"},
+		wantErr: true,
+	}, {
+		name:    "not one of",
+		data:    map[string]string{"name": "foo", "mode": "medium"},
+		wantErr: true,
+	}, {
+		name:    "out of range",
+		data:    map[string]string{"name": "foo", "replicas": "11"},
+		wantErr: true,
+	}, {
+		name:    "not a number",
+		data:    map[string]string{"name": "foo", "replicas": "many"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "config"},
+				Data:       test.data,
+			}
+			got, err := schema.Apply(cm)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Apply() = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !cmp.Equal(got.Data, test.want) {
+				t.Errorf("Apply() (-want, +got) = %s", cmp.Diff(test.want, got.Data))
+			}
+			if len(cm.Data) != len(test.data) {
+				t.Error("Apply() modified its input")
+			}
+		})
+	}
+}
+
+func TestWithSchema(t *testing.T) {
+	schema := Schema{
+		Defaults:   map[string]string{"mode": "fast"},
+		Validators: []ParseFunc{OneOf("mode", "fast", "slow")},
+	}
+
+	var observed *corev1.ConfigMap
+	var rejected error
+	observer := WithSchema(schema, func(_ *corev1.ConfigMap, err error) {
+		rejected = err
+	}, func(cm *corev1.ConfigMap) {
+		observed = cm
+	})
+
+	observer(&corev1.ConfigMap{})
+	if observed == nil || observed.Data["mode"] != "fast" {
+		t.Fatalf("observed = %v, want defaulted ConfigMap", observed)
+	}
+
+	observer(&corev1.ConfigMap{Data: map[string]string{"mode": "medium"}})
+	if rejected == nil {
+		t.Error("Invalid ConfigMap was not rejected")
+	}
+	if observed.Data["mode"] != "fast" {
+		t.Errorf("observed mode = %q, want last valid value", observed.Data["mode"])
+	}
+
+	rejected = nil
+	observer(&corev1.ConfigMap{Data: map[string]string{"mode": "slow"}})
+	if rejected != nil {
+		t.Errorf("Valid ConfigMap was rejected: %v", rejected)
+	}
+	if observed.Data["mode"] != "slow" {
+		t.Errorf("observed mode = %q, want slow", observed.Data["mode"])
+	}
+}
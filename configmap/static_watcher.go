@@ -18,6 +18,7 @@ package configmap
 
 import (
 	"fmt"
+	"sync"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -32,9 +33,13 @@ func NewStaticWatcher(cms ...*corev1.ConfigMap) *StaticWatcher {
 }
 
 // StaticWatcher is a Watcher with static ConfigMaps. Callbacks will
-// occur when Watch is invoked for a specific Observer
+// occur when Watch is invoked for a specific Observer, and whenever
+// the ConfigMap is replaced through OnChange.
 type StaticWatcher struct {
-	cfgs map[string]*corev1.ConfigMap
+	// Guards cfgs and observers.
+	m         sync.Mutex
+	cfgs      map[string]*corev1.ConfigMap
+	observers map[string][]Observer
 }
 
 // Asserts that fixedImpl implements Watcher.
@@ -42,17 +47,45 @@ var _ Watcher = (*StaticWatcher)(nil)
 
 // Watch implements Watcher
 func (di *StaticWatcher) Watch(name string, o ...Observer) {
+	di.m.Lock()
 	cm, ok := di.cfgs[name]
-	if ok {
-		for _, observer := range o {
-			observer(cm)
-		}
-	} else {
+	if !ok {
+		di.m.Unlock()
 		panic(fmt.Sprintf("Tried to watch unknown config with name %q", name))
 	}
+	if di.observers == nil {
+		di.observers = make(map[string][]Observer, 1)
+	}
+	di.observers[name] = append(di.observers[name], o...)
+	di.m.Unlock()
+
+	// The observers are invoked without the lock held, so that they may
+	// themselves call into the watcher.
+	for _, observer := range o {
+		observer(cm)
+	}
 }
 
 // Start implements Watcher
 func (di *StaticWatcher) Start(<-chan struct{}) error {
 	return nil
 }
+
+// OnChange replaces the ConfigMap of the same name, and invokes the
+// observers watching it with the new ConfigMap. This allows tests to
+// exercise how the observers react to updates. The ConfigMap must be
+// one the StaticWatcher was created with.
+func (di *StaticWatcher) OnChange(cm *corev1.ConfigMap) {
+	di.m.Lock()
+	if _, ok := di.cfgs[cm.Name]; !ok {
+		di.m.Unlock()
+		panic(fmt.Sprintf("Tried to change unknown config with name %q", cm.Name))
+	}
+	di.cfgs[cm.Name] = cm
+	observers := append([]Observer(nil), di.observers[cm.Name]...)
+	di.m.Unlock()
+
+	for _, observer := range observers {
+		observer(cm)
+	}
+}
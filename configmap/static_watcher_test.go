@@ -70,3 +70,88 @@ func TestUnknownConfigMapName(t *testing.T) {
 	cm := NewStaticWatcher()
 	cm.Watch("unknown", func(*corev1.ConfigMap) {})
 }
+
+func TestStaticWatcherOnChange(t *testing.T) {
+	fooCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-system",
+			Name:      "foo",
+		},
+		Data: map[string]string{"key": "before"},
+	}
+	barCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-system",
+			Name:      "bar",
+		},
+	}
+
+	cm := NewStaticWatcher(fooCM, barCM)
+	foo := &counter{name: "foo"}
+	cm.Watch("foo", foo.callback)
+	bar := &counter{name: "bar"}
+	cm.Watch("bar", bar.callback)
+
+	updated := fooCM.DeepCopy()
+	updated.Data["key"] = "after"
+	cm.OnChange(updated)
+
+	if got, want := foo.count(), 2; got != want {
+		t.Fatalf("foo.count = %v, want %v", got, want)
+	}
+	if got, want := foo.cfg[1].Data["key"], "after"; got != want {
+		t.Errorf("Observed key = %q, want %q", got, want)
+	}
+	if got, want := bar.count(), 1; got != want {
+		t.Errorf("bar.count = %v, want %v", got, want)
+	}
+
+	// Observers registered later see the latest version.
+	late := &counter{name: "late"}
+	cm.Watch("foo", late.callback)
+	if got, want := late.cfg[0].Data["key"], "after"; got != want {
+		t.Errorf("Observed key = %q, want %q", got, want)
+	}
+}
+
+func TestStaticWatcherOnChangeUnknown(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected calling OnChange with an unknown configmap name to panic")
+		}
+	}()
+
+	cm := NewStaticWatcher()
+	cm.OnChange(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unknown"}})
+}
+
+func TestStaticWatcherReentrantObserver(t *testing.T) {
+	fooCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-system",
+			Name:      "foo",
+		},
+		Data: map[string]string{"key": "before"},
+	}
+
+	cm := NewStaticWatcher(fooCM)
+	late := &counter{name: "late"}
+	registered := false
+	cm.Watch("foo", func(*corev1.ConfigMap) {
+		// Watching from within an observer must not deadlock.
+		if !registered {
+			registered = true
+			cm.Watch("foo", late.callback)
+		}
+	})
+	if got, want := late.count(), 1; got != want {
+		t.Fatalf("late.count = %v, want %v", got, want)
+	}
+
+	updated := fooCM.DeepCopy()
+	updated.Data["key"] = "after"
+	cm.OnChange(updated)
+	if got, want := late.count(), 2; got != want {
+		t.Errorf("late.count = %v, want %v", got, want)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package configmap
 
 import (
+	"fmt"
 	"reflect"
 	"sync/atomic"
 
@@ -54,6 +55,9 @@ type UntypedStore struct {
 	constructors map[string]reflect.Value
 
 	onAfterStore []func(name string, value interface{})
+
+	// onChange holds the callbacks registered through OnChange, by config name.
+	onChange map[string][]func(value interface{})
 }
 
 // NewUntypedStore creates an UntypedStore with given name,
@@ -88,6 +92,7 @@ func NewUntypedStore(
 		storages:     make(map[string]*atomic.Value),
 		constructors: make(map[string]reflect.Value),
 		onAfterStore: onAfterStore,
+		onChange:     make(map[string][]func(interface{})),
 	}
 
 	for configName, constructor := range constructors {
@@ -122,6 +127,30 @@ func (s *UntypedStore) UntypedLoad(name string) interface{} {
 	return storage.Load()
 }
 
+// UntypedLoadAll returns a snapshot of the constructed values of all the
+// ConfigMaps seen so far, keyed by ConfigMap name.
+func (s *UntypedStore) UntypedLoadAll() map[string]interface{} {
+	snapshot := make(map[string]interface{}, len(s.storages))
+	for name, storage := range s.storages {
+		if value := storage.Load(); value != nil {
+			snapshot[name] = value
+		}
+	}
+	return snapshot
+}
+
+// OnChange registers a callback to be invoked with the value constructed
+// from the named ConfigMap, whenever it differs from the value stored before.
+// Unlike the onAfterStore callbacks, it is not invoked for updates of the
+// ConfigMap that do not change the constructed value, nor for other configs.
+// Callbacks must be registered before the store starts watching.
+func (s *UntypedStore) OnChange(name string, f func(value interface{})) {
+	if _, ok := s.storages[name]; !ok {
+		panic(fmt.Sprintf("no constructor for config %q", name))
+	}
+	s.onChange[name] = append(s.onChange[name], f)
+}
+
 // OnConfigChanged will invoke the mapped constructor against
 // a Kubernetes ConfigMap. If successful it will be stored.
 // If construction fails during the first appearance the store
@@ -130,6 +159,11 @@ func (s *UntypedStore) UntypedLoad(name string) interface{} {
 func (s *UntypedStore) OnConfigChanged(c *corev1.ConfigMap) {
 	name := c.ObjectMeta.Name
 
+	if ExampleModified(c) {
+		s.warnf("%s config %q has a modified %q block, which has no effect. Settings belong at the top-level of the data",
+			s.name, name, ExampleKey)
+	}
+
 	storage := s.storages[name]
 	constructor := s.constructors[name]
 
@@ -152,9 +186,27 @@ func (s *UntypedStore) OnConfigChanged(c *corev1.ConfigMap) {
 	}
 
 	s.logger.Infof("%s config %q config was added or updated: %#v", s.name, name, result)
+	previous := storage.Load()
 	storage.Store(result)
 
+	if !reflect.DeepEqual(previous, result) {
+		for _, f := range s.onChange[name] {
+			f(result)
+		}
+	}
+
 	for _, f := range s.onAfterStore {
 		f(name, result)
 	}
 }
+
+// warnf logs at warning level if the logger supports it, at info level otherwise.
+func (s *UntypedStore) warnf(format string, args ...interface{}) {
+	if wl, ok := s.logger.(interface {
+		Warnf(string, ...interface{})
+	}); ok {
+		wl.Warnf(format, args...)
+		return
+	}
+	s.logger.Infof(format, args...)
+}
@@ -257,3 +257,102 @@ var _ Watcher = (*mockWatcher)(nil)
 var sortStrings = cmpopts.SortSlices(func(x, y string) bool {
 	return x < y
 })
+
+type warningsLogger struct {
+	Logger
+	warnings []string
+}
+
+func (l *warningsLogger) Warnf(format string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func TestStoreWarnsOnModifiedExample(t *testing.T) {
+	logger := &warningsLogger{Logger: TestLogger(t)}
+	store := NewUntypedStore("name", logger, Constructors{config1: constructor})
+
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: config1,
+			Annotations: map[string]string{
+				ExampleChecksumAnnotation: Checksum("foo: bar"),
+			},
+		},
+		Data: map[string]string{ExampleKey: "foo: bar"},
+	})
+	if got := len(logger.warnings); got != 0 {
+		t.Errorf("Got %d warnings for a pristine example, want: 0", got)
+	}
+
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: config1,
+			Annotations: map[string]string{
+				ExampleChecksumAnnotation: Checksum("foo: bar"),
+			},
+		},
+		Data: map[string]string{ExampleKey: "foo: baz"},
+	})
+	if got := len(logger.warnings); got != 1 {
+		t.Errorf("Got %d warnings for a modified example, want: 1", got)
+	}
+}
+
+func TestStoreOnChange(t *testing.T) {
+	dataConstructor := func(c *corev1.ConfigMap) (map[string]string, error) {
+		return c.Data, nil
+	}
+	store := NewUntypedStore("name", TestLogger(t), Constructors{
+		config1: dataConstructor,
+		config2: dataConstructor,
+	})
+
+	var changes []interface{}
+	store.OnChange(config1, func(value interface{}) {
+		changes = append(changes, value)
+	})
+
+	for _, cm := range []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config1},
+		Data:       map[string]string{"foo": "bar"},
+	}, {
+		// Same data, e.g. only the labels changed.
+		ObjectMeta: metav1.ObjectMeta{Name: config1, Labels: map[string]string{"a": "b"}},
+		Data:       map[string]string{"foo": "bar"},
+	}, {
+		// Another config.
+		ObjectMeta: metav1.ObjectMeta{Name: config2},
+		Data:       map[string]string{"foo": "baz"},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: config1},
+		Data:       map[string]string{"foo": "baz"},
+	}} {
+		store.OnConfigChanged(cm)
+	}
+
+	want := []interface{}{
+		map[string]string{"foo": "bar"},
+		map[string]string{"foo": "baz"},
+	}
+	if !cmp.Equal(changes, want) {
+		t.Errorf("OnChange callbacks (-want, +got): %s", cmp.Diff(want, changes))
+	}
+
+	wantAll := map[string]interface{}{
+		config1: map[string]string{"foo": "baz"},
+		config2: map[string]string{"foo": "baz"},
+	}
+	if got := store.UntypedLoadAll(); !cmp.Equal(got, wantAll) {
+		t.Errorf("UntypedLoadAll() (-want, +got): %s", cmp.Diff(wantAll, got))
+	}
+}
+
+func TestStoreOnChangeUnknownConfig(t *testing.T) {
+	store := NewUntypedStore("name", TestLogger(t), Constructors{config1: constructor})
+	defer func() {
+		if recover() == nil {
+			t.Error("OnChange() for an unknown config did not panic")
+		}
+	}()
+	store.OnChange(config2, func(interface{}) {})
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// ErrorBackoff is the backoff policy for keys whose reconciliation failed
+// with a given class of errors, e.g. conflicts or rate limiting by a remote
+// API, which typically call for very different retry delays.
+type ErrorBackoff struct {
+	// Matches returns whether the error belongs to the class of errors
+	// this policy applies to, e.g. apierrors.IsConflict. If nil, the policy
+	// applies to all errors.
+	Matches func(error) bool
+
+	// RateLimiter returns how long to wait before retrying the key.
+	RateLimiter workqueue.RateLimiter
+}
+
+// backoffFor returns the rate limiter of the first of the policies that
+// matches the error, or nil if none does.
+func backoffFor(policies []ErrorBackoff, err error) workqueue.RateLimiter {
+	for _, p := range policies {
+		if p.Matches == nil || p.Matches(err) {
+			return p.RateLimiter
+		}
+	}
+	return nil
+}
+
+// forget resets the backoff of the key, in the queue as well as in all the
+// error backoff policies.
+func (c *Impl) forget(q *twoLaneQueue, key types.NamespacedName) {
+	q.Forget(key)
+	for _, p := range c.errorBackoffs {
+		p.RateLimiter.Forget(key)
+	}
+}
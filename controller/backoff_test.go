@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+// recordingRateLimiter retries immediately and records the keys it was
+// asked about.
+type recordingRateLimiter struct {
+	mu        sync.Mutex
+	whens     []interface{}
+	forgotten []interface{}
+}
+
+func (rl *recordingRateLimiter) When(item interface{}) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.whens = append(rl.whens, item)
+	return 0
+}
+
+func (rl *recordingRateLimiter) Forget(item interface{}) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.forgotten = append(rl.forgotten, item)
+}
+
+func (rl *recordingRateLimiter) NumRequeues(interface{}) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.whens)
+}
+
+func (rl *recordingRateLimiter) counts() (int, int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.whens), len(rl.forgotten)
+}
+
+func TestBackoffFor(t *testing.T) {
+	conflicts, throttled := &recordingRateLimiter{}, &recordingRateLimiter{}
+	policies := []ErrorBackoff{{
+		Matches:     apierrs.IsConflict,
+		RateLimiter: conflicts,
+	}, {
+		Matches:     apierrs.IsTooManyRequests,
+		RateLimiter: throttled,
+	}}
+
+	gr := schema.GroupResource{Resource: "foos"}
+	tests := []struct {
+		name string
+		err  error
+		want *recordingRateLimiter
+	}{{
+		name: "conflict",
+		err:  apierrs.NewConflict(gr, "bar", errors.New("stale")),
+		want: conflicts,
+	}, {
+		name: "too many requests",
+		err:  apierrs.NewTooManyRequests("slow down", 60),
+		want: throttled,
+	}, {
+		name: "other",
+		err:  errors.New("boom"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := backoffFor(policies, test.err)
+			if test.want == nil {
+				if got != nil {
+					t.Errorf("backoffFor() = %v, wanted nil", got)
+				}
+				return
+			}
+			if got != test.want {
+				t.Errorf("backoffFor() = %p, wanted %p", got, test.want)
+			}
+		})
+	}
+
+	// A policy without Matches catches all the errors that reach it.
+	catchAll := &recordingRateLimiter{}
+	policies = append(policies, ErrorBackoff{RateLimiter: catchAll})
+	if got := backoffFor(policies, errors.New("boom")); got != catchAll {
+		t.Errorf("backoffFor() = %p, wanted %p", got, catchAll)
+	}
+	if got := backoffFor(policies, apierrs.NewTooManyRequests("slow down", 60)); got != throttled {
+		t.Errorf("backoffFor() = %p, wanted %p", got, throttled)
+	}
+}
+
+// conflictingReconciler fails with a conflict the given number of times.
+type conflictingReconciler struct {
+	mu       sync.Mutex
+	failures int
+}
+
+func (cr *conflictingReconciler) Reconcile(context.Context, string) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.failures > 0 {
+		cr.failures--
+		return apierrs.NewConflict(schema.GroupResource{Resource: "foos"}, "bar", errors.New("stale"))
+	}
+	return nil
+}
+
+func TestErrorBackoffs(t *testing.T) {
+	rl := &recordingRateLimiter{}
+	impl := NewImplFull(&conflictingReconciler{failures: 2}, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Testing",
+		Reporter:      &FakeStatsReporter{},
+		ErrorBackoffs: []ErrorBackoff{{
+			Matches:     apierrs.IsConflict,
+			RateLimiter: rl,
+		}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go impl.RunContext(ctx, 1)
+
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+
+	// The two conflicts are backed off by the policy, and the success
+	// resets the backoff of the key.
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		whens, forgotten := rl.counts()
+		return whens == 2 && forgotten == 1, nil
+	}); err != nil {
+		whens, forgotten := rl.counts()
+		t.Errorf("When() calls = %d, Forget() calls = %d, wanted 2 and 1", whens, forgotten)
+	}
+}
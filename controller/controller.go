@@ -56,6 +56,13 @@ var (
 	// may adjust this process-wide default.  For finer control, invoke
 	// Run on the controller directly.
 	DefaultThreadsPerController = 2
+
+	// DefaultDrainTimeout bounds how long a controller waits on shutdown
+	// for the keys it is processing to finish reconciling, when not set
+	// through ControllerOptions. Zero or negative values mean waiting for as
+	// long as it takes, which is the default. Controller binaries may set
+	// this process-wide default to bound their shutdown.
+	DefaultDrainTimeout time.Duration
 )
 
 // Reconciler is the interface that controller implementations are expected
@@ -74,6 +81,17 @@ func PassNew(f func(interface{})) func(interface{}, interface{}) {
 	}
 }
 
+// PassNewIf is like PassNew, but only delegates to f when the predicate
+// over the old and new objects holds, e.g. to skip updates that did not
+// change anything the handler cares about.
+func PassNewIf(pred func(oldObj, newObj interface{}) bool, f func(interface{})) func(interface{}, interface{}) {
+	return func(first, second interface{}) {
+		if pred(first, second) {
+			f(second)
+		}
+	}
+}
+
 // HandleAll wraps the provided handler function into a cache.ResourceEventHandler
 // that sends all events to the given handler.  For Updates, only the new object
 // is forwarded.
@@ -108,7 +126,7 @@ func FilterGroupVersionKind(gvk schema.GroupVersionKind) func(obj interface{}) b
 // schema.GroupVersionKind of the controlling resources.
 func FilterControllerGVK(gvk schema.GroupVersionKind) func(obj interface{}) bool {
 	return func(obj interface{}) bool {
-		object, ok := obj.(metav1.Object)
+		object, ok := kmeta.DeletionHandlingObject(obj)
 		if !ok {
 			return false
 		}
@@ -134,7 +152,7 @@ func FilterGroupKind(gk schema.GroupKind) func(obj interface{}) bool {
 // schema.GroupKind of the controlling resources.
 func FilterControllerGK(gk schema.GroupKind) func(obj interface{}) bool {
 	return func(obj interface{}) bool {
-		object, ok := obj.(metav1.Object)
+		object, ok := kmeta.DeletionHandlingObject(obj)
 		if !ok {
 			return false
 		}
@@ -155,7 +173,7 @@ func FilterControllerGK(gk schema.GroupKind) func(obj interface{}) bool {
 // cache.FilteringResourceEventHandler that filter based on a name.
 func FilterWithName(name string) func(obj interface{}) bool {
 	return func(obj interface{}) bool {
-		if object, ok := obj.(metav1.Object); ok {
+		if object, ok := kmeta.DeletionHandlingObject(obj); ok {
 			return name == object.GetName()
 		}
 		return false
@@ -166,7 +184,7 @@ func FilterWithName(name string) func(obj interface{}) bool {
 // cache.FilteringResourceEventHandler that filter based on a namespace and a name.
 func FilterWithNameAndNamespace(namespace, name string) func(obj interface{}) bool {
 	return func(obj interface{}) bool {
-		if object, ok := obj.(metav1.Object); ok {
+		if object, ok := kmeta.DeletionHandlingObject(obj); ok {
 			return name == object.GetName() &&
 				namespace == object.GetNamespace()
 		}
@@ -185,14 +203,18 @@ type Impl struct {
 	// from the workqueue to process.  Public for testing.
 	Reconciler Reconciler
 
-	// workQueue is a rate-limited two-lane work queue.
+	// workQueue exposes all the shards as a single rate-limited work queue.
 	// This is used to queue work to be processed instead of performing it as
 	// soon as a change happens. This means we can ensure we only process a
 	// fixed amount of resources at a time, and makes it easy to ensure we are
 	// never processing the same item simultaneously in two different workers.
 	// The slow queue is used for global resync and other background processes
 	// which are not required to complete at the highest priority.
-	workQueue *twoLaneQueue
+	workQueue *shardedWorkQueue
+
+	// shards holds all the work queues of the controller, keys are spread
+	// across them by hash.
+	shards shardedQueue
 
 	// Sugared logger is easier to use but is not as performant as the
 	// raw logger. In performance critical paths, call logger.Desugar()
@@ -203,6 +225,13 @@ type Impl struct {
 
 	// StatsReporter is used to send common controller metrics.
 	statsReporter StatsReporter
+
+	// drainTimeout bounds how long RunContext waits for the workers
+	// to wrap up once its context is cancelled.
+	drainTimeout time.Duration
+
+	// errorBackoffs are the backoff policies for specific classes of errors.
+	errorBackoffs []ErrorBackoff
 }
 
 // ControllerOptions encapsulates options for creating a new controller,
@@ -212,6 +241,27 @@ type ControllerOptions struct {
 	Logger        *zap.SugaredLogger
 	Reporter      StatsReporter
 	RateLimiter   workqueue.RateLimiter
+
+	// NewRateLimiter creates the rate limiter of each shard. It takes
+	// precedence over RateLimiter, which is shared by all the shards.
+	NewRateLimiter func() workqueue.RateLimiter
+
+	// Shards is the number of work queues keys are spread across by hash.
+	// Each shard has its own workers and, unless a single RateLimiter is
+	// set, its own rate limiter, so a key that is slow to reconcile can only
+	// delay the keys sharing its shard. Values below 2 result in a single queue.
+	Shards int
+
+	// ErrorBackoffs are the backoff policies for keys that failed to reconcile
+	// with specific classes of errors. The first policy matching the error is
+	// used, and the keys failing with errors matched by none are rate limited
+	// by RateLimiter.
+	ErrorBackoffs []ErrorBackoff
+
+	// DrainTimeout bounds how long the controller waits on shutdown for
+	// the keys it is processing to finish reconciling. If unset,
+	// DefaultDrainTimeout is used, and if negative, there is no bound.
+	DrainTimeout time.Duration
 }
 
 // NewImpl instantiates an instance of our controller that will feed work to the
@@ -230,22 +280,31 @@ func NewImplWithStats(r Reconciler, logger *zap.SugaredLogger, workQueueName str
 // NewImplFull accepts the full set of options available to all controllers.
 func NewImplFull(r Reconciler, options ControllerOptions) *Impl {
 	logger := options.Logger.Named(options.WorkQueueName)
-	if options.RateLimiter == nil {
-		options.RateLimiter = workqueue.DefaultControllerRateLimiter()
-	}
 	if options.Reporter == nil {
 		options.Reporter = MustNewStatsReporter(options.WorkQueueName, options.Logger)
 	}
+	if options.DrainTimeout == 0 {
+		options.DrainTimeout = DefaultDrainTimeout
+	}
+	newRL := options.NewRateLimiter
+	if newRL == nil && options.RateLimiter != nil {
+		newRL = func() workqueue.RateLimiter { return options.RateLimiter }
+	}
+	shards := newShardedQueue(options.WorkQueueName, options.Shards, newRL)
 	return &Impl{
 		Name:          options.WorkQueueName,
 		Reconciler:    r,
-		workQueue:     newTwoLaneWorkQueue(options.WorkQueueName, options.RateLimiter),
+		workQueue:     newShardedWorkQueue(shards),
+		shards:        shards,
 		logger:        logger,
 		statsReporter: options.Reporter,
+		drainTimeout:  options.DrainTimeout,
+		errorBackoffs: options.ErrorBackoffs,
 	}
 }
 
 // WorkQueue permits direct access to the work queue.
+// When the controller is sharded, it spans all the shards.
 func (c *Impl) WorkQueue() workqueue.RateLimitingInterface {
 	return c.workQueue
 }
@@ -264,10 +323,11 @@ func (c *Impl) EnqueueAfter(obj interface{}, after time.Duration) {
 // EnqueueSlowKey takes a resource, converts it into a namespace/name string,
 // and enqueues that key in the slow lane.
 func (c *Impl) EnqueueSlowKey(key types.NamespacedName) {
-	c.workQueue.SlowLane().Add(key)
+	q := c.shards.shardFor(key)
+	q.SlowLane().Add(key)
 	c.logger.With(zap.String(logkey.Key, key.String())).
 		Debugf("Adding to the slow queue %s (depth(total/slow): %d/%d)",
-			safeKey(key), c.workQueue.Len(), c.workQueue.SlowLane().Len())
+			safeKey(key), q.Len(), q.SlowLane().Len())
 }
 
 // EnqueueSlow extracts namesspeced name from the object and enqueues it on the slow
@@ -392,9 +452,10 @@ func (c *Impl) EnqueueNamespaceOf(obj interface{}) {
 
 // EnqueueKey takes a namespace/name string and puts it onto the work queue.
 func (c *Impl) EnqueueKey(key types.NamespacedName) {
-	c.workQueue.Add(key)
+	q := c.shards.shardFor(key)
+	q.Add(key)
 	c.logger.With(zap.String(logkey.Key, key.String())).
-		Debugf("Adding to queue %s (depth: %d)", safeKey(key), c.workQueue.Len())
+		Debugf("Adding to queue %s (depth: %d)", safeKey(key), q.Len())
 }
 
 // MaybeEnqueueBucketKey takes a Bucket and namespace/name string and puts it onto
@@ -408,24 +469,30 @@ func (c *Impl) MaybeEnqueueBucketKey(bkt reconciler.Bucket, key types.Namespaced
 // EnqueueKeyAfter takes a namespace/name string and schedules its execution in
 // the work queue after given delay.
 func (c *Impl) EnqueueKeyAfter(key types.NamespacedName, delay time.Duration) {
-	c.workQueue.AddAfter(key, delay)
+	q := c.shards.shardFor(key)
+	q.AddAfter(key, delay)
 	c.logger.With(zap.String(logkey.Key, key.String())).
-		Debugf("Adding to queue %s (delay: %v, depth: %d)", safeKey(key), delay, c.workQueue.Len())
+		Debugf("Adding to queue %s (delay: %v, depth: %d)", safeKey(key), delay, q.Len())
 }
 
 // RunContext starts the controller's worker threads, the number of which is threadiness.
 // If the context has been decorated for LeaderElection, then an elector is built and run.
 // It then blocks until the context is cancelled, at which point it shuts down its
-// internal work queue and waits for workers to finish processing their current
-// work items.
+// internal work queue, so that no new keys are accepted, and waits for workers to
+// finish processing their current work items, for at most the drain timeout.
 func (c *Impl) RunContext(ctx context.Context, threadiness int) error {
 	sg := sync.WaitGroup{}
 	defer func() {
-		c.workQueue.ShutDown()
-		for c.workQueue.Len() > 0 {
-			time.Sleep(time.Millisecond * 100)
-		}
-		sg.Wait()
+		c.shards.ShutDown()
+		drained := make(chan struct{})
+		go func() {
+			defer close(drained)
+			for c.shards.Len() > 0 {
+				time.Sleep(time.Millisecond * 100)
+			}
+			sg.Wait()
+		}()
+		c.waitForDrain(drained)
 		runtime.HandleCrash()
 	}()
 
@@ -442,15 +509,18 @@ func (c *Impl) RunContext(ctx context.Context, threadiness int) error {
 		}()
 	}
 
-	// Launch workers to process resources that get enqueued to our workqueue.
+	// Launch workers to process resources that get enqueued to our workqueues,
+	// threadiness of them per shard.
 	c.logger.Info("Starting controller and workers")
-	for i := 0; i < threadiness; i++ {
-		sg.Add(1)
-		go func() {
-			defer sg.Done()
-			for c.processNextWorkItem() {
-			}
-		}()
+	for _, q := range c.shards {
+		for i := 0; i < threadiness; i++ {
+			sg.Add(1)
+			go func(q *twoLaneQueue) {
+				defer sg.Done()
+				for c.processNextWorkItem(q) {
+				}
+			}(q)
+		}
 	}
 
 	c.logger.Info("Started workers")
@@ -460,6 +530,20 @@ func (c *Impl) RunContext(ctx context.Context, threadiness int) error {
 	return nil
 }
 
+// waitForDrain blocks until drained is closed, or the drain timeout elapses.
+func (c *Impl) waitForDrain(drained <-chan struct{}) {
+	if c.drainTimeout <= 0 {
+		<-drained
+		return
+	}
+	select {
+	case <-drained:
+	case <-time.After(c.drainTimeout):
+		c.logger.Warnf("Timed out after %v waiting for in-flight reconciles to finish (depth: %d)",
+			c.drainTimeout, c.shards.Len())
+	}
+}
+
 // DEPRECATED use RunContext instead.
 func (c *Impl) Run(threadiness int, stopCh <-chan struct{}) error {
 	// Create a context that is cancelled when the stopCh is called.
@@ -471,36 +555,46 @@ func (c *Impl) Run(threadiness int, stopCh <-chan struct{}) error {
 	return c.RunContext(ctx, threadiness)
 }
 
-// processNextWorkItem will read a single work item off the workqueue and
-// attempt to process it, by calling Reconcile on our Reconciler.
-func (c *Impl) processNextWorkItem() bool {
-	obj, shutdown := c.workQueue.Get()
+// reportReconcile reports the reconcile operation on key, with its result
+// when the StatsReporter implements ReconcileResultReporter.
+func (c *Impl) reportReconcile(duration time.Duration, result string, key types.NamespacedName) {
+	if rr, ok := c.statsReporter.(ReconcileResultReporter); ok {
+		rr.ReportReconcileResult(duration, result, key)
+		return
+	}
+	success := falseString
+	if result == ReconcileSuccess {
+		success = trueString
+	}
+	c.statsReporter.ReportReconcile(duration, success)
+}
+
+// processNextWorkItem will read a single work item off the given workqueue
+// and attempt to process it, by calling Reconcile on our Reconciler.
+func (c *Impl) processNextWorkItem(q *twoLaneQueue) bool {
+	obj, shutdown := q.Get()
 	if shutdown {
 		return false
 	}
 	key := obj.(types.NamespacedName)
 	keyStr := safeKey(key)
 
-	c.logger.Debugf("Processing from queue %s (depth: %d)", safeKey(key), c.workQueue.Len())
+	c.logger.Debugf("Processing from queue %s (depth: %d)", safeKey(key), q.Len())
 
 	startTime := time.Now()
 	// Send the metrics for the current queue depth
-	c.statsReporter.ReportQueueDepth(int64(c.workQueue.Len()))
+	c.statsReporter.ReportQueueDepth(int64(c.shards.Len()))
 
-	var err error
+	result := ReconcileSuccess
 	defer func() {
-		status := trueString
-		if err != nil {
-			status = falseString
-		}
-		c.statsReporter.ReportReconcile(time.Since(startTime), status)
+		c.reportReconcile(time.Since(startTime), result, key)
 
 		// We call Done here so the workqueue knows we have finished
 		// processing this item. We also must remember to call Forget if
 		// reconcile succeeds. If a transient error occurs, we do not call
 		// Forget and put the item back to the queue with an increased
 		// delay.
-		c.workQueue.Done(key)
+		q.Done(key)
 	}()
 
 	// Embed the key into the logger and attach that to the context we pass
@@ -510,34 +604,48 @@ func (c *Impl) processNextWorkItem() bool {
 
 	// Run Reconcile, passing it the namespace/name string of the
 	// resource to be synced.
-	if err = c.Reconciler.Reconcile(ctx, keyStr); err != nil {
-		c.handleErr(err, key)
+	if err := c.Reconciler.Reconcile(ctx, keyStr); err != nil {
+		if c.handleErr(q, err, key) {
+			result = ReconcileRequeue
+		} else {
+			result = ReconcileError
+		}
 		logger.Info("Reconcile failed. Time taken: ", time.Since(startTime))
 		return true
 	}
 
 	// Finally, if no error occurs we Forget this item so it does not
 	// have any delay when another change happens.
-	c.workQueue.Forget(key)
+	c.forget(q, key)
 	logger.Info("Reconcile succeeded. Time taken: ", time.Since(startTime))
 
 	return true
 }
 
-func (c *Impl) handleErr(err error, key types.NamespacedName) {
+// handleErr requeues the key unless the error is permanent or the queue is
+// shutting down, and returns whether the key was requeued.
+func (c *Impl) handleErr(q *twoLaneQueue, err error, key types.NamespacedName) bool {
 	c.logger.Errorw("Reconcile error", zap.Error(err))
 
 	// Re-queue the key if it's a transient error.
 	// We want to check that the queue is shutting down here
 	// since controller Run might have exited by now (since while this item was
 	// being processed, queue.Len==0).
-	if !IsPermanentError(err) && !c.workQueue.ShuttingDown() {
-		c.workQueue.AddRateLimited(key)
-		c.logger.Debugf("Requeuing key %s due to non-permanent error (depth: %d)", safeKey(key), c.workQueue.Len())
-		return
+	if !IsPermanentError(err) && !q.ShuttingDown() {
+		if rl := backoffFor(c.errorBackoffs, err); rl != nil {
+			q.AddAfter(key, rl.When(key))
+		} else {
+			q.AddRateLimited(key)
+		}
+		c.logger.Debugf("Requeuing key %s due to non-permanent error (depth: %d)", safeKey(key), q.Len())
+		return true
+	}
+	if IsPermanentError(err) {
+		c.logger.Debugf("Not requeuing key %s due to permanent error", safeKey(key))
 	}
 
-	c.workQueue.Forget(key)
+	c.forget(q, key)
+	return false
 }
 
 // GlobalResync enqueues into the slow lane all objects from the passed SharedInformer
@@ -562,8 +670,14 @@ func (c *Impl) FilteredGlobalResync(f func(interface{}) bool, si cache.SharedInf
 
 // NewPermanentError returns a new instance of permanentError.
 // Users can wrap an error as permanentError with this in reconcile
-// when they do not expect the key to get re-queued.
+// when they do not expect the key to get re-queued. The key will be
+// processed again once the object changes, or on the next resync.
+// Wrapping a nil error returns nil, so that the result may be returned
+// from Reconcile unconditionally.
 func NewPermanentError(err error) error {
+	if err == nil {
+		return nil
+	}
 	return permanentError{e: err}
 }
 
@@ -61,6 +61,27 @@ func TestPassNew(t *testing.T) {
 	})(oldObj, newObj)
 }
 
+func TestPassNewIf(t *testing.T) {
+	calls := 0
+	f := PassNewIf(func(o, n interface{}) bool {
+		return o != n
+	}, func(got interface{}) {
+		calls++
+		if newObj != got.(string) {
+			t.Errorf("PassNewIf() = %v, wanted %v", got, newObj)
+		}
+	})
+
+	f(newObj, newObj)
+	if calls != 0 {
+		t.Errorf("PassNewIf() called the handler %d times for an unchanged object, wanted 0", calls)
+	}
+	f(oldObj, newObj)
+	if calls != 1 {
+		t.Errorf("PassNewIf() called the handler %d times for a changed object, wanted 1", calls)
+	}
+}
+
 func TestHandleAll(t *testing.T) {
 	ha := HandleAll(func(got interface{}) {
 		if newObj != got.(string) {
@@ -125,6 +146,18 @@ func TestFilterWithNameAndNamespace(t *testing.T) {
 			},
 		},
 		want: true,
+	}, {
+		name: "tombstone matches",
+		input: cache.DeletedFinalStateUnknown{
+			Key: "test-namespace/test-name",
+			Obj: &Resource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "test-namespace",
+				},
+			},
+		},
+		want: true,
 	}}
 
 	for _, test := range tests {
@@ -184,6 +217,18 @@ func TestFilterWithName(t *testing.T) {
 			},
 		},
 		want: true,
+	}, {
+		name: "tombstone matches",
+		input: cache.DeletedFinalStateUnknown{
+			Key: "test-namespace/test-name",
+			Obj: &Resource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-name",
+					Namespace: "test-namespace",
+				},
+			},
+		},
+		want: true,
 	}}
 
 	for _, test := range tests {
@@ -1092,6 +1137,136 @@ func TestStartAndShutdownWithWork(t *testing.T) {
 	checkStats(t, reporter, 1, 0, 1, trueString)
 }
 
+type blockingReconciler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (br *blockingReconciler) Reconcile(context.Context, string) error {
+	close(br.started)
+	<-br.release
+	return nil
+}
+
+func TestDefaultDrainTimeout(t *testing.T) {
+	newImpl := func() *Impl {
+		impl := NewImplFull(&nopReconciler{}, ControllerOptions{
+			Logger:        TestLogger(t),
+			WorkQueueName: "Testing",
+			Reporter:      &FakeStatsReporter{},
+		})
+		impl.WorkQueue().ShutDown()
+		return impl
+	}
+
+	// The drain is unbounded unless the binary bounds it.
+	if got := newImpl().drainTimeout; got > 0 {
+		t.Errorf("drainTimeout = %v, want it unbounded", got)
+	}
+
+	defer func(d time.Duration) { DefaultDrainTimeout = d }(DefaultDrainTimeout)
+	DefaultDrainTimeout = 30 * time.Second
+	if got, want := newImpl().drainTimeout, 30*time.Second; got != want {
+		t.Errorf("drainTimeout = %v, want: %v", got, want)
+	}
+}
+
+func TestShutdownDrainTimeout(t *testing.T) {
+	r := &blockingReconciler{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	t.Cleanup(func() { close(r.release) })
+	impl := NewImplFull(r, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Testing",
+		Reporter:      &FakeStatsReporter{},
+		DrainTimeout:  50 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	doneCh := make(chan struct{})
+
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+
+	go func() {
+		defer close(doneCh)
+		StartAll(ctx, impl)
+	}()
+
+	select {
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the reconcile to start.")
+	case <-r.started:
+	}
+	cancel()
+
+	select {
+	case <-time.After(time.Second):
+		t.Error("Timed out waiting for controller to give up draining.")
+	case <-doneCh:
+		// We expect the drain to be abandoned while Reconcile is still blocked.
+	}
+}
+
+// keyedBlockingReconciler blocks reconciling the given key until released
+// and counts the reconciles of all the other keys.
+type keyedBlockingReconciler struct {
+	blocked string
+	release chan struct{}
+	count   atomic.Int32
+}
+
+func (kr *keyedBlockingReconciler) Reconcile(_ context.Context, key string) error {
+	if key == kr.blocked {
+		<-kr.release
+		return nil
+	}
+	kr.count.Inc()
+	return nil
+}
+
+func TestShardedSlowKey(t *testing.T) {
+	slow := types.NamespacedName{Namespace: "foo", Name: "slow"}
+	r := &keyedBlockingReconciler{
+		blocked: slow.String(),
+		release: make(chan struct{}),
+	}
+	t.Cleanup(func() { close(r.release) })
+	impl := NewImplFull(r, ControllerOptions{
+		Logger:        TestLogger(t),
+		WorkQueueName: "Testing",
+		Reporter:      &FakeStatsReporter{},
+		Shards:        2,
+		DrainTimeout:  10 * time.Millisecond,
+	})
+
+	// Pick keys that land in the other shard than the slow one.
+	var others []types.NamespacedName
+	for i := 0; len(others) < 3; i++ {
+		key := types.NamespacedName{Namespace: "foo", Name: fmt.Sprint("bar-", i)}
+		if impl.shards.shardFor(key) != impl.shards.shardFor(slow) {
+			others = append(others, key)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go impl.RunContext(ctx, 1)
+
+	impl.EnqueueKey(slow)
+	for _, key := range others {
+		impl.EnqueueKey(key)
+	}
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return r.count.Load() == int32(len(others)), nil
+	}); err != nil {
+		t.Errorf("reconcile count = %d, wanted %d", r.count.Load(), len(others))
+	}
+}
+
 type fakeError struct{}
 
 var _ error = (*fakeError)(nil)
@@ -1119,6 +1294,10 @@ func TestPermanentError(t *testing.T) {
 	if !errors.As(permErr, &unwrapErr) {
 		t.Errorf("Could not unwrap %T from permanentError", unwrapErr)
 	}
+
+	if err := NewPermanentError(nil); err != nil {
+		t.Errorf("NewPermanentError(nil) = %v, wanted nil", err)
+	}
 }
 
 type errorReconciler struct{}
@@ -1331,6 +1510,46 @@ func checkStats(t *testing.T, r *FakeStatsReporter, reportCount, lastQueueDepth,
 	}
 }
 
+// resultStatsReporter is a FakeStatsReporter also implementing
+// ReconcileResultReporter.
+type resultStatsReporter struct {
+	FakeStatsReporter
+	results []string
+	keys    []types.NamespacedName
+}
+
+func (r *resultStatsReporter) ReportReconcileResult(_ time.Duration, result string, key types.NamespacedName) error {
+	r.results = append(r.results, result)
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func TestReportReconcileThroughResultReporter(t *testing.T) {
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+
+	// A StatsReporter only implementing ReportReconcile gets the success.
+	reporter := &FakeStatsReporter{}
+	impl := NewImplWithStats(&nopReconciler{}, TestLogger(t), "Testing", reporter)
+	impl.reportReconcile(time.Millisecond, ReconcileRequeue, key)
+	if rd := reporter.GetReconcileData(); len(rd) != 1 || rd[0].Success != falseString {
+		t.Errorf("GetReconcileData() = %v, wanted a single %q report", rd, falseString)
+	}
+
+	// A ReconcileResultReporter gets the result and the key instead.
+	resultReporter := &resultStatsReporter{}
+	impl = NewImplWithStats(&nopReconciler{}, TestLogger(t), "Testing", resultReporter)
+	impl.reportReconcile(time.Millisecond, ReconcileRequeue, key)
+	if got, want := resultReporter.results, []string{ReconcileRequeue}; !cmp.Equal(got, want) {
+		t.Errorf("ReportReconcileResult() results = %v, wanted %v", got, want)
+	}
+	if got, want := resultReporter.keys, []types.NamespacedName{key}; !cmp.Equal(got, want) {
+		t.Errorf("ReportReconcileResult() keys = %v, wanted %v", got, want)
+	}
+	if rd := resultReporter.GetReconcileData(); len(rd) != 0 {
+		t.Errorf("GetReconcileData() = %v, wanted no reports", rd)
+	}
+}
+
 type fixedInformer struct {
 	m    sync.Mutex
 	sunk bool
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/logging"
+)
+
+// NewEventRecorder returns the record.EventRecorder attached to the context,
+// if any, or else builds one that records events on behalf of the named
+// component through the given client. Events are also logged with the
+// context's logger. The recorder stops recording once the context is done.
+//
+// Tests attach a record.FakeRecorder to the context, so reconcilers built
+// with NewEventRecorder have their events captured by the table tests.
+func NewEventRecorder(ctx context.Context, kc kubernetes.Interface, component string) record.EventRecorder {
+	if recorder := GetEventRecorder(ctx); recorder != nil {
+		return recorder
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Debug("Creating event broadcaster")
+	eventBroadcaster := record.NewBroadcaster()
+	watches := []watch.Interface{
+		eventBroadcaster.StartLogging(logger.Named("event-broadcaster").Infof),
+		eventBroadcaster.StartRecordingToSink(
+			&typedcorev1.EventSinkImpl{Interface: kc.CoreV1().Events("")}),
+	}
+	go func() {
+		<-ctx.Done()
+		for _, w := range watches {
+			w.Stop()
+		}
+	}()
+	return eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
+}
+
+// Normalf records an event of type Normal about the object with the
+// record.EventRecorder attached to the context. It is a no-op when the
+// context carries no recorder.
+func Normalf(ctx context.Context, obj runtime.Object, reason, messageFmt string, args ...interface{}) {
+	eventf(ctx, obj, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// Warningf records an event of type Warning about the object with the
+// record.EventRecorder attached to the context. It is a no-op when the
+// context carries no recorder.
+func Warningf(ctx context.Context, obj runtime.Object, reason, messageFmt string, args ...interface{}) {
+	eventf(ctx, obj, corev1.EventTypeWarning, reason, messageFmt, args...)
+}
+
+func eventf(ctx context.Context, obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder := GetEventRecorder(ctx); recorder != nil {
+		recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+	}
+}
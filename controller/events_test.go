@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestNewEventRecorderFromContext(t *testing.T) {
+	fake := record.NewFakeRecorder(10)
+	ctx := WithEventRecorder(context.Background(), fake)
+
+	if got := NewEventRecorder(ctx, fakekube.NewSimpleClientset(), "component"); got != fake {
+		t.Errorf("NewEventRecorder() = %v, wanted the recorder from the context", got)
+	}
+}
+
+func TestNewEventRecorder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if got := NewEventRecorder(ctx, fakekube.NewSimpleClientset(), "component"); got == nil {
+		t.Error("NewEventRecorder() = nil, wanted a recorder")
+	}
+}
+
+func TestNormalfWarningf(t *testing.T) {
+	obj := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"}}
+
+	// Without a recorder these are no-ops.
+	Normalf(context.Background(), obj, "Reason", "message %d", 1)
+	Warningf(context.Background(), obj, "Reason", "message %d", 2)
+
+	fake := record.NewFakeRecorder(10)
+	ctx := WithEventRecorder(context.Background(), fake)
+	Normalf(ctx, obj, "Created", "created %q", "name")
+	Warningf(ctx, obj, "Failed", "failed %q", "name")
+	close(fake.Events)
+
+	var got []string
+	for e := range fake.Events {
+		got = append(got, e)
+	}
+	want := []string{`Normal Created created "name"`, `Warning Failed failed "name"`}
+	if len(got) != len(want) {
+		t.Fatalf("Events = %v, wanted %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Event[%d] = %q, wanted %q", i, got[i], want[i])
+		}
+	}
+}
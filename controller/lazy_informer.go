@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"sync"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// LazyInformer wraps an Informer that should only run once it is actually
+// needed, e.g. an informer for an optional integration whose CRDs may not
+// even be installed. It may be passed to StartInformers and RunInformers like
+// any other Informer, but the wrapped informer only starts running (and being
+// waited on) once Start is called.
+type LazyInformer struct {
+	inner Informer
+
+	once  sync.Once
+	start chan struct{}
+}
+
+var _ Informer = (*LazyInformer)(nil)
+
+// NewLazyInformer returns a LazyInformer deferring the start of inf until
+// Start is called.
+func NewLazyInformer(inf Informer) *LazyInformer {
+	return &LazyInformer{
+		inner: inf,
+		start: make(chan struct{}),
+	}
+}
+
+// Run implements Informer. It blocks until either Start is called, in which
+// case the wrapped informer is run with stopCh, or stopCh is closed.
+func (li *LazyInformer) Run(stopCh <-chan struct{}) {
+	select {
+	case <-li.start:
+		li.inner.Run(stopCh)
+	case <-stopCh:
+	}
+}
+
+// HasSynced implements Informer. Until Start is called there is nothing to
+// wait on, so it reports true; afterwards it defers to the wrapped informer.
+func (li *LazyInformer) HasSynced() bool {
+	if !li.Started() {
+		return true
+	}
+	return li.inner.HasSynced()
+}
+
+// Started returns whether Start has been called.
+func (li *LazyInformer) Started() bool {
+	select {
+	case <-li.start:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start lets the wrapped informer run and waits for its cache to sync, or for
+// stopCh to be closed. It is safe to call Start multiple times, and from
+// multiple goroutines, e.g. from every reconciliation needing the informer.
+// Note that the informer only runs once Run has been called as well, which
+// StartInformers and RunInformers take care of.
+func (li *LazyInformer) Start(stopCh <-chan struct{}) error {
+	li.once.Do(func() {
+		close(li.start)
+	})
+	if !cache.WaitForCacheSync(stopCh, li.inner.HasSynced) {
+		return errors.New("failed to wait for the lazy informer cache to sync")
+	}
+	return nil
+}
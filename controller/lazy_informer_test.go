@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+type runTrackingInformer struct {
+	fixedInformer
+	ran atomic.Bool
+}
+
+func (ri *runTrackingInformer) Run(stopCh <-chan struct{}) {
+	ri.ran.Store(true)
+	ri.fixedInformer.Run(stopCh)
+}
+
+func TestLazyInformer(t *testing.T) {
+	inner := &runTrackingInformer{}
+	li := NewLazyInformer(inner)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	// Not needed yet, so StartInformers doesn't wait for it.
+	if err := StartInformers(stopCh, li); err != nil {
+		t.Fatal("StartInformers() =", err)
+	}
+	if li.Started() {
+		t.Error("Started() = true before Start")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if inner.ran.Load() {
+		t.Error("The wrapped informer ran before Start")
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- li.Start(stopCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		t.Fatal("Start() returned before the informer synced:", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if !li.Started() {
+		t.Error("Started() = false after Start")
+	}
+	if li.HasSynced() {
+		t.Error("HasSynced() = true before the wrapped informer synced")
+	}
+
+	inner.ToggleSynced(true)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Error("Start() =", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for Start() to return")
+	}
+	if !inner.ran.Load() {
+		t.Error("The wrapped informer never ran")
+	}
+
+	// Starting again is a no-op.
+	if err := li.Start(stopCh); err != nil {
+		t.Error("Start() =", err)
+	}
+}
+
+func TestLazyInformerNeverStarted(t *testing.T) {
+	inner := &runTrackingInformer{}
+	li := NewLazyInformer(inner)
+
+	stopCh := make(chan struct{})
+	wait, err := RunInformers(stopCh, li)
+	if err != nil {
+		t.Fatal("RunInformers() =", err)
+	}
+	close(stopCh)
+	wait()
+
+	if inner.ran.Load() {
+		t.Error("The wrapped informer ran without Start")
+	}
+}
+
+func TestLazyInformerStartStopped(t *testing.T) {
+	li := NewLazyInformer(&runTrackingInformer{})
+
+	stopCh := make(chan struct{})
+	close(stopCh)
+	if err := li.Start(stopCh); err == nil {
+		t.Error("Start() = nil, wanted an error when stopped before syncing")
+	}
+}
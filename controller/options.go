@@ -35,6 +35,13 @@ type Options struct {
 	// SkipStatusUpdates configures this reconciler to either do automated status
 	// updates (default) or skip them if this is set to true.
 	SkipStatusUpdates bool
+
+	// PatchStatus configures this reconciler to write status changes as a JSON
+	// merge patch of the changed fields to the status subresource, instead of
+	// updating the whole status. Patches carry no resourceVersion, so they
+	// don't conflict with concurrent writers, but they are computed against
+	// the status in the informer cache.
+	PatchStatus bool
 }
 
 // OptionsFn is a callback method signature that accepts an Impl and returns
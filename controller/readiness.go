@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.uber.org/atomic"
+)
+
+// InformersHaveSynced returns whether all of the passed informers have
+// synchronized their caches.
+func InformersHaveSynced(informers ...Informer) bool {
+	for _, informer := range informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// NewReadinessHandler returns an http.Handler suitable as the target of a
+// readiness probe. It answers 503 Service Unavailable until all of the passed
+// informers have synchronized their caches, and 200 OK from then on, so that
+// pods don't receive traffic or leases while working off of cold caches.
+func NewReadinessHandler(informers ...Informer) http.Handler {
+	// Informers never go back to being unsynced, so latch once we are ready
+	// instead of re-checking every informer on every probe.
+	ready := atomic.NewBool(false)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() {
+			if !InformersHaveSynced(informers...) {
+				http.Error(w, "informers have not synced", http.StatusServiceUnavailable)
+				return
+			}
+			ready.Store(true)
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "ok")
+	})
+}
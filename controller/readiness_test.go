@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/atomic"
+)
+
+type syncedInformer struct {
+	synced *atomic.Bool
+}
+
+func (si *syncedInformer) Run(<-chan struct{}) {}
+
+func (si *syncedInformer) HasSynced() bool {
+	return si.synced.Load()
+}
+
+func TestReadinessHandler(t *testing.T) {
+	first := &syncedInformer{synced: atomic.NewBool(true)}
+	second := &syncedInformer{synced: atomic.NewBool(false)}
+	handler := NewReadinessHandler(first, second)
+
+	probe := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+
+	if got, want := probe(), http.StatusServiceUnavailable; got != want {
+		t.Errorf("Probe before sync = %d, want: %d", got, want)
+	}
+	if InformersHaveSynced(first, second) {
+		t.Error("InformersHaveSynced() = true, want false")
+	}
+
+	second.synced.Store(true)
+	if got, want := probe(), http.StatusOK; got != want {
+		t.Errorf("Probe after sync = %d, want: %d", got, want)
+	}
+	if !InformersHaveSynced(first, second) {
+		t.Error("InformersHaveSynced() = false, want true")
+	}
+}
+
+func TestReadinessHandlerNoInformers(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Errorf("Probe = %d, want: %d", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	cm "knative.dev/pkg/configmap"
+)
+
+const (
+	resyncConfigMapNameEnv = "CONFIG_RESYNC_NAME"
+
+	// ResyncPeriodKey is the key of the informer resync period in the
+	// resync ConfigMap.
+	ResyncPeriodKey = "resync-period"
+)
+
+// ResyncConfigMapName returns the name of the ConfigMap to read the
+// informer resync period from.
+func ResyncConfigMapName() string {
+	if name := os.Getenv(resyncConfigMapNameEnv); name != "" {
+		return name
+	}
+	return "config-resync"
+}
+
+// NewResyncPeriodFromConfigMap returns the resync period set in the given
+// ConfigMap, or fallback if the ConfigMap is nil or doesn't set it.
+func NewResyncPeriodFromConfigMap(configMap *corev1.ConfigMap, fallback time.Duration) (time.Duration, error) {
+	if configMap == nil {
+		return fallback, nil
+	}
+	period := fallback
+	if err := cm.Parse(configMap.Data, cm.AsDuration(ResyncPeriodKey, &period)); err != nil {
+		return 0, err
+	}
+	if period <= 0 {
+		return 0, fmt.Errorf("%s: value must be positive, was: %v", ResyncPeriodKey, period)
+	}
+	return period, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewResyncPeriodFromConfigMap(t *testing.T) {
+	const fallback = 7 * time.Minute
+	tests := []struct {
+		name    string
+		cm      *corev1.ConfigMap
+		want    time.Duration
+		wantErr bool
+	}{{
+		name: "nil",
+		want: fallback,
+	}, {
+		name: "unset",
+		cm:   &corev1.ConfigMap{Data: map[string]string{"other": "thing"}},
+		want: fallback,
+	}, {
+		name: "set",
+		cm:   &corev1.ConfigMap{Data: map[string]string{ResyncPeriodKey: "30s"}},
+		want: 30 * time.Second,
+	}, {
+		name:    "malformed",
+		cm:      &corev1.ConfigMap{Data: map[string]string{ResyncPeriodKey: "often"}},
+		wantErr: true,
+	}, {
+		name:    "not positive",
+		cm:      &corev1.ConfigMap{Data: map[string]string{ResyncPeriodKey: "0s"}},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewResyncPeriodFromConfigMap(test.cm, fallback)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewResyncPeriodFromConfigMap() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("NewResyncPeriodFromConfigMap() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResyncConfigMapName(t *testing.T) {
+	if got, want := ResyncConfigMapName(), "config-resync"; got != want {
+		t.Errorf("ResyncConfigMapName() = %q, want: %q", got, want)
+	}
+
+	os.Setenv(resyncConfigMapNameEnv, "custom-resync")
+	defer os.Unsetenv(resyncConfigMapNameEnv)
+	if got, want := ResyncConfigMapName(), "custom-resync"; got != want {
+		t.Errorf("ResyncConfigMapName() = %q, want: %q", got, want)
+	}
+}
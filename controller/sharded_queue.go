@@ -0,0 +1,181 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// shardedQueue spreads keys across a number of independent two-lane queues
+// by the hash of the key, so that a key that is slow to reconcile only holds
+// up the keys that share its shard.
+type shardedQueue []*twoLaneQueue
+
+// newShardedQueue creates a shardedQueue with the given number of shards.
+// A single shard keeps the queue name as is, otherwise the shard index is
+// appended to it. Every shard gets its own rate limiter from newRL, so the
+// backoff of a key never leaks into other shards.
+func newShardedQueue(name string, shards int, newRL func() workqueue.RateLimiter) shardedQueue {
+	if shards < 1 {
+		shards = 1
+	}
+	if newRL == nil {
+		newRL = workqueue.DefaultControllerRateLimiter
+	}
+	sq := make(shardedQueue, shards)
+	for i := range sq {
+		n := name
+		if shards > 1 {
+			n += "-" + strconv.Itoa(i)
+		}
+		sq[i] = newTwoLaneWorkQueue(n, newRL())
+	}
+	return sq
+}
+
+// shardFor returns the shard responsible for the given key.
+func (sq shardedQueue) shardFor(key types.NamespacedName) *twoLaneQueue {
+	if len(sq) == 1 {
+		return sq[0]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key.String()))
+	return sq[h.Sum32()%uint32(len(sq))]
+}
+
+// shardOf returns the shard responsible for the given item. Items that are
+// not keys go to the first shard.
+func (sq shardedQueue) shardOf(item interface{}) *twoLaneQueue {
+	if key, ok := item.(types.NamespacedName); ok {
+		return sq.shardFor(key)
+	}
+	return sq[0]
+}
+
+// Len returns the sum of the lengths of all the shards.
+func (sq shardedQueue) Len() int {
+	l := 0
+	for _, q := range sq {
+		l += q.Len()
+	}
+	return l
+}
+
+// ShutDown shuts down all the shards.
+func (sq shardedQueue) ShutDown() {
+	for _, q := range sq {
+		q.ShutDown()
+	}
+}
+
+// ShuttingDown returns true if any of the shards is shutting down.
+func (sq shardedQueue) ShuttingDown() bool {
+	for _, q := range sq {
+		if q.ShuttingDown() {
+			return true
+		}
+	}
+	return false
+}
+
+// shardedWorkQueue exposes a shardedQueue as a single work queue. Operations
+// on an item are routed to the shard of the item, while Len, ShuttingDown
+// and ShutDown fan out to every shard.
+type shardedWorkQueue struct {
+	shardedQueue
+
+	// next is the shard Get looks at first, so that the shards take turns.
+	next uint32
+}
+
+var _ workqueue.RateLimitingInterface = (*shardedWorkQueue)(nil)
+
+// shardPollInterval is how often Get looks for an item in the shards when
+// all of them are empty.
+const shardPollInterval = 10 * time.Millisecond
+
+func newShardedWorkQueue(sq shardedQueue) *shardedWorkQueue {
+	return &shardedWorkQueue{shardedQueue: sq}
+}
+
+// Add implements workqueue.Interface.
+func (swq *shardedWorkQueue) Add(item interface{}) {
+	swq.shardOf(item).Add(item)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (swq *shardedWorkQueue) AddAfter(item interface{}, duration time.Duration) {
+	swq.shardOf(item).AddAfter(item, duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (swq *shardedWorkQueue) AddRateLimited(item interface{}) {
+	swq.shardOf(item).AddRateLimited(item)
+}
+
+// Forget implements workqueue.RateLimitingInterface.
+func (swq *shardedWorkQueue) Forget(item interface{}) {
+	swq.shardOf(item).Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimitingInterface.
+func (swq *shardedWorkQueue) NumRequeues(item interface{}) int {
+	return swq.shardOf(item).NumRequeues(item)
+}
+
+// Done implements workqueue.Interface.
+func (swq *shardedWorkQueue) Done(item interface{}) {
+	swq.shardOf(item).Done(item)
+}
+
+// Get implements workqueue.Interface. It returns the next item of any of
+// the shards, and reports shutdown once all the shards are shut down and
+// drained. Get polls the shards rather than blocking on all of them, so an
+// item is only taken out of its shard when it is handed to the caller.
+// When another consumer takes the item first, Get waits for the next item
+// of that shard.
+func (swq *shardedWorkQueue) Get() (interface{}, bool) {
+	if len(swq.shardedQueue) == 1 {
+		return swq.shardedQueue[0].Get()
+	}
+	for {
+		first := int(atomic.AddUint32(&swq.next, 1))
+		drained := 0
+		for i := range swq.shardedQueue {
+			q := swq.shardedQueue[(first+i)%len(swq.shardedQueue)]
+			// The consumer queue only shuts down once both lanes are drained,
+			// after which Get returns right away.
+			if q.consumerQueue.Len() == 0 && !q.consumerQueue.ShuttingDown() {
+				continue
+			}
+			if item, shutdown := q.Get(); !shutdown {
+				return item, false
+			}
+			drained++
+		}
+		if drained == len(swq.shardedQueue) {
+			return nil, true
+		}
+		time.Sleep(shardPollInterval)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestShardedQueue(t *testing.T) {
+	for _, shards := range []int{-1, 0, 1, 3} {
+		t.Run(strconv.Itoa(shards), func(t *testing.T) {
+			sq := newShardedQueue("test", shards, nil)
+			defer sq.ShutDown()
+
+			want := shards
+			if want < 1 {
+				want = 1
+			}
+			if got := len(sq); got != want {
+				t.Fatalf("len(shards) = %d, want: %d", got, want)
+			}
+
+			used := make(map[*twoLaneQueue]bool, len(sq))
+			for i := 0; i < 100; i++ {
+				key := types.NamespacedName{Namespace: "ns", Name: "name-" + strconv.Itoa(i)}
+				q := sq.shardFor(key)
+				if again := sq.shardFor(key); again != q {
+					t.Errorf("shardFor(%v) is not stable", key)
+				}
+				used[q] = true
+				q.Add(key)
+			}
+			if got := len(used); got != want {
+				t.Errorf("Keys landed in %d shards, want: %d", got, want)
+			}
+			// Items move asynchronously from the lanes to the consumer queues,
+			// so wait for them to settle.
+			if err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+				return sq.Len() == 100, nil
+			}); err != nil {
+				t.Errorf("Len() = %d, want: 100", sq.Len())
+			}
+		})
+	}
+}
+
+func TestShardedQueueRateLimiterPerShard(t *testing.T) {
+	var limiters []workqueue.RateLimiter
+	sq := newShardedQueue("test", 3, func() workqueue.RateLimiter {
+		rl := workqueue.DefaultControllerRateLimiter()
+		limiters = append(limiters, rl)
+		return rl
+	})
+	defer sq.ShutDown()
+
+	if got, want := len(limiters), 3; got != want {
+		t.Fatalf("Created %d rate limiters, want: %d", got, want)
+	}
+	for i := range limiters {
+		for j := i + 1; j < len(limiters); j++ {
+			if limiters[i] == limiters[j] {
+				t.Errorf("Shards %d and %d share a rate limiter", i, j)
+			}
+		}
+	}
+}
+
+func TestShardedWorkQueue(t *testing.T) {
+	sq := newShardedQueue("test", 3, nil)
+	swq := newShardedWorkQueue(sq)
+
+	keys := make([]types.NamespacedName, 30)
+	for i := range keys {
+		keys[i] = types.NamespacedName{Namespace: "ns", Name: "name-" + strconv.Itoa(i)}
+		swq.Add(keys[i])
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return swq.Len() == len(keys), nil
+	}); err != nil {
+		t.Fatalf("Len() = %d, want: %d", swq.Len(), len(keys))
+	}
+	for _, q := range sq {
+		if q.Len() == len(keys) {
+			t.Fatal("All the keys landed in one shard")
+		}
+	}
+
+	swq.AddRateLimited(keys[0])
+	if got, want := swq.NumRequeues(keys[0]), 1; got != want {
+		t.Errorf("NumRequeues() = %d, want: %d", got, want)
+	}
+	if got, want := sq.shardFor(keys[0]).NumRequeues(keys[0]), 1; got != want {
+		t.Errorf("Shard NumRequeues() = %d, want: %d", got, want)
+	}
+	swq.Forget(keys[0])
+	if got, want := swq.NumRequeues(keys[0]), 0; got != want {
+		t.Errorf("NumRequeues() after Forget = %d, want: %d", got, want)
+	}
+
+	seen := make(map[types.NamespacedName]bool, len(keys))
+	for len(seen) < len(keys) {
+		item, shutdown := swq.Get()
+		if shutdown {
+			t.Fatal("Get() reported shutdown before the queue was shut down")
+		}
+		seen[item.(types.NamespacedName)] = true
+		swq.Done(item)
+	}
+
+	if swq.ShuttingDown() {
+		t.Error("ShuttingDown() = true before ShutDown")
+	}
+	swq.ShutDown()
+	for i, q := range sq {
+		if !q.ShuttingDown() {
+			t.Errorf("Shard %d is not shutting down", i)
+		}
+	}
+	if !swq.ShuttingDown() {
+		t.Error("ShuttingDown() = false after ShutDown")
+	}
+	if _, shutdown := swq.Get(); !shutdown {
+		t.Error("Get() did not report shutdown after ShutDown")
+	}
+}
+
+func TestShardedWorkQueueGetLeavesOtherItems(t *testing.T) {
+	sq := newShardedQueue("test", 3, nil)
+	defer sq.ShutDown()
+	swq := newShardedWorkQueue(sq)
+
+	keys := make([]types.NamespacedName, 30)
+	for i := range keys {
+		keys[i] = types.NamespacedName{Namespace: "ns", Name: "name-" + strconv.Itoa(i)}
+		swq.Add(keys[i])
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return swq.Len() == len(keys), nil
+	}); err != nil {
+		t.Fatalf("Len() = %d, want: %d", swq.Len(), len(keys))
+	}
+
+	item, _ := swq.Get()
+	swq.Done(item)
+
+	// Only the item handed out left its shard, the others are still there
+	// for the controller's workers.
+	time.Sleep(5 * shardPollInterval)
+	if got, want := swq.Len(), len(keys)-1; got != want {
+		t.Errorf("Len() = %d, want: %d", got, want)
+	}
+	got := 0
+	for _, q := range sq {
+		for n := q.Len(); n > 0; n-- {
+			key, _ := q.Get()
+			q.Done(key)
+			got++
+		}
+	}
+	if want := len(keys) - 1; got != want {
+		t.Errorf("Got %d keys from the shards, want: %d", got, want)
+	}
+}
@@ -25,10 +25,12 @@ import (
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	kubemetrics "k8s.io/client-go/tools/metrics"
 	"k8s.io/client-go/util/workqueue"
 	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
 )
 
 var (
@@ -47,6 +49,22 @@ var (
 	// - characters are printable US-ASCII
 	reconcilerTagKey = tag.MustNewKey("reconciler")
 	successTagKey    = tag.MustNewKey("success")
+	resultTagKey     = tag.MustNewKey(metricskey.LabelReconcileResult)
+	namespaceTagKey  = tag.MustNewKey(metricskey.LabelNamespaceName)
+)
+
+// The values of the result tag classifying the outcome of a reconcile operation.
+const (
+	// ReconcileSuccess is reported when Reconcile returns no error.
+	ReconcileSuccess = "success"
+
+	// ReconcileError is reported when Reconcile returns an error and the
+	// key is not requeued, e.g. because the error is permanent.
+	ReconcileError = "error"
+
+	// ReconcileRequeue is reported when Reconcile returns a transient
+	// error and the key is requeued with backoff.
+	ReconcileRequeue = "requeue"
 )
 
 func init() {
@@ -170,12 +188,12 @@ func init() {
 		Description: "Number of reconcile operations",
 		Measure:     reconcileCountStat,
 		Aggregation: view.Count(),
-		TagKeys:     []tag.Key{reconcilerTagKey, successTagKey},
+		TagKeys:     []tag.Key{reconcilerTagKey, successTagKey, resultTagKey, namespaceTagKey},
 	}, {
 		Description: "Latency of reconcile operations",
 		Measure:     reconcileLatencyStat,
 		Aggregation: reconcileDistribution,
-		TagKeys:     []tag.Key{reconcilerTagKey, successTagKey},
+		TagKeys:     []tag.Key{reconcilerTagKey, successTagKey, resultTagKey, namespaceTagKey},
 	}}
 	views = append(views, wp.DefaultViews()...)
 	views = append(views, rp.DefaultViews()...)
@@ -184,7 +202,9 @@ func init() {
 	// Create views to see our measurements. This can return an error if
 	// a previously-registered view has the same name with a different value.
 	// View name defaults to the measure name if unspecified.
-	if err := view.Register(views...); err != nil {
+	// Registering them as resource views applies the bucket boundaries
+	// configured for their distributions.
+	if err := metrics.RegisterResourceView(views...); err != nil {
 		panic(err)
 	}
 }
@@ -198,6 +218,15 @@ type StatsReporter interface {
 	ReportReconcile(duration time.Duration, success string) error
 }
 
+// ReconcileResultReporter is implemented by the StatsReporters that also
+// report the result and namespace of the reconcile operations. Impl reports
+// through it instead of ReportReconcile when its StatsReporter implements it.
+type ReconcileResultReporter interface {
+	// ReportReconcileResult reports the count and latency metrics for a
+	// reconcile operation on the given key, tagged with its result.
+	ReportReconcileResult(duration time.Duration, result string, key types.NamespacedName) error
+}
+
 // Reporter holds cached metric objects to report metrics
 type reporter struct {
 	reconciler string
@@ -238,10 +267,33 @@ func (r *reporter) ReportQueueDepth(v int64) error {
 
 // ReportReconcile reports the count and latency metrics for a reconcile operation
 func (r *reporter) ReportReconcile(duration time.Duration, success string) error {
-	ctx, err := tag.New(
-		context.Background(),
+	result := ReconcileSuccess
+	if success != trueString {
+		result = ReconcileError
+	}
+	return r.report(duration, success, result, "")
+}
+
+// ReportReconcileResult reports the count and latency metrics for a reconcile
+// operation on the given key, tagged with its result.
+func (r *reporter) ReportReconcileResult(duration time.Duration, result string, key types.NamespacedName) error {
+	success := falseString
+	if result == ReconcileSuccess {
+		success = trueString
+	}
+	return r.report(duration, success, result, key.Namespace)
+}
+
+func (r *reporter) report(duration time.Duration, success, result, namespace string) error {
+	mutators := []tag.Mutator{
 		tag.Insert(reconcilerTagKey, r.reconciler),
-		tag.Insert(successTagKey, success))
+		tag.Insert(successTagKey, success),
+		tag.Insert(resultTagKey, result),
+	}
+	if namespace != "" {
+		mutators = append(mutators, tag.Insert(namespaceTagKey, namespace))
+	}
+	ctx, err := tag.New(context.Background(), mutators...)
 	if err != nil {
 		return err
 	}
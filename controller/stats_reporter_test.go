@@ -22,7 +22,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
+	"k8s.io/apimachinery/pkg/types"
 	"knative.dev/pkg/metrics/metricstest"
 	_ "knative.dev/pkg/metrics/testing"
 )
@@ -65,10 +68,14 @@ func TestReportQueueDepth(t *testing.T) {
 }
 
 func TestReportReconcile(t *testing.T) {
-	r, _ := NewStatsReporter("testreconciler")
+	sr, _ := NewStatsReporter("testreconciler")
+	r := sr.(ReconcileResultReporter)
+	key := types.NamespacedName{Namespace: "testns", Name: "test"}
 	wantTags := map[string]string{
-		"reconciler": "testreconciler",
-		"success":    "true",
+		"reconciler":     "testreconciler",
+		"success":        "true",
+		"result":         ReconcileSuccess,
+		"namespace_name": "testns",
 	}
 
 	initialReconcileCount := int64(0)
@@ -85,20 +92,101 @@ func TestReportReconcile(t *testing.T) {
 
 	slow, fast := initialMax+5, initialMin-5
 
-	expectSuccess(t, func() error { return r.ReportReconcile(time.Duration(fast)*time.Millisecond, "true") })
+	expectSuccess(t, func() error {
+		return r.ReportReconcileResult(time.Duration(fast)*time.Millisecond, ReconcileSuccess, key)
+	})
 	metricstest.CheckCountData(t, "reconcile_count", wantTags, initialReconcileCount+1)
 	metricstest.CheckDistributionData(t, "reconcile_latency", wantTags, initialDistributionCount+1,
 		fast, initialMax)
 
-	expectSuccess(t, func() error { return r.ReportReconcile(time.Duration(slow)*time.Millisecond, "true") })
+	expectSuccess(t, func() error {
+		return r.ReportReconcileResult(time.Duration(slow)*time.Millisecond, ReconcileSuccess, key)
+	})
 	metricstest.CheckCountData(t, "reconcile_count", wantTags, initialReconcileCount+2)
 	metricstest.CheckDistributionData(t, "reconcile_latency", wantTags, initialDistributionCount+2,
 		fast, slow)
 }
 
+func TestReportReconcileResult(t *testing.T) {
+	sr, _ := NewStatsReporter("testreconciler")
+	r := sr.(ReconcileResultReporter)
+	key := types.NamespacedName{Namespace: "otherns", Name: "test"}
+
+	for _, result := range []string{ReconcileError, ReconcileRequeue} {
+		wantTags := map[string]string{
+			"reconciler":     "testreconciler",
+			"success":        "false",
+			"result":         result,
+			"namespace_name": "otherns",
+		}
+		expectSuccess(t, func() error { return r.ReportReconcileResult(time.Millisecond, result, key) })
+
+		if got, ok := reconcileCount(t, wantTags); !ok {
+			t.Errorf("No reconcile_count row with tags %v", wantTags)
+		} else if got != 1 {
+			t.Errorf("reconcile_count{result=%q} = %d, want: 1", result, got)
+		}
+	}
+}
+
+func TestReportReconcileSuccess(t *testing.T) {
+	r, _ := NewStatsReporter("successreconciler")
+	wantTags := map[string]string{
+		"reconciler": "successreconciler",
+		"success":    "false",
+		"result":     ReconcileError,
+	}
+
+	// Reporting the success alone tags the result, but not the namespace.
+	expectSuccess(t, func() error { return r.ReportReconcile(time.Millisecond, "false") })
+	if got, ok := reconcileCount(t, wantTags); !ok {
+		t.Errorf("No reconcile_count row with tags %v", wantTags)
+	} else if got != 1 {
+		t.Errorf("reconcile_count = %d, want: 1", got)
+	}
+}
+
+// reconcileCount returns the reconcile_count of the row with exactly the
+// given tags, if any.
+func reconcileCount(t *testing.T, wantTags map[string]string) (int64, bool) {
+	t.Helper()
+	rows, err := view.RetrieveData("reconcile_count")
+	if err != nil {
+		t.Fatal("RetrieveData() =", err)
+	}
+	for _, row := range rows {
+		got := make(map[string]string, len(row.Tags))
+		for _, t := range row.Tags {
+			got[t.Key.Name()] = t.Value
+		}
+		if cmp.Equal(got, wantTags) {
+			return row.Data.(*view.CountData).Value, true
+		}
+	}
+	return 0, false
+}
+
 func expectSuccess(t *testing.T, f func() error) {
 	t.Helper()
 	if err := f(); err != nil {
 		t.Error("Reporter.Report() expected success but got error", err)
 	}
 }
+
+func TestWorkqueueViews(t *testing.T) {
+	for name, unit := range map[string]string{
+		"workqueue_adds_total":            stats.UnitNone,
+		"workqueue_depth":                 stats.UnitNone,
+		"workqueue_work_duration_seconds": "s",
+		"reconcile_latency":               stats.UnitMilliseconds,
+	} {
+		v := view.Find(name)
+		if v == nil {
+			t.Errorf("view.Find(%q) = nil, wanted a registered view", name)
+			continue
+		}
+		if got := v.Measure.Unit(); got != unit {
+			t.Errorf("Unit of %q = %q, want %q", name, got, unit)
+		}
+	}
+}
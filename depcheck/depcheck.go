@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package depcheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// graph maps each import path to the import paths it directly imports.
+type graph map[string][]string
+
+// buildGraph loads the dependency graph of the given import paths.
+func buildGraph(importpaths ...string) (graph, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps,
+	}, importpaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	g := make(graph)
+	var errs []string
+	packages.Visit(pkgs, func(pkg *packages.Package) bool {
+		if _, ok := g[pkg.PkgPath]; ok {
+			return false
+		}
+		for _, e := range pkg.Errors {
+			errs = append(errs, e.Error())
+		}
+		imports := make([]string, 0, len(pkg.Imports))
+		for _, imp := range pkg.Imports {
+			imports = append(imports, imp.PkgPath)
+		}
+		sort.Strings(imports)
+		g[pkg.PkgPath] = imports
+		return true
+	}, nil)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to load %v: %s", importpaths, strings.Join(errs, "; "))
+	}
+	return g, nil
+}
+
+// path returns the shortest import chain from one package to another
+// satisfying to, or nil if from does not depend on any.
+func (g graph) path(from string, to func(string) bool) []string {
+	parent := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		ip := queue[0]
+		queue = queue[1:]
+		if ip != from && to(ip) {
+			var chain []string
+			for ; ip != ""; ip = parent[ip] {
+				chain = append([]string{ip}, chain...)
+			}
+			return chain
+		}
+		for _, dep := range g[ip] {
+			if _, ok := parent[dep]; !ok {
+				parent[dep] = ip
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// matches returns whether the import path is the given one or one of its
+// subpackages, so that banning k8s.io/client-go also bans
+// k8s.io/client-go/kubernetes.
+func matches(ip, prefix string) bool {
+	return ip == prefix || strings.HasPrefix(ip, prefix+"/")
+}
+
+// isStdLib returns whether the import path belongs to the standard library,
+// whose first path element has no dot.
+func isStdLib(ip string) bool {
+	first := strings.SplitN(ip, "/", 2)[0]
+	return !strings.Contains(first, ".")
+}
+
+// CheckNoDependency checks that the given import path does not
+// transitively depend on any of the banned import paths, or their
+// subpackages.
+func CheckNoDependency(ip string, banned []string) error {
+	g, err := buildGraph(ip)
+	if err != nil {
+		return err
+	}
+	for _, b := range banned {
+		if chain := g.path(ip, func(dep string) bool { return matches(dep, b) }); chain != nil {
+			return fmt.Errorf("%s depends on banned dependency %s via:\n\t%s",
+				ip, b, strings.Join(chain, "\n\t-> "))
+		}
+	}
+	return nil
+}
+
+// AssertNoDependency checks that the import paths in the keys of banned
+// don't transitively depend on any of the import paths in their values.
+func AssertNoDependency(t *testing.T, banned map[string][]string) {
+	t.Helper()
+	for ip, b := range banned {
+		t.Run(ip, func(t *testing.T) {
+			if err := CheckNoDependency(ip, b); err != nil {
+				t.Error("CheckNoDependency() =", err)
+			}
+		})
+	}
+}
+
+// CheckOnlyDependencies checks that the given import path only
+// transitively depends on itself, the standard library, and the
+// allowed import paths, or their subpackages.
+func CheckOnlyDependencies(ip string, allowed map[string]struct{}) error {
+	g, err := buildGraph(ip)
+	if err != nil {
+		return err
+	}
+	var disallowed []string
+	for dep := range g {
+		if dep == ip || isStdLib(dep) || isAllowed(dep, allowed) {
+			continue
+		}
+		disallowed = append(disallowed, dep)
+	}
+	if len(disallowed) == 0 {
+		return nil
+	}
+	sort.Strings(disallowed)
+	chains := make([]string, 0, len(disallowed))
+	for _, dep := range disallowed {
+		dep := dep
+		chains = append(chains, strings.Join(g.path(ip, func(d string) bool { return d == dep }), " -> "))
+	}
+	return fmt.Errorf("%s depends on disallowed packages:\n\t%s", ip, strings.Join(chains, "\n\t"))
+}
+
+// isAllowed returns whether the import path is one of the allowed ones or
+// one of their subpackages.
+func isAllowed(ip string, allowed map[string]struct{}) bool {
+	for a := range allowed {
+		if matches(ip, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertOnlyDependencies checks that the import paths in the keys of allowed
+// only transitively depend on themselves, the standard library, and the
+// import paths in their values.
+func AssertOnlyDependencies(t *testing.T, allowed map[string][]string) {
+	t.Helper()
+	for ip, a := range allowed {
+		set := make(map[string]struct{}, len(a))
+		for _, dep := range a {
+			set[dep] = struct{}{}
+		}
+		t.Run(ip, func(t *testing.T) {
+			if err := CheckOnlyDependencies(ip, set); err != nil {
+				t.Error("CheckOnlyDependencies() =", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package depcheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	AssertNoDependency(t, map[string][]string{
+		"knative.dev/pkg/ptr": {
+			"k8s.io/client-go/kubernetes",
+		},
+		"knative.dev/pkg/pool": {
+			"knative.dev/pkg/logging",
+		},
+	})
+
+	AssertOnlyDependencies(t, map[string][]string{
+		"knative.dev/pkg/ptr": {},
+		"knative.dev/pkg/pool": {
+			"golang.org/x/sync/errgroup",
+		},
+	})
+}
+
+func TestCheckNoDependencyFails(t *testing.T) {
+	err := CheckNoDependency("knative.dev/pkg/network/prober", []string{"knative.dev/pkg/logging"})
+	if err == nil {
+		t.Fatal("CheckNoDependency() = nil, wanted an error")
+	}
+	if got, want := err.Error(), "knative.dev/pkg/network/prober\n\t-> knative.dev/pkg/logging"; !strings.Contains(got, want) {
+		t.Errorf("CheckNoDependency() = %v, wanted it to contain the import chain %q", got, want)
+	}
+}
+
+func TestCheckNoDependencySubpackage(t *testing.T) {
+	// knative.dev/pkg/version only imports k8s.io/client-go/kubernetes/scheme.
+	err := CheckNoDependency("knative.dev/pkg/version", []string{"k8s.io/client-go/kubernetes"})
+	if err == nil {
+		t.Fatal("CheckNoDependency() = nil, wanted an error")
+	}
+	if got, want := err.Error(), "-> k8s.io/client-go/kubernetes/scheme"; !strings.Contains(got, want) {
+		t.Errorf("CheckNoDependency() = %v, wanted it to contain the import chain %q", got, want)
+	}
+
+	// Only whole path elements match.
+	if err := CheckNoDependency("knative.dev/pkg/pool", []string{"golang.org/x/sync/err"}); err != nil {
+		t.Error("CheckNoDependency() =", err)
+	}
+}
+
+func TestCheckOnlyDependenciesSubpackage(t *testing.T) {
+	if err := CheckOnlyDependencies("knative.dev/pkg/pool", map[string]struct{}{"golang.org/x/sync": {}}); err != nil {
+		t.Error("CheckOnlyDependencies() =", err)
+	}
+}
+
+func TestCheckOnlyDependenciesFails(t *testing.T) {
+	err := CheckOnlyDependencies("knative.dev/pkg/pool", map[string]struct{}{})
+	if err == nil {
+		t.Fatal("CheckOnlyDependencies() = nil, wanted an error")
+	}
+	if !strings.Contains(err.Error(), "golang.org/x/sync/errgroup") {
+		t.Errorf("CheckOnlyDependencies() = %v, wanted it to name golang.org/x/sync/errgroup", err)
+	}
+}
+
+func TestCheckUnknownPackage(t *testing.T) {
+	if err := CheckNoDependency("knative.dev/pkg/does-not-exist", nil); err == nil {
+		t.Error("CheckNoDependency() = nil, wanted an error for an unknown package")
+	}
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package depcheck is a test library for asserting the transitive
+// dependencies of packages, so that coupling and binary size regressions
+// fail unit tests. Import paths also match their subpackages, so that
+// data-plane packages can assert that they don't pull in client-go:
+//
+//	func TestNoDeps(t *testing.T) {
+//	  depcheck.AssertNoDependency(t, map[string][]string{
+//	    "knative.dev/pkg/network": {
+//	      "k8s.io/client-go",
+//	    },
+//	  })
+//	}
+package depcheck
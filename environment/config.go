@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// DefaultResyncPeriod is the resync period used when none is given.
+const DefaultResyncPeriod = 10 * time.Hour
+
+// Config is the configuration common to adapters and controllers. Binaries
+// needing more configuration embed it in their own struct, and read the rest
+// with envconfig tags too.
+type Config struct {
+	// Namespace is the namespace the binary is running for.
+	Namespace string `envconfig:"NAMESPACE" required:"true"`
+
+	// Sink is the URI events are sent to.
+	Sink string `envconfig:"K_SINK"`
+
+	// LegacySink is the URI events are sent to when Sink is unset.
+	LegacySink string `envconfig:"SINK_URI"`
+
+	// LoggingConfigJSON is the JSON encoded logging.Config.
+	LoggingConfigJSON string `envconfig:"K_LOGGING_CONFIG"`
+
+	// MetricsConfigJSON is the JSON encoded metrics.ExporterOptions.
+	MetricsConfigJSON string `envconfig:"K_METRICS_CONFIG"`
+
+	// TracingConfigJSON is the JSON encoded tracing config.Config.
+	TracingConfigJSON string `envconfig:"K_TRACING_CONFIG"`
+
+	// ResyncPeriod is how often informers resync their caches.
+	ResyncPeriod time.Duration `envconfig:"K_RESYNC_PERIOD" default:"10h"`
+}
+
+// Validator is implemented by configurations checking themselves once
+// they are read.
+type Validator interface {
+	// Validate returns an error if the configuration is not usable.
+	Validate() error
+}
+
+// Process fills in cfg, a pointer to a struct embedding Config, from the
+// environment, and validates it if it implements Validator.
+func Process(cfg interface{}) error {
+	if err := envconfig.Process("", cfg); err != nil {
+		return err
+	}
+	if v, ok := cfg.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// FromEnv returns the Config read from the environment.
+func FromEnv() (*Config, error) {
+	c := &Config{}
+	if err := Process(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// InitFlags registers flags overriding the values of c on fs, defaulting to
+// the values already in c. Call it after reading c from the environment so
+// that flags take precedence.
+func (c *Config) InitFlags(fs *flag.FlagSet) {
+	if c.ResyncPeriod == 0 {
+		c.ResyncPeriod = DefaultResyncPeriod
+	}
+	fs.StringVar(&c.Namespace, "namespace", c.Namespace,
+		"The namespace to run for.")
+	fs.StringVar(&c.Sink, "sink", c.Sink,
+		"The URI events are sent to.")
+	fs.StringVar(&c.LoggingConfigJSON, "logging-config", c.LoggingConfigJSON,
+		"The JSON encoded logging configuration.")
+	fs.StringVar(&c.MetricsConfigJSON, "metrics-config", c.MetricsConfigJSON,
+		"The JSON encoded metrics configuration.")
+	fs.StringVar(&c.TracingConfigJSON, "tracing-config", c.TracingConfigJSON,
+		"The JSON encoded tracing configuration.")
+	fs.DurationVar(&c.ResyncPeriod, "resync-period", c.ResyncPeriod,
+		"How often informers resync their caches.")
+}
+
+// GetSink returns the URI events are sent to.
+func (c *Config) GetSink() string {
+	if c.Sink != "" {
+		return c.Sink
+	}
+	return c.LegacySink
+}
+
+// Validate implements Validator.
+func (c *Config) Validate() error {
+	if c.Namespace == "" {
+		return errors.New("namespace must be set")
+	}
+	if sink := c.GetSink(); sink != "" {
+		u, err := url.Parse(sink)
+		if err != nil {
+			return fmt.Errorf("failed to parse sink %q: %w", sink, err)
+		}
+		if !u.IsAbs() || u.Host == "" {
+			return fmt.Errorf("sink %q must be an absolute URI", sink)
+		}
+	}
+	for _, cfg := range []struct{ name, value string }{
+		{"K_LOGGING_CONFIG", c.LoggingConfigJSON},
+		{"K_METRICS_CONFIG", c.MetricsConfigJSON},
+		{"K_TRACING_CONFIG", c.TracingConfigJSON},
+	} {
+		if cfg.value != "" && !json.Valid([]byte(cfg.value)) {
+			return fmt.Errorf("%s is not valid JSON", cfg.name)
+		}
+	}
+	if c.ResyncPeriod <= 0 {
+		return fmt.Errorf("resync period must be positive, was %v", c.ResyncPeriod)
+	}
+	return nil
+}
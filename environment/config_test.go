@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"time"
+)
+
+func setenv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for k, v := range env {
+		os.Setenv(k, v)
+		k := k
+		t.Cleanup(func() { os.Unsetenv(k) })
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	setenv(t, map[string]string{
+		"NAMESPACE":        "ns",
+		"SINK_URI":         "http://legacy.ns.svc.cluster.local",
+		"K_LOGGING_CONFIG": `{"zap-logger-config": "{}"}`,
+	})
+
+	c, err := FromEnv()
+	if err != nil {
+		t.Fatal("FromEnv() =", err)
+	}
+	if got, want := c.Namespace, "ns"; got != want {
+		t.Errorf("Namespace = %q, want %q", got, want)
+	}
+	if got, want := c.ResyncPeriod, DefaultResyncPeriod; got != want {
+		t.Errorf("ResyncPeriod = %v, want %v", got, want)
+	}
+	if got, want := c.GetSink(), "http://legacy.ns.svc.cluster.local"; got != want {
+		t.Errorf("GetSink() = %q, want %q", got, want)
+	}
+	c.Sink = "http://sink.ns.svc.cluster.local"
+	if got, want := c.GetSink(), "http://sink.ns.svc.cluster.local"; got != want {
+		t.Errorf("GetSink() = %q, want %q", got, want)
+	}
+}
+
+func TestFromEnvErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{{
+		name: "missing namespace",
+		env:  map[string]string{},
+	}, {
+		name: "relative sink",
+		env:  map[string]string{"NAMESPACE": "ns", "K_SINK": "/path"},
+	}, {
+		name: "bad metrics config",
+		env:  map[string]string{"NAMESPACE": "ns", "K_METRICS_CONFIG": "{"},
+	}, {
+		name: "bad resync period",
+		env:  map[string]string{"NAMESPACE": "ns", "K_RESYNC_PERIOD": "soon"},
+	}, {
+		name: "negative resync period",
+		env:  map[string]string{"NAMESPACE": "ns", "K_RESYNC_PERIOD": "-1m"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setenv(t, test.env)
+			if c, err := FromEnv(); err == nil {
+				t.Errorf("FromEnv() = %+v, wanted an error", c)
+			}
+		})
+	}
+}
+
+func TestInitFlags(t *testing.T) {
+	c := &Config{Namespace: "ns", Sink: "http://env.ns.svc.cluster.local"}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c.InitFlags(fs)
+
+	if err := fs.Parse([]string{"--sink=http://flag.ns.svc.cluster.local", "--resync-period=1h"}); err != nil {
+		t.Fatal("Parse() =", err)
+	}
+	if got, want := c.Namespace, "ns"; got != want {
+		t.Errorf("Namespace = %q, want %q", got, want)
+	}
+	if got, want := c.Sink, "http://flag.ns.svc.cluster.local"; got != want {
+		t.Errorf("Sink = %q, want %q", got, want)
+	}
+	if got, want := c.ResyncPeriod, time.Hour; got != want {
+		t.Errorf("ResyncPeriod = %v, want %v", got, want)
+	}
+	if err := c.Validate(); err != nil {
+		t.Error("Validate() =", err)
+	}
+}
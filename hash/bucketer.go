@@ -97,7 +97,8 @@ func (bs *BucketSet) sortedBucketNames() []string {
 	return bs.buckets.List()
 }
 
-// Owner returns the owner of the key.
+// Owner returns the owner of the key, or the empty string
+// if the set has no buckets.
 // Owner will cache the results for faster lookup.
 func (bs *BucketSet) Owner(key string) string {
 	if v, ok := bs.cache.Get(key); ok {
@@ -105,6 +106,9 @@ func (bs *BucketSet) Owner(key string) string {
 	}
 	bs.mu.RLock()
 	defer bs.mu.RUnlock()
+	if len(bs.buckets) == 0 {
+		return ""
+	}
 	l := ChooseSubset(bs.buckets, 1 /*single query wanted*/, key)
 	ret := l.UnsortedList()[0]
 	bs.cache.Add(key, ret)
@@ -113,6 +117,9 @@ func (bs *BucketSet) Owner(key string) string {
 
 // HasBucket returns true if this BucketSet has the given bucket name.
 func (bs *BucketSet) HasBucket(bkt string) bool {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
 	return bs.buckets.Has(bkt)
 }
 
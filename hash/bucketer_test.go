@@ -17,6 +17,7 @@ limitations under the License.
 package hash
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
 	"testing"
@@ -157,3 +158,44 @@ func TestBucketName(t *testing.T) {
 		t.Errorf("Name = %q, want: %q", got, want)
 	}
 }
+
+func TestBucketSetEmpty(t *testing.T) {
+	bs := NewBucketSet(sets.NewString())
+	if got := bs.Owner("key"); got != "" {
+		t.Errorf("Owner() = %q, want empty", got)
+	}
+}
+
+func TestBucketSetLowChurn(t *testing.T) {
+	const numKeys = 2000
+	names := func(n int) sets.String {
+		ret := sets.NewString()
+		for i := 0; i < n; i++ {
+			ret.Insert(fmt.Sprintf("bucket-%d", i))
+		}
+		return ret
+	}
+
+	bs := NewBucketSet(names(10))
+	before := make(map[string]string, numKeys)
+	for i := 0; i < numKeys; i++ {
+		key := fmt.Sprintf("ns/key-%d", i)
+		before[key] = bs.Owner(key)
+	}
+
+	// Adding an 11th bucket should ideally move 1/11 of the keys, and
+	// only ever to the new bucket. Allow some slack for the hashing.
+	bs.Update(names(11))
+	moved := 0
+	for key, owner := range before {
+		if now := bs.Owner(key); now != owner {
+			moved++
+			if now != "bucket-10" {
+				t.Errorf("Key %s moved from %s to %s, not to the new bucket", key, owner, now)
+			}
+		}
+	}
+	if max := 2 * numKeys / 11; moved > max {
+		t.Errorf("Adding a bucket moved %d of %d keys, want at most %d", moved, numKeys, max)
+	}
+}
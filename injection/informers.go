@@ -44,6 +44,12 @@ func (i *impl) GetInformers() []InformerInjector {
 }
 
 func (i *impl) SetupInformers(ctx context.Context, cfg *rest.Config) (context.Context, []controller.Informer) {
+	// Make the config available to the injectors and everything downstream
+	// of them, unless the caller already did.
+	if GetConfig(ctx) == nil && cfg != nil {
+		ctx = WithConfig(ctx, cfg)
+	}
+
 	// Based on the reconcilers we have linked, build up a set of clients and inject
 	// them onto the context.
 	for _, ci := range i.GetClients() {
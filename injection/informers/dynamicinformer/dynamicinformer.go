@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamicinformer
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterInformerFactory(withInformerFactory)
+}
+
+// Key is used as the key for associating information
+// with a context.Context.
+type Key struct{}
+
+func withInformerFactory(ctx context.Context) context.Context {
+	return WithFactory(ctx, dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		dynamicclient.Get(ctx), controller.GetResyncPeriod(ctx),
+		injection.GetNamespaceScope(ctx), nil))
+}
+
+// WithFactory associates the given factory with the context.
+func WithFactory(ctx context.Context, f dynamicinformer.DynamicSharedInformerFactory) context.Context {
+	return context.WithValue(ctx, Key{}, f)
+}
+
+// GetFactory extracts the dynamic informer factory from the context.
+func GetFactory(ctx context.Context) dynamicinformer.DynamicSharedInformerFactory {
+	untyped := ctx.Value(Key{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic(
+			"Unable to fetch k8s.io/client-go/dynamic/dynamicinformer.DynamicSharedInformerFactory from context.")
+	}
+	return untyped.(dynamicinformer.DynamicSharedInformerFactory)
+}
+
+// Get returns the informer for the given resource, which is shared by all
+// callers asking for it. Since the resources are typically only known at
+// runtime, after the injected informers were started, the informer is
+// started if needed, and stops along with the context.
+func Get(ctx context.Context, gvr schema.GroupVersionResource) informers.GenericInformer {
+	f := GetFactory(ctx)
+	inf := f.ForResource(gvr)
+	f.Start(ctx.Done())
+	return inf
+}
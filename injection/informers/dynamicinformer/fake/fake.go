@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"k8s.io/client-go/dynamic/dynamicinformer"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/dynamicclient/fake"
+	dynamicinformerinjection "knative.dev/pkg/injection/informers/dynamicinformer"
+)
+
+var (
+	Get        = dynamicinformerinjection.Get
+	GetFactory = dynamicinformerinjection.GetFactory
+)
+
+func init() {
+	injection.Fake.RegisterInformerFactory(withInformerFactory)
+}
+
+func withInformerFactory(ctx context.Context) context.Context {
+	return dynamicinformerinjection.WithFactory(ctx, dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		fake.Get(ctx), controller.GetResyncPeriod(ctx),
+		injection.GetNamespaceScope(ctx), nil))
+}
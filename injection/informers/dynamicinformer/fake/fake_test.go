@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/dynamicclient/fake"
+)
+
+func TestGetFactoryPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("GetFactory() should have panicked")
+		}
+	}()
+
+	GetFactory(context.Background())
+}
+
+func TestRegistration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx, _ = injection.Fake.SetupInformers(ctx, &rest.Config{})
+	if GetFactory(ctx) == nil {
+		t.Fatal("GetFactory() = nil, wanted non-nil")
+	}
+
+	gvr := schema.GroupVersionResource{Group: "sources.knative.dev", Version: "v1", Resource: "pingsources"}
+	src := &unstructured.Unstructured{}
+	src.SetAPIVersion("sources.knative.dev/v1")
+	src.SetKind("PingSource")
+	src.SetNamespace("ns")
+	src.SetName("ping")
+	ctx, _ = fake.With(ctx, runtime.NewScheme(), src)
+	ctx = withInformerFactory(ctx)
+
+	inf := Get(ctx, gvr)
+	if !cache.WaitForCacheSync(ctx.Done(), inf.Informer().HasSynced) {
+		t.Fatal("Failed to sync the informer")
+	}
+	if _, err := inf.Lister().ByNamespace("ns").Get("ping"); err != nil {
+		t.Error("Get(ping) =", err)
+	}
+	if inf2 := Get(ctx, gvr); inf2.Informer() != inf.Informer() {
+		t.Error("Get() returned a different informer for the same resource")
+	}
+}
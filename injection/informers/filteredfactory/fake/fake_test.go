@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/informers/filteredfactory"
+)
+
+func TestGetPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Get() should have panicked")
+		}
+	}()
+
+	Get(context.Background(), "foo=bar")
+}
+
+func TestFilteredFactory(t *testing.T) {
+	const selector = "eventing.knative.dev/source=true"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx = filteredfactory.WithSelectors(ctx, selector)
+	ctx, _ = injection.Fake.SetupInformers(ctx, &rest.Config{})
+
+	for _, name := range []string{"source", "other"} {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+		if name == "source" {
+			cm.Labels = map[string]string{"eventing.knative.dev/source": "true"}
+		}
+		if _, err := fake.Get(ctx).CoreV1().ConfigMaps("ns").Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			t.Fatal("Create() =", err)
+		}
+	}
+
+	f := Get(ctx, selector)
+	inf := f.Core().V1().ConfigMaps()
+	informer := inf.Informer()
+	f.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		t.Fatal("Failed to sync the informer")
+	}
+	cms, err := inf.Lister().List(labels.Everything())
+	if err != nil {
+		t.Fatal("List() =", err)
+	}
+	if len(cms) != 1 || cms[0].Name != "source" {
+		t.Errorf("List() = %v, wanted only the source ConfigMap", cms)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filteredfactory
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterInformerFactory(withInformerFactory)
+}
+
+// Filter selects the objects the informers of a factory cache.
+type Filter struct {
+	LabelSelector string
+	FieldSelector string
+}
+
+// Key is used as the key for associating the factory of a Filter
+// with a context.Context.
+type Key struct {
+	Filter Filter
+}
+
+// filtersKey is used to associate the requested filters with a context.
+type filtersKey struct{}
+
+// WithFilters requests shared informer factories filtered with each of the
+// given filters, e.g. to only cache the objects labeled with
+// `eventing.knative.dev/source=true`. It must be called before the
+// informers are set up.
+func WithFilters(ctx context.Context, filters ...Filter) context.Context {
+	return context.WithValue(ctx, filtersKey{}, append(getFilters(ctx), filters...))
+}
+
+// WithSelectors is WithFilters for filters with only a label selector.
+func WithSelectors(ctx context.Context, selectors ...string) context.Context {
+	filters := make([]Filter, 0, len(selectors))
+	for _, s := range selectors {
+		filters = append(filters, Filter{LabelSelector: s})
+	}
+	return WithFilters(ctx, filters...)
+}
+
+func getFilters(ctx context.Context) []Filter {
+	filters, _ := ctx.Value(filtersKey{}).([]Filter)
+	return filters
+}
+
+func withInformerFactory(ctx context.Context) context.Context {
+	return WithFactories(ctx, client.Get(ctx))
+}
+
+// WithFactories associates a factory over the given client with the
+// context for each of the requested filters.
+func WithFactories(ctx context.Context, c kubernetes.Interface) context.Context {
+	for _, f := range getFilters(ctx) {
+		f := f
+		opts := []informers.SharedInformerOption{
+			informers.WithTweakListOptions(func(l *metav1.ListOptions) {
+				l.LabelSelector = f.LabelSelector
+				l.FieldSelector = f.FieldSelector
+			}),
+		}
+		if injection.HasNamespaceScope(ctx) {
+			opts = append(opts, informers.WithNamespace(injection.GetNamespaceScope(ctx)))
+		}
+		ctx = context.WithValue(ctx, Key{Filter: f},
+			informers.NewSharedInformerFactoryWithOptions(c, controller.GetResyncPeriod(ctx), opts...))
+	}
+	return ctx
+}
+
+// GetFiltered extracts the InformerFactory for the given filter from the
+// context. Informers obtained from it after the injected informers were
+// started must be started by calling its Start method once their
+// Informer() was requested.
+func GetFiltered(ctx context.Context, f Filter) informers.SharedInformerFactory {
+	untyped := ctx.Value(Key{Filter: f})
+	if untyped == nil {
+		logging.FromContext(ctx).Panicf(
+			"Unable to fetch k8s.io/client-go/informers.SharedInformerFactory with filter %+v from context.", f)
+	}
+	return untyped.(informers.SharedInformerFactory)
+}
+
+// Get is GetFiltered for a filter with only a label selector.
+func Get(ctx context.Context, selector string) informers.SharedInformerFactory {
+	return GetFiltered(ctx, Filter{LabelSelector: selector})
+}
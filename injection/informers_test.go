@@ -61,9 +61,13 @@ func TestRegisterInformersAndSetup(t *testing.T) {
 	i.RegisterInformer(injectFooInformer)
 	i.RegisterInformer(injectBarInformer)
 
-	_, infs := i.SetupInformers(context.Background(), &rest.Config{})
+	cfg := &rest.Config{}
+	ctx, infs := i.SetupInformers(context.Background(), cfg)
 
 	if want, got := 2, len(infs); got != want {
 		t.Errorf("SetupInformers() = %d, wanted %d", want, got)
 	}
+	if want, got := cfg, GetConfig(ctx); got != want {
+		t.Errorf("GetConfig() = %v, wanted %v", got, want)
+	}
 }
@@ -20,11 +20,13 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/user"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	"go.opencensus.io/stats/view"
@@ -34,6 +36,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
@@ -41,6 +44,7 @@ import (
 	_ "go.uber.org/automaxprocs" // automatically set GOMAXPROCS based on cgroups
 	"go.uber.org/zap"
 
+	"knative.dev/pkg/changeset"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -53,16 +57,19 @@ import (
 	"knative.dev/pkg/reconciler"
 	"knative.dev/pkg/signals"
 	"knative.dev/pkg/system"
+	"knative.dev/pkg/tracing"
+	tracingconfig "knative.dev/pkg/tracing/config"
 	"knative.dev/pkg/version"
 	"knative.dev/pkg/webhook"
+	"knative.dev/pkg/webhook/certificates"
 )
 
 // GetConfig returns a rest.Config to be used for kubernetes client creation.
 // It does so in the following order:
-//   1. Use the passed kubeconfig/serverURL.
-//   2. Fallback to the KUBECONFIG environment variable.
-//   3. Fallback to in-cluster config.
-//   4. Fallback to the ~/.kube/config.
+//  1. Use the passed kubeconfig/serverURL.
+//  2. Fallback to the KUBECONFIG environment variable.
+//  3. Fallback to in-cluster config.
+//  4. Fallback to the ~/.kube/config.
 func GetConfig(serverURL, kubeconfig string) (*rest.Config, error) {
 	if kubeconfig == "" {
 		kubeconfig = os.Getenv("KUBECONFIG")
@@ -127,11 +134,29 @@ func GetLeaderElectionConfig(ctx context.Context) (*leaderelection.Config, error
 	return leaderelection.NewConfigFromConfigMap(leaderElectionConfigMap)
 }
 
+// GetResyncPeriod gets the informer resync period from the resync ConfigMap,
+// falling back to the one associated with the context. It reads the ConfigMap
+// with a client of its own, so that it can be called before injection is
+// enabled, when the informer factories are not created yet.
+func GetResyncPeriod(ctx context.Context, cfg *rest.Config) (time.Duration, error) {
+	kc, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return 0, err
+	}
+	resyncConfigMap, err := kc.CoreV1().ConfigMaps(system.Namespace()).Get(ctx, controller.ResyncConfigMapName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return controller.GetResyncPeriod(ctx), nil
+	} else if err != nil {
+		return 0, err
+	}
+	return controller.NewResyncPeriodFromConfigMap(resyncConfigMap, controller.GetResyncPeriod(ctx))
+}
+
 // EnableInjectionOrDie enables Knative Injection and starts the informers.
 // Both Context and Config are optional.
 func EnableInjectionOrDie(ctx context.Context, cfg *rest.Config) context.Context {
 	if ctx == nil {
-		ctx = signals.NewContext()
+		ctx = newSignalContext()
 	}
 	if cfg == nil {
 		cfg = ParseAndGetConfigOrDie()
@@ -147,6 +172,7 @@ func EnableInjectionOrDie(ctx context.Context, cfg *rest.Config) context.Context
 	ctx = injection.WithConfig(ctx, cfg)
 
 	ctx, informers := injection.Default.SetupInformers(ctx, cfg)
+	ctx = withInformers(ctx, informers)
 
 	// Start the injection clients and informers.
 	logging.FromContext(ctx).Info("Starting informers...")
@@ -160,12 +186,69 @@ func EnableInjectionOrDie(ctx context.Context, cfg *rest.Config) context.Context
 	return ctx
 }
 
+// ReadinessPath is the path on the profiling port at which MainWithConfig
+// serves the readiness of the component, see ReadinessHandler.
+const ReadinessPath = "/readiness"
+
+type informersKey struct{}
+
+func withInformers(ctx context.Context, informers []controller.Informer) context.Context {
+	return context.WithValue(ctx, informersKey{}, informers)
+}
+
+// ReadinessHandler returns an http.Handler that reports the component as
+// ready once the informers started by EnableInjectionOrDie on the given
+// context have synced.
+func ReadinessHandler(ctx context.Context) http.Handler {
+	informers, _ := ctx.Value(informersKey{}).([]controller.Informer)
+	return controller.NewReadinessHandler(informers...)
+}
+
+// shutdownMargin is the time left to flush the metrics and logs after the
+// controllers drained, before a shutdown is considered stuck.
+const shutdownMargin = 15 * time.Second
+
+// newSignalContext returns a context done on the first termination signal,
+// which exits the process if it did not shut down by the time the
+// controllers should have drained, plus shutdownMargin.
+func newSignalContext() context.Context {
+	var drain time.Duration
+	if controller.DefaultDrainTimeout > 0 {
+		drain = controller.DefaultDrainTimeout + shutdownMargin
+	}
+	return signals.NewContextWithDrain(drain)
+}
+
 // Main runs the generic main flow with a new context.
 // If any of the contructed controllers are AdmissionControllers or Conversion webhooks,
 // then a webhook is started to serve them.
 func Main(component string, ctors ...injection.ControllerConstructor) {
 	// Set up signals so we handle the first shutdown signal gracefully.
-	MainWithContext(signals.NewContext(), component, ctors...)
+	MainWithContext(newSignalContext(), component, ctors...)
+}
+
+// WebhookMain runs the generic main flow with a new context for webhook
+// binaries. The webhooks among the constructed controllers are served with
+// the given options, and the certificates they are served with are managed
+// in the options' SecretName. When unset, the port is read from the
+// WEBHOOK_PORT environment variable, defaulting to 8443.
+func WebhookMain(component string, opts webhook.Options, ctors ...injection.ControllerConstructor) {
+	if err := validateWebhookOptions(opts); err != nil {
+		log.Fatal("Invalid webhook options: ", err)
+	}
+	if opts.Port == 0 {
+		opts.Port = webhook.PortFromEnv(8443)
+	}
+	ctx := webhook.WithOptions(newSignalContext(), opts)
+	MainWithContext(ctx, component, append([]injection.ControllerConstructor{certificates.NewController}, ctors...)...)
+}
+
+// validateWebhookOptions checks the options WebhookMain can't default.
+func validateWebhookOptions(opts webhook.Options) error {
+	if opts.SecretName == "" {
+		return errors.New("SecretName must name the Secret holding the webhook's certificates")
+	}
+	return nil
 }
 
 // Legacy aliases for back-compat.
@@ -207,7 +290,10 @@ func IsHADisabled(ctx context.Context) bool {
 }
 
 // MainWithConfig runs the generic main flow for controllers and webhooks
-// with the given config.
+// with the given config. The informer factories cannot change their resync
+// period, so when the one of the resync ConfigMap changes, everything is
+// wound down and the process exits, for the container to be restarted with
+// the new period.
 func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, ctors ...injection.ControllerConstructor) {
 	log.Printf("Registering %d clients", len(injection.Default.GetClients()))
 	log.Printf("Registering %d informer factories", len(injection.Default.GetInformerFactories()))
@@ -215,6 +301,9 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 	log.Printf("Registering %d controllers", len(ctors))
 
 	MemStatsOrDie(ctx)
+	if err := metrics.RegisterRuntimeViews(ctx, component, 30*time.Second); err != nil {
+		log.Fatal("Error registering the runtime metrics views: ", err)
+	}
 
 	// Respect user provided settings, but if omitted customize the default behavior.
 	if cfg.QPS == 0 {
@@ -225,13 +314,46 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 		cfg.Burst = len(ctors) * rest.DefaultBurst
 	}
 
+	defaultResync := controller.GetResyncPeriod(ctx)
+	resync, err := GetResyncPeriod(ctx, cfg)
+	if err != nil {
+		log.Fatal("Error loading resync configuration: ", err)
+	}
+	if run(controller.WithResyncPeriod(ctx, resync), component, cfg, defaultResync, ctors...) {
+		log.Fatal("Exiting to be restarted with the new resync period")
+	}
+}
+
+// run sets up the informers, controllers and webhooks with the resync period
+// associated with the context and runs them until the context is done or the
+// resync period changes. It returns whether it wound down because of the
+// latter.
+func run(ctx context.Context, component string, cfg *rest.Config, defaultResync time.Duration, ctors ...injection.ControllerConstructor) bool {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var resyncChanged int32
+	restart := func() {
+		atomic.StoreInt32(&resyncChanged, 1)
+		cancel()
+	}
+
 	ctx = EnableInjectionOrDie(ctx, cfg)
 
 	logger, atomicLevel := SetupLoggerOrDie(ctx, component)
 	defer flush(logger)
 	ctx = logging.WithLogger(ctx, logger)
+	// The same levels are updated from config-logging and the level handler.
+	levels := logging.NewLevelRegistry()
+	levels.Register(component, atomicLevel)
 	profilingHandler := profiling.NewHandler(logger, false)
-	profilingServer := profiling.NewServer(profilingHandler)
+	mux := http.NewServeMux()
+	mux.Handle("/", profilingHandler)
+	mux.Handle(ReadinessPath, ReadinessHandler(ctx))
+	if token := logging.LevelHandlerToken(); token != "" {
+		mux.Handle(logging.LevelHandlerPath, logging.NewLevelHandler(logger, levels, token))
+	}
+	profilingServer := profiling.NewServer(mux)
 
 	CheckK8sClientMinimumVersionOrDie(ctx, logger)
 	cmw := SetupConfigMapWatchOrDie(ctx, logger)
@@ -249,8 +371,11 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 	}
 
 	controllers, webhooks := ControllersAndWebhooksFromCtors(ctx, cmw, ctors...)
-	WatchLoggingConfigOrDie(ctx, cmw, logger, atomicLevel, component)
+	WatchLoggingLevelsOrDie(ctx, cmw, logger, levels)
 	WatchObservabilityConfigOrDie(ctx, cmw, profilingHandler, logger, component)
+	WatchResyncConfigOrDie(ctx, cmw, logger, defaultResync, restart)
+	tracer := WatchTracingConfigOrDie(ctx, cmw, logger, component)
+	defer tracer.Finish()
 
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(profilingServer.ListenAndServe)
@@ -284,22 +409,36 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 		wh.InformersHaveSynced()
 	}
 	logger.Info("Starting controllers...")
-	go controller.StartAll(ctx, controllers...)
-
-	// This will block until either a signal arrives or one of the grouped functions
-	// returns an error.
+	controllersDone := make(chan struct{})
+	go func() {
+		defer close(controllersDone)
+		controller.StartAll(egCtx, controllers...)
+	}()
+
+	// This will block until either a signal arrives, the resync period
+	// changes, or one of the grouped functions returns an error.
 	<-egCtx.Done()
 
+	// Let the controllers finish their in-flight reconciles (bounded by
+	// controller.DefaultDrainTimeout, if set) before metrics and logs are
+	// flushed.
+	logger.Info("Draining controllers...")
+	<-controllersDone
+
 	profilingServer.Shutdown(context.Background())
 	// Don't forward ErrServerClosed as that indicates we're already shutting down.
 	if err := eg.Wait(); err != nil && err != http.ErrServerClosed {
 		logger.Errorw("Error while running server", zap.Error(err))
 	}
+	return atomic.LoadInt32(&resyncChanged) == 1 && parent.Err() == nil
 }
 
 func flush(logger *zap.SugaredLogger) {
 	logger.Sync()
-	metrics.FlushExporter()
+	// Leave no more than the shutdown margin for a last Prometheus scrape.
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownMargin)
+	defer cancel()
+	metrics.ShutdownExporter(ctx, metrics.ReportingPeriod())
 }
 
 // ParseAndGetConfigOrDie parses the rest config flags and creates a client or
@@ -310,18 +449,83 @@ func ParseAndGetConfigOrDie() *rest.Config {
 			"The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 		kubeconfig = flag.String("kubeconfig", "",
 			"Path to a kubeconfig. Only required if out-of-cluster.")
+		rateLimits   = addRateLimitFlags(flag.CommandLine)
+		printVersion = versionFlag(flag.CommandLine)
 	)
 	klog.InitFlags(flag.CommandLine)
 	flag.Parse()
 
+	if *printVersion {
+		fmt.Println(changeset.Version())
+		os.Exit(0)
+	}
+
 	cfg, err := GetConfig(*serverURL, *kubeconfig)
 	if err != nil {
 		log.Fatalf("Error building kubeconfig: %v", err)
 	}
+	rateLimits.apply(cfg)
 
 	return cfg
 }
 
+// versionFlag defines the --version flag on the given FlagSet, unless the
+// binary already defined its own, which is then left to handle it.
+func versionFlag(fs *flag.FlagSet) *bool {
+	if fs.Lookup("version") != nil {
+		return new(bool)
+	}
+	return fs.Bool("version", false, "Print the commit the binary was built from and exit.")
+}
+
+// rateLimitFlags are the flags tuning the client-side rate limits.
+type rateLimitFlags struct {
+	qps     float64
+	burst   int
+	disable bool
+}
+
+// addRateLimitFlags defines the client-side rate limit flags on the given
+// FlagSet.
+func addRateLimitFlags(fs *flag.FlagSet) *rateLimitFlags {
+	f := &rateLimitFlags{}
+	fs.Float64Var(&f.qps, "kube-api-qps", 0,
+		"The maximum sustained queries per second to the Kubernetes API server. Defaults based on the number of controllers.")
+	fs.IntVar(&f.burst, "kube-api-burst", 0,
+		"The maximum burst of queries to the Kubernetes API server. Defaults based on the number of controllers.")
+	fs.BoolVar(&f.disable, "disable-client-rate-limit", false,
+		"Whether to disable the client-side rate limiting of queries to the Kubernetes API server, "+
+			"leaving it to the server's priority and fairness.")
+	return f
+}
+
+// apply applies the parsed flags to the given config, see ApplyRateLimits.
+func (f *rateLimitFlags) apply(cfg *rest.Config) {
+	qps := float32(f.qps)
+	if f.disable {
+		qps = -1
+	}
+	ApplyRateLimits(cfg, qps, f.burst)
+}
+
+// ApplyRateLimits sets the client-side rate limits of the given config,
+// leaving the ones that are zero to be defaulted. A negative qps disables
+// client-side rate limiting altogether.
+func ApplyRateLimits(cfg *rest.Config, qps float32, burst int) {
+	if qps == 0 && burst == 0 {
+		return
+	}
+	if qps != 0 {
+		cfg.QPS = qps
+	}
+	if burst != 0 {
+		cfg.Burst = burst
+	}
+	// Any rate limiter carried over from the kubeconfig takes precedence
+	// over QPS and Burst.
+	cfg.RateLimiter = nil
+}
+
 // MemStatsOrDie sets up reporting on Go memory usage every 30 seconds or dies
 // by calling log.Fatalf.
 func MemStatsOrDie(ctx context.Context) {
@@ -340,7 +544,9 @@ func SetupLoggerOrDie(ctx context.Context, component string) (*zap.SugaredLogger
 	if err != nil {
 		log.Fatalf("Error reading/parsing logging configuration: %v", err)
 	}
-	l, level := logging.NewLoggerFromConfig(loggingConfig, component)
+	// Keep credentials out of the logs, even at debug level.
+	l, level := logging.NewLoggerFromConfig(loggingConfig, component,
+		logging.WithRedactor(logging.DefaultRedactor()))
 
 	// If PodName is injected into the env vars, set it on the logger.
 	// This is needed for HA components to distinguish logs from different
@@ -383,9 +589,17 @@ func SetupConfigMapWatchOrDie(ctx context.Context, logger *zap.SugaredLogger) *c
 // calling log.Fatalw. Note, if the config does not exist, it will be defaulted
 // and this method will not die.
 func WatchLoggingConfigOrDie(ctx context.Context, cmw *configmap.InformedWatcher, logger *zap.SugaredLogger, atomicLevel zap.AtomicLevel, component string) {
+	levels := logging.NewLevelRegistry()
+	levels.Register(component, atomicLevel)
+	WatchLoggingLevelsOrDie(ctx, cmw, logger, levels)
+}
+
+// WatchLoggingLevelsOrDie is like WatchLoggingConfigOrDie, but updates the
+// level of every component of the given registry.
+func WatchLoggingLevelsOrDie(ctx context.Context, cmw *configmap.InformedWatcher, logger *zap.SugaredLogger, levels *logging.LevelRegistry) {
 	if _, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(ctx, logging.ConfigMapName(),
 		metav1.GetOptions{}); err == nil {
-		cmw.Watch(logging.ConfigMapName(), logging.UpdateLevelFromConfigMap(logger, atomicLevel, component))
+		cmw.Watch(logging.ConfigMapName(), levels.UpdateLevelFromConfigMap(logger))
 	} else if !apierrors.IsNotFound(err) {
 		logger.Fatalw("Error reading ConfigMap "+logging.ConfigMapName(), zap.Error(err))
 	}
@@ -405,6 +619,56 @@ func WatchObservabilityConfigOrDie(ctx context.Context, cmw *configmap.InformedW
 	}
 }
 
+// WatchTracingConfigOrDie establishes a watch of the tracing config or dies by
+// calling log.Fatalw. Note, if the config does not exist, tracing stays
+// disabled and this method will not die. The returned tracer should be
+// finished on shutdown, to flush the pending spans.
+func WatchTracingConfigOrDie(ctx context.Context, cmw *configmap.InformedWatcher, logger *zap.SugaredLogger, component string) *tracing.OpenCensusTracer {
+	tracer := tracing.NewOpenCensusTracer(tracing.WithExporter(component, logger))
+	if _, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(ctx, tracingconfig.ConfigName,
+		metav1.GetOptions{}); err == nil {
+		cmw.Watch(tracingconfig.ConfigName, func(configMap *corev1.ConfigMap) {
+			cfg, err := tracingconfig.NewTracingConfigFromConfigMap(configMap)
+			if err != nil {
+				logger.Errorw("Failed to parse the tracing configmap. Previous config will be used.", zap.Error(err))
+				return
+			}
+			if err := tracer.ApplyConfig(cfg); err != nil {
+				logger.Errorw("Failed to apply the tracing config", zap.Error(err))
+			}
+		})
+	} else if !apierrors.IsNotFound(err) {
+		logger.Fatalw("Error reading ConfigMap "+tracingconfig.ConfigName, zap.Error(err))
+	}
+	return tracer
+}
+
+// WatchResyncConfigOrDie sets up a watch on the resync ConfigMap, if present,
+// calling restart once the resync period it sets, or fallback when it sets
+// none, differs from the one associated with the context. The informer
+// factories cannot change their resync period, so restart is expected to
+// wind them down, for the process to exit and be restarted with the new
+// period, as MainWithConfig does.
+func WatchResyncConfigOrDie(ctx context.Context, cmw *configmap.InformedWatcher, logger *zap.SugaredLogger, fallback time.Duration, restart func()) {
+	if _, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(ctx, controller.ResyncConfigMapName(),
+		metav1.GetOptions{}); err == nil {
+		current := controller.GetResyncPeriod(ctx)
+		cmw.Watch(controller.ResyncConfigMapName(), func(configMap *corev1.ConfigMap) {
+			resync, err := controller.NewResyncPeriodFromConfigMap(configMap, fallback)
+			if err != nil {
+				logger.Errorw("Failed to parse the resync configuration", zap.Error(err))
+				return
+			}
+			if resync != current {
+				logger.Infof("Resync period changed from %v to %v, winding down to restart with it", current, resync)
+				restart()
+			}
+		})
+	} else if !apierrors.IsNotFound(err) {
+		logger.Fatalw("Error reading ConfigMap "+controller.ResyncConfigMapName(), zap.Error(err))
+	}
+}
+
 // SecretFetcher provides a helper function to fetch individual Kubernetes
 // Secrets (for example, a key for client-side TLS). Note that this is not
 // intended for high-volume usage; the current use is when establishing a
@@ -0,0 +1,240 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedmain
+
+import (
+	"context"
+	"flag"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/system"
+	_ "knative.dev/pkg/system/testing" // Setup system.Namespace()
+	"knative.dev/pkg/webhook"
+)
+
+func resyncConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace(),
+			Name:      controller.ResyncConfigMapName(),
+		},
+		Data: data,
+	}
+}
+
+func TestWatchResyncConfig(t *testing.T) {
+	const (
+		current  = 10 * time.Hour
+		fallback = 5 * time.Hour
+	)
+	tests := []struct {
+		name        string
+		update      *corev1.ConfigMap
+		wantRestart bool
+	}{{
+		name:   "same period",
+		update: resyncConfigMap(map[string]string{controller.ResyncPeriodKey: "10h"}),
+	}, {
+		name:        "changed period",
+		update:      resyncConfigMap(map[string]string{controller.ResyncPeriodKey: "1h"}),
+		wantRestart: true,
+	}, {
+		name:        "period removed, falling back",
+		update:      resyncConfigMap(nil),
+		wantRestart: true,
+	}, {
+		name:   "invalid period",
+		update: resyncConfigMap(map[string]string{controller.ResyncPeriodKey: "-1h"}),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, kc := fakekubeclient.With(context.Background(),
+				resyncConfigMap(map[string]string{controller.ResyncPeriodKey: "10h"}))
+			ctx = controller.WithResyncPeriod(ctx, current)
+			cmw := configmap.NewInformedWatcher(kc, system.Namespace())
+
+			restarted := false
+			WatchResyncConfigOrDie(ctx, cmw, TestLogger(t), fallback, func() { restarted = true })
+			cmw.OnChange(test.update)
+
+			if restarted != test.wantRestart {
+				t.Errorf("restarted = %v, want: %v", restarted, test.wantRestart)
+			}
+		})
+	}
+}
+
+func TestWatchResyncConfigWithoutConfigMap(t *testing.T) {
+	ctx, kc := fakekubeclient.With(context.Background())
+	ctx = controller.WithResyncPeriod(ctx, time.Hour)
+	cmw := configmap.NewInformedWatcher(kc, system.Namespace())
+
+	restarted := false
+	WatchResyncConfigOrDie(ctx, cmw, TestLogger(t), 10*time.Hour, func() { restarted = true })
+	// Nothing watches the ConfigMap when it doesn't exist at startup.
+	cmw.OnChange(resyncConfigMap(map[string]string{controller.ResyncPeriodKey: "1m"}))
+
+	if restarted {
+		t.Error("restarted = true, want: false")
+	}
+}
+
+func TestWatchLoggingLevels(t *testing.T) {
+	loggingConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      logging.ConfigMapName(),
+			},
+			Data: data,
+		}
+	}
+	ctx, kc := fakekubeclient.With(context.Background(), loggingConfigMap(nil))
+	cmw := configmap.NewInformedWatcher(kc, system.Namespace())
+
+	controllerLevel, webhookLevel := zap.NewAtomicLevel(), zap.NewAtomicLevel()
+	levels := logging.NewLevelRegistry()
+	levels.Register("controller", controllerLevel)
+	levels.Register("webhook", webhookLevel)
+
+	WatchLoggingLevelsOrDie(ctx, cmw, TestLogger(t), levels)
+	cmw.OnChange(loggingConfigMap(map[string]string{
+		"loglevel.controller": "debug",
+		"loglevel.webhook":    "error",
+	}))
+
+	if got, want := controllerLevel.Level(), zapcore.DebugLevel; got != want {
+		t.Errorf("controller level = %v, want: %v", got, want)
+	}
+	if got, want := webhookLevel.Level(), zapcore.ErrorLevel; got != want {
+		t.Errorf("webhook level = %v, want: %v", got, want)
+	}
+}
+
+func TestVersionFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	printVersion := versionFlag(fs)
+	if err := fs.Parse([]string{"--version"}); err != nil {
+		t.Fatal("Parse() =", err)
+	}
+	if !*printVersion {
+		t.Error("--version was not parsed")
+	}
+
+	// A binary defining its own version flag keeps it.
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	own := fs.String("version", "", "The version to report.")
+	printVersion = versionFlag(fs)
+	if err := fs.Parse([]string{"--version=v1"}); err != nil {
+		t.Fatal("Parse() =", err)
+	}
+	if *printVersion {
+		t.Error("versionFlag() took over the binary's version flag")
+	}
+	if got, want := *own, "v1"; got != want {
+		t.Errorf("version = %q, want: %q", got, want)
+	}
+}
+
+func TestRateLimitFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantQPS     float32
+		wantBurst   int
+		wantLimiter bool
+	}{{
+		name:        "defaults keep the kubeconfig limits",
+		wantQPS:     5,
+		wantBurst:   10,
+		wantLimiter: true,
+	}, {
+		name:        "qps and burst",
+		args:        []string{"--kube-api-qps=50", "--kube-api-burst=100"},
+		wantQPS:     50,
+		wantBurst:   100,
+		wantLimiter: true,
+	}, {
+		name:      "disabled",
+		args:      []string{"--kube-api-burst=100", "--disable-client-rate-limit"},
+		wantQPS:   -1,
+		wantBurst: 100,
+	}, {
+		name:      "negative qps",
+		args:      []string{"--kube-api-qps=-1"},
+		wantQPS:   -1,
+		wantBurst: 10,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			rateLimits := addRateLimitFlags(fs)
+			if err := fs.Parse(test.args); err != nil {
+				t.Fatal("Parse() =", err)
+			}
+
+			cfg := &rest.Config{
+				Host:        "https://kubernetes.default.svc",
+				QPS:         5,
+				Burst:       10,
+				RateLimiter: flowcontrol.NewTokenBucketRateLimiter(5, 10),
+			}
+			rateLimits.apply(cfg)
+			if cfg.QPS != test.wantQPS || cfg.Burst != test.wantBurst {
+				t.Errorf("QPS, Burst = %v, %v, want: %v, %v", cfg.QPS, cfg.Burst, test.wantQPS, test.wantBurst)
+			}
+
+			// The limits reach the clients built from the config.
+			kc, err := kubernetes.NewForConfig(cfg)
+			if err != nil {
+				t.Fatal("NewForConfig() =", err)
+			}
+			limiter := kc.CoreV1().RESTClient().GetRateLimiter()
+			if got := limiter != nil; got != test.wantLimiter {
+				t.Fatalf("Client rate limited = %v, want: %v", got, test.wantLimiter)
+			}
+			if limiter != nil && limiter.QPS() != test.wantQPS {
+				t.Errorf("Client QPS = %v, want: %v", limiter.QPS(), test.wantQPS)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookOptions(t *testing.T) {
+	if err := validateWebhookOptions(webhook.Options{}); err == nil {
+		t.Error("validateWebhookOptions() = nil, wanted an error without a SecretName")
+	}
+	if err := validateWebhookOptions(webhook.Options{SecretName: "webhook-certs"}); err != nil {
+		t.Error("validateWebhookOptions() =", err)
+	}
+}
@@ -61,6 +61,18 @@ func DeletionHandlingAccessor(obj interface{}) (Accessor, error) {
 	return accessor, nil
 }
 
+// DeletionHandlingObject returns the metav1.Object behind obj, looking
+// through the tombstones informers hand out on deletions they missed the
+// final state of. Unlike DeletionHandlingAccessor it does not require the
+// TypeMeta accessors, so it works for any object an informer delivers.
+func DeletionHandlingObject(obj interface{}) (metav1.Object, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, ok := obj.(metav1.Object)
+	return object, ok
+}
+
 // ObjectReference returns an core/v1.ObjectReference for the given object
 func ObjectReference(obj Accessor) corev1.ObjectReference {
 	gvk := obj.GroupVersionKind()
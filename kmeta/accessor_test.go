@@ -77,6 +77,42 @@ func TestAccessor(t *testing.T) {
 	}
 }
 
+func TestDeletionHandlingObject(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm"}}
+
+	tests := []struct {
+		name string
+		o    interface{}
+		want metav1.Object
+	}{{
+		name: "not an object",
+		o:    struct{}{},
+	}, {
+		name: "deleted with bad final state",
+		o:    cache.DeletedFinalStateUnknown{Obj: struct{}{}},
+	}, {
+		name: "object without TypeMeta accessors",
+		o:    cm,
+		want: cm,
+	}, {
+		name: "deleted with good final state",
+		o:    cache.DeletedFinalStateUnknown{Obj: cm},
+		want: cm,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := DeletionHandlingObject(test.o)
+			if ok != (test.want != nil) {
+				t.Fatalf("DeletionHandlingObject() = %v, %v", got, ok)
+			}
+			if ok && got != test.want {
+				t.Errorf("DeletionHandlingObject() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
 func TestObjectReference(t *testing.T) {
 	r := &Resource{
 		TypeMeta: metav1.TypeMeta{
@@ -27,7 +27,14 @@ import (
 const (
 	longest = 63
 	md5Len  = 32
-	head    = longest - md5Len // How much to truncate to fit the hash.
+
+	// DNSLabelMaxLength is the longest name of resources whose names must
+	// be DNS labels, e.g. Services. ChildName produces names of this length.
+	DNSLabelMaxLength = longest
+
+	// DNSSubdomainMaxLength is the longest name of resources whose names
+	// must be DNS subdomains, e.g. ConfigMaps or Secrets.
+	DNSSubdomainMaxLength = 253
 )
 
 // ChildName generates a name for the resource based upon the parent resource and suffix.
@@ -37,8 +44,24 @@ const (
 // and `parent|hash|suffix` will be returned, where parent and suffix will be trimmed to
 // fit (prefix of parent at most of length 31, and prefix of suffix at most length 30).
 func ChildName(parent, suffix string) string {
+	return BoundedChildName(parent, suffix, longest)
+}
+
+// BoundedChildName is like ChildName, but produces names of at most
+// limit characters, e.g. DNSSubdomainMaxLength. When the limit leaves no
+// room next to the 32 characters of the hash, names that don't fit are
+// replaced by the hash, truncated to the limit.
+func BoundedChildName(parent, suffix string, limit int) string {
+	if limit <= md5Len && len(parent)+len(suffix) > limit {
+		if limit <= 0 {
+			return ""
+		}
+		// nolint:gosec // No strong cryptography needed.
+		return fmt.Sprintf("%x", md5.Sum([]byte(parent+suffix)))[:limit]
+	}
+	head := limit - md5Len // How much to truncate to fit the hash.
 	n := parent
-	if len(parent) > (longest - len(suffix)) {
+	if len(parent) > (limit - len(suffix)) {
 		// If the suffix is longer than the longest allowed suffix, then
 		// we hash the whole combined string and use that as the suffix.
 		if head-len(suffix) <= 0 {
@@ -48,11 +71,11 @@ func ChildName(parent, suffix string) string {
 			if head < len(parent) {
 				parent = parent[:head]
 			}
-			// Format the return string, if it's shorter than longest: pad with
+			// Format the return string, if it's shorter than limit: pad with
 			// beginning of the suffix. This happens, for example, when parent is
 			// short, but the suffix is very long.
 			ret := parent + fmt.Sprintf("%x", h)
-			if d := longest - len(ret); d > 0 {
+			if d := limit - len(ret); d > 0 {
 				ret += suffix[:d]
 			}
 			// If due to trimming above we're terminating the string with a `-`,
@@ -17,6 +17,7 @@ limitations under the license.
 package kmeta
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 
@@ -66,3 +67,50 @@ func TestChildName(t *testing.T) {
 		})
 	}
 }
+
+func TestBoundedChildName(t *testing.T) {
+	parent := strings.Repeat("f", 250)
+	if got, want := BoundedChildName("asdf", "-config", DNSSubdomainMaxLength), "asdf-config"; got != want {
+		t.Errorf("BoundedChildName() = %s, want %s", got, want)
+	}
+	if got, want := BoundedChildName(parent[:63], "-config", DNSSubdomainMaxLength), parent[:63]+"-config"; got != want {
+		t.Errorf("BoundedChildName() = %s, want %s", got, want)
+	}
+
+	got := BoundedChildName(parent, "-config", DNSSubdomainMaxLength)
+	if len(got) != DNSSubdomainMaxLength || !strings.HasSuffix(got, "-config") {
+		t.Errorf("BoundedChildName() = %s (%d chars), want %d chars ending with -config", got, len(got), DNSSubdomainMaxLength)
+	}
+	if again := BoundedChildName(parent, "-config", DNSSubdomainMaxLength); again != got {
+		t.Errorf("BoundedChildName() is not deterministic: %s != %s", again, got)
+	}
+	if other := BoundedChildName(parent+"g", "-config", DNSSubdomainMaxLength); other == got {
+		t.Error("BoundedChildName() collided for different parents")
+	}
+
+	if got, want := BoundedChildName(parent, "-deployment", DNSLabelMaxLength), ChildName(parent, "-deployment"); got != want {
+		t.Errorf("BoundedChildName(DNSLabelMaxLength) = %s, want %s", got, want)
+	}
+}
+
+func TestBoundedChildNameShortLimit(t *testing.T) {
+	for _, limit := range []int{1, 13, 20, md5Len} {
+		t.Run(strconv.Itoa(limit), func(t *testing.T) {
+			got := BoundedChildName("parent", "-a-suffix-longer-than-the-hash-of-md5", limit)
+			if len(got) != limit {
+				t.Errorf("BoundedChildName() = %s (%d chars), want %d chars", got, len(got), limit)
+			}
+			if other := BoundedChildName("other", "-a-suffix-longer-than-the-hash-of-md5", limit); other == got {
+				t.Errorf("BoundedChildName() collided for different parents: %s", got)
+			}
+		})
+	}
+
+	// Names that fit are kept as is.
+	if got, want := BoundedChildName("parent", "-suffix", 13), "parent-suffix"; got != want {
+		t.Errorf("BoundedChildName() = %s, want %s", got, want)
+	}
+	if got := BoundedChildName("parent", "-suffix", 0); got != "" {
+		t.Errorf("BoundedChildName() = %s, want an empty name", got)
+	}
+}
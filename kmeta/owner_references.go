@@ -17,6 +17,8 @@ limitations under the License.
 package kmeta
 
 import (
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -36,3 +38,32 @@ type OwnerRefable interface {
 func NewControllerRef(obj OwnerRefable) *metav1.OwnerReference {
 	return metav1.NewControllerRef(obj.GetObjectMeta(), obj.GetGroupVersionKind())
 }
+
+// SetControllerRef makes owner the controller of obj. It is a no-op if owner
+// already controls obj, and an error if obj is controlled by another object.
+func SetControllerRef(obj metav1.Object, owner OwnerRefable) error {
+	ref := NewControllerRef(owner)
+	if existing := metav1.GetControllerOf(obj); existing != nil {
+		if existing.UID == ref.UID {
+			return nil
+		}
+		return fmt.Errorf("object %q is already controlled by %s %q", obj.GetName(), existing.Kind, existing.Name)
+	}
+	obj.SetOwnerReferences(append(obj.GetOwnerReferences(), *ref))
+	return nil
+}
+
+// FilterControlledBy returns a filter for informer event handlers that
+// passes the objects whose controller is owner, including deleted ones.
+// Unlike controller.FilterControllerGVK, it matches the owner by UID.
+func FilterControlledBy(owner OwnerRefable) func(obj interface{}) bool {
+	uid := owner.GetObjectMeta().GetUID()
+	return func(obj interface{}) bool {
+		accessor, err := DeletionHandlingAccessor(obj)
+		if err != nil {
+			return false
+		}
+		ref := metav1.GetControllerOf(accessor)
+		return ref != nil && ref.UID == uid
+	}
+}
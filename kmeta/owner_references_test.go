@@ -20,8 +20,10 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
 )
 
 type Frobber struct {
@@ -62,3 +64,68 @@ func TestNewControllerRef(t *testing.T) {
 		t.Error("Unexpected OwnerReference (-want +got):", diff)
 	}
 }
+
+func TestSetControllerRef(t *testing.T) {
+	owner := &Frobber{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "42"}}
+	other := &Frobber{ObjectMeta: metav1.ObjectMeta{Name: "bar", UID: "43"}}
+	child := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "child",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Namespace",
+				Name:       "ns",
+				UID:        "1",
+			}},
+		},
+	}
+
+	if err := SetControllerRef(child, owner); err != nil {
+		t.Fatal("SetControllerRef() =", err)
+	}
+	want := []metav1.OwnerReference{child.OwnerReferences[0], *NewControllerRef(owner)}
+	if diff := cmp.Diff(want, child.OwnerReferences); diff != "" {
+		t.Error("Unexpected OwnerReferences (-want +got):", diff)
+	}
+
+	// Setting the same controller again is a no-op.
+	if err := SetControllerRef(child, owner); err != nil {
+		t.Fatal("SetControllerRef() =", err)
+	}
+	if got := len(child.OwnerReferences); got != 2 {
+		t.Errorf("len(OwnerReferences) = %d, want 2", got)
+	}
+
+	err := SetControllerRef(child, other)
+	if err == nil {
+		t.Fatal("SetControllerRef() = nil, wanted an error for a second controller")
+	}
+	if got, want := err.Error(), `object "child" is already controlled by Frobber "foo"`; got != want {
+		t.Errorf("SetControllerRef() = %q, want: %q", got, want)
+	}
+}
+
+func TestFilterControlledBy(t *testing.T) {
+	owner := &Frobber{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "42"}}
+	other := &Frobber{ObjectMeta: metav1.ObjectMeta{Name: "foo", UID: "43"}}
+	filter := FilterControlledBy(owner)
+
+	child := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "child"}}
+	if filter(child) {
+		t.Error("filter() = true for an object without controller")
+	}
+
+	SetControllerRef(child, owner)
+	if !filter(child) {
+		t.Error("filter() = false for a controlled object")
+	}
+	if !filter(cache.DeletedFinalStateUnknown{Key: "child", Obj: child}) {
+		t.Error("filter() = false for a deleted controlled object")
+	}
+	if FilterControlledBy(other)(child) {
+		t.Error("filter() = true for an object controlled by another object with the same name")
+	}
+	if filter("not an object") {
+		t.Error("filter() = true for something that is not an object")
+	}
+}
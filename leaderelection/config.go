@@ -27,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 
 	cm "knative.dev/pkg/configmap"
@@ -56,8 +57,8 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		return nil, err
 	}
 
-	if config.Buckets < 1 || config.Buckets > MaxBuckets {
-		return nil, fmt.Errorf("buckets: value must be between %d <= %d <= %d", 1, config.Buckets, MaxBuckets)
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
 	return config, nil
 }
@@ -86,6 +87,27 @@ type Config struct {
 	EnabledComponents sets.String
 }
 
+// Validate checks that the bucket count is within bounds and that the
+// durations satisfy the constraints client-go's leader elector puts on them.
+func (c *Config) Validate() error {
+	if c.Buckets < 1 || c.Buckets > MaxBuckets {
+		return fmt.Errorf("buckets: value must be between %d <= %d <= %d", 1, c.Buckets, MaxBuckets)
+	}
+	if c.RetryPeriod <= 0 {
+		return fmt.Errorf("retryPeriod: value must be positive, was %v", c.RetryPeriod)
+	}
+	if c.RenewDeadline <= time.Duration(leaderelection.JitterFactor*float64(c.RetryPeriod)) {
+		return fmt.Errorf("renewDeadline: value %v must be greater than retryPeriod %v * %v",
+			c.RenewDeadline, c.RetryPeriod, leaderelection.JitterFactor)
+	}
+	if c.LeaseDuration <= c.RenewDeadline {
+		return fmt.Errorf("leaseDuration: value %v must be greater than renewDeadline %v",
+			c.LeaseDuration, c.RenewDeadline)
+	}
+	return nil
+}
+
+// GetComponentConfig returns the ComponentConfig for the named component.
 func (c *Config) GetComponentConfig(name string) ComponentConfig {
 	return ComponentConfig{
 		Component:     name,
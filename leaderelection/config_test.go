@@ -113,6 +113,24 @@ func TestNewConfigMapFromData(t *testing.T) {
 			"buckets": strconv.Itoa(int(MaxBuckets + 1)),
 		}),
 		err: fmt.Sprintf("buckets: value must be between 1 <= %d <= %d", MaxBuckets+1, MaxBuckets),
+	}, {
+		name: "invalid retryPeriod - not positive",
+		data: kmeta.UnionMaps(okData(), map[string]string{
+			"retryPeriod": "0s",
+		}),
+		err: "retryPeriod: value must be positive, was 0s",
+	}, {
+		name: "invalid renewDeadline - not greater than retryPeriod",
+		data: kmeta.UnionMaps(okData(), map[string]string{
+			"renewDeadline": "2s",
+		}),
+		err: "renewDeadline: value 2s must be greater than retryPeriod 2s * 1.2",
+	}, {
+		name: "invalid leaseDuration - not greater than renewDeadline",
+		data: kmeta.UnionMaps(okData(), map[string]string{
+			"leaseDuration": "10s",
+		}),
+		err: "leaseDuration: value 10s must be greater than renewDeadline 10s",
 	}}
 
 	for _, tc := range cases {
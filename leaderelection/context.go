@@ -173,6 +173,12 @@ func (b *standardBuilder) buildElector(ctx context.Context, la reconciler.Leader
 }
 
 func newStandardBuckets(queueName string, cc ComponentConfig) []reconciler.Bucket {
+	if cc.Buckets == 0 {
+		// An unset bucket count would leave us without any lease to
+		// acquire, and so nobody would ever reconcile the queue.
+		// Treat it as a single bucket, i.e. classic single-leader election.
+		cc.Buckets = 1
+	}
 	ln := cc.LeaseName
 	if ln == nil {
 		ln = func(i uint32) string {
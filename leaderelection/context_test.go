@@ -221,6 +221,19 @@ func TestBuilderWithCustomizedLeaseName(t *testing.T) {
 	}
 }
 
+func TestNewStandardBucketsDefaultsToOne(t *testing.T) {
+	bkts := newStandardBuckets("queue", ComponentConfig{Component: "the-component"})
+	if got, want := len(bkts), 1; got != want {
+		t.Fatalf("len(buckets) = %d, want: %d", got, want)
+	}
+	if got, want := bkts[0].Name(), "the-component.queue.00-of-01"; got != want {
+		t.Errorf("Name() = %q, want: %q", got, want)
+	}
+	if !bkts[0].Has(types.NamespacedName{Namespace: "ns", Name: "name"}) {
+		t.Error("The single bucket should own every key")
+	}
+}
+
 func TestNewStatefulSetBucketAndSet(t *testing.T) {
 	wantNames := []string{
 		"http://as-0.autoscaler.knative-testing.svc.cluster.local:80",
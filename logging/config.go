@@ -21,11 +21,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"knative.dev/pkg/changeset"
 	"knative.dev/pkg/logging/logkey"
@@ -35,6 +37,31 @@ const (
 	configMapNameEnv   = "CONFIG_LOGGING_NAME"
 	loggerConfigKey    = "zap-logger-config"
 	fallbackLoggerName = "fallback-logger"
+
+	// The keys below override the matching fields of the zap-logger-config,
+	// so that the common knobs don't require editing its JSON.
+	encodingKey          = "encoding"
+	timeEncoderKey       = "time-encoder"
+	disableCallerKey     = "disable-caller"
+	disableStacktraceKey = "disable-stacktrace"
+	outputPathsKey       = "output-paths"
+	errorOutputPathsKey  = "error-output-paths"
+
+	// Sampling logs the first `sampling.initial` entries with the same level
+	// and message each second, and every `sampling.thereafter`th entry after
+	// that, dropping the rest.
+	samplingInitialKey    = "sampling.initial"
+	samplingThereafterKey = "sampling.thereafter"
+
+	// The sampling settings of zap.NewProductionConfig, used when only one
+	// of them is set.
+	defaultSamplingInitial    = 100
+	defaultSamplingThereafter = 100
+)
+
+var (
+	validEncodings    = sets.NewString("json", "console", StackdriverEncoding)
+	validTimeEncoders = sets.NewString("iso8601", "rfc3339", "rfc3339nano", "epoch", "millis", "nanos")
 )
 
 var (
@@ -166,6 +193,11 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 	if zlc, ok := data[loggerConfigKey]; ok {
 		lc.LoggingConfig = zlc
 	}
+	zlc, err := applyZapOverrides(lc.LoggingConfig, data)
+	if err != nil {
+		return nil, err
+	}
+	lc.LoggingConfig = zlc
 
 	for k, v := range data {
 		if component := strings.TrimPrefix(k, "loglevel."); component != k && component != "" {
@@ -181,6 +213,147 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 	return lc, nil
 }
 
+// applyZapOverrides sets the encoder, caller, stacktrace and output settings
+// found in data on the given zap-logger-config JSON.
+func applyZapOverrides(zlc string, data map[string]string) (string, error) {
+	encoding := data[encodingKey]
+	timeEncoder := data[timeEncoderKey]
+	outputPaths := pathsFromString(data[outputPathsKey])
+	errorOutputPaths := pathsFromString(data[errorOutputPathsKey])
+	disableCaller, err := boolFromData(data, disableCallerKey)
+	if err != nil {
+		return "", err
+	}
+	disableStacktrace, err := boolFromData(data, disableStacktraceKey)
+	if err != nil {
+		return "", err
+	}
+	samplingInitial, err := int32FromData(data, samplingInitialKey)
+	if err != nil {
+		return "", err
+	}
+	samplingThereafter, err := int32FromData(data, samplingThereafterKey)
+	if err != nil {
+		return "", err
+	}
+
+	overrides := make(map[string]interface{})
+	if encoding != "" {
+		if !validEncodings.Has(encoding) {
+			return "", fmt.Errorf("invalid %s %q, must be one of %v", encodingKey, encoding, validEncodings.List())
+		}
+		overrides["encoding"] = encoding
+	}
+	if _, ok := data[disableCallerKey]; ok {
+		overrides["disableCaller"] = disableCaller
+	}
+	if _, ok := data[disableStacktraceKey]; ok {
+		overrides["disableStacktrace"] = disableStacktrace
+	}
+	if len(outputPaths) > 0 {
+		overrides["outputPaths"] = outputPaths
+	}
+	if len(errorOutputPaths) > 0 {
+		overrides["errorOutputPaths"] = errorOutputPaths
+	}
+	sampling := make(map[string]interface{})
+	if _, ok := data[samplingInitialKey]; ok {
+		if samplingInitial <= 0 {
+			return "", fmt.Errorf("%s must be positive, was: %d", samplingInitialKey, samplingInitial)
+		}
+		sampling["initial"] = samplingInitial
+	}
+	if _, ok := data[samplingThereafterKey]; ok {
+		if samplingThereafter < 0 {
+			return "", fmt.Errorf("%s must not be negative, was: %d", samplingThereafterKey, samplingThereafter)
+		}
+		sampling["thereafter"] = samplingThereafter
+	}
+	if timeEncoder != "" && !validTimeEncoders.Has(timeEncoder) {
+		return "", fmt.Errorf("invalid %s %q, must be one of %v", timeEncoderKey, timeEncoder, validTimeEncoders.List())
+	}
+	if len(overrides) == 0 && timeEncoder == "" && len(sampling) == 0 {
+		return zlc, nil
+	}
+
+	if zlc == "" {
+		zlc = defaultZLC
+	}
+	var cfg map[string]interface{}
+	if err := json.Unmarshal([]byte(zlc), &cfg); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", loggerConfigKey, err)
+	}
+	for k, v := range overrides {
+		cfg[k] = v
+	}
+	if timeEncoder != "" {
+		encoderCfg, _ := cfg["encoderConfig"].(map[string]interface{})
+		if encoderCfg == nil {
+			encoderCfg = make(map[string]interface{})
+		}
+		encoderCfg["timeEncoder"] = timeEncoder
+		cfg["encoderConfig"] = encoderCfg
+	}
+	if len(sampling) > 0 {
+		// Each of the settings defaults to the one of the zap-logger-config,
+		// or else to the one of the zap production config.
+		samplingCfg, _ := cfg["sampling"].(map[string]interface{})
+		if samplingCfg == nil {
+			samplingCfg = map[string]interface{}{
+				"initial":    defaultSamplingInitial,
+				"thereafter": defaultSamplingThereafter,
+			}
+		}
+		for k, v := range sampling {
+			samplingCfg[k] = v
+		}
+		cfg["sampling"] = samplingCfg
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// pathsFromString splits the comma separated list of paths, keeping their
+// configured order.
+func pathsFromString(raw string) []string {
+	var paths []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// boolFromData parses the value at key as a boolean, if it exists.
+func boolFromData(data map[string]string, key string) (bool, error) {
+	raw, ok := data[key]
+	if !ok {
+		return false, nil
+	}
+	val, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %q: %w", key, err)
+	}
+	return val, nil
+}
+
+// int32FromData parses the value at key as an int32, if it exists.
+func int32FromData(data map[string]string, key string) (int32, error) {
+	raw, ok := data[key]
+	if !ok {
+		return 0, nil
+	}
+	val, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q: %w", key, err)
+	}
+	return int32(val), nil
+}
+
 // NewConfigFromConfigMap creates a Config from the supplied ConfigMap,
 // expecting the given list of components.
 func NewConfigFromConfigMap(configMap *corev1.ConfigMap) (*Config, error) {
@@ -207,20 +380,11 @@ func UpdateLevelFromConfigMap(logger *zap.SugaredLogger, atomicLevel zap.AtomicL
 			return
 		}
 
-		level, defined := config.LoggingLevel[levelKey]
-		if !defined {
-			// reset to global level
-			loggingCfg, err := zapConfigFromJSON(config.LoggingConfig)
-			switch {
-			case err == errEmptyLoggerConfig:
-				level = zap.NewAtomicLevel().Level()
-			case err != nil:
-				logger.With(zap.Error(err)).Errorf("Failed to parse logger configuration. "+
-					"Previous log level retained for %v", levelKey)
-				return
-			default:
-				level = loggingCfg.Level.Level()
-			}
+		level, err := componentLevel(config, levelKey)
+		if err != nil {
+			logger.With(zap.Error(err)).Errorf("Failed to parse logger configuration. "+
+				"Previous log level retained for %v", levelKey)
+			return
 		}
 
 		if atomicLevel.Level() != level {
@@ -230,6 +394,24 @@ func UpdateLevelFromConfigMap(logger *zap.SugaredLogger, atomicLevel zap.AtomicL
 	}
 }
 
+// componentLevel returns the logging level of the given component, falling
+// back to the global level when the config doesn't define one for it.
+func componentLevel(config *Config, component string) (zapcore.Level, error) {
+	if level, defined := config.LoggingLevel[component]; defined {
+		return level, nil
+	}
+	// reset to global level
+	loggingCfg, err := zapConfigFromJSON(config.LoggingConfig)
+	switch {
+	case err == errEmptyLoggerConfig:
+		return zap.NewAtomicLevel().Level(), nil
+	case err != nil:
+		return zapcore.InfoLevel, err
+	default:
+		return loggingCfg.Level.Level(), nil
+	}
+}
+
 // ConfigMapName gets the name of the logging ConfigMap
 func ConfigMapName() string {
 	if cm := os.Getenv(configMapNameEnv); cm != "" {
@@ -494,3 +494,100 @@ func TestConfigMapName(t *testing.T) {
 		t.Errorf("ConfigMapName = %q, want: %q", got, want)
 	}
 }
+
+func TestNewConfigZapOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    string
+		wantErr bool
+	}{{
+		name: "no overrides",
+		data: map[string]string{loggerConfigKey: `{"level": "info"}`},
+		want: `{"level": "info"}`,
+	}, {
+		name: "encoder and outputs",
+		data: map[string]string{
+			loggerConfigKey:      `{"level": "info", "encoding": "json"}`,
+			encodingKey:          "console",
+			timeEncoderKey:       "rfc3339",
+			disableCallerKey:     "true",
+			disableStacktraceKey: "false",
+			outputPathsKey:       "stdout, stderr",
+			errorOutputPathsKey:  "stderr",
+		},
+		want: `{"disableCaller":true,"disableStacktrace":false,"encoderConfig":{"timeEncoder":"rfc3339"},` +
+			`"encoding":"console","errorOutputPaths":["stderr"],"level":"info","outputPaths":["stdout","stderr"]}`,
+	}, {
+		name:    "invalid encoding",
+		data:    map[string]string{encodingKey: "xml"},
+		wantErr: true,
+	}, {
+		name:    "invalid time encoder",
+		data:    map[string]string{timeEncoderKey: "sundial"},
+		wantErr: true,
+	}, {
+		name:    "invalid bool",
+		data:    map[string]string{disableCallerKey: "maybe"},
+		wantErr: true,
+	}, {
+		name: "sampling",
+		data: map[string]string{
+			loggerConfigKey:       `{"level": "info"}`,
+			samplingInitialKey:    "10",
+			samplingThereafterKey: "0",
+		},
+		want: `{"level":"info","sampling":{"initial":10,"thereafter":0}}`,
+	}, {
+		name: "sampling initial only",
+		data: map[string]string{
+			loggerConfigKey:    `{"level": "info"}`,
+			samplingInitialKey: "10",
+		},
+		want: `{"level":"info","sampling":{"initial":10,"thereafter":100}}`,
+	}, {
+		name: "sampling thereafter only",
+		data: map[string]string{
+			loggerConfigKey:       `{"level": "info"}`,
+			samplingThereafterKey: "5",
+		},
+		want: `{"level":"info","sampling":{"initial":100,"thereafter":5}}`,
+	}, {
+		name: "sampling thereafter only, with the initial of the config",
+		data: map[string]string{
+			loggerConfigKey:       `{"level": "info", "sampling": {"initial": 20, "thereafter": 50}}`,
+			samplingThereafterKey: "5",
+		},
+		want: `{"level":"info","sampling":{"initial":20,"thereafter":5}}`,
+	}, {
+		name:    "invalid sampling initial",
+		data:    map[string]string{samplingInitialKey: "0"},
+		wantErr: true,
+	}, {
+		name:    "invalid sampling thereafter",
+		data:    map[string]string{samplingThereafterKey: "-1"},
+		wantErr: true,
+	}, {
+		name:    "malformed sampling initial",
+		data:    map[string]string{samplingInitialKey: "many"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := NewConfigFromMap(test.data)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewConfigFromMap() = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if c.LoggingConfig != test.want {
+				t.Errorf("LoggingConfig = %s, want %s", c.LoggingConfig, test.want)
+			}
+			if logger, _ := NewLoggerFromConfig(c, "test"); logger == nil {
+				t.Error("Expected a non-nil logger")
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// LevelHandlerPath is the path the LevelHandler is conventionally
+	// served on.
+	LevelHandlerPath = "/debug/loglevel"
+
+	// LevelHandlerTokenEnv is the environment variable holding the bearer
+	// token required by the LevelHandler. The handler isn't served when
+	// it's unset.
+	LevelHandlerTokenEnv = "LOG_LEVEL_TOKEN"
+)
+
+// LevelHandler serves the log levels of the components registered in a
+// LevelRegistry, and allows changing them, so that the logging of a
+// single pod can be adjusted without touching the logging ConfigMap.
+//
+//	GET  /debug/loglevel                              lists all levels
+//	GET  /debug/loglevel?component=controller         shows one level
+//	PUT  /debug/loglevel?component=controller&level=debug  sets one level
+//
+// Requests must carry an `Authorization: Bearer <token>` header.
+type LevelHandler struct {
+	registry *LevelRegistry
+	token    string
+	log      *zap.SugaredLogger
+}
+
+// NewLevelHandler creates a LevelHandler over the given registry that
+// authenticates requests with the given token. An empty token rejects
+// every request.
+func NewLevelHandler(logger *zap.SugaredLogger, registry *LevelRegistry, token string) *LevelHandler {
+	return &LevelHandler{
+		registry: registry,
+		token:    token,
+		log:      logger,
+	}
+}
+
+// LevelHandlerToken returns the token configured through
+// LevelHandlerTokenEnv.
+func LevelHandlerToken() string {
+	return os.Getenv(LevelHandlerTokenEnv)
+}
+
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	component := r.URL.Query().Get("component")
+	switch r.Method {
+	case http.MethodGet:
+		levels := make(map[string]string)
+		if component != "" {
+			level, ok := h.registry.Level(component)
+			if !ok {
+				http.Error(w, fmt.Sprintf("unknown component %q", component), http.StatusNotFound)
+				return
+			}
+			levels[component] = level.Level().String()
+		} else {
+			for _, c := range h.registry.Components() {
+				level, _ := h.registry.Level(c)
+				levels[c] = level.Level().String()
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(levels)
+
+	case http.MethodPut, http.MethodPost:
+		atomicLevel, ok := h.registry.Level(component)
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown component %q", component), http.StatusNotFound)
+			return
+		}
+		level, err := levelFromString(r.URL.Query().Get("level"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.log.Infof("Updating logging level for %v from %v to %v through %s.",
+			component, atomicLevel.Level(), *level, LevelHandlerPath)
+		atomicLevel.SetLevel(*level)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPut, http.MethodPost}, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *LevelHandler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		return false
+	}
+	got := r.Header.Get("Authorization")
+	if !strings.HasPrefix(got, "Bearer ") {
+		return false
+	}
+	got = strings.TrimPrefix(got, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) == 1
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelHandler(t *testing.T) {
+	const token = "s3cr3t"
+	logger, _ := NewLogger("", "debug")
+	controller := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	r := NewLevelRegistry()
+	r.Register("controller", controller)
+
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		auth     string
+		token    string
+		wantCode int
+		wantBody string
+		want     zapcore.Level
+	}{{
+		name:     "no token configured",
+		method:   http.MethodGet,
+		auth:     "Bearer ",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "missing auth",
+		method:   http.MethodGet,
+		token:    token,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "wrong auth",
+		method:   http.MethodGet,
+		auth:     "Bearer nope",
+		token:    token,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "token without scheme",
+		method:   http.MethodGet,
+		auth:     token,
+		token:    token,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "list levels",
+		method:   http.MethodGet,
+		auth:     "Bearer " + token,
+		token:    token,
+		wantCode: http.StatusOK,
+		wantBody: `{"controller":"info"}` + "\n",
+	}, {
+		name:     "unknown component",
+		method:   http.MethodGet,
+		query:    "?component=webhook",
+		auth:     "Bearer " + token,
+		token:    token,
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "invalid level",
+		method:   http.MethodPut,
+		query:    "?component=controller&level=loud",
+		auth:     "Bearer " + token,
+		token:    token,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "set level",
+		method:   http.MethodPut,
+		query:    "?component=controller&level=debug",
+		auth:     "Bearer " + token,
+		token:    token,
+		wantCode: http.StatusNoContent,
+		want:     zapcore.DebugLevel,
+	}, {
+		name:     "wrong method",
+		method:   http.MethodDelete,
+		auth:     "Bearer " + token,
+		token:    token,
+		wantCode: http.StatusMethodNotAllowed,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			controller.SetLevel(zapcore.InfoLevel)
+			h := NewLevelHandler(logger, r, test.token)
+
+			req := httptest.NewRequest(test.method, LevelHandlerPath+test.query, nil)
+			if test.auth != "" {
+				req.Header.Set("Authorization", test.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Code; got != test.wantCode {
+				t.Errorf("StatusCode = %d, want %d", got, test.wantCode)
+			}
+			if test.wantBody != "" && rec.Body.String() != test.wantBody {
+				t.Errorf("Body = %q, want %q", rec.Body.String(), test.wantBody)
+			}
+			if got := controller.Level(); got != test.want {
+				t.Errorf("Level = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LevelRegistry keeps the AtomicLevel of each component running in a
+// process, so that all of them can be updated from the logging ConfigMap.
+type LevelRegistry struct {
+	m      sync.RWMutex
+	levels map[string]zap.AtomicLevel
+}
+
+// NewLevelRegistry creates an empty LevelRegistry.
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{
+		levels: make(map[string]zap.AtomicLevel),
+	}
+}
+
+// Register sets the AtomicLevel of the given component, replacing any
+// previously registered one.
+func (r *LevelRegistry) Register(component string, level zap.AtomicLevel) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.levels[component] = level
+}
+
+// Level returns the AtomicLevel registered for the given component.
+func (r *LevelRegistry) Level(component string) (zap.AtomicLevel, bool) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	level, ok := r.levels[component]
+	return level, ok
+}
+
+// Components returns the sorted names of the registered components.
+func (r *LevelRegistry) Components() []string {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	components := make([]string, 0, len(r.levels))
+	for c := range r.levels {
+		components = append(components, c)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// UpdateLevelFromConfigMap returns a helper func that can be used to update
+// the logging level of every registered component when the logging
+// ConfigMap is updated, e.g. when `loglevel.controller` changes.
+func (r *LevelRegistry) UpdateLevelFromConfigMap(logger *zap.SugaredLogger) func(configMap *corev1.ConfigMap) {
+	return func(configMap *corev1.ConfigMap) {
+		config, err := NewConfigFromConfigMap(configMap)
+		if err != nil {
+			logger.Errorw("Failed to parse the logging configmap. Previous config map will be used.", zap.Error(err))
+			return
+		}
+
+		r.m.RLock()
+		defer r.m.RUnlock()
+		for component, atomicLevel := range r.levels {
+			level, err := componentLevel(config, component)
+			if err != nil {
+				logger.With(zap.Error(err)).Errorf("Failed to parse logger configuration. "+
+					"Previous log level retained for %v", component)
+				continue
+			}
+			if atomicLevel.Level() != level {
+				logger.Infof("Updating logging level for %v from %v to %v.", component, atomicLevel.Level(), level)
+				atomicLevel.SetLevel(level)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLevelRegistry(t *testing.T) {
+	logger, _ := NewLogger("", "debug")
+	r := NewLevelRegistry()
+	controller := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	webhook := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	r.Register("controller", controller)
+	r.Register("webhook", webhook)
+
+	if got, want := r.Components(), []string{"controller", "webhook"}; !cmp.Equal(got, want) {
+		t.Errorf("Components() = %v, want %v", got, want)
+	}
+	if _, ok := r.Level("autoscaler"); ok {
+		t.Error("Level(autoscaler) was found, but never registered")
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName()},
+		Data: map[string]string{
+			"zap-logger-config":   `{"level": "warn"}`,
+			"loglevel.controller": "debug",
+		},
+	}
+	r.UpdateLevelFromConfigMap(logger)(cm)
+	if got, want := controller.Level(), zapcore.DebugLevel; got != want {
+		t.Errorf("controller level = %v, want %v", got, want)
+	}
+	if got, want := webhook.Level(), zapcore.WarnLevel; got != want {
+		t.Errorf("webhook level = %v, want %v", got, want)
+	}
+
+	// Invalid configs retain the previous levels.
+	cm.Data["loglevel.controller"] = "loud"
+	r.UpdateLevelFromConfigMap(logger)(cm)
+	if got, want := controller.Level(), zapcore.DebugLevel; got != want {
+		t.Errorf("controller level = %v, want %v", got, want)
+	}
+}
@@ -19,7 +19,11 @@ package logging
 import (
 	"context"
 
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"knative.dev/pkg/logging/logkey"
 )
 
 type loggerKey struct{}
@@ -47,11 +51,57 @@ func WithLogger(ctx context.Context, logger *zap.SugaredLogger) context.Context
 }
 
 // FromContext returns the logger stored in context.
-// Returns nil if no logger is set in context, or if the stored value is
-// not of correct type.
+// Returns the fallback logger if no logger is set in context, or if the
+// stored value is not of correct type.
+// When the context carries a valid trace span, the returned logger tags its
+// entries with the trace and span IDs, so that logs and spans correlate.
 func FromContext(ctx context.Context) *zap.SugaredLogger {
-	if logger, ok := ctx.Value(loggerKey{}).(*zap.SugaredLogger); ok {
+	logger, ok := ctx.Value(loggerKey{}).(*zap.SugaredLogger)
+	if !ok {
+		logger = fallbackLogger
+	}
+	if span := trace.FromContext(ctx); span != nil {
+		logger = withSpanContext(logger, span.SpanContext())
+	}
+	return logger
+}
+
+// withSpanContext returns a logger tagging its entries with the IDs of the
+// span context, unless the span context is invalid or the logger already
+// tags its entries with it. A logger tagged with another span context, e.g.
+// the parent span's, has its span fields replaced rather than repeated.
+func withSpanContext(logger *zap.SugaredLogger, sc trace.SpanContext) *zap.SugaredLogger {
+	if sc.TraceID == (trace.TraceID{}) || sc.SpanID == (trace.SpanID{}) {
 		return logger
 	}
-	return fallbackLogger
+	base := logger.Desugar().Core()
+	if c, ok := base.(*spanContextCore); ok {
+		if c.sc == sc {
+			return logger
+		}
+		base = c.base
+	}
+	return logger.Desugar().WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return &spanContextCore{
+			Core: base.With([]zapcore.Field{
+				zap.String(logkey.SpanContextTraceID, sc.TraceID.String()),
+				zap.String(logkey.SpanContextSpanID, sc.SpanID.String()),
+			}),
+			base: base,
+			sc:   sc,
+		}
+	})).Sugar()
+}
+
+// spanContextCore marks the cores tagging their entries with the IDs of a
+// span context, so that they are not tagged twice. It keeps the core without
+// the span fields, so that they can be replaced for another span context.
+type spanContextCore struct {
+	zapcore.Core
+	base zapcore.Core
+	sc   trace.SpanContext
+}
+
+func (c *spanContextCore) With(fields []zapcore.Field) zapcore.Core {
+	return &spanContextCore{Core: c.Core.With(fields), base: c.base.With(fields), sc: c.sc}
 }
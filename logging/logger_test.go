@@ -20,7 +20,12 @@ import (
 	"context"
 	"testing"
 
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"knative.dev/pkg/logging/logkey"
 )
 
 func TestContext(t *testing.T) {
@@ -51,3 +56,85 @@ func checkFromContext(ctx context.Context, want *zap.SugaredLogger, t *testing.T
 		t.Errorf("unexpected logger in context. want: %v, got: %v", want, got)
 	}
 }
+
+func TestContextWithSpan(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := WithLogger(context.Background(), zap.New(core).Sugar())
+	ctx, span := trace.StartSpan(ctx, "reconcile", trace.WithSampler(trace.AlwaysSample()))
+	defer span.End()
+
+	FromContext(ctx).Info("reconciling")
+
+	sc := span.SpanContext()
+	fields := logs.All()[0].ContextMap()
+	if got, want := fields[logkey.SpanContextTraceID], sc.TraceID.String(); got != want {
+		t.Errorf("%s = %v, want %v", logkey.SpanContextTraceID, got, want)
+	}
+	if got, want := fields[logkey.SpanContextSpanID], sc.SpanID.String(); got != want {
+		t.Errorf("%s = %v, want %v", logkey.SpanContextSpanID, got, want)
+	}
+}
+
+func TestContextWithSpanTagsOnce(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := WithLogger(context.Background(), zap.New(core).Sugar().With(logkey.TraceID, "reconcile-uuid"))
+	ctx, span := trace.StartSpan(ctx, "reconcile", trace.WithSampler(trace.AlwaysSample()))
+	defer span.End()
+
+	// Storing the tagged logger back in the context must not tag it again.
+	ctx = WithLogger(ctx, FromContext(ctx).With("step", 1))
+	FromContext(ctx).Info("reconciling")
+
+	entry := logs.All()[0]
+	counts := make(map[string]int, len(entry.Context))
+	for _, f := range entry.Context {
+		counts[f.Key]++
+	}
+	for _, key := range []string{logkey.TraceID, logkey.SpanContextTraceID, logkey.SpanContextSpanID, "step"} {
+		if counts[key] != 1 {
+			t.Errorf("Field %s logged %d times, want once", key, counts[key])
+		}
+	}
+	if got, want := entry.ContextMap()[logkey.TraceID], "reconcile-uuid"; got != want {
+		t.Errorf("%s = %v, want %v", logkey.TraceID, got, want)
+	}
+}
+
+func TestContextWithNestedSpans(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := WithLogger(context.Background(), zap.New(core).Sugar())
+	ctx, parent := trace.StartSpan(ctx, "reconcile", trace.WithSampler(trace.AlwaysSample()))
+	defer parent.End()
+
+	// Storing the parent's logger back in the context must not make the
+	// child span's entries carry both span contexts.
+	ctx = WithLogger(ctx, FromContext(ctx).With("step", 1))
+	ctx, child := trace.StartSpan(ctx, "update-status")
+	defer child.End()
+	FromContext(ctx).Info("updating")
+
+	entry := logs.All()[0]
+	counts := make(map[string]int, len(entry.Context))
+	for _, f := range entry.Context {
+		counts[f.Key]++
+	}
+	for _, key := range []string{logkey.SpanContextTraceID, logkey.SpanContextSpanID, "step"} {
+		if counts[key] != 1 {
+			t.Errorf("Field %s logged %d times, want once", key, counts[key])
+		}
+	}
+	sc := child.SpanContext()
+	fields := entry.ContextMap()
+	if got, want := fields[logkey.SpanContextTraceID], sc.TraceID.String(); got != want {
+		t.Errorf("%s = %v, want %v", logkey.SpanContextTraceID, got, want)
+	}
+	if got, want := fields[logkey.SpanContextSpanID], sc.SpanID.String(); got != want {
+		t.Errorf("%s = %v, want %v", logkey.SpanContextSpanID, got, want)
+	}
+}
+
+func TestContextWithInvalidSpan(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	ctx := trace.NewContext(WithLogger(context.Background(), logger), &trace.Span{})
+	checkFromContext(ctx, logger, t)
+}
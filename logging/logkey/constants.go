@@ -26,6 +26,14 @@ const (
 	// TraceID is the key used to track an asynchronous or long running operation.
 	TraceID = "knative.dev/traceid"
 
+	// SpanContextTraceID is the key used for the trace ID of the current
+	// trace span in logs
+	SpanContextTraceID = "knative.dev/spancontext/traceid"
+
+	// SpanContextSpanID is the key used for the ID of the current trace span
+	// in logs
+	SpanContextSpanID = "knative.dev/spancontext/spanid"
+
 	// Namespace is the key used for namespace in structured logs
 	Namespace = "knative.dev/namespace"
 
@@ -38,6 +46,15 @@ const (
 	// Name is the key used to represent name of an object in logs
 	Name = "knative.dev/name"
 
+	// UID is the key used to represent the UID of an object in logs
+	UID = "knative.dev/uid"
+
+	// ResourceVersion is the key used to represent the resourceVersion of an object in logs
+	ResourceVersion = "knative.dev/resourceversion"
+
+	// Reference is the key used to represent a reference to an object in logs
+	Reference = "knative.dev/reference"
+
 	// Operation is the key used to represent an operation in logs
 	Operation = "knative.dev/operation"
 
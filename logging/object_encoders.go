@@ -19,9 +19,13 @@ package logging
 import (
 	"strings"
 
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"knative.dev/pkg/logging/logkey"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -58,3 +62,60 @@ func NamespacedName(n types.NamespacedName) zapcore.ObjectMarshalerFunc {
 		return nil
 	}
 }
+
+// Object returns a field holding the identity of a Kubernetes object, i.e.
+// its namespace, name, uid and resourceVersion, in place of the whole object.
+//	logger.Infow("Reconciling", logging.Object("object", obj))
+func Object(key string, o metav1.Object) zap.Field {
+	return zap.Object(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		if o.GetNamespace() != "" {
+			enc.AddString(logkey.Namespace, o.GetNamespace())
+		}
+		enc.AddString(logkey.Name, o.GetName())
+		if o.GetUID() != "" {
+			enc.AddString(logkey.UID, string(o.GetUID()))
+		}
+		if o.GetResourceVersion() != "" {
+			enc.AddString(logkey.ResourceVersion, o.GetResourceVersion())
+		}
+		return nil
+	}))
+}
+
+// GVK returns a field holding the given GroupVersionKind.
+//	logger.Infow("Watching", logging.GVK(gvk))
+func GVK(gvk schema.GroupVersionKind) zap.Field {
+	return zap.Object(logkey.Kind, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		if gvk.Group != "" {
+			enc.AddString("group", gvk.Group)
+		}
+		enc.AddString("version", gvk.Version)
+		enc.AddString("kind", gvk.Kind)
+		return nil
+	}))
+}
+
+// Ref returns a field holding the identity of the object referenced by the
+// given ObjectReference.
+//	logger.Infow("Resolving", logging.Ref(ref))
+func Ref(ref corev1.ObjectReference) zap.Field {
+	return zap.Object(logkey.Reference, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		if ref.APIVersion != "" {
+			enc.AddString("apiVersion", ref.APIVersion)
+		}
+		if ref.Kind != "" {
+			enc.AddString("kind", ref.Kind)
+		}
+		if ref.Namespace != "" {
+			enc.AddString(logkey.Namespace, ref.Namespace)
+		}
+		enc.AddString(logkey.Name, ref.Name)
+		if ref.UID != "" {
+			enc.AddString(logkey.UID, string(ref.UID))
+		}
+		if ref.ResourceVersion != "" {
+			enc.AddString(logkey.ResourceVersion, ref.ResourceVersion)
+		}
+		return nil
+	}))
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestObjectFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		field zap.Field
+		want  map[string]interface{}
+	}{{
+		name: "object",
+		field: Object("object", &metav1.ObjectMeta{
+			Namespace:       "ns",
+			Name:            "foo",
+			UID:             "1234",
+			ResourceVersion: "42",
+		}),
+		want: map[string]interface{}{"object": map[string]interface{}{
+			"knative.dev/namespace":       "ns",
+			"knative.dev/name":            "foo",
+			"knative.dev/uid":             "1234",
+			"knative.dev/resourceversion": "42",
+		}},
+	}, {
+		name:  "cluster scoped object",
+		field: Object("object", &metav1.ObjectMeta{Name: "foo"}),
+		want: map[string]interface{}{"object": map[string]interface{}{
+			"knative.dev/name": "foo",
+		}},
+	}, {
+		name:  "gvk",
+		field: GVK(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}),
+		want: map[string]interface{}{"knative.dev/kind": map[string]interface{}{
+			"group":   "apps",
+			"version": "v1",
+			"kind":    "Deployment",
+		}},
+	}, {
+		name: "ref",
+		field: Ref(corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Namespace:  "ns",
+			Name:       "foo",
+		}),
+		want: map[string]interface{}{"knative.dev/reference": map[string]interface{}{
+			"apiVersion":            "v1",
+			"kind":                  "Service",
+			"knative.dev/namespace": "ns",
+			"knative.dev/name":      "foo",
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			enc := zapcore.NewMapObjectEncoder()
+			test.field.AddTo(enc)
+			if !cmp.Equal(enc.Fields, test.want) {
+				t.Errorf("Fields (-want, +got) = %s", cmp.Diff(test.want, enc.Fields))
+			}
+		})
+	}
+}
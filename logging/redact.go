@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Redacted replaces the sensitive values removed from log entries.
+const Redacted = "[REDACTED]"
+
+// Redactor removes sensitive values from log entries before they reach
+// the log sinks.
+type Redactor struct {
+	// Fields holds the lowercased names of the fields whose values are
+	// always redacted.
+	Fields sets.String
+
+	// Patterns match the sensitive parts of messages and string values.
+	// When a pattern has capture groups, only the groups are redacted.
+	Patterns []*regexp.Regexp
+}
+
+// DefaultRedactor returns a Redactor for the credentials commonly found in
+// logs: secrets, tokens, passwords and the userinfo of connection strings.
+func DefaultRedactor() Redactor {
+	return Redactor{
+		Fields: sets.NewString("password", "passwd", "secret", "token", "authorization",
+			"apikey", "api-key", "api_key", "accesskey", "access_key"),
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)bearer\s+([a-z0-9._~+/=-]+)`),
+			regexp.MustCompile(`://[^/\s:@]*:([^/\s@]+)@`),
+			regexp.MustCompile(`(?i)(?:password|passwd|secret|token)\s*[=:]\s*([^\s&,;"']+)`),
+		},
+	}
+}
+
+// WithRedactor returns an option that applies the given Redactor to every
+// entry of the logger.
+func WithRedactor(r Redactor) zap.Option {
+	return zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return &redactingCore{Core: c, r: r}
+	})
+}
+
+// redactString redacts the parts of s matched by the patterns.
+func (r Redactor) redactString(s string) string {
+	for _, p := range r.Patterns {
+		s = p.ReplaceAllStringFunc(s, func(match string) string {
+			groups := p.FindStringSubmatchIndex(match)
+			if len(groups) <= 2 {
+				return Redacted
+			}
+			var sb strings.Builder
+			last := 0
+			for i := 2; i < len(groups); i += 2 {
+				if groups[i] < 0 {
+					continue
+				}
+				sb.WriteString(match[last:groups[i]])
+				sb.WriteString(Redacted)
+				last = groups[i+1]
+			}
+			sb.WriteString(match[last:])
+			return sb.String()
+		})
+	}
+	return s
+}
+
+func (r Redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, f := range fields {
+		nf, ok := r.redactField(f)
+		if !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]zapcore.Field, len(fields))
+			copy(redacted, fields)
+		}
+		redacted[i] = nf
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// redactField returns the redacted version of the field, and whether it
+// differs from the original.
+func (r Redactor) redactField(f zapcore.Field) (zapcore.Field, bool) {
+	if r.Fields.Has(strings.ToLower(f.Key)) {
+		return zap.String(f.Key, Redacted), true
+	}
+	var s string
+	switch f.Type {
+	case zapcore.StringType:
+		s = f.String
+	case zapcore.ErrorType:
+		s = f.Interface.(error).Error()
+	default:
+		return f, false
+	}
+	if rs := r.redactString(s); rs != s {
+		return zap.String(f.Key, rs), true
+	}
+	return f, false
+}
+
+// redactingCore is a zapcore.Core that redacts entries before passing them
+// on to the Core it wraps.
+type redactingCore struct {
+	zapcore.Core
+	r Redactor
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.r.redactFields(fields)), r: c.r}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// Let the wrapped core decide whether the entry is logged, so that its
+	// level checks and sampling still apply, but write it through this core
+	// so that it gets redacted.
+	if c.Core.Check(ent, nil) != nil {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.r.redactString(ent.Message)
+	return c.Core.Write(ent, c.r.redactFields(fields))
+}
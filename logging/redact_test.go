@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactor(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core, WithRedactor(DefaultRedactor())).Sugar()
+
+	logger.With("Token", "abc").Infow("connecting to postgres://user:hunter2@db:5432/app",
+		"header", "Authorization: Bearer abc.def",
+		zap.Error(errors.New("login failed: password=hunter2")),
+		"attempt", 3)
+	logger.Info("nothing to hide")
+
+	entries := logs.AllUntimed()
+	if got, want := entries[0].Message, "connecting to postgres://user:[REDACTED]@db:5432/app"; got != want {
+		t.Errorf("Message = %q, want %q", got, want)
+	}
+	want := map[string]interface{}{
+		"Token":   Redacted,
+		"header":  "Authorization: Bearer [REDACTED]",
+		"error":   "login failed: password=[REDACTED]",
+		"attempt": int64(3),
+	}
+	got := entries[0].ContextMap()
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("Field %q = %v, want %v", k, got[k], v)
+		}
+	}
+	if got, want := entries[1].Message, "nothing to hide"; got != want {
+		t.Errorf("Message = %q, want %q", got, want)
+	}
+}
+
+func TestRedactorKeepsSampling(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	sampled := zapcore.NewSampler(core, time.Minute, 2, 100)
+	logger := zap.New(sampled, WithRedactor(DefaultRedactor())).Sugar()
+
+	for i := 0; i < 10; i++ {
+		logger.Info("password=hunter2")
+	}
+	logger.Debug("below the level")
+
+	entries := logs.AllUntimed()
+	if got, want := len(entries), 2; got != want {
+		t.Fatalf("Logged %d entries, want %d", got, want)
+	}
+	for _, e := range entries {
+		if got, want := e.Message, "password=[REDACTED]"; got != want {
+			t.Errorf("Message = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+
+	"knative.dev/pkg/logging/logkey"
+)
+
+const (
+	// StackdriverEncoding is the zap encoding producing entries in the
+	// structured format of Cloud Logging, e.g. `"encoding": "stackdriver"`.
+	StackdriverEncoding = "stackdriver"
+
+	// StackdriverTraceKey is the key Cloud Logging correlates traces on.
+	StackdriverTraceKey = "logging.googleapis.com/trace"
+
+	// StackdriverSpanIDKey is the key Cloud Logging correlates trace spans on.
+	StackdriverSpanIDKey = "logging.googleapis.com/spanId"
+
+	// StackdriverProjectEnv is the environment variable naming the GCP
+	// project the trace IDs of the entries belong to. It is read when the
+	// encoder is built.
+	StackdriverProjectEnv = "GOOGLE_CLOUD_PROJECT"
+
+	stackdriverSourceLocationKey = "logging.googleapis.com/sourceLocation"
+)
+
+func init() {
+	if err := zap.RegisterEncoder(StackdriverEncoding, newStackdriverEncoder); err != nil {
+		panic(err)
+	}
+}
+
+// StackdriverTrace returns a field correlating the entry with the given
+// trace in Cloud Logging.
+func StackdriverTrace(project, traceID string) zap.Field {
+	return zap.String(StackdriverTraceKey, fmt.Sprintf("projects/%s/traces/%s", project, traceID))
+}
+
+// stackdriverEncoder is a JSON encoder using the keys and severities
+// expected by Cloud Logging, and reporting callers as sourceLocations.
+// The span context fields FromContext tags the entries with are written
+// under the trace and spanId keys of Cloud Logging. The trace is only
+// mapped when the project is known, as Cloud Logging expects it to be
+// qualified by the project.
+type stackdriverEncoder struct {
+	zapcore.Encoder
+	// project is the GCP project the trace IDs belong to.
+	project string
+}
+
+func newStackdriverEncoder(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	cfg.TimeKey = "timestamp"
+	cfg.LevelKey = "severity"
+	cfg.MessageKey = "message"
+	cfg.CallerKey = ""
+	cfg.EncodeLevel = stackdriverLevelEncoder
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	if cfg.EncodeDuration == nil {
+		cfg.EncodeDuration = zapcore.StringDurationEncoder
+	}
+	if cfg.EncodeName == nil {
+		cfg.EncodeName = zapcore.FullNameEncoder
+	}
+	return &stackdriverEncoder{
+		Encoder: zapcore.NewJSONEncoder(cfg),
+		project: os.Getenv(StackdriverProjectEnv),
+	}, nil
+}
+
+func (e *stackdriverEncoder) Clone() zapcore.Encoder {
+	return &stackdriverEncoder{Encoder: e.Encoder.Clone(), project: e.project}
+}
+
+// AddString maps the span context fields added through With.
+func (e *stackdriverEncoder) AddString(key, value string) {
+	key, value = e.mapSpanContext(key, value)
+	e.Encoder.AddString(key, value)
+}
+
+func (e *stackdriverEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	mapped := make([]zapcore.Field, 0, len(fields)+1)
+	for _, f := range fields {
+		if f.Type == zapcore.StringType {
+			f.Key, f.String = e.mapSpanContext(f.Key, f.String)
+		}
+		mapped = append(mapped, f)
+	}
+	if ent.Caller.Defined {
+		mapped = append(mapped, zap.Object(stackdriverSourceLocationKey, sourceLocation(ent.Caller)))
+	}
+	return e.Encoder.EncodeEntry(ent, mapped)
+}
+
+// mapSpanContext returns the Cloud Logging key and value of the span
+// context fields, and any other field unchanged.
+func (e *stackdriverEncoder) mapSpanContext(key, value string) (string, string) {
+	switch key {
+	case logkey.SpanContextTraceID:
+		if e.project != "" {
+			return StackdriverTraceKey, fmt.Sprintf("projects/%s/traces/%s", e.project, value)
+		}
+	case logkey.SpanContextSpanID:
+		return StackdriverSpanIDKey, value
+	}
+	return key, value
+}
+
+func sourceLocation(c zapcore.EntryCaller) zapcore.ObjectMarshalerFunc {
+	return func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("file", c.File)
+		enc.AddString("line", fmt.Sprint(c.Line))
+		if fn := runtime.FuncForPC(c.PC); fn != nil {
+			enc.AddString("function", fn.Name())
+		}
+		return nil
+	}
+}
+
+// stackdriverLevelEncoder encodes the zap levels as Cloud Logging severities.
+func stackdriverLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch l {
+	case zapcore.DebugLevel:
+		enc.AppendString("DEBUG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INFO")
+	case zapcore.WarnLevel:
+		enc.AppendString("WARNING")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERROR")
+	case zapcore.DPanicLevel:
+		enc.AppendString("CRITICAL")
+	case zapcore.PanicLevel:
+		enc.AppendString("ALERT")
+	case zapcore.FatalLevel:
+		enc.AppendString("EMERGENCY")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"knative.dev/pkg/logging/logkey"
+)
+
+func TestStackdriverEncoder(t *testing.T) {
+	enc, err := newStackdriverEncoder(zap.NewProductionEncoderConfig())
+	if err != nil {
+		t.Fatal("newStackdriverEncoder() =", err)
+	}
+	pc, file, line, _ := runtime.Caller(0)
+	buf, err := enc.EncodeEntry(zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "hello",
+		Caller:  zapcore.NewEntryCaller(pc, file, line, true),
+	}, []zapcore.Field{StackdriverTrace("my-project", "abc123")})
+	if err != nil {
+		t.Fatal("EncodeEntry() =", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal(%s) = %v", buf.String(), err)
+	}
+	for k, want := range map[string]interface{}{
+		"severity":                     "WARNING",
+		"message":                      "hello",
+		"timestamp":                    "2020-01-02T03:04:05.000Z",
+		"logging.googleapis.com/trace": "projects/my-project/traces/abc123",
+	} {
+		if got[k] != want {
+			t.Errorf("%s = %v, want %v", k, got[k], want)
+		}
+	}
+	loc, ok := got[stackdriverSourceLocationKey].(map[string]interface{})
+	if !ok {
+		t.Fatalf("%s = %v, want an object", stackdriverSourceLocationKey, got[stackdriverSourceLocationKey])
+	}
+	if loc["file"] != file || loc["function"] != "knative.dev/pkg/logging.TestStackdriverEncoder" {
+		t.Errorf("sourceLocation = %v, want file %s in TestStackdriverEncoder", loc, file)
+	}
+}
+
+func TestStackdriverEncoderSpanContext(t *testing.T) {
+	for _, project := range []string{"my-project", ""} {
+		t.Run("project="+project, func(t *testing.T) {
+			// The project is read once, when the encoder is built.
+			os.Setenv(StackdriverProjectEnv, project)
+			enc, err := newStackdriverEncoder(zap.NewProductionEncoderConfig())
+			os.Unsetenv(StackdriverProjectEnv)
+			if err != nil {
+				t.Fatal("newStackdriverEncoder() =", err)
+			}
+
+			var out bytes.Buffer
+			core := zapcore.NewCore(enc, zapcore.AddSync(&out), zapcore.InfoLevel)
+			ctx := WithLogger(context.Background(), zap.New(core).Sugar())
+			ctx, span := trace.StartSpan(ctx, "reconcile", trace.WithSampler(trace.AlwaysSample()))
+			defer span.End()
+
+			// The span context is tagged through With by FromContext, and
+			// passed as fields of the entry otherwise.
+			sc := span.SpanContext()
+			FromContext(ctx).Info("with")
+			zap.New(core).Info("fields",
+				zap.String(logkey.SpanContextTraceID, sc.TraceID.String()),
+				zap.String(logkey.SpanContextSpanID, sc.SpanID.String()))
+
+			dec := json.NewDecoder(&out)
+			for _, msg := range []string{"with", "fields"} {
+				var got map[string]interface{}
+				if err := dec.Decode(&got); err != nil {
+					t.Fatal("Decode() =", err)
+				}
+				want := map[string]interface{}{
+					StackdriverTraceKey:       "projects/my-project/traces/" + sc.TraceID.String(),
+					StackdriverSpanIDKey:      sc.SpanID.String(),
+					logkey.SpanContextTraceID: nil,
+					logkey.SpanContextSpanID:  nil,
+				}
+				if project == "" {
+					// The trace cannot be qualified, so it keeps its key.
+					want[StackdriverTraceKey] = nil
+					want[logkey.SpanContextTraceID] = sc.TraceID.String()
+				}
+				for k, w := range want {
+					if got[k] != w {
+						t.Errorf("%s: %s = %v, want %v", msg, k, got[k], w)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStackdriverEncoding(t *testing.T) {
+	c, err := NewConfigFromMap(map[string]string{encodingKey: StackdriverEncoding})
+	if err != nil {
+		t.Fatal("NewConfigFromMap() =", err)
+	}
+	if _, _, err := newLoggerFromConfig(c.LoggingConfig, "", nil); err != nil {
+		t.Error("newLoggerFromConfig() =", err)
+	}
+}
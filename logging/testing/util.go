@@ -20,7 +20,9 @@ import (
 	"context"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 
 	"knative.dev/pkg/logging"
 )
@@ -39,3 +41,26 @@ func TestLogger(t zaptest.TestingT) *zap.SugaredLogger {
 func TestContextWithLogger(t zaptest.TestingT) context.Context {
 	return logging.WithLogger(context.Background(), TestLogger(t))
 }
+
+// ObservedTestLogger gets a logger to use in unit tests, which in addition to
+// logging like TestLogger records every entry at or above the given level, so
+// that tests can assert on their level, message and fields.
+func ObservedTestLogger(t zaptest.TestingT, level zapcore.LevelEnabler) (*zap.SugaredLogger, *observer.ObservedLogs) {
+	core, logs := observer.New(level)
+	opts := zaptest.WrapOptions(
+		zap.AddCaller(),
+		zap.Development(),
+		zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c, core)
+		}),
+	)
+
+	return zaptest.NewLogger(t, opts).Sugar(), logs
+}
+
+// TestContextWithObservedLogger returns a context with an observed logger to
+// be used in tests, along with the entries it records.
+func TestContextWithObservedLogger(t zaptest.TestingT, level zapcore.LevelEnabler) (context.Context, *observer.ObservedLogs) {
+	logger, logs := ObservedTestLogger(t, level)
+	return logging.WithLogger(context.Background(), logger), logs
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"knative.dev/pkg/logging"
+)
+
+func TestObservedTestLogger(t *testing.T) {
+	ctx, logs := TestContextWithObservedLogger(t, zapcore.InfoLevel)
+	logger := logging.FromContext(ctx)
+
+	logger.Debug("dropped")
+	logger.Infow("kept", zap.String("key", "value"))
+	logger.Errorf("failed %d times", 3)
+
+	if got, want := logs.Len(), 2; got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+	if got := logs.FilterField(zap.String("key", "value")).Len(); got != 1 {
+		t.Errorf("Entries with key=value = %d, want 1", got)
+	}
+	entry := logs.All()[1]
+	if entry.Level != zapcore.ErrorLevel || entry.Message != "failed 3 times" {
+		t.Errorf("Entry = %v %q, want error %q", entry.Level, entry.Message, "failed 3 times")
+	}
+}
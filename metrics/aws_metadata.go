@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+const (
+	awsMetadataTokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+	awsMetadataTokenHeader    = "X-aws-ec2-metadata-token"
+)
+
+var (
+	// awsMetadataEndpoint is the address of the EC2 instance metadata service.
+	awsMetadataEndpoint = "http://169.254.169.254"
+
+	// awsMetadataFunc is the function used to fetch AWS metadata.
+	awsMetadataFunc = retrieveAWSMetadata
+)
+
+type awsMetadata struct {
+	region     string
+	instanceID string
+	cluster    string
+}
+
+// retrieveAWSMetadata reads the region, instance and EKS cluster of the node
+// from the EC2 instance metadata service, using IMDSv2. The cluster is only
+// known when the instance tags are exposed in the metadata. Unavailable
+// values are left unknown.
+func retrieveAWSMetadata() *awsMetadata {
+	am := awsMetadata{
+		region:     metricskey.ValueUnknown,
+		instanceID: metricskey.ValueUnknown,
+		cluster:    metricskey.ValueUnknown,
+	}
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	token, err := awsMetadataRequest(client, http.MethodPut, "/latest/api/token", "")
+	if err != nil {
+		// Not on EC2, or the metadata service is unreachable.
+		return &am
+	}
+	for path, value := range map[string]*string{
+		"/latest/meta-data/placement/region":               &am.region,
+		"/latest/meta-data/instance-id":                    &am.instanceID,
+		"/latest/meta-data/tags/instance/eks:cluster-name": &am.cluster,
+	} {
+		if v, err := awsMetadataRequest(client, http.MethodGet, path, token); err == nil && v != "" {
+			*value = v
+		}
+	}
+	return &am
+}
+
+func awsMetadataRequest(client *http.Client, method, path, token string) (string, error) {
+	req, err := http.NewRequest(method, awsMetadataEndpoint+path, nil)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		req.Header.Set(awsMetadataTokenTTLHeader, "60")
+	} else {
+		req.Header.Set(awsMetadataTokenHeader, token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the metadata service responded with status %d to %s", resp.StatusCode, path)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
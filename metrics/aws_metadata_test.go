@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+func TestRetrieveAWSMetadata(t *testing.T) {
+	const token = "imds-token"
+	values := map[string]string{
+		"/latest/meta-data/placement/region":               "us-west-2",
+		"/latest/meta-data/instance-id":                    "i-0123456789",
+		"/latest/meta-data/tags/instance/eks:cluster-name": "prod",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/latest/api/token" {
+			if r.Header.Get(awsMetadataTokenTTLHeader) == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(token))
+			return
+		}
+		if r.Header.Get(awsMetadataTokenHeader) != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		v, ok := values[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(v + "\n"))
+	}))
+	defer server.Close()
+
+	defer func(endpoint string) {
+		awsMetadataEndpoint = endpoint
+	}(awsMetadataEndpoint)
+	awsMetadataEndpoint = server.URL
+
+	got := retrieveAWSMetadata()
+	want := awsMetadata{region: "us-west-2", instanceID: "i-0123456789", cluster: "prod"}
+	if *got != want {
+		t.Errorf("retrieveAWSMetadata() = %+v, want %+v", *got, want)
+	}
+
+	// Without the instance tags in the metadata the cluster is unknown.
+	delete(values, "/latest/meta-data/tags/instance/eks:cluster-name")
+	if got := retrieveAWSMetadata(); got.cluster != metricskey.ValueUnknown || got.region != "us-west-2" {
+		t.Errorf("retrieveAWSMetadata() = %+v, want an unknown cluster in us-west-2", *got)
+	}
+
+	// Off EC2 everything is unknown.
+	server.Close()
+	want = awsMetadata{region: metricskey.ValueUnknown, instanceID: metricskey.ValueUnknown, cluster: metricskey.ValueUnknown}
+	if got := retrieveAWSMetadata(); *got != want {
+		t.Errorf("retrieveAWSMetadata() = %+v, want %+v", *got, want)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+)
+
+// BackendOptions is the configuration given to the factory of a registered
+// backend.
+type BackendOptions struct {
+	// Domain is the metrics domain, e.g. "knative.dev".
+	Domain string
+	// Component is the name of the component that emits the metrics.
+	Component string
+	// ReportingPeriod is the interval between reporting aggregated views.
+	ReportingPeriod time.Duration
+	// ConfigMap is the data of the config-observability ConfigMap, from which
+	// backends read their own settings.
+	ConfigMap map[string]string
+	// Secrets fetches Secrets, e.g. holding credentials for the backend.
+	Secrets SecretFetcher
+}
+
+// BackendFactory creates the exporter of a registered backend. The factory
+// need not register the exporter with OpenCensus, that is done for it.
+type BackendFactory func(*BackendOptions, *zap.SugaredLogger) (view.Exporter, error)
+
+// exporterFactory creates the exporter of a built-in backend.
+type exporterFactory func(*metricsConfig, *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error)
+
+var (
+	// builtinBackends holds the factories of the backends supported out of
+	// the box. Backends registered with RegisterBackendFactory may not reuse
+	// their names.
+	builtinBackends = map[metricsBackend]exporterFactory{
+		stackdriver:           newStackdriverExporter,
+		openCensus:            newOpenCensusExporter,
+		openTelemetry:         newOpenTelemetryExporter,
+		datadog:               newDatadogExporter,
+		cloudWatch:            newCloudWatchExporter,
+		prometheus:            newPrometheusExporter,
+		prometheusPushgateway: newPushgatewayExporter,
+		debugBackend:          newDebugExporter,
+		none: func(*metricsConfig, *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
+			return nil, nil, nil
+		},
+	}
+
+	backendFactoriesMux sync.RWMutex
+	backendFactories    = map[metricsBackend]BackendFactory{}
+)
+
+// isBuiltinBackend returns whether the backend is supported out of the box.
+func isBuiltinBackend(backend metricsBackend) bool {
+	_, ok := builtinBackends[backend]
+	return ok
+}
+
+// RegisterBackendFactory makes a custom metrics backend available under the
+// given name, which config-observability selects through
+// metrics.backend-destination. It is meant to be called from init functions,
+// and panics if the name is empty, taken by a built-in backend or already
+// registered.
+func RegisterBackendFactory(name string, f BackendFactory) {
+	backend := metricsBackend(strings.ToLower(name))
+	if backend == "" || f == nil {
+		panic("metrics: RegisterBackendFactory needs a name and a factory")
+	}
+	if isBuiltinBackend(backend) {
+		panic(fmt.Sprintf("metrics: backend %q is built in", name))
+	}
+
+	backendFactoriesMux.Lock()
+	defer backendFactoriesMux.Unlock()
+	if _, ok := backendFactories[backend]; ok {
+		panic(fmt.Sprintf("metrics: backend %q is already registered", name))
+	}
+	backendFactories[backend] = f
+}
+
+// getBackendFactory returns the factory registered for the backend, if any.
+func getBackendFactory(backend metricsBackend) BackendFactory {
+	backendFactoriesMux.RLock()
+	defer backendFactoriesMux.RUnlock()
+	return backendFactories[backend]
+}
+
+// newRegisteredExporter creates the exporter of a registered backend. Its
+// exporter is shared by all the resources.
+func newRegisteredExporter(f BackendFactory, config *metricsConfig, logger *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
+	e, err := f(&BackendOptions{
+		Domain:          config.domain,
+		Component:       config.component,
+		ReportingPeriod: config.reportingPeriod,
+		ConfigMap:       config.backendConfig,
+		Secrets:         config.secrets,
+	}, logger)
+	if err != nil {
+		logger.Errorw("Failed to create the exporter of backend "+string(config.backendDestination), zap.Error(err))
+		return nil, nil, err
+	}
+	logger.Infof("Created %s exporter", config.backendDestination)
+	view.RegisterExporter(e)
+	return e, func(*resource.Resource) (view.Exporter, error) { return e, nil }, nil
+}
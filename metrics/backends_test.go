@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+const fakeBackend = "fake-backend"
+
+type fakeBackendExporter struct {
+	testExporter
+	opts BackendOptions
+}
+
+var fakeBackendExporters []*fakeBackendExporter
+
+func init() {
+	RegisterBackendFactory(fakeBackend, func(opts *BackendOptions, _ *zap.SugaredLogger) (view.Exporter, error) {
+		if opts.ConfigMap["metrics.fake-fail"] == "true" {
+			return nil, errors.New("failed as configured")
+		}
+		e := &fakeBackendExporter{opts: *opts}
+		fakeBackendExporters = append(fakeBackendExporters, e)
+		return e, nil
+	})
+}
+
+func TestRegisterBackendFactoryPanics(t *testing.T) {
+	factory := func(*BackendOptions, *zap.SugaredLogger) (view.Exporter, error) { return nil, nil }
+	for _, name := range []string{"", "Prometheus", "opencensus", "prometheus-pushgateway", "debug", "none", fakeBackend} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterBackendFactory(%q) did not panic", name)
+				}
+			}()
+			RegisterBackendFactory(name, factory)
+		})
+	}
+}
+
+func TestRegisteredBackend(t *testing.T) {
+	setCurMetricsConfig(nil)
+	defer setCurMetricsConfig(nil)
+	fakeBackendExporters = nil
+	ctx := context.Background()
+
+	cm := map[string]string{
+		BackendDestinationKey: "Fake-Backend",
+		"metrics.fake-url":    "https://fake.example.com",
+	}
+	if err := UpdateExporter(ctx, ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: cm,
+	}, TestLogger(t)); err != nil {
+		t.Fatal("UpdateExporter() =", err)
+	}
+	if len(fakeBackendExporters) != 1 {
+		t.Fatalf("Created %d exporters, want 1", len(fakeBackendExporters))
+	}
+	e := fakeBackendExporters[0]
+	if getCurMetricsExporter() != e {
+		t.Errorf("Current exporter = %v, want the registered backend's", getCurMetricsExporter())
+	}
+	want := BackendOptions{
+		Domain:          servingDomain,
+		Component:       testComponent,
+		ReportingPeriod: time.Minute,
+		ConfigMap:       cm,
+	}
+	if !cmp.Equal(e.opts, want, cmp.Comparer(func(a, b SecretFetcher) bool { return a == nil && b == nil })) {
+		t.Errorf("BackendOptions = %+v, want %+v", e.opts, want)
+	}
+
+	// The same settings keep the exporter.
+	if err := UpdateExporter(ctx, ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{
+			BackendDestinationKey: "Fake-Backend",
+			"metrics.fake-url":    "https://fake.example.com",
+		},
+	}, TestLogger(t)); err != nil {
+		t.Fatal("UpdateExporter() =", err)
+	}
+	if len(fakeBackendExporters) != 1 {
+		t.Errorf("Created %d exporters, want 1", len(fakeBackendExporters))
+	}
+
+	// Changed settings flush the old exporter and create a new one.
+	if err := UpdateExporter(ctx, ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{
+			BackendDestinationKey: fakeBackend,
+			"metrics.fake-url":    "https://other.example.com",
+		},
+	}, TestLogger(t)); err != nil {
+		t.Fatal("UpdateExporter() =", err)
+	}
+	if len(fakeBackendExporters) != 2 {
+		t.Fatalf("Created %d exporters, want 2", len(fakeBackendExporters))
+	}
+	if e.flushes == 0 {
+		t.Error("The replaced exporter was not flushed")
+	}
+
+	// Factory errors are surfaced.
+	if err := UpdateExporter(ctx, ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{
+			BackendDestinationKey: fakeBackend,
+			"metrics.fake-fail":   "true",
+		},
+	}, TestLogger(t)); err == nil {
+		t.Error("UpdateExporter() = nil, wanted the factory's error")
+	}
+}
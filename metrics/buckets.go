@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/stats/view"
+)
+
+// bucketsKeyPrefix prefixes the ConfigMap keys overriding the bucket
+// boundaries of a measure, e.g. `metrics.buckets.request_latencies: "1,5,10"`.
+const bucketsKeyPrefix = "metrics.buckets."
+
+// bucketBoundaries holds the bucket boundaries overriding the ones of the
+// distribution views of a measure, by measure name. Those of the ConfigMap
+// take precedence over the registered ones.
+var bucketBoundaries = struct {
+	registered map[string][]float64
+	configured map[string][]float64
+	lock       sync.RWMutex
+}{
+	registered: map[string][]float64{},
+	configured: map[string][]float64{},
+}
+
+// RegisterBucketBoundaries overrides the bucket boundaries of the distribution
+// views of the given measure, which are registered through RegisterResourceView
+// or built by this package, so components can tune them without redefining
+// the views. It must be called before the views are registered. The
+// boundaries must be increasing.
+func RegisterBucketBoundaries(measureName string, bounds []float64) error {
+	if err := validateBuckets(bounds); err != nil {
+		return fmt.Errorf("invalid bucket boundaries for %q: %w", measureName, err)
+	}
+	bucketBoundaries.lock.Lock()
+	defer bucketBoundaries.lock.Unlock()
+	bucketBoundaries.registered[measureName] = append([]float64(nil), bounds...)
+	return nil
+}
+
+func validateBuckets(bounds []float64) error {
+	if len(bounds) == 0 {
+		return fmt.Errorf("no boundaries")
+	}
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			return fmt.Errorf("%v is not greater than %v", bounds[i], bounds[i-1])
+		}
+	}
+	return nil
+}
+
+// parseBucketBoundaries returns the bucket boundaries set in the ConfigMap,
+// by measure name.
+func parseBucketBoundaries(m map[string]string) (map[string][]float64, error) {
+	var buckets map[string][]float64
+	for k, v := range m {
+		if !strings.HasPrefix(k, bucketsKeyPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(k, bucketsKeyPrefix)
+		var bounds []float64
+		for _, s := range strings.Split(v, ",") {
+			b, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value %q", k, v)
+			}
+			bounds = append(bounds, b)
+		}
+		if err := validateBuckets(bounds); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", k, v, err)
+		}
+		if buckets == nil {
+			buckets = make(map[string][]float64)
+		}
+		buckets[name] = bounds
+	}
+	return buckets, nil
+}
+
+// bucketsFor returns the bucket boundaries of the measure's distributions,
+// given the ones of the view.
+func bucketsFor(measureName string, buckets []float64) []float64 {
+	bucketBoundaries.lock.RLock()
+	defer bucketBoundaries.lock.RUnlock()
+	if b, ok := bucketBoundaries.configured[measureName]; ok {
+		return b
+	}
+	if b, ok := bucketBoundaries.registered[measureName]; ok {
+		return b
+	}
+	return buckets
+}
+
+// withBuckets returns the aggregation with the overridden bucket boundaries
+// of the measure, if it is a distribution.
+func withBuckets(measureName string, agg *view.Aggregation) *view.Aggregation {
+	if agg == nil || agg.Type != view.AggTypeDistribution {
+		return agg
+	}
+	return view.Distribution(bucketsFor(measureName, agg.Buckets)...)
+}
+
+// setConfiguredBuckets replaces the bucket boundaries set in the ConfigMap,
+// and registers again the resource views whose boundaries changed. Their
+// data is reset.
+func setConfiguredBuckets(buckets map[string][]float64) error {
+	bucketBoundaries.lock.Lock()
+	changed := map[string]bool{}
+	for name, b := range bucketBoundaries.configured {
+		if !reflect.DeepEqual(b, buckets[name]) {
+			changed[name] = true
+		}
+	}
+	for name := range buckets {
+		if _, ok := bucketBoundaries.configured[name]; !ok {
+			changed[name] = true
+		}
+	}
+	bucketBoundaries.configured = make(map[string][]float64, len(buckets))
+	for name, b := range buckets {
+		bucketBoundaries.configured[name] = b
+	}
+	bucketBoundaries.lock.Unlock()
+
+	if len(changed) == 0 {
+		return nil
+	}
+	allMeters.lock.Lock()
+	defer allMeters.lock.Unlock()
+	resourceViews.lock.Lock()
+	defer resourceViews.lock.Unlock()
+	var views []*view.View
+	for _, v := range resourceViews.views {
+		if changed[v.Measure.Name()] && v.Aggregation.Type == view.AggTypeDistribution {
+			views = append(views, v)
+		}
+	}
+	return reregisterViews(views)
+}
+
+// reregisterViews registers again the views with every meter, resetting
+// their data. It must be called with the allMeters and resourceViews locks
+// held.
+func reregisterViews(views []*view.View) error {
+	var retErr error
+	for _, meter := range allMeters.meters {
+		for _, v := range views {
+			if old := meter.m.Find(viewName(v)); old != nil {
+				meter.m.Unregister(old)
+			}
+		}
+		if err := meter.m.Register(copyViews(views)...); err != nil {
+			retErr = err
+		}
+	}
+	return retErr
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+func resetBucketBoundaries(t *testing.T) {
+	t.Cleanup(func() {
+		bucketBoundaries.lock.Lock()
+		defer bucketBoundaries.lock.Unlock()
+		bucketBoundaries.registered = map[string][]float64{}
+		bucketBoundaries.configured = map[string][]float64{}
+	})
+}
+
+func viewBuckets(t *testing.T, name string) []float64 {
+	t.Helper()
+	v := view.Find(name)
+	if v == nil {
+		t.Fatalf("view.Find(%q) = nil", name)
+	}
+	return v.Aggregation.Buckets
+}
+
+func TestRegisterBucketBoundaries(t *testing.T) {
+	resetBucketBoundaries(t)
+
+	for _, bounds := range [][]float64{nil, {1, 1}, {5, 1}} {
+		if err := RegisterBucketBoundaries("latencies", bounds); err == nil {
+			t.Errorf("RegisterBucketBoundaries(%v) = nil, wanted an error", bounds)
+		}
+	}
+
+	want := []float64{1, 10, 100}
+	if err := RegisterBucketBoundaries("latencies", want); err != nil {
+		t.Fatal("RegisterBucketBoundaries() =", err)
+	}
+	measure := stats.Float64("latencies", "Latencies", stats.UnitMilliseconds)
+	v := &view.View{Measure: measure, Aggregation: view.Distribution(1, 2, 5)}
+	if err := RegisterResourceView(v); err != nil {
+		t.Fatal("RegisterResourceView() =", err)
+	}
+	t.Cleanup(func() { UnregisterResourceView(v) })
+
+	if got := viewBuckets(t, measure.Name()); !cmp.Equal(got, want) {
+		t.Errorf("Buckets = %v, want %v", got, want)
+	}
+	// The registered view itself is left alone.
+	if got := v.Aggregation.Buckets; !cmp.Equal(got, []float64{1, 2, 5}) {
+		t.Errorf("View buckets = %v, want the original ones", got)
+	}
+	// Views built by this package honor the boundaries too.
+	if got := measureView(measure, view.Distribution(1, 2)).Aggregation.Buckets; !cmp.Equal(got, want) {
+		t.Errorf("measureView() buckets = %v, want %v", got, want)
+	}
+	if got := measureView(measure, view.Count()).Aggregation.Type; got != view.AggTypeCount {
+		t.Errorf("measureView() aggregation = %v, want a count", got)
+	}
+}
+
+func TestParseBucketBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    map[string][]float64
+		wantErr bool
+	}{{
+		name: "none",
+		data: map[string]string{BackendDestinationKey: "prometheus"},
+	}, {
+		name: "buckets",
+		data: map[string]string{
+			bucketsKeyPrefix + "request_latencies": "1, 5,10.5",
+			bucketsKeyPrefix + "queue_depth":       "10",
+		},
+		want: map[string][]float64{
+			"request_latencies": {1, 5, 10.5},
+			"queue_depth":       {10},
+		},
+	}, {
+		name:    "not numbers",
+		data:    map[string]string{bucketsKeyPrefix + "request_latencies": "1,five"},
+		wantErr: true,
+	}, {
+		name:    "not increasing",
+		data:    map[string]string{bucketsKeyPrefix + "request_latencies": "10,5"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseBucketBoundaries(test.data)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseBucketBoundaries() = %v, wantErr %v", err, test.wantErr)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Error("parseBucketBoundaries() (-want, +got):", cmp.Diff(test.want, got))
+			}
+		})
+	}
+
+	mc, err := createMetricsConfig(context.Background(), ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{bucketsKeyPrefix + "request_latencies": "1,5"},
+	})
+	if err != nil {
+		t.Fatal("createMetricsConfig() =", err)
+	}
+	if got, want := mc.bucketBoundaries, map[string][]float64{"request_latencies": {1, 5}}; !cmp.Equal(got, want) {
+		t.Errorf("bucketBoundaries = %v, want %v", got, want)
+	}
+}
+
+func TestConfiguredBuckets(t *testing.T) {
+	resetBucketBoundaries(t)
+
+	if err := RegisterBucketBoundaries("queue_latencies", []float64{1, 10}); err != nil {
+		t.Fatal("RegisterBucketBoundaries() =", err)
+	}
+	measure := stats.Float64("queue_latencies", "Latencies", stats.UnitMilliseconds)
+	v := &view.View{Measure: measure, Aggregation: view.Distribution(1, 2, 5)}
+	if err := RegisterResourceView(v); err != nil {
+		t.Fatal("RegisterResourceView() =", err)
+	}
+	t.Cleanup(func() { UnregisterResourceView(v) })
+
+	// The ConfigMap takes precedence, and applies to the registered views.
+	configured := []float64{100, 200, 300}
+	if err := setConfiguredBuckets(map[string][]float64{measure.Name(): configured}); err != nil {
+		t.Fatal("setConfiguredBuckets() =", err)
+	}
+	if got := viewBuckets(t, measure.Name()); !cmp.Equal(got, configured) {
+		t.Errorf("Buckets = %v, want %v", got, configured)
+	}
+	stats.Record(context.Background(), measure.M(250))
+	rows, err := view.RetrieveData(measure.Name())
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("RetrieveData() = %v, %v, wanted a single row", rows, err)
+	}
+	if got, want := rows[0].Data.(*view.DistributionData).CountPerBucket, []int64{0, 0, 1, 0}; !cmp.Equal(got, want) {
+		t.Errorf("CountPerBucket = %v, want %v", got, want)
+	}
+
+	// Dropping the ConfigMap override restores the registered boundaries.
+	if err := setConfiguredBuckets(nil); err != nil {
+		t.Fatal("setConfiguredBuckets() =", err)
+	}
+	if got, want := viewBuckets(t, measure.Name()), []float64{1, 10}; !cmp.Equal(got, want) {
+		t.Errorf("Buckets = %v, want %v", got, want)
+	}
+}
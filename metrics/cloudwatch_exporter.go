@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+	"net"
+	"sort"
+
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+const (
+	// defaultCloudWatchAgentAddress is where the CloudWatch agent listens
+	// for metrics in the embedded metric format by default.
+	defaultCloudWatchAgentAddress = "localhost:25888"
+
+	// cloudWatchClusterDimension is the dimension holding the cluster name.
+	cloudWatchClusterDimension = "ClusterName"
+	// cloudWatchRegionDimension is the dimension holding the AWS region.
+	cloudWatchRegionDimension = "Region"
+
+	// cloudWatchMaxDimensions is the most dimensions CloudWatch accepts in
+	// a dimension set.
+	cloudWatchMaxDimensions = 30
+)
+
+// cloudWatchExporter exports view data to CloudWatch through the CloudWatch
+// agent, as documents in the embedded metric format. The region, cluster,
+// resource labels and tags become dimensions, in that order of precedence
+// up to cloudWatchMaxDimensions; the tags beyond that are kept in the
+// documents as properties only. OpenCensus views hold cumulative values,
+// which are published as is.
+type cloudWatchExporter struct {
+	namespace  string
+	dimensions map[string]string
+	// conn is the connection to the CloudWatch agent. It is shared with the
+	// exporters of the resources, and closed by the default one.
+	conn   net.Conn
+	logger *zap.SugaredLogger
+}
+
+var _ view.Exporter = (*cloudWatchExporter)(nil)
+var _ stoppable = (*cloudWatchExporter)(nil)
+
+func newCloudWatchExporter(config *metricsConfig, logger *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
+	am := awsMetadataFunc()
+	logger.Infof("Detected AWS region %q, instance %q and cluster %q", am.region, am.instanceID, am.cluster)
+	cluster := config.cloudWatchClusterName
+	if cluster == "" {
+		cluster = am.cluster
+	}
+	address := config.cloudWatchAgentAddress
+	if address == "" {
+		address = defaultCloudWatchAgentAddress
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		logger.Errorw("Failed to connect to the CloudWatch agent.", zap.Error(err))
+		return nil, nil, err
+	}
+	dimensions := map[string]string{}
+	if am.region != "" && am.region != metricskey.ValueUnknown {
+		dimensions[cloudWatchRegionDimension] = am.region
+	}
+	if cluster != "" && cluster != metricskey.ValueUnknown {
+		dimensions[cloudWatchClusterDimension] = cluster
+	}
+	e := &cloudWatchExporter{
+		namespace:  config.cloudWatchNamespace,
+		dimensions: dimensions,
+		conn:       conn,
+		logger:     logger,
+	}
+	logger.Infof("Created CloudWatch exporter for namespace %q", e.namespace)
+	return e, e.forResource, nil
+}
+
+// forResource returns an exporter adding the labels of the resource as
+// dimensions.
+func (e *cloudWatchExporter) forResource(r *resource.Resource) (view.Exporter, error) {
+	if r == nil || (r.Type == "" && len(r.Labels) == 0) {
+		return e, nil
+	}
+	dimensions := make(map[string]string, len(e.dimensions)+len(r.Labels))
+	for k, v := range e.dimensions {
+		dimensions[k] = v
+	}
+	for k, v := range r.Labels {
+		dimensions[k] = v
+	}
+	return &cloudWatchExporter{
+		namespace:  e.namespace,
+		dimensions: dimensions,
+		conn:       e.conn,
+		logger:     e.logger,
+	}, nil
+}
+
+// StopMetricsExporter implements stoppable, closing the connection to the
+// CloudWatch agent.
+func (e *cloudWatchExporter) StopMetricsExporter() {
+	e.conn.Close()
+}
+
+// ExportView implements view.Exporter, sending a document per row.
+func (e *cloudWatchExporter) ExportView(vd *view.Data) {
+	var dropped int64
+	for _, row := range vd.Rows {
+		doc, err := json.Marshal(e.toEMF(vd, row))
+		if err != nil {
+			e.logger.Errorw("Failed to encode view "+vd.View.Name+" for CloudWatch", zap.Error(err))
+			dropped++
+			continue
+		}
+		if _, err := e.conn.Write(doc); err != nil {
+			e.logger.Errorw("Failed to export view "+vd.View.Name+" to CloudWatch", zap.Error(err))
+			dropped++
+		}
+	}
+	recordViewExport(cloudWatch, vd, dropped)
+}
+
+// toEMF returns the embedded metric format document for the row.
+func (e *cloudWatchExporter) toEMF(vd *view.Data, row *view.Row) map[string]interface{} {
+	doc := make(map[string]interface{}, len(e.dimensions)+len(row.Tags)+2)
+	for _, t := range row.Tags {
+		doc[t.Key.Name()] = t.Value
+	}
+	for k, v := range e.dimensions {
+		doc[k] = v
+	}
+	dimensions := e.dimensionSet(row)
+
+	values := aggregationValues(vd.View.Name, row.Data)
+	metrics := make([]map[string]string, 0, len(values))
+	for _, v := range values {
+		doc[v.name] = v.value
+		metrics = append(metrics, map[string]string{"Name": v.name})
+	}
+	doc["_aws"] = map[string]interface{}{
+		"Timestamp": vd.End.UnixNano() / 1e6,
+		"CloudWatchMetrics": []map[string]interface{}{{
+			"Namespace":  e.namespace,
+			"Dimensions": [][]string{dimensions},
+			"Metrics":    metrics,
+		}},
+	}
+	return doc
+}
+
+// dimensionSet returns the sorted dimensions of the row: those of the
+// exporter, the region, cluster and resource labels, then the tags, up to
+// cloudWatchMaxDimensions.
+func (e *cloudWatchExporter) dimensionSet(row *view.Row) []string {
+	dimensions := make([]string, 0, len(e.dimensions)+len(row.Tags))
+	for _, k := range []string{cloudWatchRegionDimension, cloudWatchClusterDimension} {
+		if _, ok := e.dimensions[k]; ok {
+			dimensions = append(dimensions, k)
+		}
+	}
+	labels := make([]string, 0, len(e.dimensions))
+	for k := range e.dimensions {
+		if k != cloudWatchRegionDimension && k != cloudWatchClusterDimension {
+			labels = append(labels, k)
+		}
+	}
+	sort.Strings(labels)
+	dimensions = append(dimensions, labels...)
+
+	tags := make([]string, 0, len(row.Tags))
+	for _, t := range row.Tags {
+		if _, ok := e.dimensions[t.Key.Name()]; !ok {
+			tags = append(tags, t.Key.Name())
+		}
+	}
+	sort.Strings(tags)
+	dimensions = append(dimensions, tags...)
+
+	if len(dimensions) > cloudWatchMaxDimensions {
+		dimensions = dimensions[:cloudWatchMaxDimensions]
+	}
+	sort.Strings(dimensions)
+	return dimensions
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestCloudWatchExporter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("ListenPacket() =", err)
+	}
+	defer conn.Close()
+
+	defer func(f func() *awsMetadata) {
+		awsMetadataFunc = f
+	}(awsMetadataFunc)
+	awsMetadataFunc = func() *awsMetadata {
+		return &awsMetadata{region: "us-west-2", instanceID: "i-0123456789", cluster: "prod"}
+	}
+
+	e, f, err := newCloudWatchExporter(&metricsConfig{
+		component:              testComponent,
+		backendDestination:     cloudWatch,
+		cloudWatchAgentAddress: conn.LocalAddr().String(),
+		cloudWatchNamespace:    "knative",
+	}, TestLogger(t))
+	if err != nil {
+		t.Fatal("newCloudWatchExporter() =", err)
+	}
+	re, err := f(&resource.Resource{Type: "knative_revision", Labels: map[string]string{"namespace_name": "ns"}})
+	if err != nil {
+		t.Fatal("factory() =", err)
+	}
+	if re == e {
+		t.Error("factory() returned the default exporter for a resource")
+	}
+	re.ExportView(testViewData(t))
+
+	var docs []map[string]interface{}
+	buf := make([]byte, 64*1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for i := 0; i < 2; i++ {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal("ReadFrom() =", err)
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(buf[:n], &doc); err != nil {
+			t.Fatal("Unmarshal() =", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	want := []map[string]interface{}{{
+		"ClusterName":       "prod",
+		"Region":            "us-west-2",
+		"namespace_name":    "ns",
+		"route":             "r1",
+		"request_latencies": 3.0,
+		"_aws": map[string]interface{}{
+			"Timestamp": 1600000000000.0,
+			"CloudWatchMetrics": []interface{}{map[string]interface{}{
+				"Namespace":  "knative",
+				"Dimensions": []interface{}{[]interface{}{"ClusterName", "Region", "namespace_name", "route"}},
+				"Metrics":    []interface{}{map[string]interface{}{"Name": "request_latencies"}},
+			}},
+		},
+	}, {
+		"ClusterName":             "prod",
+		"Region":                  "us-west-2",
+		"namespace_name":          "ns",
+		"request_latencies.count": 2.0,
+		"request_latencies.avg":   3.0,
+		"request_latencies.min":   1.0,
+		"request_latencies.max":   5.0,
+		"_aws": map[string]interface{}{
+			"Timestamp": 1600000000000.0,
+			"CloudWatchMetrics": []interface{}{map[string]interface{}{
+				"Namespace":  "knative",
+				"Dimensions": []interface{}{[]interface{}{"ClusterName", "Region", "namespace_name"}},
+				"Metrics": []interface{}{
+					map[string]interface{}{"Name": "request_latencies.count"},
+					map[string]interface{}{"Name": "request_latencies.avg"},
+					map[string]interface{}{"Name": "request_latencies.min"},
+					map[string]interface{}{"Name": "request_latencies.max"},
+				},
+			}},
+		},
+	}}
+	if !cmp.Equal(docs, want) {
+		t.Error("EMF documents (-want, +got):", cmp.Diff(want, docs))
+	}
+
+	e.(stoppable).StopMetricsExporter()
+	if _, err := e.(*cloudWatchExporter).conn.Write([]byte("x")); err == nil {
+		t.Error("The connection to the agent is open after StopMetricsExporter()")
+	}
+}
+
+func TestCloudWatchDimensionLimit(t *testing.T) {
+	e := &cloudWatchExporter{
+		namespace: "knative",
+		dimensions: map[string]string{
+			cloudWatchRegionDimension:  "us-west-2",
+			cloudWatchClusterDimension: "prod",
+			"namespace_name":           "ns",
+		},
+	}
+	row := &view.Row{Data: &view.CountData{Value: 1}}
+	for i := 0; i < cloudWatchMaxDimensions; i++ {
+		row.Tags = append(row.Tags, tag.Tag{Key: tag.MustNewKey(fmt.Sprintf("tag_%02d", i)), Value: "v"})
+	}
+
+	doc := e.toEMF(&view.Data{View: &view.View{Name: "requests"}, End: time.Unix(1600000000, 0)}, row)
+	dimensions := doc["_aws"].(map[string]interface{})["CloudWatchMetrics"].([]map[string]interface{})[0]["Dimensions"].([][]string)[0]
+	if got, want := len(dimensions), cloudWatchMaxDimensions; got != want {
+		t.Fatalf("len(Dimensions) = %d, want %d", got, want)
+	}
+	// The dimensions of the exporter take precedence over the tags, and
+	// the tags left out are still in the document.
+	have := sets.NewString(dimensions...)
+	for _, k := range []string{cloudWatchRegionDimension, cloudWatchClusterDimension, "namespace_name", "tag_00", "tag_26"} {
+		if !have.Has(k) {
+			t.Errorf("Dimensions = %v, want %s among them", dimensions, k)
+		}
+	}
+	for _, k := range []string{"tag_27", "tag_29"} {
+		if have.Has(k) {
+			t.Errorf("Dimensions = %v, want %s left out", dimensions, k)
+		}
+		if doc[k] != "v" {
+			t.Errorf("%s = %v, want it kept as a property", k, doc[k])
+		}
+	}
+}
+
+func TestCloudWatchConfig(t *testing.T) {
+	mc, err := createMetricsConfig(context.Background(), ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{
+			BackendDestinationKey:    string(cloudWatch),
+			cloudWatchClusterNameKey: "prod",
+		},
+	})
+	if err != nil {
+		t.Fatal("createMetricsConfig() =", err)
+	}
+	if got, want := mc.cloudWatchNamespace, path.Join(servingDomain, testComponent); got != want {
+		t.Errorf("cloudWatchNamespace = %q, want %q", got, want)
+	}
+	if got, want := mc.cloudWatchClusterName, "prod"; got != want {
+		t.Errorf("cloudWatchClusterName = %q, want %q", got, want)
+	}
+	if got, want := mc.reportingPeriod, time.Minute; got != want {
+		t.Errorf("reportingPeriod = %v, want %v", got, want)
+	}
+
+	setCurMetricsConfig(mc)
+	defer setCurMetricsConfig(nil)
+	newConfig := *mc
+	if isNewExporterRequired(&newConfig) {
+		t.Error("isNewExporterRequired() = true, wanted false for the same config")
+	}
+	newConfig.cloudWatchClusterName = "staging"
+	if !isNewExporterRequired(&newConfig) {
+		t.Error("isNewExporterRequired() = false, wanted true for a new cluster")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path"
 	"strconv"
@@ -49,12 +50,47 @@ const (
 	collectorSecureKey               = "metrics.opencensus-require-tls"
 	reportingPeriodKey               = "metrics.reporting-period-seconds"
 
+	// OpenTelemetry Collector configuration keys
+	openTelemetryAddressKey = "metrics.opentelemetry-address"
+	openTelemetrySecureKey  = "metrics.opentelemetry-require-tls"
+	openTelemetryHeadersKey = "metrics.opentelemetry-headers"
+
+	// Prometheus exporter configuration keys
+	prometheusHostKey        = "metrics.prometheus-host"
+	prometheusPortKey        = "metrics.prometheus-port"
+	prometheusTLSCertFileKey = "metrics.prometheus-tls-cert-file"
+	prometheusTLSKeyFileKey  = "metrics.prometheus-tls-key-file"
+
+	// Debug exporter configuration keys
+	debugOutputPathKey = "metrics.debug-output-path"
+
+	// Prometheus Pushgateway configuration keys
+	pushgatewayURLKey = "metrics.prometheus-pushgateway-url"
+	pushgatewayJobKey = "metrics.prometheus-pushgateway-job"
+	// pushgatewayGroupByInstanceKey groups the pushed metrics by pod, see
+	// pushgatewayGroupByInstance.
+	pushgatewayGroupByInstanceKey = "metrics.prometheus-pushgateway-group-by-instance"
+
+	// Datadog configuration keys
+	datadogAgentAddressKey = "metrics.datadog-agent-address"
+	datadogSiteKey         = "metrics.datadog-site"
+	datadogTagsKey         = "metrics.datadog-tags"
+
+	// CloudWatch configuration keys
+	cloudWatchAgentAddressKey = "metrics.cloudwatch-agent-address"
+	cloudWatchNamespaceKey    = "metrics.cloudwatch-namespace"
+	cloudWatchClusterNameKey  = "metrics.cloudwatch-cluster-name"
+
 	// Stackdriver client configuration keys
 	stackdriverClusterNameKey           = "metrics.stackdriver-cluster-name"
 	stackdriverCustomMetricSubDomainKey = "metrics.stackdriver-custom-metrics-subdomain"
+	stackdriverCustomMetricPrefixKey    = "metrics.stackdriver-custom-metrics-prefix"
 	stackdriverGCPLocationKey           = "metrics.stackdriver-gcp-location"
 	stackdriverProjectIDKey             = "metrics.stackdriver-project-id"
 	stackdriverUseSecretKey             = "metrics.stackdriver-use-secret"
+	stackdriverSecretNameKey            = "metrics.stackdriver-gcp-secret-name"
+	stackdriverSecretNamespaceKey       = "metrics.stackdriver-gcp-secret-namespace"
+	stackdriverCredentialsFileKey       = "metrics.stackdriver-gcp-credentials-file"
 
 	defaultBackendEnvName = "DEFAULT_METRICS_BACKEND"
 	defaultPrometheusPort = 9090
@@ -69,9 +105,22 @@ const (
 	stackdriver metricsBackend = "stackdriver"
 	// prometheus is used for Prometheus backend
 	prometheus metricsBackend = "prometheus"
+	// prometheusPushgateway is used to push to a Prometheus Pushgateway, for
+	// the components which do not live long enough to be scraped.
+	prometheusPushgateway metricsBackend = "prometheus-pushgateway"
 	// openCensus is used to export to the OpenCensus Agent / Collector,
 	// which can send to many other services.
 	openCensus metricsBackend = "opencensus"
+	// openTelemetry is used to export to an OpenTelemetry Collector over
+	// OTLP/gRPC.
+	openTelemetry metricsBackend = "opentelemetry"
+	// datadog is used to export to a DogStatsD agent or the Datadog API.
+	datadog metricsBackend = "datadog"
+	// cloudWatch is used to export to AWS CloudWatch through the CloudWatch agent.
+	cloudWatch metricsBackend = "cloudwatch"
+	// debug is used to write the metrics as JSON to stdout or a file, for
+	// local development.
+	debugBackend metricsBackend = "debug"
 	// none is used to export, well, nothing.
 	none metricsBackend = "none"
 )
@@ -87,6 +136,13 @@ type metricsConfig struct {
 	// If duration is less than or equal to zero, it enables the default behavior.
 	reportingPeriod time.Duration
 
+	// labelFilter drops or hashes the disallowed tags, see setLabelFilter.
+	labelFilter *labelFilter
+
+	// bucketBoundaries overrides the bucket boundaries of the distributions
+	// of a measure, by measure name.
+	bucketBoundaries map[string][]float64
+
 	// recorder provides a hook for performing custom transformations before
 	// writing the metrics to the stats.RecordWithOptions interface.
 	recorder func(context.Context, []stats.Measurement, ...stats.Options) error
@@ -94,16 +150,73 @@ type metricsConfig struct {
 	// secret contains credentials for an exporter to use for authentication.
 	secret *corev1.Secret
 
-	// ---- OpenCensus specific below ----
+	// ---- OpenCensus and OpenTelemetry specific below ----
 	// collectorAddress is the address of the collector, if not `localhost:55678`
+	// for OpenCensus or `localhost:4317` for OpenTelemetry.
 	collectorAddress string
 	// Require mutual TLS. Defaults to "false" because mutual TLS is hard to set up.
 	requireSecure bool
+	// collectorHeaders are sent with every export to the OpenTelemetry
+	// Collector, e.g. to authenticate.
+	collectorHeaders map[string]string
 
 	// ---- Prometheus specific below ----
+	// prometheusHost is the host where metrics are exposed in Prometheus
+	// format. It defaults to all the interfaces.
+	prometheusHost string
 	// prometheusPort is the port where metrics are exposed in Prometheus
 	// format. It defaults to 9090.
 	prometheusPort int
+	// prometheusTLSCertFile and prometheusTLSKeyFile, when set, are the
+	// certificate and key used to serve the metrics over HTTPS.
+	prometheusTLSCertFile string
+	prometheusTLSKeyFile  string
+
+	// ---- Prometheus Pushgateway specific below ----
+	// pushgatewayURL is the URL of the Pushgateway the metrics are pushed to.
+	pushgatewayURL string
+	// pushgatewayJob is the job label of the pushed metrics. It defaults to
+	// the component.
+	pushgatewayJob string
+	// pushgatewayGroupByInstance adds the pod name as the instance label of
+	// the pushed metrics, so that the replicas of the job do not replace each
+	// other's metrics. The Pushgateway never forgets a group, so every pod
+	// leaves its metrics behind when it goes away, until they are deleted
+	// from the Pushgateway, e.g. by a cleanup job.
+	pushgatewayGroupByInstance bool
+
+	// ---- Datadog specific below ----
+	// datadogAgentAddress is the address of the DogStatsD agent. When
+	// empty, metrics are submitted to the Datadog API with the API key in
+	// the secret.
+	datadogAgentAddress string
+	// datadogSite is the Datadog site of the API, e.g. "datadoghq.eu".
+	datadogSite string
+	// datadogTags are added to every metric, e.g. "env:prod".
+	datadogTags []string
+
+	// ---- CloudWatch specific below ----
+	// cloudWatchAgentAddress is the address of the CloudWatch agent's
+	// embedded metric format listener, if not `localhost:25888`.
+	cloudWatchAgentAddress string
+	// cloudWatchNamespace is the CloudWatch namespace of the metrics. It
+	// defaults to the domain joined with the component.
+	cloudWatchNamespace string
+	// cloudWatchClusterName is the cluster dimension of the metrics. When
+	// empty, it is read from the EC2 instance metadata.
+	cloudWatchClusterName string
+
+	// ---- Debug specific below ----
+	// debugOutputPath is the file the metrics are appended to, stdout when
+	// empty.
+	debugOutputPath string
+
+	// ---- Registered backends specific below ----
+	// backendConfig is the config-observability data given to the factory
+	// of a registered backend.
+	backendConfig map[string]string
+	// secrets fetches the Secrets a registered backend needs.
+	secrets SecretFetcher
 
 	// ---- Stackdriver specific below ----
 	// True if backendDestination equals to "stackdriver". Store this in a variable
@@ -119,6 +232,15 @@ type metricsConfig struct {
 	stackdriverCustomMetricTypePrefix string
 	// stackdriverClientConfig is the metadata to configure the metrics exporter's Stackdriver client.
 	stackdriverClientConfig StackdriverClientConfig
+	// stackdriverProjects routes the metrics of a namespace to another GCP
+	// project than the one of stackdriverClientConfig, by namespace.
+	stackdriverProjects map[string]string
+	// platformMetadataProvider names the provider of the project, location
+	// and cluster of the metrics, GCP when empty.
+	platformMetadataProvider string
+	// stackdriverPrecreateDescriptors creates the metric descriptors of the
+	// registered views when the exporter starts.
+	stackdriverPrecreateDescriptors bool
 }
 
 // StackdriverClientConfig encapsulates the metadata required to configure a Stackdriver client.
@@ -140,6 +262,16 @@ type StackdriverClientConfig struct {
 	// If UseSecret is false, Google Application Default Credentials
 	// will be used (https://cloud.google.com/docs/authentication/production).
 	UseSecret bool
+	// SecretName is the name of the Kubernetes Secret holding the service account key.
+	// Setting it implies UseSecret, and takes precedence over the location given to
+	// metrics.SetStackdriverSecretLocation.
+	SecretName string
+	// SecretNamespace is the namespace of the Secret named by SecretName. It defaults to
+	// the system namespace.
+	SecretNamespace string
+	// CredentialsFile is the path to a service account key file to authenticate with.
+	// It cannot be combined with a Secret.
+	CredentialsFile string
 }
 
 // NewStackdriverClientConfigFromMap creates a stackdriverClientConfig from the given map
@@ -148,8 +280,28 @@ func NewStackdriverClientConfigFromMap(config map[string]string) *StackdriverCli
 		ProjectID:   config[stackdriverProjectIDKey],
 		GCPLocation: config[stackdriverGCPLocationKey],
 		ClusterName: config[stackdriverClusterNameKey],
-		UseSecret:   strings.EqualFold(config[stackdriverUseSecretKey], "true"),
+		UseSecret:   strings.EqualFold(config[stackdriverUseSecretKey], "true") || config[stackdriverSecretNameKey] != "",
+
+		SecretName:      config[stackdriverSecretNameKey],
+		SecretNamespace: config[stackdriverSecretNamespaceKey],
+		CredentialsFile: config[stackdriverCredentialsFileKey],
+	}
+}
+
+// String formats the config for the logs, leaving out the values of the
+// collector headers, which usually hold credentials.
+func (mc *metricsConfig) String() string {
+	if mc == nil {
+		return "<nil>"
+	}
+	redacted := *mc
+	if len(mc.collectorHeaders) > 0 {
+		redacted.collectorHeaders = make(map[string]string, len(mc.collectorHeaders))
+		for k := range mc.collectorHeaders {
+			redacted.collectorHeaders[k] = "REDACTED"
+		}
 	}
+	return fmt.Sprintf("%+v", redacted)
 }
 
 // record applies the `ros` Options to each measurement in `mss` and then records the resulting
@@ -161,6 +313,11 @@ func (mc *metricsConfig) record(ctx context.Context, mss []stats.Measurement, ro
 		return nil
 	}
 
+	ctx, err := hashLabels(ctx)
+	if err != nil {
+		return err
+	}
+
 	if mc.recorder == nil {
 		opt, err := optionForResource(metricskey.GetResource(ctx))
 		if err != nil {
@@ -201,46 +358,141 @@ func createMetricsConfig(ctx context.Context, ops ExporterOptions) (*metricsConf
 		backend = backendFromConfig
 	}
 	lb := metricsBackend(strings.ToLower(backend))
-	switch lb {
-	case stackdriver, prometheus, openCensus:
+	switch {
+	case isBuiltinBackend(lb):
+		mc.backendDestination = lb
+	case getBackendFactory(lb) != nil:
 		mc.backendDestination = lb
+		mc.backendConfig = m
+		mc.secrets = ops.Secrets
 	default:
 		return nil, fmt.Errorf("unsupported metrics backend value %q", backend)
 	}
 
+	lf, err := newLabelFilter(m)
+	if err != nil {
+		return nil, err
+	}
+	mc.labelFilter = lf
+	if mc.bucketBoundaries, err = parseBucketBoundaries(m); err != nil {
+		return nil, err
+	}
+
 	if mc.backendDestination == openCensus {
 		mc.collectorAddress = ops.ConfigMap[collectorAddressKey]
-		if isSecure := ops.ConfigMap[collectorSecureKey]; isSecure != "" {
-			var err error
-			if mc.requireSecure, err = strconv.ParseBool(isSecure); err != nil {
-				return nil, fmt.Errorf("invalid %s value %q", collectorSecureKey, isSecure)
+		if err := validateCollectorAddress(mc.collectorAddress); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", collectorAddressKey, mc.collectorAddress, err)
+		}
+		if mc.requireSecure, err = parseBool(m, collectorSecureKey); err != nil {
+			return nil, err
+		}
+		if mc.requireSecure {
+			mc.secret, err = getBackendSecret(ops.Component, "opencensus", ops.Secrets)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if mc.backendDestination == openTelemetry {
+		mc.collectorAddress = m[openTelemetryAddressKey]
+		if err := validateCollectorAddress(mc.collectorAddress); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", openTelemetryAddressKey, mc.collectorAddress, err)
+		}
+		if mc.requireSecure, err = parseBool(m, openTelemetrySecureKey); err != nil {
+			return nil, err
+		}
+		if mc.requireSecure {
+			mc.secret, err = getBackendSecret(ops.Component, "opentelemetry", ops.Secrets)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if headers := m[openTelemetryHeadersKey]; headers != "" {
+			if mc.collectorHeaders, err = parseHeaders(headers); err != nil {
+				return nil, fmt.Errorf("invalid %s value %q: %w", openTelemetryHeadersKey, headers, err)
 			}
+		}
+	}
 
-			if mc.requireSecure {
-				mc.secret, err = getOpenCensusSecret(ops.Component, ops.Secrets)
-				if err != nil {
-					return nil, err
-				}
+	if mc.backendDestination == datadog {
+		mc.datadogAgentAddress = m[datadogAgentAddressKey]
+		mc.datadogSite = m[datadogSiteKey]
+		if mc.datadogSite == "" {
+			mc.datadogSite = defaultDatadogSite
+		}
+		for _, tag := range strings.Split(m[datadogTagsKey], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				mc.datadogTags = append(mc.datadogTags, tag)
+			}
+		}
+		if mc.datadogAgentAddress == "" {
+			var err error
+			if mc.secret, err = getBackendSecret(ops.Component, "datadog", ops.Secrets); err != nil {
+				return nil, err
 			}
 		}
 	}
 
+	if mc.backendDestination == cloudWatch {
+		mc.cloudWatchAgentAddress = m[cloudWatchAgentAddressKey]
+		mc.cloudWatchClusterName = m[cloudWatchClusterNameKey]
+		mc.cloudWatchNamespace = m[cloudWatchNamespaceKey]
+		if mc.cloudWatchNamespace == "" {
+			mc.cloudWatchNamespace = path.Join(mc.domain, mc.component)
+		}
+	}
+
 	if mc.backendDestination == prometheus {
 		pp := ops.PrometheusPort
 		if pp == 0 {
-			var err error
+			if pp, err = parsePrometheusPort(m); err != nil {
+				return nil, err
+			}
+		}
+		if pp == 0 {
 			pp, err = prometheusPort()
 			if err != nil {
 				return nil, fmt.Errorf("failed to determine Prometheus port: %w", err)
 			}
 		}
 
-		if pp < minPrometheusPort || pp > maxPrometheusPort {
-			return nil, fmt.Errorf("invalid port %d, should be between %d and %d",
-				pp, minPrometheusPort, maxPrometheusPort)
+		if err := validatePrometheusPort(pp); err != nil {
+			return nil, err
 		}
 
 		mc.prometheusPort = pp
+		mc.prometheusHost = m[prometheusHostKey]
+
+		mc.prometheusTLSCertFile = m[prometheusTLSCertFileKey]
+		mc.prometheusTLSKeyFile = m[prometheusTLSKeyFileKey]
+		if (mc.prometheusTLSCertFile == "") != (mc.prometheusTLSKeyFile == "") {
+			return nil, fmt.Errorf("%s and %s must be set together", prometheusTLSCertFileKey, prometheusTLSKeyFileKey)
+		}
+	}
+
+	if mc.backendDestination == debugBackend {
+		mc.debugOutputPath = m[debugOutputPathKey]
+	}
+
+	if mc.backendDestination == prometheusPushgateway {
+		mc.pushgatewayURL = m[pushgatewayURLKey]
+		if mc.pushgatewayURL == "" {
+			return nil, fmt.Errorf("%s must be set for the %s backend", pushgatewayURLKey, prometheusPushgateway)
+		}
+		if err := validatePushgatewayURL(mc.pushgatewayURL); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", pushgatewayURLKey, mc.pushgatewayURL, err)
+		}
+		mc.pushgatewayJob = m[pushgatewayJobKey]
+		if mc.pushgatewayJob == "" {
+			mc.pushgatewayJob = mc.component
+		}
+		if err := validatePushgatewayJob(mc.pushgatewayJob); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", pushgatewayJobKey, mc.pushgatewayJob)
+		}
+		if mc.pushgatewayGroupByInstance, err = parseBool(m, pushgatewayGroupByInstanceKey); err != nil {
+			return nil, err
+		}
 	}
 
 	// If stackdriverClientConfig is not provided for stackdriver backend destination, OpenCensus will try to
@@ -250,8 +502,6 @@ func createMetricsConfig(ctx context.Context, ops ExporterOptions) (*metricsConf
 		scc := NewStackdriverClientConfigFromMap(m)
 		mc.stackdriverClientConfig = *scc
 		mc.isStackdriverBackend = true
-		var allowCustomMetrics bool
-		var err error
 		mc.stackdriverMetricTypePrefix = path.Join(mc.domain, mc.component)
 
 		customMetricsSubDomain := m[stackdriverCustomMetricSubDomainKey]
@@ -259,17 +509,42 @@ func createMetricsConfig(ctx context.Context, ops ExporterOptions) (*metricsConf
 			customMetricsSubDomain = defaultCustomMetricSubDomain
 		}
 		mc.stackdriverCustomMetricTypePrefix = path.Join(customMetricTypePrefix, customMetricsSubDomain, mc.component)
-		if ascmStr := m[allowStackdriverCustomMetricsKey]; ascmStr != "" {
-			allowCustomMetrics, err = strconv.ParseBool(ascmStr)
-			if err != nil {
-				return nil, fmt.Errorf("invalid %s value %q", allowStackdriverCustomMetricsKey, ascmStr)
+		// A custom prefix replaces both custom.googleapis.com and the subdomain,
+		// e.g. "custom.googleapis.com/mycompany.dev".
+		if prefix := m[stackdriverCustomMetricPrefixKey]; prefix != "" {
+			if err := validateCustomMetricPrefix(prefix); err != nil {
+				return nil, fmt.Errorf("invalid %s value %q: %w", stackdriverCustomMetricPrefixKey, prefix, err)
+			}
+			mc.stackdriverCustomMetricTypePrefix = path.Join(prefix, mc.component)
+		}
+		if err := validateCustomMetricSubDomain(m); err != nil {
+			return nil, err
+		}
+		allowCustomMetrics, err := parseBool(m, allowStackdriverCustomMetricsKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if mc.stackdriverProjects, err = parseStackdriverProjects(m); err != nil {
+			return nil, err
+		}
+		if p := m[platformMetadataProviderKey]; p != "" {
+			if err := validatePlatformMetadataProvider(p); err != nil {
+				return nil, err
 			}
+			mc.platformMetadataProvider = p
+		}
+		if mc.stackdriverPrecreateDescriptors, err = parseBool(m, stackdriverPrecreateDescriptorsKey); err != nil {
+			return nil, err
 		}
 
 		mc.recorder = sdCustomMetricsRecorder(mc, allowCustomMetrics)
 
+		if err := validateCredentialsFile(scc); err != nil {
+			return nil, err
+		}
 		if scc.UseSecret {
-			secret, err := getStackdriverSecret(ctx, ops.Secrets)
+			secret, err := getStackdriverSecret(ctx, scc, ops.Secrets)
 			if err != nil {
 				return nil, err
 			}
@@ -285,23 +560,109 @@ func createMetricsConfig(ctx context.Context, ops ExporterOptions) (*metricsConf
 	// For Prometheus, we will use a lower value since the exporter doesn't
 	// push anything but just responds to pull requests, and shorter durations
 	// do not really hurt the performance and we rely on the scraping configuration.
-	if repStr, ok := m[reportingPeriodKey]; ok && repStr != "" {
-		repInt, err := strconv.Atoi(repStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid %s value %q", reportingPeriodKey, repStr)
-		}
-		mc.reportingPeriod = time.Duration(repInt) * time.Second
-	} else {
+	if mc.reportingPeriod, err = parseReportingPeriod(m); err != nil {
+		return nil, err
+	}
+	if mc.reportingPeriod == 0 {
 		switch mc.backendDestination {
-		case stackdriver, openCensus:
-			mc.reportingPeriod = time.Minute
-		case prometheus:
+		case prometheus, prometheusPushgateway, debugBackend:
 			mc.reportingPeriod = 5 * time.Second
+		default:
+			mc.reportingPeriod = time.Minute
 		}
 	}
 	return &mc, nil
 }
 
+// The following parse and validate the settings of the metrics ConfigMap.
+// They are shared by createMetricsConfig and ValidateObservabilityConfigMap.
+
+// parseBool returns the boolean value of key in m, false if it is not set.
+func parseBool(m map[string]string, key string) (bool, error) {
+	v := m[key]
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value %q", key, v)
+	}
+	return b, nil
+}
+
+// parseReportingPeriod returns the reporting period set in m, zero if it is
+// not set.
+func parseReportingPeriod(m map[string]string) (time.Duration, error) {
+	repStr := m[reportingPeriodKey]
+	if repStr == "" {
+		return 0, nil
+	}
+	repInt, err := strconv.Atoi(repStr)
+	if err != nil || repInt <= 0 {
+		return 0, fmt.Errorf("invalid %s value %q", reportingPeriodKey, repStr)
+	}
+	return time.Duration(repInt) * time.Second, nil
+}
+
+// parsePrometheusPort returns the Prometheus port set in m, zero if it is not
+// set. The port is not checked against the allowed range, see
+// validatePrometheusPort.
+func parsePrometheusPort(m map[string]string) (int, error) {
+	ppStr := m[prometheusPortKey]
+	if ppStr == "" {
+		return 0, nil
+	}
+	p, err := strconv.ParseUint(ppStr, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", prometheusPortKey, ppStr)
+	}
+	return int(p), nil
+}
+
+// validatePrometheusPort checks that pp is in the range of the ports the
+// Prometheus exporter may listen on.
+func validatePrometheusPort(pp int) error {
+	if pp < minPrometheusPort || pp > maxPrometheusPort {
+		return fmt.Errorf("invalid port %d, should be between %d and %d",
+			pp, minPrometheusPort, maxPrometheusPort)
+	}
+	return nil
+}
+
+// validatePushgatewayURL checks that u is a URL the Pushgateway exporter can
+// push to.
+func validatePushgatewayURL(u string) error {
+	_, err := url.Parse(u)
+	return err
+}
+
+// validatePushgatewayJob checks that job can be used as a path segment of the
+// Pushgateway URL.
+func validatePushgatewayJob(job string) error {
+	if strings.Contains(job, "/") {
+		return errors.New("must not contain a /")
+	}
+	return nil
+}
+
+// validateCustomMetricSubDomain checks that the custom metric subdomain is
+// not combined with a custom metric prefix, which replaces it.
+func validateCustomMetricSubDomain(m map[string]string) error {
+	if m[stackdriverCustomMetricPrefixKey] != "" && m[stackdriverCustomMetricSubDomainKey] != "" {
+		return fmt.Errorf("%s cannot be combined with %s", stackdriverCustomMetricPrefixKey, stackdriverCustomMetricSubDomainKey)
+	}
+	return nil
+}
+
+// validateCredentialsFile checks that the credentials file of scc is not
+// combined with a Secret.
+func validateCredentialsFile(scc *StackdriverClientConfig) error {
+	if scc.UseSecret && scc.CredentialsFile != "" {
+		return fmt.Errorf("%s cannot be combined with a Secret", stackdriverCredentialsFileKey)
+	}
+	return nil
+}
+
 // Domain holds the metrics domain to use for surfacing metrics.
 func Domain() string {
 	if domain := os.Getenv(DomainEnv); domain != "" {
@@ -374,3 +735,15 @@ func MetricsOptionsToJson(opts *ExporterOptions) (string, error) {
 
 	return string(jsonOpts), nil
 }
+
+// validateCustomMetricPrefix checks that the prefix is under one of the
+// domains Stackdriver accepts user-defined metrics under, which are the
+// only ones whose metric descriptors the exporter creates.
+func validateCustomMetricPrefix(prefix string) error {
+	for _, domain := range []string{customMetricTypePrefix, externalMetricTypePrefix} {
+		if strings.HasPrefix(prefix, domain+"/") && len(prefix) > len(domain)+1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("must start with %s/ or %s/", customMetricTypePrefix, externalMetricTypePrefix)
+}
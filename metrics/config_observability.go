@@ -17,7 +17,9 @@ limitations under the License.
 package metrics
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	texttemplate "text/template"
 
@@ -42,6 +44,13 @@ const (
 
 	// EnableReqLogKey is the CM key to enable request log.
 	EnableReqLogKey = "logging.enable-request-log"
+
+	// The following are the CM keys of the other observability settings.
+	enableVarLogCollectionKey = "logging.enable-var-log-collection"
+	revisionURLTemplateKey    = "logging.revision-url-template"
+	enableProbeRequestLogKey  = "logging.enable-probe-request-log"
+	requestMetricsBackendKey  = "metrics.request-metrics-backend-destination"
+	enableProfilingKey        = "profiling.enable"
 )
 
 // ObservabilityConfig contains the configuration defined in the observability ConfigMap.
@@ -75,6 +84,10 @@ type ObservabilityConfig struct {
 	// MetricsCollectorAddress specifies the metrics collector address. This is only used
 	// when the metrics backend is opencensus.
 	MetricsCollectorAddress string
+
+	// MetricsCollectorRequireTLS specifies whether the connection to the metrics
+	// collector uses mutual TLS. This is only used when the metrics backend is opencensus.
+	MetricsCollectorRequireTLS bool
 }
 
 func defaultConfig() *ObservabilityConfig {
@@ -90,18 +103,26 @@ func NewObservabilityConfigFromConfigMap(configMap *corev1.ConfigMap) (*Observab
 	oc := defaultConfig()
 
 	if err := cm.Parse(configMap.Data,
-		cm.AsBool("logging.enable-var-log-collection", &oc.EnableVarLogCollection),
-		cm.AsString("logging.revision-url-template", &oc.LoggingURLTemplate),
+		cm.AsBool(enableVarLogCollectionKey, &oc.EnableVarLogCollection),
+		cm.AsString(revisionURLTemplateKey, &oc.LoggingURLTemplate),
 		cm.AsString(ReqLogTemplateKey, &oc.RequestLogTemplate),
 		cm.AsBool(EnableReqLogKey, &oc.EnableRequestLog),
-		cm.AsBool("logging.enable-probe-request-log", &oc.EnableProbeRequestLog),
-		cm.AsString("metrics.request-metrics-backend-destination", &oc.RequestMetricsBackend),
-		cm.AsBool("profiling.enable", &oc.EnableProfiling),
-		cm.AsString("metrics.opencensus-address", &oc.MetricsCollectorAddress),
+		cm.AsBool(enableProbeRequestLogKey, &oc.EnableProbeRequestLog),
+		cm.AsString(requestMetricsBackendKey, &oc.RequestMetricsBackend),
+		cm.AsBool(enableProfilingKey, &oc.EnableProfiling),
+		cm.AsString(collectorAddressKey, &oc.MetricsCollectorAddress),
+		cm.AsBool(collectorSecureKey, &oc.MetricsCollectorRequireTLS),
 	); err != nil {
 		return nil, err
 	}
 
+	// The collector address is only used by the opencensus backend.
+	if oc.RequestMetricsBackend == string(openCensus) || configMap.Data[BackendDestinationKey] == string(openCensus) {
+		if err := validateCollectorAddress(oc.MetricsCollectorAddress); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", collectorAddressKey, oc.MetricsCollectorAddress, err)
+		}
+	}
+
 	if oc.RequestLogTemplate == "" && oc.EnableRequestLog {
 		return nil, fmt.Errorf("%q was set to true, but no %q was specified", EnableReqLogKey, ReqLogTemplateKey)
 	}
@@ -116,6 +137,22 @@ func NewObservabilityConfigFromConfigMap(configMap *corev1.ConfigMap) (*Observab
 	return oc, nil
 }
 
+// validateCollectorAddress checks that the collector address, if any, is a
+// host:port pair.
+func validateCollectorAddress(address string) error {
+	if address == "" {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if host == "" || port == "" {
+		return errors.New("the address must be of the form host:port")
+	}
+	return nil
+}
+
 // ConfigMapName gets the name of the metrics ConfigMap
 func ConfigMapName() string {
 	if cm := os.Getenv(configMapNameEnv); cm != "" {
@@ -140,6 +140,52 @@ func TestObservabilityConfiguration(t *testing.T) {
 			"metrics.request-metrics-backend-destination": "opencensus",
 			"metrics.opencensus-address":                  "otel:55678",
 		},
+	}, {
+		name: "observability configuration with secure collector",
+		wantConfig: &ObservabilityConfig{
+			LoggingURLTemplate:         DefaultLogURLTemplate,
+			RequestLogTemplate:         DefaultRequestLogTemplate,
+			RequestMetricsBackend:      "opencensus",
+			MetricsCollectorAddress:    "otel.observability:55678",
+			MetricsCollectorRequireTLS: true,
+		},
+		data: map[string]string{
+			"metrics.request-metrics-backend-destination": "opencensus",
+			"metrics.opencensus-address":                  "otel.observability:55678",
+			"metrics.opencensus-require-tls":              "true",
+		},
+	}, {
+		name:    "invalid collector address",
+		wantErr: true,
+		data: map[string]string{
+			"metrics.request-metrics-backend-destination": "opencensus",
+			"metrics.opencensus-address":                  "otel",
+		},
+	}, {
+		name:    "invalid collector address for the metrics backend",
+		wantErr: true,
+		data: map[string]string{
+			"metrics.backend-destination": "opencensus",
+			"metrics.opencensus-address":  "otel",
+		},
+	}, {
+		name: "unused collector address",
+		wantConfig: &ObservabilityConfig{
+			LoggingURLTemplate:      DefaultLogURLTemplate,
+			RequestLogTemplate:      DefaultRequestLogTemplate,
+			RequestMetricsBackend:   "prometheus",
+			MetricsCollectorAddress: "otel",
+		},
+		data: map[string]string{
+			"metrics.backend-destination": "stackdriver",
+			"metrics.opencensus-address":  "otel",
+		},
+	}, {
+		name:    "invalid collector TLS toggle",
+		wantErr: true,
+		data: map[string]string{
+			"metrics.opencensus-require-tls": "yep",
+		},
 	}}
 
 	for _, tt := range observabilityConfigTests {
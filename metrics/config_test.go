@@ -17,6 +17,7 @@ package metrics
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"os"
 	"path"
@@ -30,6 +31,8 @@ import (
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	. "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/metrics/metricstest"
@@ -106,6 +109,73 @@ var (
 			Component: testComponent,
 		},
 		expectedErr: "invalid " + reportingPeriodKey + ` value "test"`,
+	}, {
+		name: "emptyStackdriverProject",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:                  string(stackdriver),
+				stackdriverProjectKeyPrefix + "tenant": "",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + stackdriverProjectKeyPrefix + `tenant value ""`,
+	}, {
+		name: "unsupportedPlatformMetadataProvider",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:       string(stackdriver),
+				platformMetadataProviderKey: "mainframe",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "unsupported " + platformMetadataProviderKey + ` value "mainframe"`,
+	}, {
+		name: "invalidStackdriverPrecreateDescriptors",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:              string(stackdriver),
+				stackdriverPrecreateDescriptorsKey: "eagerly",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + stackdriverPrecreateDescriptorsKey + ` value "eagerly"`,
+	}, {
+		name: "invalidStackdriverCustomMetricPrefix",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:            string(stackdriver),
+				stackdriverCustomMetricPrefixKey: "mycompany.dev",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + stackdriverCustomMetricPrefixKey + ` value "mycompany.dev": must start with custom.googleapis.com/ or external.googleapis.com/`,
+	}, {
+		name: "stackdriverCustomMetricPrefixWithSubdomain",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:               string(stackdriver),
+				stackdriverCustomMetricPrefixKey:    "custom.googleapis.com/mycompany.dev",
+				stackdriverCustomMetricSubDomainKey: customSubDomain,
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: stackdriverCustomMetricPrefixKey + " cannot be combined with " + stackdriverCustomMetricSubDomainKey,
+	}, {
+		name: "nonPositiveReportingPeriod",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey: string(prometheus),
+				reportingPeriodKey:    "0",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + reportingPeriodKey + ` value "0"`,
 	}, {
 		name: "invalidOpenCensusSecuritySetting",
 		ops: ExporterOptions{
@@ -117,6 +187,72 @@ var (
 			Component: testComponent,
 		},
 		expectedErr: "invalid " + collectorSecureKey + ` value "yep"`,
+	}, {
+		name: "invalidOpenCensusAddress",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey: string(openCensus),
+				collectorAddressKey:   ":55678",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + collectorAddressKey + ` value ":55678": the address must be of the form host:port`,
+	}, {
+		name: "invalidOpenTelemetryAddress",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:   string(openTelemetry),
+				openTelemetryAddressKey: ":4317",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + openTelemetryAddressKey + ` value ":4317": the address must be of the form host:port`,
+	}, {
+		name: "invalidOpenTelemetrySecuritySetting",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:  string(openTelemetry),
+				openTelemetrySecureKey: "yep",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + openTelemetrySecureKey + ` value "yep"`,
+	}, {
+		name: "invalidOpenTelemetryHeaders",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:   string(openTelemetry),
+				openTelemetryHeadersKey: "api-key",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + openTelemetryHeadersKey + ` value "api-key": header "api-key" is not of the form key=value`,
+	}, {
+		name: "invalidPrometheusPortKey",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey: string(prometheus),
+				prometheusPortKey:     "ninety",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: "invalid " + prometheusPortKey + ` value "ninety"`,
+	}, {
+		name: "prometheusTLSCertWithoutKey",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:    string(prometheus),
+				prometheusTLSCertFileKey: "/etc/metrics/tls.crt",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedErr: prometheusTLSCertFileKey + " and " + prometheusTLSKeyFileKey + " must be set together",
 	}, {
 		name: "invalidAllowStackdriverCustomMetrics",
 		ops: ExporterOptions{
@@ -295,6 +431,38 @@ var (
 			},
 		},
 		expectedNewExporter: true,
+	}, {
+		name: "validOpenTelemetrySettings",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:   string(openTelemetry),
+				openTelemetryAddressKey: "otel-collector.observability:55678",
+				openTelemetrySecureKey:  "true",
+				openTelemetryHeadersKey: "api-key=secret, tenant=knative",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+			Secrets: fakeSecretList(corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testComponent + "-opentelemetry",
+				},
+			}).Get,
+		},
+		expectedConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: openTelemetry,
+			reportingPeriod:    time.Minute,
+			collectorAddress:   "otel-collector.observability:55678",
+			requireSecure:      true,
+			collectorHeaders:   map[string]string{"api-key": "secret", "tenant": "knative"},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testComponent + "-opentelemetry",
+				},
+			},
+		},
+		expectedNewExporter: true,
 	}, {
 		name: "validPrometheus",
 		ops: ExporterOptions{
@@ -312,6 +480,30 @@ var (
 			prometheusPort:     defaultPrometheusPort,
 		},
 		expectedNewExporter: true,
+	}, {
+		name: "validPrometheusHostPortAndTLS",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:    string(prometheus),
+				prometheusHostKey:        "127.0.0.1",
+				prometheusPortKey:        "9092",
+				prometheusTLSCertFileKey: "testdata/server-cert.pem",
+				prometheusTLSKeyFileKey:  "testdata/server-key.pem",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedConfig: metricsConfig{
+			domain:                servingDomain,
+			component:             testComponent,
+			backendDestination:    prometheus,
+			reportingPeriod:       5 * time.Second,
+			prometheusHost:        "127.0.0.1",
+			prometheusPort:        9092,
+			prometheusTLSCertFile: "testdata/server-cert.pem",
+			prometheusTLSKeyFile:  "testdata/server-key.pem",
+		},
+		expectedNewExporter: true,
 	}, {
 		name: "validCapitalStackdriver",
 		ops: ExporterOptions{
@@ -417,6 +609,8 @@ var (
 				ProjectID: "test2",
 			},
 		},
+		// The Stackdriver exporter's reporting interval changed.
+		expectedNewExporter: true,
 	}, {
 		name: "emptyReportingPeriodPrometheus",
 		ops: ExporterOptions{
@@ -507,6 +701,87 @@ var (
 				ProjectID: "test2",
 			},
 		},
+		expectedNewExporter: true,
+	}, {
+		name: "stackdriverCustomMetricPrefix",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:            string(stackdriver),
+				stackdriverProjectIDKey:          "test2",
+				stackdriverCustomMetricPrefixKey: "external.googleapis.com/mycompany.dev",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedConfig: metricsConfig{
+			domain:                            servingDomain,
+			component:                         testComponent,
+			backendDestination:                stackdriver,
+			reportingPeriod:                   time.Minute,
+			isStackdriverBackend:              true,
+			stackdriverMetricTypePrefix:       path.Join(servingDomain, testComponent),
+			stackdriverCustomMetricTypePrefix: path.Join("external.googleapis.com/mycompany.dev", testComponent),
+			stackdriverClientConfig: StackdriverClientConfig{
+				ProjectID: "test2",
+			},
+		},
+		expectedNewExporter: true,
+	}, {
+		name: "stackdriverProjects",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:                    string(stackdriver),
+				stackdriverProjectIDKey:                  "test2",
+				stackdriverProjectKeyPrefix + "tenant-a": "project-a",
+				stackdriverProjectKeyPrefix + "tenant-b": "project-b",
+				platformMetadataProviderKey:              "env",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedConfig: metricsConfig{
+			domain:                            servingDomain,
+			component:                         testComponent,
+			backendDestination:                stackdriver,
+			reportingPeriod:                   time.Minute,
+			isStackdriverBackend:              true,
+			stackdriverMetricTypePrefix:       path.Join(servingDomain, testComponent),
+			stackdriverCustomMetricTypePrefix: path.Join(customMetricTypePrefix, defaultCustomMetricSubDomain, testComponent),
+			stackdriverClientConfig: StackdriverClientConfig{
+				ProjectID: "test2",
+			},
+			stackdriverProjects: map[string]string{
+				"tenant-a": "project-a",
+				"tenant-b": "project-b",
+			},
+			platformMetadataProvider: "env",
+		},
+		expectedNewExporter: true,
+	}, {
+		name: "stackdriverPrecreateDescriptors",
+		ops: ExporterOptions{
+			ConfigMap: map[string]string{
+				BackendDestinationKey:              string(stackdriver),
+				stackdriverProjectIDKey:            "test2",
+				stackdriverPrecreateDescriptorsKey: "true",
+			},
+			Domain:    servingDomain,
+			Component: testComponent,
+		},
+		expectedConfig: metricsConfig{
+			domain:                            servingDomain,
+			component:                         testComponent,
+			backendDestination:                stackdriver,
+			reportingPeriod:                   time.Minute,
+			isStackdriverBackend:              true,
+			stackdriverMetricTypePrefix:       path.Join(servingDomain, testComponent),
+			stackdriverCustomMetricTypePrefix: path.Join(customMetricTypePrefix, defaultCustomMetricSubDomain, testComponent),
+			stackdriverClientConfig: StackdriverClientConfig{
+				ProjectID: "test2",
+			},
+			stackdriverPrecreateDescriptors: true,
+		},
+		expectedNewExporter: true,
 	}, {
 		name: "overridePrometheusPort",
 		ops: ExporterOptions{
@@ -687,6 +962,35 @@ func TestIsNewExporterRequiredFromNilConfig(t *testing.T) {
 	}
 }
 
+func TestMetricsConfigStringRedactsHeaders(t *testing.T) {
+	mc := &metricsConfig{
+		backendDestination: openTelemetry,
+		collectorAddress:   "otel-collector.observability:4317",
+		collectorHeaders:   map[string]string{"api-key": "hunter2"},
+	}
+	got := mc.String()
+	if strings.Contains(got, "hunter2") {
+		t.Errorf("String() = %s, wanted the header values left out", got)
+	}
+	if !strings.Contains(got, "api-key:REDACTED") || !strings.Contains(got, mc.collectorAddress) {
+		t.Errorf("String() = %s, wanted the header keys and the address", got)
+	}
+	// The logs format the config with String, through %v or as a field.
+	if got := fmt.Sprintf("%v", mc); got != mc.String() {
+		t.Errorf("Sprintf(%%v) = %s, wanted String()", got)
+	}
+	if f := zap.Any("config", mc); f.Type != zapcore.StringerType {
+		t.Errorf("zap.Any() type = %v, wanted a Stringer", f.Type)
+	}
+	if got := (*metricsConfig)(nil).String(); got != "<nil>" {
+		t.Errorf("nil String() = %s, wanted <nil>", got)
+	}
+	// The headers of the config itself are left untouched.
+	if got := mc.collectorHeaders["api-key"]; got != "hunter2" {
+		t.Errorf("collectorHeaders[api-key] = %s, wanted hunter2", got)
+	}
+}
+
 func TestIsNewExporterRequired(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -782,6 +1086,132 @@ func TestIsNewExporterRequired(t *testing.T) {
 			},
 		},
 		newExporterRequired: true,
+	}, {
+		name: "backendStackdriverChangeReportingPeriod",
+		oldConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: stackdriver,
+			reportingPeriod:    time.Minute,
+		},
+		newConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: stackdriver,
+			reportingPeriod:    2 * time.Minute,
+		},
+		newExporterRequired: true,
+	}, {
+		name: "backendPrometheusChangeReportingPeriod",
+		oldConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: prometheus,
+			reportingPeriod:    5 * time.Second,
+		},
+		newConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: prometheus,
+			reportingPeriod:    10 * time.Second,
+		},
+		newExporterRequired: false,
+	}, {
+		name: "backendPrometheusChangePort",
+		oldConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: prometheus,
+			prometheusPort:     9090,
+		},
+		newConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: prometheus,
+			prometheusPort:     9091,
+		},
+		newExporterRequired: true,
+	}, {
+		name: "backendPrometheusEnableTLS",
+		oldConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: prometheus,
+			prometheusPort:     9090,
+		},
+		newConfig: metricsConfig{
+			domain:                servingDomain,
+			component:             testComponent,
+			backendDestination:    prometheus,
+			prometheusPort:        9090,
+			prometheusTLSCertFile: "/etc/metrics/tls.crt",
+			prometheusTLSKeyFile:  "/etc/metrics/tls.key",
+		},
+		newExporterRequired: true,
+	}, {
+		name: "backendStackdriverChangeProjects",
+		oldConfig: metricsConfig{
+			domain:              servingDomain,
+			component:           testComponent,
+			backendDestination:  stackdriver,
+			stackdriverProjects: map[string]string{"tenant-a": "project-a"},
+		},
+		newConfig: metricsConfig{
+			domain:              servingDomain,
+			component:           testComponent,
+			backendDestination:  stackdriver,
+			stackdriverProjects: map[string]string{"tenant-a": "project-b"},
+		},
+		newExporterRequired: true,
+	}, {
+		name: "backendOpenTelemetryChangeHeaders",
+		oldConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: openTelemetry,
+			collectorHeaders:   map[string]string{"api-key": "old"},
+		},
+		newConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: openTelemetry,
+			collectorHeaders:   map[string]string{"api-key": "new"},
+		},
+		newExporterRequired: true,
+	}, {
+		name: "backendOpenTelemetryChangeSecret",
+		oldConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: openTelemetry,
+			requireSecure:      true,
+			secret:             &corev1.Secret{Data: map[string][]byte{"cert.pem": []byte("old")}},
+		},
+		newConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: openTelemetry,
+			requireSecure:      true,
+			secret:             &corev1.Secret{Data: map[string][]byte{"cert.pem": []byte("new")}},
+		},
+		newExporterRequired: true,
+	}, {
+		name: "backendOpenTelemetrySameConfig",
+		oldConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: openTelemetry,
+			collectorAddress:   "otel-collector.observability:55678",
+			collectorHeaders:   map[string]string{"api-key": "secret"},
+		},
+		newConfig: metricsConfig{
+			domain:             servingDomain,
+			component:          testComponent,
+			backendDestination: openTelemetry,
+			collectorAddress:   "otel-collector.observability:55678",
+			collectorHeaders:   map[string]string{"api-key": "secret"},
+		},
+		newExporterRequired: false,
 	}}
 
 	for _, test := range tests {
@@ -994,6 +1424,25 @@ func TestNewStackdriverConfigFromMap(t *testing.T) {
 			ClusterName: "cluster",
 			UseSecret:   false,
 		},
+	}, {
+		name: "secretLocation",
+		stringMap: map[string]string{
+			stackdriverSecretNameKey:      "sd-key",
+			stackdriverSecretNamespaceKey: "monitoring",
+		},
+		expectedConfig: StackdriverClientConfig{
+			UseSecret:       true,
+			SecretName:      "sd-key",
+			SecretNamespace: "monitoring",
+		},
+	}, {
+		name: "credentialsFile",
+		stringMap: map[string]string{
+			stackdriverCredentialsFileKey: "/var/secrets/key.json",
+		},
+		expectedConfig: StackdriverClientConfig{
+			CredentialsFile: "/var/secrets/key.json",
+		},
 	}, {
 		name:           "nil",
 		stringMap:      nil,
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+// gcpProjectRegexp matches GCP project IDs and project numbers.
+var gcpProjectRegexp = regexp.MustCompile(`^([a-z][-a-z0-9]{4,28}[a-z0-9]|[0-9]+)$`)
+
+// dataKey is the path of the key in the data of the ConfigMap. The keys are
+// not added with ViaKey, which would split them at their dots.
+func dataKey(key string) string {
+	return "data[" + key + "]"
+}
+
+// ValidateObservabilityConfigMap checks the metrics settings of the
+// config-observability ConfigMap, returning an error for each of its data
+// keys with an invalid value. It is meant for a validating webhook to reject
+// edits which would otherwise only fail when the exporters are created.
+// The Secrets the settings refer to are not looked up.
+func ValidateObservabilityConfigMap(configMap *corev1.ConfigMap) *apis.FieldError {
+	m := configMap.Data
+	var errs *apis.FieldError
+
+	invalid := func(key, details string) {
+		fe := apis.ErrInvalidValue(m[key], dataKey(key))
+		fe.Details = details
+		errs = errs.Also(fe)
+	}
+	isBool := func(keys ...string) {
+		for _, key := range keys {
+			if _, err := parseBool(m, key); err != nil {
+				invalid(key, "must be true or false")
+			}
+		}
+	}
+
+	backend := metricsBackend(strings.ToLower(m[BackendDestinationKey]))
+	if backend != "" && !isBuiltinBackend(backend) && getBackendFactory(backend) == nil {
+		invalid(BackendDestinationKey, "unsupported metrics backend")
+	}
+
+	if _, err := parseReportingPeriod(m); err != nil {
+		invalid(reportingPeriodKey, "must be a positive number of seconds")
+	}
+
+	// The collector addresses are only used by the opencensus and
+	// opentelemetry backends.
+	if backend == openCensus || m[requestMetricsBackendKey] == string(openCensus) {
+		if err := validateCollectorAddress(m[collectorAddressKey]); err != nil {
+			invalid(collectorAddressKey, err.Error())
+		}
+	}
+	if backend == openTelemetry {
+		if err := validateCollectorAddress(m[openTelemetryAddressKey]); err != nil {
+			invalid(openTelemetryAddressKey, err.Error())
+		}
+	}
+	if v := m[openTelemetryHeadersKey]; v != "" {
+		if _, err := parseHeaders(v); err != nil {
+			invalid(openTelemetryHeadersKey, err.Error())
+		}
+	}
+
+	if p, err := parsePrometheusPort(m); err != nil || (p != 0 && validatePrometheusPort(p) != nil) {
+		invalid(prometheusPortKey, "must be a port between "+strconv.Itoa(minPrometheusPort)+" and "+strconv.Itoa(maxPrometheusPort))
+	}
+	if m[prometheusTLSCertFileKey] != "" && m[prometheusTLSKeyFileKey] == "" {
+		errs = errs.Also(apis.ErrMissingField(dataKey(prometheusTLSKeyFileKey)))
+	}
+	if m[prometheusTLSKeyFileKey] != "" && m[prometheusTLSCertFileKey] == "" {
+		errs = errs.Also(apis.ErrMissingField(dataKey(prometheusTLSCertFileKey)))
+	}
+
+	if v := m[pushgatewayURLKey]; v != "" {
+		if err := validatePushgatewayURL(v); err != nil {
+			invalid(pushgatewayURLKey, err.Error())
+		}
+	} else if backend == prometheusPushgateway {
+		errs = errs.Also(apis.ErrMissingField(dataKey(pushgatewayURLKey)))
+	}
+	if err := validatePushgatewayJob(m[pushgatewayJobKey]); err != nil {
+		invalid(pushgatewayJobKey, err.Error())
+	}
+
+	if v := m[stackdriverProjectIDKey]; v != "" && !gcpProjectRegexp.MatchString(v) {
+		invalid(stackdriverProjectIDKey, "must be a GCP project ID or number")
+	}
+	for key, v := range m {
+		if ns := strings.TrimPrefix(key, stackdriverProjectKeyPrefix); ns != key {
+			if ns == "" || !gcpProjectRegexp.MatchString(v) {
+				invalid(key, "must name a namespace and be a GCP project ID or number")
+			}
+		}
+	}
+	if err := validateCredentialsFile(NewStackdriverClientConfigFromMap(m)); err != nil {
+		errs = errs.Also(apis.ErrGeneric("cannot be combined with a Secret", dataKey(stackdriverCredentialsFileKey)))
+	}
+	if v := m[stackdriverCustomMetricPrefixKey]; v != "" {
+		if err := validateCustomMetricPrefix(v); err != nil {
+			invalid(stackdriverCustomMetricPrefixKey, err.Error())
+		}
+	}
+	if err := validateCustomMetricSubDomain(m); err != nil {
+		errs = errs.Also(apis.ErrMultipleOneOf(dataKey(stackdriverCustomMetricPrefixKey), dataKey(stackdriverCustomMetricSubDomainKey)))
+	}
+	if v := m[platformMetadataProviderKey]; v != "" {
+		if err := validatePlatformMetadataProvider(v); err != nil {
+			invalid(platformMetadataProviderKey, "unsupported platform metadata provider")
+		}
+	}
+	if v := m[cloudWatchAgentAddressKey]; v != "" {
+		if err := validateCollectorAddress(v); err != nil {
+			invalid(cloudWatchAgentAddressKey, err.Error())
+		}
+	}
+	if v := m[datadogSiteKey]; v != "" {
+		if u, err := url.Parse("https://" + v); err != nil || u.Host != v {
+			invalid(datadogSiteKey, "must be a host name, e.g. datadoghq.eu")
+		}
+	}
+
+	for key, v := range m {
+		if strings.HasPrefix(key, bucketsKeyPrefix) {
+			if _, err := parseBucketBoundaries(map[string]string{key: v}); err != nil {
+				invalid(key, "must be increasing, comma separated bucket boundaries")
+			}
+		}
+	}
+
+	isBool(collectorSecureKey, openTelemetrySecureKey, stackdriverUseSecretKey, pushgatewayGroupByInstanceKey,
+		allowStackdriverCustomMetricsKey, stackdriverPrecreateDescriptorsKey, hashDisallowedLabelsKey, EnableReqLogKey,
+		enableVarLogCollectionKey, enableProbeRequestLogKey, enableProfilingKey)
+
+	if v, ok := m[ReqLogTemplateKey]; ok && v != "" {
+		if _, err := texttemplate.New("requestLog").Parse(v); err != nil {
+			invalid(ReqLogTemplateKey, err.Error())
+		}
+	} else if ok && strings.EqualFold(m[EnableReqLogKey], "true") {
+		errs = errs.Also(apis.ErrMissingField(dataKey(ReqLogTemplateKey)))
+	}
+
+	return errs
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestValidateObservabilityConfigMap(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]string
+		want *apis.FieldError
+	}{{
+		name: "empty",
+	}, {
+		name: "valid",
+		data: map[string]string{
+			BackendDestinationKey:                    "Stackdriver",
+			reportingPeriodKey:                       "30",
+			stackdriverProjectIDKey:                  "my-project",
+			stackdriverProjectKeyPrefix + "tenant-a": "123456789",
+			platformMetadataProviderKey:              "env",
+			bucketsKeyPrefix + "latency":             "1, 5, 10",
+			allowStackdriverCustomMetricsKey:         "true",
+			EnableReqLogKey:                          "true",
+		},
+	}, {
+		name: "bad backend",
+		data: map[string]string{
+			BackendDestinationKey: "carrier-pigeon",
+		},
+		want: &apis.FieldError{
+			Message: `invalid value: carrier-pigeon`,
+			Paths:   []string{"data[" + BackendDestinationKey + "]"},
+			Details: "unsupported metrics backend",
+		},
+	}, {
+		name: "bad reporting period",
+		data: map[string]string{
+			reportingPeriodKey: "-1",
+		},
+		want: &apis.FieldError{
+			Message: `invalid value: -1`,
+			Paths:   []string{"data[" + reportingPeriodKey + "]"},
+			Details: "must be a positive number of seconds",
+		},
+	}, {
+		name: "bad projects",
+		data: map[string]string{
+			stackdriverProjectIDKey:                  "My_Project",
+			stackdriverProjectKeyPrefix + "tenant-a": "x",
+		},
+		want: (&apis.FieldError{
+			Message: `invalid value: My_Project`,
+			Paths:   []string{"data[" + stackdriverProjectIDKey + "]"},
+			Details: "must be a GCP project ID or number",
+		}).Also(&apis.FieldError{
+			Message: `invalid value: x`,
+			Paths:   []string{"data[" + stackdriverProjectKeyPrefix + "tenant-a]"},
+			Details: "must name a namespace and be a GCP project ID or number",
+		}),
+	}, {
+		name: "credentials file with a secret",
+		data: map[string]string{
+			stackdriverSecretNameKey:      "sd-key",
+			stackdriverCredentialsFileKey: "/var/secrets/key.json",
+		},
+		want: apis.ErrGeneric("cannot be combined with a Secret", "data["+stackdriverCredentialsFileKey+"]"),
+	}, {
+		name: "collector address of another backend",
+		data: map[string]string{
+			BackendDestinationKey: string(prometheus),
+			collectorAddressKey:   ":55678",
+		},
+	}, {
+		name: "bad collector address",
+		data: map[string]string{
+			BackendDestinationKey: string(openCensus),
+			collectorAddressKey:   ":55678",
+		},
+		want: &apis.FieldError{
+			Message: `invalid value: :55678`,
+			Paths:   []string{"data[" + collectorAddressKey + "]"},
+			Details: "the address must be of the form host:port",
+		},
+	}, {
+		name: "bad opentelemetry address",
+		data: map[string]string{
+			BackendDestinationKey:   string(openTelemetry),
+			openTelemetryAddressKey: "otel-collector",
+		},
+		want: &apis.FieldError{
+			Message: `invalid value: otel-collector`,
+			Paths:   []string{"data[" + openTelemetryAddressKey + "]"},
+			Details: "address otel-collector: missing port in address",
+		},
+	}, {
+		name: "bad opentelemetry headers",
+		data: map[string]string{
+			BackendDestinationKey:   string(openTelemetry),
+			openTelemetryHeadersKey: "api-key",
+		},
+		want: &apis.FieldError{
+			Message: `invalid value: api-key`,
+			Paths:   []string{"data[" + openTelemetryHeadersKey + "]"},
+			Details: `header "api-key" is not of the form key=value`,
+		},
+	}, {
+		name: "prometheus",
+		data: map[string]string{
+			prometheusPortKey:        "80",
+			prometheusTLSCertFileKey: "/etc/tls.crt",
+		},
+		want: (&apis.FieldError{
+			Message: `invalid value: 80`,
+			Paths:   []string{"data[" + prometheusPortKey + "]"},
+			Details: "must be a port between 1024 and 65535",
+		}).Also(apis.ErrMissingField("data[" + prometheusTLSKeyFileKey + "]")),
+	}, {
+		name: "pushgateway",
+		data: map[string]string{
+			BackendDestinationKey: string(prometheusPushgateway),
+			pushgatewayJobKey:     "a/b",
+		},
+		want: apis.ErrMissingField("data[" + pushgatewayURLKey + "]").Also(&apis.FieldError{
+			Message: `invalid value: a/b`,
+			Paths:   []string{"data[" + pushgatewayJobKey + "]"},
+			Details: "must not contain a /",
+		}),
+	}, {
+		name: "bad booleans and buckets",
+		data: map[string]string{
+			collectorSecureKey:           "yes",
+			bucketsKeyPrefix + "latency": "10, 5",
+		},
+		want: (&apis.FieldError{
+			Message: `invalid value: yes`,
+			Paths:   []string{"data[" + collectorSecureKey + "]"},
+			Details: "must be true or false",
+		}).Also(&apis.FieldError{
+			Message: `invalid value: 10, 5`,
+			Paths:   []string{"data[" + bucketsKeyPrefix + "latency]"},
+			Details: "must be increasing, comma separated bucket boundaries",
+		}),
+	}, {
+		name: "request log enabled without template",
+		data: map[string]string{
+			EnableReqLogKey:   "true",
+			ReqLogTemplateKey: "",
+		},
+		want: apis.ErrMissingField("data[" + ReqLogTemplateKey + "]"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ValidateObservabilityConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName()},
+				Data:       test.data,
+			})
+			if got.Error() != test.want.Error() {
+				t.Errorf("ValidateObservabilityConfigMap() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
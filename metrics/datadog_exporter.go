@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultDatadogSite is the Datadog site metrics are submitted to when
+	// none is configured.
+	defaultDatadogSite = "datadoghq.com"
+
+	// datadogAPIKeyKey is the key of the API key in the Datadog secret.
+	datadogAPIKeyKey = "api-key"
+
+	// datadogMaxPacketSize bounds the DogStatsD datagrams sent to the agent.
+	datadogMaxPacketSize = 1432
+)
+
+// datadogSeries is a metric submitted to the Datadog API.
+type datadogSeries struct {
+	Metric string       `json:"metric"`
+	Points [][2]float64 `json:"points"`
+	Type   string       `json:"type"`
+	Tags   []string     `json:"tags,omitempty"`
+}
+
+// datadogExporter exports view data to Datadog, either through a DogStatsD
+// agent or directly to the Datadog API. OpenCensus views hold cumulative
+// values, so every value is submitted as a gauge.
+type datadogExporter struct {
+	namespace string
+	tags      []string
+	logger    *zap.SugaredLogger
+
+	// submit sends the series to Datadog.
+	submit func([]datadogSeries) error
+	// queue runs the submissions. It is shared with the exporters of the
+	// resources, and stopped by the default one.
+	queue *exportQueue
+	// conn is the connection to the DogStatsD agent, if any. It is shared
+	// with the exporters of the resources, and closed by the default one.
+	conn net.Conn
+}
+
+var _ view.Exporter = (*datadogExporter)(nil)
+var _ flushable = (*datadogExporter)(nil)
+var _ stoppable = (*datadogExporter)(nil)
+
+func newDatadogExporter(config *metricsConfig, logger *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
+	e := &datadogExporter{
+		namespace: config.component,
+		tags:      config.datadogTags,
+		logger:    logger,
+	}
+	if config.datadogAgentAddress != "" {
+		conn, err := net.Dial("udp", config.datadogAgentAddress)
+		if err != nil {
+			logger.Errorw("Failed to connect to the DogStatsD agent.", zap.Error(err))
+			return nil, nil, err
+		}
+		e.conn = conn
+		e.submit = dogStatsDSubmitter(conn)
+	} else {
+		var apiKey string
+		if config.secret != nil {
+			apiKey = string(config.secret.Data[datadogAPIKeyKey])
+		}
+		if apiKey == "" {
+			return nil, nil, fmt.Errorf("the Datadog secret has no %q", datadogAPIKeyKey)
+		}
+		e.submit = datadogAPISubmitter(&http.Client{Timeout: 10 * time.Second},
+			"https://api."+config.datadogSite+"/api/v1/series", apiKey)
+	}
+	e.queue = newExportQueue(exportQueueSize)
+	logger.Infof("Created Datadog exporter for site %q with tags %v", config.datadogSite, config.datadogTags)
+	return e, e.forResource, nil
+}
+
+// forResource returns an exporter tagging the data with the labels of the
+// resource.
+func (e *datadogExporter) forResource(r *resource.Resource) (view.Exporter, error) {
+	if r == nil || (r.Type == "" && len(r.Labels) == 0) {
+		return e, nil
+	}
+	tags := make([]string, 0, len(e.tags)+len(r.Labels))
+	tags = append(tags, e.tags...)
+	for k, v := range r.Labels {
+		tags = append(tags, k+":"+v)
+	}
+	sort.Strings(tags[len(e.tags):])
+	return &datadogExporter{
+		namespace: e.namespace,
+		tags:      tags,
+		logger:    e.logger,
+		submit:    e.submit,
+		queue:     e.queue,
+	}, nil
+}
+
+// Flush implements flushable, waiting for the queued series to be submitted.
+func (e *datadogExporter) Flush() {
+	e.queue.flush()
+}
+
+// StopMetricsExporter implements stoppable, submitting the queued series
+// and closing the connection to the DogStatsD agent.
+func (e *datadogExporter) StopMetricsExporter() {
+	e.queue.stop()
+	if e.conn != nil {
+		e.conn.Close()
+	}
+}
+
+// ExportView implements view.Exporter, queueing the series for submission.
+func (e *datadogExporter) ExportView(vd *view.Data) {
+	series := e.toSeries(vd)
+	if !e.queue.enqueue(func() {
+		var dropped int64
+		if err := e.submit(series); err != nil {
+			e.logger.Errorw("Failed to export view "+vd.View.Name+" to Datadog", zap.Error(err))
+			dropped = int64(len(vd.Rows))
+		}
+		recordViewExport(datadog, vd, dropped)
+	}) {
+		e.logger.Warn("Dropped view " + vd.View.Name + ": too many views are waiting to be exported to Datadog")
+		recordViewExport(datadog, vd, int64(len(vd.Rows)))
+	}
+}
+
+// toSeries converts the rows of the view data into Datadog series.
+func (e *datadogExporter) toSeries(vd *view.Data) []datadogSeries {
+	name := vd.View.Name
+	if e.namespace != "" {
+		name = e.namespace + "." + name
+	}
+	ts := float64(vd.End.Unix())
+
+	series := make([]datadogSeries, 0, len(vd.Rows))
+	for _, row := range vd.Rows {
+		tags := make([]string, 0, len(e.tags)+len(row.Tags))
+		tags = append(tags, e.tags...)
+		for _, t := range row.Tags {
+			tags = append(tags, t.Key.Name()+":"+t.Value)
+		}
+		for _, v := range aggregationValues(name, row.Data) {
+			series = append(series, datadogSeries{
+				Metric: v.name,
+				Points: [][2]float64{{ts, v.value}},
+				Type:   "gauge",
+				Tags:   tags,
+			})
+		}
+	}
+	return series
+}
+
+// dogStatsDSubmitter returns a submitter writing the series to a DogStatsD
+// agent, packing as many lines per datagram as fit.
+func dogStatsDSubmitter(conn net.Conn) func([]datadogSeries) error {
+	return func(series []datadogSeries) error {
+		var buf bytes.Buffer
+		flush := func() error {
+			if buf.Len() == 0 {
+				return nil
+			}
+			_, err := conn.Write(buf.Bytes())
+			buf.Reset()
+			return err
+		}
+		for _, s := range series {
+			line := formatDogStatsD(s)
+			if buf.Len() > 0 && buf.Len()+1+len(line) > datadogMaxPacketSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(line)
+		}
+		return flush()
+	}
+}
+
+// formatDogStatsD formats the last point of the series as a DogStatsD
+// gauge, e.g. "activator.request_count:3|g|#env:prod".
+func formatDogStatsD(s datadogSeries) string {
+	var sb strings.Builder
+	sb.WriteString(s.Metric)
+	sb.WriteByte(':')
+	sb.WriteString(strconv.FormatFloat(s.Points[len(s.Points)-1][1], 'f', -1, 64))
+	sb.WriteString("|g")
+	if len(s.Tags) > 0 {
+		sb.WriteString("|#")
+		sb.WriteString(strings.Join(s.Tags, ","))
+	}
+	return sb.String()
+}
+
+// datadogAPISubmitter returns a submitter posting the series to the Datadog
+// API at url.
+func datadogAPISubmitter(client *http.Client, url, apiKey string) func([]datadogSeries) error {
+	return func(series []datadogSeries) error {
+		if len(series) == 0 {
+			return nil
+		}
+		body, err := json.Marshal(struct {
+			Series []datadogSeries `json:"series"`
+		}{series})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("DD-API-KEY", apiKey)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("the Datadog API responded with status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func testViewData(t *testing.T) *view.Data {
+	t.Helper()
+	key, err := tag.NewKey("route")
+	if err != nil {
+		t.Fatal("NewKey() =", err)
+	}
+	m := stats.Int64("latency", "Latency", stats.UnitMilliseconds)
+	return &view.Data{
+		View: &view.View{Name: "request_latencies", Measure: m},
+		End:  time.Unix(1600000000, 0),
+		Rows: []*view.Row{{
+			Tags: []tag.Tag{{Key: key, Value: "r1"}},
+			Data: &view.CountData{Value: 3},
+		}, {
+			Data: &view.DistributionData{Count: 2, Min: 1, Max: 5, Mean: 3},
+		}},
+	}
+}
+
+func TestDatadogDogStatsD(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("ListenPacket() =", err)
+	}
+	defer conn.Close()
+
+	e, f, err := newDatadogExporter(&metricsConfig{
+		component:           testComponent,
+		backendDestination:  datadog,
+		datadogAgentAddress: conn.LocalAddr().String(),
+		datadogSite:         defaultDatadogSite,
+		datadogTags:         []string{"env:prod"},
+	}, TestLogger(t))
+	if err != nil {
+		t.Fatal("newDatadogExporter() =", err)
+	}
+	re, err := f(&resource.Resource{Type: "knative_revision", Labels: map[string]string{"namespace_name": "ns"}})
+	if err != nil {
+		t.Fatal("factory() =", err)
+	}
+	if re == e {
+		t.Error("factory() returned the default exporter for a resource")
+	}
+	re.ExportView(testViewData(t))
+
+	buf := make([]byte, datadogMaxPacketSize)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal("ReadFrom() =", err)
+	}
+	got := strings.Split(string(buf[:n]), "\n")
+	want := []string{
+		testComponent + ".request_latencies:3|g|#env:prod,namespace_name:ns,route:r1",
+		testComponent + ".request_latencies.count:2|g|#env:prod,namespace_name:ns",
+		testComponent + ".request_latencies.avg:3|g|#env:prod,namespace_name:ns",
+		testComponent + ".request_latencies.min:1|g|#env:prod,namespace_name:ns",
+		testComponent + ".request_latencies.max:5|g|#env:prod,namespace_name:ns",
+	}
+	if !cmp.Equal(got, want) {
+		t.Error("DogStatsD lines (-want, +got):", cmp.Diff(want, got))
+	}
+
+	e.(stoppable).StopMetricsExporter()
+	if _, err := e.(*datadogExporter).conn.Write([]byte("x")); err == nil {
+		t.Error("The connection to the agent is open after StopMetricsExporter()")
+	}
+}
+
+func TestDatadogAPI(t *testing.T) {
+	var (
+		gotKey  string
+		gotBody struct {
+			Series []datadogSeries `json:"series"`
+		}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("DD-API-KEY")
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &gotBody)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	submit := datadogAPISubmitter(server.Client(), server.URL, "secret-key")
+	e := &datadogExporter{namespace: testComponent, logger: TestLogger(t)}
+	if err := submit(e.toSeries(testViewData(t))); err != nil {
+		t.Fatal("submit() =", err)
+	}
+	if gotKey != "secret-key" {
+		t.Errorf("DD-API-KEY = %q, want %q", gotKey, "secret-key")
+	}
+	var metrics []string
+	for _, s := range gotBody.Series {
+		metrics = append(metrics, s.Metric)
+		if s.Type != "gauge" || s.Points[0][0] != 1600000000 {
+			t.Errorf("Series = %+v, want a gauge at the end of the view data", s)
+		}
+	}
+	sort.Strings(metrics)
+	want := []string{
+		testComponent + ".request_latencies",
+		testComponent + ".request_latencies.avg",
+		testComponent + ".request_latencies.count",
+		testComponent + ".request_latencies.max",
+		testComponent + ".request_latencies.min",
+	}
+	if !cmp.Equal(metrics, want) {
+		t.Error("Metrics (-want, +got):", cmp.Diff(want, metrics))
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	if err := submit(e.toSeries(testViewData(t))); err == nil {
+		t.Error("submit() = nil, wanted an error for a rejected submission")
+	}
+}
+
+func TestDatadogExportDoesNotWait(t *testing.T) {
+	release := make(chan struct{})
+	var submitted int32
+	e := &datadogExporter{
+		namespace: testComponent,
+		logger:    TestLogger(t),
+		submit: func([]datadogSeries) error {
+			<-release
+			atomic.AddInt32(&submitted, 1)
+			return nil
+		},
+		queue: newExportQueue(1),
+	}
+
+	// While Datadog is stuck, exporting returns right away, dropping the
+	// series which don't fit the queue.
+	exported := make(chan struct{})
+	go func() {
+		defer close(exported)
+		for i := 0; i < 10; i++ {
+			e.ExportView(testViewData(t))
+		}
+	}()
+	select {
+	case <-exported:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ExportView() waited on the submission")
+	}
+
+	close(release)
+	e.Flush()
+	// At most one submission in flight, and one queued.
+	if got := atomic.LoadInt32(&submitted); got < 1 || got > 2 {
+		t.Errorf("Submitted %d series, want 1 or 2", got)
+	}
+
+	e.StopMetricsExporter()
+	// Flushing a stopped exporter does not wait.
+	e.Flush()
+}
+
+func TestDatadogConfig(t *testing.T) {
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "datadog"},
+		Data:       map[string][]byte{datadogAPIKeyKey: []byte("secret-key")},
+	}
+	mc, err := createMetricsConfig(context.Background(), ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{
+			BackendDestinationKey: string(datadog),
+			datadogSiteKey:        "datadoghq.eu",
+			datadogTagsKey:        "env:prod, team:serving,",
+		},
+		Secrets: fakeSecretList(secret).Get,
+	})
+	if err != nil {
+		t.Fatal("createMetricsConfig() =", err)
+	}
+	if got, want := mc.datadogSite, "datadoghq.eu"; got != want {
+		t.Errorf("datadogSite = %q, want %q", got, want)
+	}
+	if got, want := mc.datadogTags, []string{"env:prod", "team:serving"}; !cmp.Equal(got, want) {
+		t.Errorf("datadogTags = %v, want %v", got, want)
+	}
+	if got, want := mc.reportingPeriod, time.Minute; got != want {
+		t.Errorf("reportingPeriod = %v, want %v", got, want)
+	}
+	if mc.secret == nil || mc.secret.Name != "datadog" {
+		t.Errorf("secret = %v, want the datadog secret", mc.secret)
+	}
+	if _, _, err := newDatadogExporter(mc, TestLogger(t)); err != nil {
+		t.Error("newDatadogExporter() =", err)
+	}
+
+	// Submitting to the API needs the secret.
+	if _, err := createMetricsConfig(context.Background(), ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{BackendDestinationKey: string(datadog)},
+		Secrets:   fakeSecretList().Get,
+	}); err == nil {
+		t.Error("createMetricsConfig() = nil, wanted an error without the secret")
+	}
+	if _, _, err := newDatadogExporter(&metricsConfig{
+		component:          testComponent,
+		backendDestination: datadog,
+		datadogSite:        defaultDatadogSite,
+	}, TestLogger(t)); err == nil {
+		t.Error("newDatadogExporter() = nil, wanted an error without an API key")
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+)
+
+// debugExporter writes the view data to stdout or a file as newline
+// delimited JSON, one document per view.Data, for local development.
+type debugExporter struct {
+	out      *debugOutput
+	resource *debugResource
+	logger   *zap.SugaredLogger
+}
+
+// debugOutput is the destination shared by the exporters of every resource.
+type debugOutput struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	// closer is the file written to, nil for stdout.
+	closer io.Closer
+}
+
+var _ view.Exporter = (*debugExporter)(nil)
+var _ stoppable = (*debugExporter)(nil)
+
+// debugViewData is the JSON document of a view.Data.
+type debugViewData struct {
+	Name     string         `json:"name"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Resource *debugResource `json:"resource,omitempty"`
+	Rows     []debugRow     `json:"rows"`
+}
+
+type debugResource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// debugRow is the JSON document of a view.Row. Only the field of the
+// aggregation of the view is set.
+type debugRow struct {
+	Tags         map[string]string  `json:"tags,omitempty"`
+	Count        *int64             `json:"count,omitempty"`
+	Sum          *float64           `json:"sum,omitempty"`
+	LastValue    *float64           `json:"lastValue,omitempty"`
+	Distribution *debugDistribution `json:"distribution,omitempty"`
+}
+
+type debugDistribution struct {
+	Count           int64     `json:"count"`
+	Min             float64   `json:"min"`
+	Max             float64   `json:"max"`
+	Mean            float64   `json:"mean"`
+	SumOfSquaredDev float64   `json:"sumOfSquaredDev"`
+	Bounds          []float64 `json:"bounds,omitempty"`
+	CountPerBucket  []int64   `json:"countPerBucket"`
+}
+
+func newDebugExporter(config *metricsConfig, logger *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
+	out := &debugOutput{}
+	var w io.Writer = os.Stdout
+	if config.debugOutputPath != "" {
+		f, err := os.OpenFile(config.debugOutputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			logger.Errorw("Failed to open the debug exporter's output file.", zap.Error(err))
+			return nil, nil, err
+		}
+		w, out.closer = f, f
+	}
+	out.enc = json.NewEncoder(w)
+	e := &debugExporter{out: out, logger: logger}
+	logger.Infof("Created debug exporter writing to %q", config.debugOutputPath)
+	return e, e.forResource, nil
+}
+
+// forResource returns an exporter adding the resource to the documents.
+func (e *debugExporter) forResource(r *resource.Resource) (view.Exporter, error) {
+	if r == nil || (r.Type == "" && len(r.Labels) == 0) {
+		return e, nil
+	}
+	return &debugExporter{
+		out:      e.out,
+		resource: &debugResource{Type: r.Type, Labels: r.Labels},
+		logger:   e.logger,
+	}, nil
+}
+
+// ExportView implements view.Exporter.
+func (e *debugExporter) ExportView(vd *view.Data) {
+	doc := debugViewData{
+		Name:     vd.View.Name,
+		Start:    vd.Start,
+		End:      vd.End,
+		Resource: e.resource,
+		Rows:     make([]debugRow, 0, len(vd.Rows)),
+	}
+	for _, row := range vd.Rows {
+		doc.Rows = append(doc.Rows, toDebugRow(vd.View, row))
+	}
+
+	e.out.mu.Lock()
+	defer e.out.mu.Unlock()
+	var dropped int64
+	if err := e.out.enc.Encode(doc); err != nil {
+		e.logger.Errorw("Failed to write view "+vd.View.Name, zap.Error(err))
+		dropped = int64(len(vd.Rows))
+	}
+	recordViewExport(debugBackend, vd, dropped)
+}
+
+// StopMetricsExporter implements stoppable, closing the output file.
+func (e *debugExporter) StopMetricsExporter() {
+	e.out.mu.Lock()
+	defer e.out.mu.Unlock()
+	if e.out.closer != nil {
+		e.out.closer.Close()
+		e.out.closer = nil
+	}
+}
+
+func toDebugRow(v *view.View, row *view.Row) debugRow {
+	dr := debugRow{}
+	if len(row.Tags) > 0 {
+		dr.Tags = make(map[string]string, len(row.Tags))
+		for _, t := range row.Tags {
+			dr.Tags[t.Key.Name()] = t.Value
+		}
+	}
+	switch data := row.Data.(type) {
+	case *view.CountData:
+		dr.Count = &data.Value
+	case *view.SumData:
+		dr.Sum = &data.Value
+	case *view.LastValueData:
+		dr.LastValue = &data.Value
+	case *view.DistributionData:
+		dr.Distribution = &debugDistribution{
+			Count:           data.Count,
+			Min:             data.Min,
+			Max:             data.Max,
+			Mean:            data.Mean,
+			SumOfSquaredDev: data.SumOfSquaredDev,
+			CountPerBucket:  data.CountPerBucket,
+		}
+		if v.Aggregation != nil {
+			dr.Distribution.Bounds = v.Aggregation.Buckets
+		}
+	}
+	return dr
+}
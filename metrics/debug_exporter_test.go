@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/resource"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestDebugExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "debug-exporter")
+	if err != nil {
+		t.Fatal("TempDir() =", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "metrics.json")
+
+	e, f, err := newDebugExporter(&metricsConfig{
+		component:          testComponent,
+		backendDestination: debugBackend,
+		debugOutputPath:    file,
+	}, TestLogger(t))
+	if err != nil {
+		t.Fatal("newDebugExporter() =", err)
+	}
+	re, err := f(&resource.Resource{Type: "knative_revision", Labels: map[string]string{"namespace_name": "ns"}})
+	if err != nil {
+		t.Fatal("factory() =", err)
+	}
+	e.ExportView(testViewData(t))
+	re.ExportView(testViewData(t))
+	e.(stoppable).StopMetricsExporter()
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal("ReadFile() =", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Got %d lines, want 2:\n%s", len(lines), b)
+	}
+	var docs []map[string]interface{}
+	for _, line := range lines {
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			t.Fatal("Unmarshal() =", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	rows := []interface{}{
+		map[string]interface{}{
+			"tags":  map[string]interface{}{"route": "r1"},
+			"count": 3.0,
+		},
+		map[string]interface{}{
+			"distribution": map[string]interface{}{
+				"count":           2.0,
+				"min":             1.0,
+				"max":             5.0,
+				"mean":            3.0,
+				"sumOfSquaredDev": 0.0,
+				"countPerBucket":  nil,
+			},
+		},
+	}
+	start := time.Time{}.Format(time.RFC3339Nano)
+	end := time.Unix(1600000000, 0).Format(time.RFC3339Nano)
+	want := []map[string]interface{}{{
+		"name":  "request_latencies",
+		"start": start,
+		"end":   end,
+		"rows":  rows,
+	}, {
+		"name":  "request_latencies",
+		"start": start,
+		"end":   end,
+		"resource": map[string]interface{}{
+			"type":   "knative_revision",
+			"labels": map[string]interface{}{"namespace_name": "ns"},
+		},
+		"rows": rows,
+	}}
+	if diff := cmp.Diff(want, docs); diff != "" {
+		t.Error("Written documents (-want, +got) =", diff)
+	}
+}
+
+func TestDebugConfig(t *testing.T) {
+	mc, err := createMetricsConfig(context.Background(), ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{
+			BackendDestinationKey: string(debugBackend),
+			debugOutputPathKey:    "/tmp/metrics.json",
+		},
+	})
+	if err != nil {
+		t.Fatal("createMetricsConfig() =", err)
+	}
+	if got, want := mc.debugOutputPath, "/tmp/metrics.json"; got != want {
+		t.Errorf("debugOutputPath = %q, want %q", got, want)
+	}
+
+	setCurMetricsConfig(mc)
+	defer setCurMetricsConfig(nil)
+	newConfig := *mc
+	if isNewExporterRequired(&newConfig) {
+		t.Error("isNewExporterRequired() = true, wanted false for the same config")
+	}
+	newConfig.debugOutputPath = ""
+	if !isNewExporterRequired(&newConfig) {
+		t.Error("isNewExporterRequired() = false, wanted true for a new output")
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "sync"
+
+// exportQueueSize bounds the work waiting in the queue of a backend.
+const exportQueueSize = 1024
+
+// exportQueue runs the work queued by an exporter, in order, on a goroutine
+// of its own.
+//
+// Exporters are called on the goroutine of the OpenCensus worker, which
+// stats.Record waits on when its buffer is full, so an exporter waiting on a
+// slow or unavailable backend would hold up the recording of measurements.
+// Exporters talking to a backend queue that work instead, and drop it rather
+// than block when the queue is full.
+type exportQueue struct {
+	queue    chan func()
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+}
+
+// newExportQueue starts a queue holding up to size pieces of work.
+func newExportQueue(size int) *exportQueue {
+	q := &exportQueue{
+		queue:  make(chan func(), size),
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// run runs the queued work, until the queue is stopped.
+func (q *exportQueue) run() {
+	defer close(q.doneCh)
+	for {
+		select {
+		case f := <-q.queue:
+			f()
+		case <-q.stopCh:
+			return
+		}
+	}
+}
+
+// enqueue queues f, returning false if the queue is full and f was dropped.
+func (q *exportQueue) enqueue(f func()) bool {
+	select {
+	case q.queue <- f:
+		return true
+	default:
+		return false
+	}
+}
+
+// flush returns once the work queued so far ran, or the queue is stopped.
+func (q *exportQueue) flush() {
+	done := make(chan struct{})
+	select {
+	case q.queue <- func() { close(done) }:
+	case <-q.doneCh:
+		return
+	}
+	select {
+	case <-done:
+	case <-q.doneCh:
+	}
+}
+
+// stop runs the queued work and stops the queue.
+func (q *exportQueue) stop() {
+	q.stopOnce.Do(func() {
+		q.flush()
+		close(q.stopCh)
+		<-q.doneCh
+	})
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExportQueue(t *testing.T) {
+	q := newExportQueue(1)
+	release := make(chan struct{})
+	var ran []int
+
+	// While the first piece of work is stuck, enqueueing returns right away,
+	// dropping the work which doesn't fit the queue.
+	started := make(chan struct{})
+	if !q.enqueue(func() {
+		close(started)
+		<-release
+		ran = append(ran, 0)
+	}) {
+		t.Fatal("enqueue() = false for an empty queue")
+	}
+	<-started
+	if !q.enqueue(func() { ran = append(ran, 1) }) {
+		t.Fatal("enqueue() = false for a queue with room")
+	}
+	enqueued := make(chan bool)
+	go func() { enqueued <- q.enqueue(func() { ran = append(ran, 2) }) }()
+	select {
+	case ok := <-enqueued:
+		if ok {
+			t.Error("enqueue() = true for a full queue")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("enqueue() waited on the queued work")
+	}
+
+	close(release)
+	q.flush()
+	if want := []int{0, 1}; !cmp.Equal(ran, want) {
+		t.Errorf("Ran %v, want %v", ran, want)
+	}
+
+	// Stopping runs the queued work first.
+	q.enqueue(func() { ran = append(ran, 3) })
+	q.stop()
+	q.stop()
+	if want := []int{0, 1, 3}; !cmp.Equal(ran, want) {
+		t.Errorf("Ran %v, want %v", ran, want)
+	}
+	// Flushing a stopped queue does not wait.
+	q.flush()
+}
@@ -20,12 +20,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
+	"time"
 
+	prom "contrib.go.opencensus.io/exporter/prometheus"
 	"go.opencensus.io/stats/view"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 var (
@@ -38,6 +42,24 @@ var (
 // a specific Secret. This avoids requiring global or namespace list in controllers.
 type SecretFetcher func(string) (*corev1.Secret, error)
 
+// getBackendSecret attempts to locate the secret of a metrics backend,
+// e.g. holding TLS credentials or an API key. To do this, it first looks
+// for a secret named "<component>-<base>", then for a generic "<base>"
+// secret.
+func getBackendSecret(component, base string, lister SecretFetcher) (*corev1.Secret, error) {
+	if lister == nil {
+		return nil, fmt.Errorf("no secret lister provided for component %q; cannot fetch the %s secret", component, base)
+	}
+	secret, err := lister(component + "-" + base)
+	if apierrors.IsNotFound(err) {
+		secret, err = lister(base)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s secret for %q: %w", base, component, err)
+	}
+	return secret, nil
+}
+
 type flushable interface {
 	// Flush waits for metrics to be uploaded.
 	Flush()
@@ -48,6 +70,12 @@ type stoppable interface {
 	StopMetricsExporter()
 }
 
+type drainable interface {
+	// Drain exports the metrics recorded since the last export, for
+	// exporters reading them at their own interval.
+	Drain()
+}
+
 // ExporterOptions contains options for configuring the exporter.
 type ExporterOptions struct {
 	// Domain is the metrics domain. e.g. "knative.dev". Must be present.
@@ -156,18 +184,24 @@ func UpdateExporter(ctx context.Context, ops ExporterOptions, logger *zap.Sugare
 		flushGivenExporter(curMetricsExporter)
 		e, f, err := newMetricsExporter(newConfig, logger)
 		if err != nil {
-			logger.Errorw("Failed to update a new metrics exporter based on metric config", newConfig, zap.Error(err))
+			logger.Errorw("Failed to update a new metrics exporter based on metric config", zap.Any("config", newConfig), zap.Error(err))
 			return err
 		}
 		existingConfig := curMetricsConfig
 		curMetricsExporter = e
 		if err := setFactory(f); err != nil {
-			logger.Errorw("Failed to update metrics factory when loading metric config", newConfig, zap.Error(err))
+			logger.Errorw("Failed to update metrics factory when loading metric config", zap.Any("config", newConfig), zap.Error(err))
 			return err
 		}
 		logger.Infof("Successfully updated the metrics exporter; old config: %v; new config %v", existingConfig, newConfig)
 	}
 
+	if err := setConfiguredBuckets(newConfig.bucketBoundaries); err != nil {
+		logger.Errorw("Failed to apply the configured bucket boundaries", zap.Error(err))
+	}
+	if err := setLabelFilter(newConfig.labelFilter); err != nil {
+		logger.Errorw("Failed to apply the configured label filter", zap.Error(err))
+	}
 	setCurMetricsConfigUnlocked(newConfig)
 	return nil
 }
@@ -187,7 +221,56 @@ func isNewExporterRequired(newConfig *metricsConfig) bool {
 		return newConfig.collectorAddress != cc.collectorAddress || newConfig.requireSecure != cc.requireSecure
 	}
 
-	return newConfig.backendDestination == stackdriver && newConfig.stackdriverClientConfig != cc.stackdriverClientConfig
+	// Likewise when the OpenTelemetry Collector address, headers or secret
+	// change.
+	if newConfig.backendDestination == openTelemetry {
+		return newConfig.collectorAddress != cc.collectorAddress || newConfig.requireSecure != cc.requireSecure ||
+			!reflect.DeepEqual(newConfig.collectorHeaders, cc.collectorHeaders) || !reflect.DeepEqual(newConfig.secret, cc.secret)
+	}
+
+	// Restart the Prometheus exporter when where or how it serves changes.
+	if newConfig.backendDestination == prometheus {
+		return newConfig.prometheusHost != cc.prometheusHost || newConfig.prometheusPort != cc.prometheusPort ||
+			newConfig.prometheusTLSCertFile != cc.prometheusTLSCertFile || newConfig.prometheusTLSKeyFile != cc.prometheusTLSKeyFile
+	}
+
+	// Likewise when where the metrics are pushed, or how often, changes.
+	if newConfig.backendDestination == prometheusPushgateway {
+		return newConfig.pushgatewayURL != cc.pushgatewayURL || newConfig.pushgatewayJob != cc.pushgatewayJob ||
+			newConfig.pushgatewayGroupByInstance != cc.pushgatewayGroupByInstance || newConfig.reportingPeriod != cc.reportingPeriod
+	}
+
+	// Likewise when the file the metrics are written to changes.
+	if newConfig.backendDestination == debugBackend {
+		return newConfig.debugOutputPath != cc.debugOutputPath
+	}
+
+	// Likewise when where or how Datadog metrics are submitted changes.
+	if newConfig.backendDestination == datadog {
+		return newConfig.datadogAgentAddress != cc.datadogAgentAddress || newConfig.datadogSite != cc.datadogSite ||
+			!reflect.DeepEqual(newConfig.datadogTags, cc.datadogTags) || !reflect.DeepEqual(newConfig.secret, cc.secret)
+	}
+
+	// Likewise when the CloudWatch agent or the metrics' namespace or cluster change.
+	if newConfig.backendDestination == cloudWatch {
+		return newConfig.cloudWatchAgentAddress != cc.cloudWatchAgentAddress ||
+			newConfig.cloudWatchNamespace != cc.cloudWatchNamespace || newConfig.cloudWatchClusterName != cc.cloudWatchClusterName
+	}
+
+	// Registered backends read their settings from the ConfigMap, so restart
+	// them whenever it changes.
+	if newConfig.backendConfig != nil {
+		return !reflect.DeepEqual(newConfig.backendConfig, cc.backendConfig)
+	}
+
+	// The Stackdriver exporter reads the views at its own interval, which is
+	// fixed when it is created.
+	return newConfig.backendDestination == stackdriver && (newConfig.stackdriverClientConfig != cc.stackdriverClientConfig ||
+		newConfig.reportingPeriod != cc.reportingPeriod ||
+		newConfig.stackdriverCustomMetricTypePrefix != cc.stackdriverCustomMetricTypePrefix ||
+		!reflect.DeepEqual(newConfig.stackdriverProjects, cc.stackdriverProjects) ||
+		newConfig.platformMetadataProvider != cc.platformMetadataProvider ||
+		newConfig.stackdriverPrecreateDescriptors != cc.stackdriverPrecreateDescriptors)
 }
 
 // newMetricsExporter gets a metrics exporter based on the config.
@@ -202,16 +285,12 @@ func newMetricsExporter(config *metricsConfig, logger *zap.SugaredLogger) (view.
 		se.StopMetricsExporter()
 	}
 
-	factory := map[metricsBackend]func(*metricsConfig, *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error){
-		stackdriver: newStackdriverExporter,
-		openCensus:  newOpenCensusExporter,
-		prometheus:  newPrometheusExporter,
-		none: func(*metricsConfig, *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
-			return nil, nil, nil
-		},
+	registerExporterViews(logger)
+	if f := getBackendFactory(config.backendDestination); f != nil {
+		return newRegisteredExporter(f, config, logger)
 	}
 
-	ff := factory[config.backendDestination]
+	ff := builtinBackends[config.backendDestination]
 	if ff == nil {
 		return nil, nil, fmt.Errorf("unsuppored metrics backend %v", config.backendDestination)
 	}
@@ -251,9 +330,45 @@ func setCurMetricsConfigUnlocked(c *metricsConfig) {
 // This should be called before the process shuts down or exporter is replaced.
 // Return value indicates whether the exporter is flushable or not.
 func FlushExporter() bool {
+	start := time.Now()
 	e := getCurMetricsExporter()
 	flushResourceExporters()
-	return flushGivenExporter(e)
+	flushed := flushGivenExporter(e)
+	if c := getCurMetricsConfig(); flushed && c != nil {
+		recordFlush(c.backendDestination, start)
+	}
+	return flushed
+}
+
+// ShutdownExporter drains the exporter before the process shuts down: the
+// metrics recorded since the last export are exported and uploaded. Since
+// Prometheus scrapes the metrics rather than being sent them, they keep
+// being served for grace, for a last scrape to collect them, or until the
+// context is done, whichever comes first.
+func ShutdownExporter(ctx context.Context, grace time.Duration) {
+	e := getCurMetricsExporter()
+	if d, ok := e.(drainable); ok {
+		d.Drain()
+	}
+	FlushExporter()
+	if _, ok := e.(*prom.Exporter); ok && grace > 0 {
+		t := time.NewTimer(grace)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// ReportingPeriod returns the reporting period of the current exporter, or
+// zero when there is none. It is a reasonable grace for ShutdownExporter, as
+// Prometheus is expected to scrape about as often.
+func ReportingPeriod() time.Duration {
+	if c := getCurMetricsConfig(); c != nil {
+		return c.reportingPeriod
+	}
+	return 0
 }
 
 func flushGivenExporter(e view.Exporter) bool {
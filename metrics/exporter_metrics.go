@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// internalMetricsDomain is the Stackdriver domain of the metrics the
+// package emits about its own exporting, whatever the component.
+const internalMetricsDomain = "knative.dev/internal/metrics"
+
+var (
+	// backendTagKey is the metrics backend an exporter metric is about.
+	backendTagKey = tag.MustNewKey("backend")
+
+	// exportAttemptsM counts the writes of metrics to the backend,
+	// including the retries.
+	exportAttemptsM = stats.Int64(
+		"metrics_export_attempts",
+		"Number of attempts to write metrics to the backend",
+		stats.UnitDimensionless)
+
+	// exportFailuresM counts the writes dropped after failing.
+	exportFailuresM = stats.Int64(
+		"metrics_export_failures",
+		"Number of metric writes dropped by the exporter after failing",
+		stats.UnitDimensionless)
+
+	// droppedRowsM counts the rows, or time series, of the dropped writes.
+	droppedRowsM = stats.Int64(
+		"metrics_dropped_rows",
+		"Number of rows of the metric writes dropped by the exporter",
+		stats.UnitDimensionless)
+
+	// flushDurationM is how long flushing the exporter took.
+	flushDurationM = stats.Float64(
+		"metrics_flush_duration",
+		"The time it took to flush the exporter",
+		stats.UnitMilliseconds)
+
+	// exporterMetricNames are exported under internalMetricsDomain.
+	exporterMetricNames = sets.NewString(
+		exportAttemptsM.Name(),
+		exportFailuresM.Name(),
+		droppedRowsM.Name(),
+		flushDurationM.Name(),
+	)
+
+	exporterViewsOnce sync.Once
+
+	// exporterRecords records the exporter measurements, which are taken on
+	// the goroutines of the exporters. It is started on first use.
+	exporterRecords    *exportQueue
+	exporterRecordOnce sync.Once
+)
+
+// registerExporterViews registers the views of the exporter metrics, once.
+// They are registered as resource views, so that the label filter applies to
+// them, but without precreating their Stackdriver descriptors, which would
+// take the metricsMux held by newMetricsExporter.
+func registerExporterViews(logger *zap.SugaredLogger) {
+	exporterViewsOnce.Do(func() {
+		tagKeys := []tag.Key{backendTagKey}
+		if err := registerResourceView(&view.View{
+			Description: exportAttemptsM.Description(),
+			Measure:     exportAttemptsM,
+			Aggregation: view.Count(),
+			TagKeys:     tagKeys,
+		}, &view.View{
+			Description: exportFailuresM.Description(),
+			Measure:     exportFailuresM,
+			Aggregation: view.Count(),
+			TagKeys:     tagKeys,
+		}, &view.View{
+			Description: droppedRowsM.Description(),
+			Measure:     droppedRowsM,
+			Aggregation: view.Sum(),
+			TagKeys:     tagKeys,
+		}, &view.View{
+			Description: flushDurationM.Description(),
+			Measure:     flushDurationM,
+			Aggregation: view.Distribution(Buckets125(1, 100000)...),
+			TagKeys:     tagKeys,
+		}); err != nil {
+			logger.Errorw("Failed to register the exporter views", zap.Error(err))
+		}
+	})
+}
+
+// recordExportAttempt records an attempt to write metrics to the backend.
+func recordExportAttempt(backend metricsBackend) {
+	recordForBackend(backend, exportAttemptsM.M(1))
+}
+
+// recordExportDropped records a write of rows dropped after failing.
+func recordExportDropped(backend metricsBackend, rows int64) {
+	recordForBackend(backend, exportFailuresM.M(1), droppedRowsM.M(rows))
+}
+
+// recordViewExport records a write of view data to the backend, dropped
+// with its rows if there are any. Writing the views of the exporter metrics
+// is not recorded, so that exporting them does not feed itself.
+func recordViewExport(backend metricsBackend, vd *view.Data, droppedRows int64) {
+	if exporterMetricNames.Has(viewName(vd.View)) {
+		return
+	}
+	recordExportAttempt(backend)
+	if droppedRows > 0 {
+		recordExportDropped(backend, droppedRows)
+	}
+}
+
+// recordFlush records how long flushing the exporter of the backend took.
+func recordFlush(backend metricsBackend, start time.Time) {
+	recordForBackend(backend, flushDurationM.M(float64(time.Since(start))/float64(time.Millisecond)))
+}
+
+// recordForBackend queues the measurements for recording, without blocking.
+func recordForBackend(backend metricsBackend, ms ...stats.Measurement) {
+	exporterRecordQueue().enqueue(func() {
+		ctx, err := tag.New(context.Background(), tag.Upsert(backendTagKey, string(backend)))
+		if err == nil {
+			ctx, err = hashLabels(ctx)
+		}
+		if err != nil {
+			return
+		}
+		stats.RecordWithOptions(ctx, stats.WithMeasurements(ms...))
+	})
+}
+
+// exporterRecordQueue returns the queue recording the exporter measurements.
+func exporterRecordQueue() *exportQueue {
+	exporterRecordOnce.Do(func() { exporterRecords = newExportQueue(exportQueueSize) })
+	return exporterRecords
+}
+
+// waitExporterMetrics returns once the exporter measurements queued so far
+// were recorded.
+func waitExporterMetrics() {
+	exporterRecordQueue().flush()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+// exporterMetricRow returns the data of the exporter metric for the backend.
+func exporterMetricRow(t *testing.T, name string, backend metricsBackend) view.AggregationData {
+	t.Helper()
+	waitExporterMetrics()
+	rows, err := view.RetrieveData(name)
+	if err != nil {
+		t.Fatal("RetrieveData() =", err)
+	}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == backendTagKey && tag.Value == string(backend) {
+				return row.Data
+			}
+		}
+	}
+	return nil
+}
+
+func exporterMetricCount(t *testing.T, name string, backend metricsBackend) int64 {
+	t.Helper()
+	if data := exporterMetricRow(t, name, backend); data != nil {
+		return data.(*view.CountData).Value
+	}
+	return 0
+}
+
+func TestExporterMetrics(t *testing.T) {
+	registerExporterViews(TestLogger(t))
+	const backend = metricsBackend("test-exporter-metrics")
+
+	recordExportAttempt(backend)
+	recordExportAttempt(backend)
+	recordExportDropped(backend, 3)
+	recordFlush(backend, time.Now().Add(-10*time.Millisecond))
+
+	if got, want := exporterMetricCount(t, exportAttemptsM.Name(), backend), int64(2); got != want {
+		t.Errorf("Export attempts = %d, want %d", got, want)
+	}
+	if got, want := exporterMetricCount(t, exportFailuresM.Name(), backend), int64(1); got != want {
+		t.Errorf("Export failures = %d, want %d", got, want)
+	}
+	if data, ok := exporterMetricRow(t, droppedRowsM.Name(), backend).(*view.SumData); !ok || data.Value != 3 {
+		t.Errorf("Dropped rows = %v, want 3", data)
+	}
+	if data, ok := exporterMetricRow(t, flushDurationM.Name(), backend).(*view.DistributionData); !ok || data.Count != 1 || data.Min < 10 {
+		t.Errorf("Flush duration = %v, want one flush of at least 10ms", data)
+	}
+}
+
+func TestExporterMetricsPrefix(t *testing.T) {
+	mpf := getMetricPrefixFunc("knative.dev/serving/activator", "custom.googleapis.com/knative.dev/activator")
+	for name := range exporterMetricNames {
+		if got, want := mpf(name), internalMetricsDomain; got != want {
+			t.Errorf("getMetricPrefixFunc(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestRecordViewExport(t *testing.T) {
+	registerExporterViews(TestLogger(t))
+	const backend metricsBackend = "test-view-export"
+	attempts := exporterMetricCount(t, exportAttemptsM.Name(), backend)
+	failures := exporterMetricCount(t, exportFailuresM.Name(), backend)
+
+	recordViewExport(backend, &view.Data{View: &view.View{Name: "test-view"}}, 0)
+	recordViewExport(backend, &view.Data{View: &view.View{Name: "test-view"}}, 2)
+	// Writes of the exporter metrics are not recorded.
+	recordViewExport(backend, &view.Data{View: &view.View{Measure: exportAttemptsM}}, 1)
+
+	if got, want := exporterMetricCount(t, exportAttemptsM.Name(), backend)-attempts, int64(2); got != want {
+		t.Errorf("Export attempts = %d, want %d", got, want)
+	}
+	if got, want := exporterMetricCount(t, exportFailuresM.Name(), backend)-failures, int64(1); got != want {
+		t.Errorf("Export failures = %d, want %d", got, want)
+	}
+}
@@ -13,11 +13,16 @@ limitations under the License.
 package metrics
 
 import (
+	"context"
 	"os"
 	"path"
 	"testing"
 	"time"
 
+	"go.opencensus.io/stats"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	. "knative.dev/pkg/logging/testing"
 )
 
@@ -35,9 +40,10 @@ const (
 
 func TestMain(m *testing.M) {
 	resetCurPromSrv()
-	// Set gcpMetadataFunc and newStackdriverExporterFunc for testing
-	gcpMetadataFunc = fakeGcpMetadataFunc
+	// Set gcpMetadataFunc, newStackdriverExporterFunc and newSDProjectExporterFunc for testing
+	setGCPMetadataFunc(fakeGcpMetadataFunc)
 	newStackdriverExporterFunc = newFakeExporter
+	newSDProjectExporterFunc = newFakeProjectExporter
 	os.Exit(m.Run())
 }
 
@@ -300,3 +306,152 @@ func TestFlushExporter(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigMapWatcherSwitchesBackends(t *testing.T) {
+	setCurMetricsConfig(nil)
+	defer setCurMetricsConfig(nil)
+	ctx := context.Background()
+
+	watcher, err := UpdateExporterFromConfigMapWithOpts(ctx, ExporterOptions{
+		Domain:         servingDomain,
+		Component:      testComponent,
+		PrometheusPort: 19091,
+	}, TestLogger(t))
+	if err != nil {
+		t.Fatal("UpdateExporterFromConfigMapWithOpts() =", err)
+	}
+
+	// Keep recording while the backend changes underneath.
+	measure := stats.Int64("switch_test", "Switches", stats.UnitDimensionless)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				Record(ctx, measure.M(1))
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	for _, backend := range []metricsBackend{prometheus, openCensus, stackdriver, prometheus} {
+		watcher(&corev1.ConfigMap{Data: map[string]string{
+			BackendDestinationKey:   string(backend),
+			collectorAddressKey:     "localhost:55678",
+			stackdriverProjectIDKey: testProj,
+		}})
+		if got := getCurMetricsConfig(); got == nil || got.backendDestination != backend {
+			t.Fatalf("Backend = %v, want %v", got, backend)
+		}
+		if backend == prometheus {
+			expectPromSrv(t, ":19091")
+		} else if srv := getCurPromSrv(); srv != nil {
+			t.Errorf("Prometheus server still running after switching to %v", backend)
+		}
+	}
+}
+
+type fakeDrainableExporter struct {
+	fakeExporter
+	drained, flushed bool
+}
+
+func (e *fakeDrainableExporter) Drain() { e.drained = true }
+func (e *fakeDrainableExporter) Flush() { e.flushed = e.drained }
+
+func TestShutdownExporterPrometheusGrace(t *testing.T) {
+	c := &metricsConfig{
+		domain:             servingDomain,
+		component:          testComponent,
+		reportingPeriod:    time.Minute,
+		backendDestination: prometheus,
+		prometheusPort:     9090,
+	}
+	e, _, err := newMetricsExporter(c, TestLogger(t))
+	if err != nil {
+		t.Fatal("newMetricsExporter() =", err)
+	}
+	setCurMetricsExporter(e)
+	t.Cleanup(func() {
+		setCurMetricsExporter(nil)
+		resetCurPromSrv()
+	})
+
+	const grace = 50 * time.Millisecond
+	start := time.Now()
+	ShutdownExporter(context.Background(), grace)
+	if elapsed := time.Since(start); elapsed < grace {
+		t.Errorf("ShutdownExporter() returned after %v, want at least %v", elapsed, grace)
+	}
+
+	// The wait is cut short once the context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	start = time.Now()
+	ShutdownExporter(ctx, time.Hour)
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("ShutdownExporter() returned after %v, want it bounded by the context", elapsed)
+	}
+}
+
+func TestReportingPeriod(t *testing.T) {
+	setCurMetricsConfig(nil)
+	if got := ReportingPeriod(); got != 0 {
+		t.Errorf("ReportingPeriod() = %v without an exporter, want 0", got)
+	}
+	setCurMetricsConfig(&metricsConfig{
+		domain:             servingDomain,
+		component:          testComponent,
+		reportingPeriod:    5 * time.Second,
+		backendDestination: prometheus,
+	})
+	defer setCurMetricsConfig(nil)
+	if got, want := ReportingPeriod(), 5*time.Second; got != want {
+		t.Errorf("ReportingPeriod() = %v, want %v", got, want)
+	}
+}
+
+func TestGetBackendSecret(t *testing.T) {
+	generic := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "datadog"}}
+	specific := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: testComponent + "-datadog"}}
+	tests := []struct {
+		name    string
+		lister  SecretFetcher
+		want    string
+		wantErr bool
+	}{{
+		name:   "component secret",
+		lister: fakeSecretList(generic, specific).Get,
+		want:   specific.Name,
+	}, {
+		name:   "generic secret",
+		lister: fakeSecretList(generic).Get,
+		want:   generic.Name,
+	}, {
+		name:    "no secret",
+		lister:  fakeSecretList().Get,
+		wantErr: true,
+	}, {
+		name:    "no lister",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := getBackendSecret(testComponent, "datadog", test.lister)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("getBackendSecret() = %v, wantErr %v", err, test.wantErr)
+			}
+			if err == nil && got.Name != test.want {
+				t.Errorf("getBackendSecret() = %s, want %s", got.Name, test.want)
+			}
+		})
+	}
+}
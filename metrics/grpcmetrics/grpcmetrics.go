@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcmetrics instruments gRPC servers and clients with the
+// OpenCensus ocgrpc plugin, recording their RPC counts and latencies tagged
+// with the component and namespace they belong to.
+package grpcmetrics
+
+import (
+	"context"
+
+	"go.opencensus.io/plugin/ocgrpc"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+var (
+	namespaceKey = tag.MustNewKey(metricskey.LabelNamespaceName)
+
+	// The views are named without the "grpc.io/" prefix of the ocgrpc
+	// views, so that the backends prefix them like any other Knative metric.
+	serverTags = []tag.Key{metricskey.ComponentTagKey, namespaceKey, ocgrpc.KeyServerMethod, ocgrpc.KeyServerStatus}
+	clientTags = []tag.Key{metricskey.ComponentTagKey, namespaceKey, ocgrpc.KeyClientMethod, ocgrpc.KeyClientStatus}
+
+	// ServerCompletedRPCsView counts the RPCs served, by method and status.
+	ServerCompletedRPCsView = &view.View{
+		Name:        "grpc/server/completed_rpcs",
+		Description: "Count of RPCs served, by method and status",
+		Measure:     ocgrpc.ServerLatency,
+		Aggregation: view.Count(),
+		TagKeys:     serverTags,
+	}
+	// ServerLatencyView is the distribution of the latencies of the RPCs served.
+	ServerLatencyView = &view.View{
+		Name:        "grpc/server/server_latency",
+		Description: "Latency distribution of RPCs served, by method and status",
+		Measure:     ocgrpc.ServerLatency,
+		Aggregation: ocgrpc.DefaultMillisecondsDistribution,
+		TagKeys:     serverTags,
+	}
+	// ClientCompletedRPCsView counts the RPCs sent, by method and status.
+	ClientCompletedRPCsView = &view.View{
+		Name:        "grpc/client/completed_rpcs",
+		Description: "Count of RPCs sent, by method and status",
+		Measure:     ocgrpc.ClientRoundtripLatency,
+		Aggregation: view.Count(),
+		TagKeys:     clientTags,
+	}
+	// ClientRoundtripLatencyView is the distribution of the round trip latencies of the RPCs sent.
+	ClientRoundtripLatencyView = &view.View{
+		Name:        "grpc/client/roundtrip_latency",
+		Description: "Round trip latency distribution of RPCs sent, by method and status",
+		Measure:     ocgrpc.ClientRoundtripLatency,
+		Aggregation: ocgrpc.DefaultMillisecondsDistribution,
+		TagKeys:     clientTags,
+	}
+
+	// ServerViews are the views recorded by the servers created with ServerOption.
+	ServerViews = []*view.View{ServerCompletedRPCsView, ServerLatencyView}
+	// ClientViews are the views recorded by the connections dialed with DialOption.
+	ClientViews = []*view.View{ClientCompletedRPCsView, ClientRoundtripLatencyView}
+)
+
+// RegisterViews registers the server and client views, so that they are
+// exported through the configured metrics backend.
+func RegisterViews() error {
+	return metrics.RegisterResourceView(append(ServerViews, ClientViews...)...)
+}
+
+// UnregisterViews unregisters the views registered by RegisterViews.
+func UnregisterViews() {
+	metrics.UnregisterResourceView(append(ServerViews, ClientViews...)...)
+}
+
+// ServerOption returns a grpc.ServerOption recording the RPCs served in the
+// server views, tagged with the given component and namespace.
+func ServerOption(component, namespace string) grpc.ServerOption {
+	return grpc.StatsHandler(&serverHandler{
+		tags: mutators(component, namespace),
+	})
+}
+
+// DialOption returns a grpc.DialOption recording the RPCs sent in the client
+// views, tagged with the given component and namespace.
+func DialOption(component, namespace string) grpc.DialOption {
+	return grpc.WithStatsHandler(&clientHandler{
+		tags: mutators(component, namespace),
+	})
+}
+
+func mutators(component, namespace string) []tag.Mutator {
+	return []tag.Mutator{
+		tag.Upsert(metricskey.ComponentTagKey, component),
+		tag.Upsert(namespaceKey, namespace),
+	}
+}
+
+// serverHandler tags the RPCs after ocgrpc did, since it replaces the tags
+// of the context with the ones propagated by the client.
+type serverHandler struct {
+	ocgrpc.ServerHandler
+	tags []tag.Mutator
+}
+
+// TagRPC implements stats.Handler.
+func (h *serverHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return withTags(h.ServerHandler.TagRPC(ctx, info), h.tags)
+}
+
+// clientHandler tags the RPCs after ocgrpc did, so that the tags are not
+// propagated to the server.
+type clientHandler struct {
+	ocgrpc.ClientHandler
+	tags []tag.Mutator
+}
+
+// TagRPC implements stats.Handler.
+func (h *clientHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return withTags(h.ClientHandler.TagRPC(ctx, info), h.tags)
+}
+
+func withTags(ctx context.Context, tags []tag.Mutator) context.Context {
+	if tagged, err := tag.New(ctx, tags...); err == nil {
+		return tagged
+	}
+	return ctx
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcmetrics
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc/stats"
+
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestHandlers(t *testing.T) {
+	if err := RegisterViews(); err != nil {
+		t.Fatal("RegisterViews() =", err)
+	}
+	t.Cleanup(UnregisterViews)
+
+	// Tags already in the context are propagated by the client and
+	// replaced by ocgrpc on the server.
+	ctx, err := tag.New(context.Background(), tag.Upsert(tag.MustNewKey("unrelated"), "value"))
+	if err != nil {
+		t.Fatal("tag.New() =", err)
+	}
+	info := &stats.RPCTagInfo{FullMethodName: "/knative.Test/Method"}
+
+	client := &clientHandler{tags: mutators("client", "client-ns")}
+	cctx := client.TagRPC(ctx, info)
+	client.HandleRPC(cctx, &stats.End{Client: true})
+
+	server := &serverHandler{tags: mutators("server", "server-ns")}
+	sctx := server.TagRPC(stats.SetTags(context.Background(), stats.OutgoingTags(cctx)), info)
+	server.HandleRPC(sctx, &stats.End{})
+
+	clientTags := map[string]string{
+		"component":          "client",
+		"namespace_name":     "client-ns",
+		"grpc_client_method": "knative.Test/Method",
+		"grpc_client_status": "OK",
+	}
+	metricstest.CheckCountData(t, ClientCompletedRPCsView.Name, clientTags, 1)
+	metricstest.CheckDistributionCount(t, ClientRoundtripLatencyView.Name, clientTags, 1)
+
+	serverTags := map[string]string{
+		"component":          "server",
+		"namespace_name":     "server-ns",
+		"grpc_server_method": "knative.Test/Method",
+		"grpc_server_status": "OK",
+	}
+	metricstest.CheckCountData(t, ServerCompletedRPCsView.Name, serverTags, 1)
+	metricstest.CheckDistributionCount(t, ServerLatencyView.Name, serverTags, 1)
+
+	propagated, err := tag.Decode(stats.OutgoingTags(cctx))
+	if err != nil {
+		t.Fatal("tag.Decode() =", err)
+	}
+	if _, ok := propagated.Value(metricskey.ComponentTagKey); ok {
+		t.Error("The component tag was propagated to the server")
+	}
+	if _, ok := propagated.Value(tag.MustNewKey("unrelated")); !ok {
+		t.Error("The tags of the context were not propagated to the server")
+	}
+}
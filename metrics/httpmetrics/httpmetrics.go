@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpmetrics instruments HTTP servers and clients with the
+// OpenCensus ochttp plugin, recording their request counts, latencies and
+// sizes tagged with the component and namespace they belong to.
+package httpmetrics
+
+import (
+	"net/http"
+
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/tracing/propagation/tracecontextb3"
+)
+
+var (
+	namespaceKey = tag.MustNewKey(metricskey.LabelNamespaceName)
+
+	// The views are named without the "opencensus.io/" prefix of the ochttp
+	// views, so that the backends prefix them like any other Knative metric.
+	serverTags = []tag.Key{metricskey.ComponentTagKey, namespaceKey, ochttp.Method, ochttp.StatusCode}
+	clientTags = []tag.Key{metricskey.ComponentTagKey, namespaceKey, ochttp.KeyClientMethod, ochttp.KeyClientStatus}
+
+	// ServerRequestCountView counts the requests served, by method and status code.
+	ServerRequestCountView = &view.View{
+		Name:        "http/server/request_count",
+		Description: "Count of HTTP requests served, by method and status code",
+		Measure:     ochttp.ServerLatency,
+		Aggregation: view.Count(),
+		TagKeys:     serverTags,
+	}
+	// ServerLatencyView is the distribution of the latencies of the requests served.
+	ServerLatencyView = &view.View{
+		Name:        "http/server/latency",
+		Description: "Latency distribution of HTTP requests served, by method and status code",
+		Measure:     ochttp.ServerLatency,
+		Aggregation: ochttp.DefaultLatencyDistribution,
+		TagKeys:     serverTags,
+	}
+	// ServerResponseBytesView is the distribution of the sizes of the response bodies.
+	ServerResponseBytesView = &view.View{
+		Name:        "http/server/response_bytes",
+		Description: "Size distribution of HTTP response bodies, by method and status code",
+		Measure:     ochttp.ServerResponseBytes,
+		Aggregation: ochttp.DefaultSizeDistribution,
+		TagKeys:     serverTags,
+	}
+	// ClientRequestCountView counts the requests sent, by method and status code.
+	ClientRequestCountView = &view.View{
+		Name:        "http/client/request_count",
+		Description: "Count of HTTP requests sent, by method and status code",
+		Measure:     ochttp.ClientRoundtripLatency,
+		Aggregation: view.Count(),
+		TagKeys:     clientTags,
+	}
+	// ClientLatencyView is the distribution of the round trip latencies of the requests sent.
+	ClientLatencyView = &view.View{
+		Name:        "http/client/latency",
+		Description: "Round trip latency distribution of HTTP requests sent, by method and status code",
+		Measure:     ochttp.ClientRoundtripLatency,
+		Aggregation: ochttp.DefaultLatencyDistribution,
+		TagKeys:     clientTags,
+	}
+
+	// ServerViews are the views recorded by the handlers returned by NewHandler.
+	ServerViews = []*view.View{ServerRequestCountView, ServerLatencyView, ServerResponseBytesView}
+	// ClientViews are the views recorded by the transports returned by NewTransport.
+	ClientViews = []*view.View{ClientRequestCountView, ClientLatencyView}
+)
+
+// RegisterViews registers the server and client views, so that they are
+// exported through the configured metrics backend.
+func RegisterViews() error {
+	return metrics.RegisterResourceView(append(ServerViews, ClientViews...)...)
+}
+
+// UnregisterViews unregisters the views registered by RegisterViews.
+func UnregisterViews() {
+	metrics.UnregisterResourceView(append(ServerViews, ClientViews...)...)
+}
+
+func mutators(component, namespace string) []tag.Mutator {
+	return []tag.Mutator{
+		tag.Upsert(metricskey.ComponentTagKey, component),
+		tag.Upsert(namespaceKey, namespace),
+	}
+}
+
+// NewHandler wraps h so that the requests it serves are recorded in the
+// server views, tagged with the given component and namespace. The requests
+// are traced as well, so there is no need to also wrap h with
+// tracing.HTTPSpanMiddleware.
+func NewHandler(h http.Handler, component, namespace string) http.Handler {
+	tags := mutators(component, namespace)
+	och := &ochttp.Handler{
+		Handler:     h,
+		Propagation: tracecontextb3.TraceContextEgress,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ctx, err := tag.New(r.Context(), tags...); err == nil {
+			r = r.WithContext(ctx)
+		}
+		och.ServeHTTP(w, r)
+	})
+}
+
+// NewTransport wraps base so that the requests it sends are recorded in the
+// client views, tagged with the given component and namespace. A nil base
+// uses http.DefaultTransport.
+func NewTransport(base http.RoundTripper, component, namespace string) http.RoundTripper {
+	return &transport{
+		tags: mutators(component, namespace),
+		base: &ochttp.Transport{
+			Base:        base,
+			Propagation: tracecontextb3.TraceContextEgress,
+		},
+	}
+}
+
+type transport struct {
+	tags []tag.Mutator
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if ctx, err := tag.New(r.Context(), t.tags...); err == nil {
+		r = r.WithContext(ctx)
+	}
+	return t.base.RoundTrip(r)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpmetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestHandlerAndTransport(t *testing.T) {
+	if err := RegisterViews(); err != nil {
+		t.Fatal("RegisterViews() =", err)
+	}
+	t.Cleanup(UnregisterViews)
+
+	server := httptest.NewServer(NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	}), "server", "server-ns"))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: NewTransport(nil, "client", "client-ns")}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal("Get() =", err)
+		}
+		resp.Body.Close()
+	}
+
+	serverTags := map[string]string{
+		"component":      "server",
+		"namespace_name": "server-ns",
+		"http.method":    http.MethodGet,
+		"http.status":    "418",
+	}
+	metricstest.CheckCountData(t, ServerRequestCountView.Name, serverTags, 2)
+	metricstest.CheckDistributionCount(t, ServerLatencyView.Name, serverTags, 2)
+	metricstest.CheckDistributionData(t, ServerResponseBytesView.Name, serverTags, 2, 15, 15)
+
+	clientTags := map[string]string{
+		"component":          "client",
+		"namespace_name":     "client-ns",
+		"http_client_method": http.MethodGet,
+		"http_client_status": "418",
+	}
+	metricstest.CheckCountData(t, ClientRequestCountView.Name, clientTags, 2)
+	metricstest.CheckDistributionCount(t, ClientLatencyView.Name, clientTags, 2)
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// allowedLabelsKey lists the only tags recorded, when set.
+	allowedLabelsKey = "metrics.allowed-labels"
+	// blockedLabelsKey lists tags which are never recorded.
+	blockedLabelsKey = "metrics.blocked-labels"
+	// hashDisallowedLabelsKey replaces the values of the disallowed tags
+	// with one of hashedLabelBuckets buckets their hash falls in, rather
+	// than dropping the tags.
+	hashDisallowedLabelsKey = "metrics.hash-disallowed-labels"
+
+	// hashedLabelBuckets is the number of values a hashed tag can take, which
+	// bounds the cardinality it adds to the views.
+	hashedLabelBuckets = 16
+)
+
+// labelFilter drops, or hashes the values of, the tags which are not allowed,
+// to bound the cardinality of the metrics sent to a backend. The tags are
+// dropped from the resource views, so the views aggregate the measurements
+// without them, and hashed into hashedLabelBuckets buckets when recording.
+// Only the views registered through RegisterResourceView are filtered, which
+// is how every view of this package and its users is registered.
+type labelFilter struct {
+	// allowed are the tags to keep. All tags are allowed when it is empty.
+	allowed sets.String
+	// blocked are the tags to drop, even if allowed.
+	blocked sets.String
+	// hash is whether the disallowed tags keep a hash of their values.
+	hash bool
+}
+
+// newLabelFilter returns the filter configured in the given ConfigMap data,
+// or nil when every tag is allowed.
+func newLabelFilter(m map[string]string) (*labelFilter, error) {
+	lf := &labelFilter{
+		allowed: splitLabels(m[allowedLabelsKey]),
+		blocked: splitLabels(m[blockedLabelsKey]),
+	}
+	if s := m[hashDisallowedLabelsKey]; s != "" {
+		var err error
+		if lf.hash, err = strconv.ParseBool(s); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", hashDisallowedLabelsKey, s)
+		}
+	}
+	if lf.allowed.Len() == 0 && lf.blocked.Len() == 0 {
+		return nil, nil
+	}
+	return lf, nil
+}
+
+func splitLabels(s string) sets.String {
+	labels := sets.NewString()
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels.Insert(l)
+		}
+	}
+	return labels
+}
+
+func (lf *labelFilter) allows(name string) bool {
+	return !lf.blocked.Has(name) && (lf.allowed.Len() == 0 || lf.allowed.Has(name))
+}
+
+// labelFilters holds the label filter set in the ConfigMap, and the tag keys
+// of the resource views whose values it hashes.
+var labelFilters = struct {
+	current *labelFilter
+	hashed  []tag.Key
+	lock    sync.RWMutex
+}{}
+
+// setLabelFilter replaces the label filter set in the ConfigMap, and
+// registers again the resource views if it changed. Their data is reset.
+func setLabelFilter(lf *labelFilter) error {
+	labelFilters.lock.Lock()
+	changed := !reflect.DeepEqual(labelFilters.current, lf)
+	labelFilters.current = lf
+	labelFilters.lock.Unlock()
+
+	if !changed {
+		return nil
+	}
+	allMeters.lock.Lock()
+	defer allMeters.lock.Unlock()
+	resourceViews.lock.Lock()
+	defer resourceViews.lock.Unlock()
+	setHashedKeys(resourceViews.views)
+	return reregisterViews(resourceViews.views)
+}
+
+// filterTagKeys returns the tag keys of a view without the disallowed ones,
+// so that the view aggregates the measurements without them. The disallowed
+// keys are kept when their values are hashed.
+func filterTagKeys(keys []tag.Key) []tag.Key {
+	labelFilters.lock.RLock()
+	defer labelFilters.lock.RUnlock()
+	lf := labelFilters.current
+	filtered := make([]tag.Key, 0, len(keys))
+	for _, k := range keys {
+		if lf == nil || lf.hash || lf.allows(k.Name()) {
+			filtered = append(filtered, k)
+		}
+	}
+	return filtered
+}
+
+// setHashedKeys sets the disallowed tag keys of the views, whose values are
+// hashed. It must be called with the resourceViews lock held.
+func setHashedKeys(views []*view.View) {
+	labelFilters.lock.Lock()
+	defer labelFilters.lock.Unlock()
+	labelFilters.hashed = nil
+	lf := labelFilters.current
+	if lf == nil || !lf.hash {
+		return
+	}
+	seen := sets.NewString()
+	for _, v := range views {
+		for _, k := range v.TagKeys {
+			if !lf.allows(k.Name()) && !seen.Has(k.Name()) {
+				seen.Insert(k.Name())
+				labelFilters.hashed = append(labelFilters.hashed, k)
+			}
+		}
+	}
+}
+
+// hashLabels returns the context with the values of the disallowed tags of
+// the resource views hashed, when the filter hashes them.
+func hashLabels(ctx context.Context) (context.Context, error) {
+	labelFilters.lock.RLock()
+	hashed := labelFilters.hashed
+	labelFilters.lock.RUnlock()
+	if len(hashed) == 0 {
+		return ctx, nil
+	}
+	tags := tag.FromContext(ctx)
+	if tags == nil {
+		return ctx, nil
+	}
+
+	var mutators []tag.Mutator
+	for _, k := range hashed {
+		if v, ok := tags.Value(k); ok {
+			mutators = append(mutators, tag.Update(k, hashLabel(v)))
+		}
+	}
+	if len(mutators) == 0 {
+		return ctx, nil
+	}
+	return tag.New(ctx, mutators...)
+}
+
+// hashLabel returns the bucket the hash of a tag value falls in, out of
+// hashedLabelBuckets, so that hashing bounds the values the tag takes.
+func hashLabel(v string) string {
+	h := fnv.New32a()
+	h.Write([]byte(v))
+	return fmt.Sprintf("bucket-%02d", h.Sum32()%hashedLabelBuckets)
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+var (
+	routeKey  = tag.MustNewKey("route")
+	podKey    = tag.MustNewKey("pod_name")
+	clientKey = tag.MustNewKey("client_ip")
+)
+
+func TestNewLabelFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    *labelFilter
+		wantErr bool
+	}{{
+		name: "none",
+		data: map[string]string{},
+	}, {
+		name: "only hashing",
+		data: map[string]string{hashDisallowedLabelsKey: "true"},
+	}, {
+		name: "allowed",
+		data: map[string]string{allowedLabelsKey: "route, pod_name,"},
+		want: &labelFilter{allowed: sets.NewString("route", "pod_name"), blocked: sets.NewString()},
+	}, {
+		name: "blocked and hashed",
+		data: map[string]string{blockedLabelsKey: "client_ip", hashDisallowedLabelsKey: "true"},
+		want: &labelFilter{allowed: sets.NewString(), blocked: sets.NewString("client_ip"), hash: true},
+	}, {
+		name:    "invalid hashing",
+		data:    map[string]string{blockedLabelsKey: "client_ip", hashDisallowedLabelsKey: "sometimes"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := newLabelFilter(test.data)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("newLabelFilter() = %v, wantErr %v", err, test.wantErr)
+			}
+			if !cmp.Equal(got, test.want, cmp.AllowUnexported(labelFilter{})) {
+				t.Error("newLabelFilter() (-want, +got):", cmp.Diff(test.want, got, cmp.AllowUnexported(labelFilter{})))
+			}
+		})
+	}
+}
+
+func TestFilterTagKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *labelFilter
+		want   []tag.Key
+	}{{
+		name: "no filter",
+		want: []tag.Key{routeKey, podKey, clientKey},
+	}, {
+		name:   "allowed",
+		filter: &labelFilter{allowed: sets.NewString("route", "other"), blocked: sets.NewString()},
+		want:   []tag.Key{routeKey},
+	}, {
+		name:   "blocked",
+		filter: &labelFilter{allowed: sets.NewString(), blocked: sets.NewString("client_ip")},
+		want:   []tag.Key{routeKey, podKey},
+	}, {
+		name:   "allowed and blocked",
+		filter: &labelFilter{allowed: sets.NewString("route", "client_ip"), blocked: sets.NewString("client_ip")},
+		want:   []tag.Key{routeKey},
+	}, {
+		name:   "hashed",
+		filter: &labelFilter{allowed: sets.NewString(), blocked: sets.NewString("client_ip"), hash: true},
+		want:   []tag.Key{routeKey, podKey, clientKey},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := setLabelFilter(test.filter); err != nil {
+				t.Fatal("setLabelFilter() =", err)
+			}
+			t.Cleanup(func() { setLabelFilter(nil) })
+
+			got := filterTagKeys([]tag.Key{routeKey, podKey, clientKey})
+			if !cmp.Equal(got, test.want, cmp.Comparer(func(a, b tag.Key) bool { return a == b })) {
+				t.Errorf("filterTagKeys() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHashLabels(t *testing.T) {
+	v := &view.View{
+		Name:        "hashed_labels",
+		Measure:     stats.Int64("hashed_labels", "Number of requests", stats.UnitDimensionless),
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{routeKey, clientKey},
+	}
+	if err := RegisterResourceView(v); err != nil {
+		t.Fatal("RegisterResourceView() =", err)
+	}
+	t.Cleanup(func() {
+		UnregisterResourceView(v)
+		setLabelFilter(nil)
+	})
+	if err := setLabelFilter(&labelFilter{allowed: sets.NewString("route"), blocked: sets.NewString(), hash: true}); err != nil {
+		t.Fatal("setLabelFilter() =", err)
+	}
+
+	ctx, err := tag.New(context.Background(),
+		tag.Insert(routeKey, "r1"),
+		tag.Insert(podKey, "pod-1"),
+		tag.Insert(clientKey, "10.0.0.1"))
+	if err != nil {
+		t.Fatal("tag.New() =", err)
+	}
+	ctx, err = hashLabels(ctx)
+	if err != nil {
+		t.Fatal("hashLabels() =", err)
+	}
+	got := map[string]string{}
+	tags := tag.FromContext(ctx)
+	for _, k := range []tag.Key{routeKey, podKey, clientKey} {
+		if v, ok := tags.Value(k); ok {
+			got[k.Name()] = v
+		}
+	}
+	// The tags which are not in any view are left alone.
+	want := map[string]string{"route": "r1", "pod_name": "pod-1", "client_ip": hashLabel("10.0.0.1")}
+	if !cmp.Equal(got, want) {
+		t.Error("Tags (-want, +got):", cmp.Diff(want, got))
+	}
+}
+
+func TestHashLabel(t *testing.T) {
+	if got, want := hashLabel("10.0.0.1"), hashLabel("10.0.0.1"); got != want {
+		t.Errorf("hashLabel() = %q, then %q, wanted a stable hash", got, want)
+	}
+	buckets := sets.NewString()
+	for i := 0; i < 1000; i++ {
+		buckets.Insert(hashLabel(fmt.Sprint("10.0.", i/256, ".", i%256)))
+	}
+	// The values are spread across, and bounded by, the buckets.
+	if got, want := buckets.Len(), hashedLabelBuckets; got != want {
+		t.Errorf("hashLabel() returned %d distinct values for 1000 values, want %d", got, want)
+	}
+}
+
+func TestLabelFilterExporterViews(t *testing.T) {
+	registerExporterViews(zap.NewNop().Sugar())
+	t.Cleanup(func() { setLabelFilter(nil) })
+	if err := setLabelFilter(&labelFilter{allowed: sets.NewString(), blocked: sets.NewString(backendTagKey.Name())}); err != nil {
+		t.Fatal("setLabelFilter() =", err)
+	}
+
+	// The views of the exporter metrics are filtered like any other.
+	v := view.Find(exportAttemptsM.Name())
+	if v == nil {
+		t.Fatalf("view.Find(%q) = nil", exportAttemptsM.Name())
+	}
+	if len(v.TagKeys) != 0 {
+		t.Errorf("TagKeys = %v, wanted the blocked %s dropped", v.TagKeys, backendTagKey.Name())
+	}
+}
+
+func TestRecordFiltersLabels(t *testing.T) {
+	defer SetFakeGCPMetadata(FakeGCPMetadata{Project: testProj})()
+
+	measure := stats.Int64("filtered_requests", "Number of requests", stats.UnitDimensionless)
+	v := &view.View{
+		Measure:     measure,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{routeKey, podKey, clientKey},
+	}
+	if err := RegisterResourceView(v); err != nil {
+		t.Fatal("RegisterResourceView() =", err)
+	}
+	t.Cleanup(func() {
+		UnregisterResourceView(v)
+		setCurMetricsConfig(nil)
+		setLabelFilter(nil)
+	})
+
+	for i, backend := range []metricsBackend{prometheus, openCensus, stackdriver} {
+		t.Run(string(backend), func(t *testing.T) {
+			mc, err := createMetricsConfig(context.Background(), ExporterOptions{
+				Domain:    servingDomain,
+				Component: testComponent,
+				ConfigMap: map[string]string{
+					BackendDestinationKey:            string(backend),
+					allowStackdriverCustomMetricsKey: "true",
+					allowedLabelsKey:                 "route,client_ip",
+					blockedLabelsKey:                 "client_ip",
+					hashDisallowedLabelsKey:          strconv.FormatBool(i%2 == 1),
+				},
+			})
+			if err != nil {
+				t.Fatal("createMetricsConfig() =", err)
+			}
+			setCurMetricsConfig(mc)
+			if err := setLabelFilter(mc.labelFilter); err != nil {
+				t.Fatal("setLabelFilter() =", err)
+			}
+
+			ctx, err := tag.New(context.Background(),
+				tag.Insert(routeKey, "r1"),
+				tag.Insert(podKey, "pod-1"),
+				tag.Insert(clientKey, "10.0.0.1"))
+			if err != nil {
+				t.Fatal("tag.New() =", err)
+			}
+			Record(ctx, measure.M(int64(i+1)))
+			want := map[string]string{"route": "r1"}
+			if mc.labelFilter.hash {
+				want["pod_name"] = hashLabel("pod-1")
+				want["client_ip"] = hashLabel("10.0.0.1")
+			}
+			metricstest.CheckLastValueData(t, measure.Name(), want, float64(i+1))
+		})
+	}
+}
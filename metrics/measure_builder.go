@@ -0,0 +1,227 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+// componentPrefixes holds the metric type prefix, the domain joined with the
+// component, of the measures built for a component, by measure name. It
+// takes precedence over the prefix of the metrics config when exporting them.
+var componentPrefixes = struct {
+	sync.RWMutex
+	m map[string]string
+}{m: map[string]string{}}
+
+// componentMetricTypePrefix returns the metric type prefix of the measure with the
+// given name, def if it was not built for a component.
+func componentMetricTypePrefix(name, def string) string {
+	componentPrefixes.RLock()
+	defer componentPrefixes.RUnlock()
+	if p, ok := componentPrefixes.m[name]; ok {
+		return p
+	}
+	return def
+}
+
+// Int64MeasureBuilder creates an Int64 measure with its view, e.g.
+//
+//	requestCountM, err := metrics.NewInt64Measure("request_count", "The number of requests", stats.UnitDimensionless).
+//		WithTags(metricskey.LabelNamespaceName, "response_code").
+//		WithAggregation(view.Count()).
+//		ForComponent("knative.dev/internal/serving", "activator").
+//		Register()
+type Int64MeasureBuilder struct {
+	measureBuilder
+}
+
+// Float64MeasureBuilder creates a Float64 measure with its view, like
+// Int64MeasureBuilder.
+type Float64MeasureBuilder struct {
+	measureBuilder
+}
+
+// measureBuilder holds the settings common to the typed builders. The first
+// error is kept and returned by Register.
+type measureBuilder struct {
+	name        string
+	description string
+	unit        string
+	tagKeys     []tag.Key
+	aggregation *view.Aggregation
+	// metricTypePrefix is the domain joined with the component, when the
+	// metric type must be one of a known monitored resource.
+	metricTypePrefix string
+	err              error
+}
+
+// NewInt64Measure starts building an Int64 measure. The view has the name
+// of the measure, so that the metric type of both is the same.
+func NewInt64Measure(name, description, unit string) *Int64MeasureBuilder {
+	return &Int64MeasureBuilder{measureBuilder{name: name, description: description, unit: unit}}
+}
+
+// NewFloat64Measure starts building a Float64 measure. The view has the
+// name of the measure, so that the metric type of both is the same.
+func NewFloat64Measure(name, description, unit string) *Float64MeasureBuilder {
+	return &Float64MeasureBuilder{measureBuilder{name: name, description: description, unit: unit}}
+}
+
+// WithTags creates the tag keys the view groups the measurements by.
+func (b *Int64MeasureBuilder) WithTags(names ...string) *Int64MeasureBuilder {
+	b.withTags(names)
+	return b
+}
+
+// WithAggregation sets the aggregation of the view.
+func (b *Int64MeasureBuilder) WithAggregation(a *view.Aggregation) *Int64MeasureBuilder {
+	b.aggregation = a
+	return b
+}
+
+// ForComponent exports the metric to Stackdriver with the metric type of the
+// component of the domain, whatever the domain and component of the metrics
+// config. The metric type must be promoted to a known monitored resource,
+// failing Register otherwise instead of exporting it as a custom metric.
+func (b *Int64MeasureBuilder) ForComponent(domain, component string) *Int64MeasureBuilder {
+	b.metricTypePrefix = path.Join(domain, component)
+	return b
+}
+
+// Register creates the measure and registers its view with
+// RegisterResourceView.
+func (b *Int64MeasureBuilder) Register() (*stats.Int64Measure, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	m := stats.Int64(b.name, b.description, b.unit)
+	if err := b.register(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MustRegister is like Register, but panics on errors.
+func (b *Int64MeasureBuilder) MustRegister() *stats.Int64Measure {
+	m, err := b.Register()
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// WithTags creates the tag keys the view groups the measurements by.
+func (b *Float64MeasureBuilder) WithTags(names ...string) *Float64MeasureBuilder {
+	b.withTags(names)
+	return b
+}
+
+// WithAggregation sets the aggregation of the view.
+func (b *Float64MeasureBuilder) WithAggregation(a *view.Aggregation) *Float64MeasureBuilder {
+	b.aggregation = a
+	return b
+}
+
+// ForComponent is like Int64MeasureBuilder.ForComponent.
+func (b *Float64MeasureBuilder) ForComponent(domain, component string) *Float64MeasureBuilder {
+	b.metricTypePrefix = path.Join(domain, component)
+	return b
+}
+
+// Register creates the measure and registers its view with
+// RegisterResourceView.
+func (b *Float64MeasureBuilder) Register() (*stats.Float64Measure, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	m := stats.Float64(b.name, b.description, b.unit)
+	if err := b.register(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MustRegister is like Register, but panics on errors.
+func (b *Float64MeasureBuilder) MustRegister() *stats.Float64Measure {
+	m, err := b.Register()
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (b *measureBuilder) withTags(names []string) {
+	for _, name := range names {
+		k, err := tag.NewKey(name)
+		if err != nil {
+			if b.err == nil {
+				b.err = fmt.Errorf("invalid tag %q of measure %q: %w", name, b.name, err)
+			}
+			continue
+		}
+		b.tagKeys = append(b.tagKeys, k)
+	}
+}
+
+func (b *measureBuilder) validate() error {
+	if b.err != nil {
+		return b.err
+	}
+	if b.name == "" {
+		return errors.New("the measure name must not be empty")
+	}
+	if b.aggregation == nil {
+		return fmt.Errorf("no aggregation for measure %q", b.name)
+	}
+	if b.metricTypePrefix != "" {
+		metricType := path.Join(b.metricTypePrefix, b.name)
+		if _, ok := metricskey.GetResourceMapping(metricType); !ok {
+			return fmt.Errorf("metric type %q is not mapped to a monitored resource", metricType)
+		}
+	}
+	return nil
+}
+
+func (b *measureBuilder) register(m stats.Measure) error {
+	if err := RegisterResourceView(&view.View{
+		Name:        b.name,
+		Description: b.description,
+		Measure:     m,
+		Aggregation: b.aggregation,
+		TagKeys:     b.tagKeys,
+	}); err != nil {
+		return err
+	}
+
+	componentPrefixes.Lock()
+	defer componentPrefixes.Unlock()
+	if b.metricTypePrefix != "" {
+		componentPrefixes.m[b.name] = b.metricTypePrefix
+	} else {
+		delete(componentPrefixes.m, b.name)
+	}
+	return nil
+}
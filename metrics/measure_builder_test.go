@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestMeasureBuilder(t *testing.T) {
+	intM, err := NewInt64Measure("desired_pods", "Desired pods", stats.UnitDimensionless).
+		WithTags(metricskey.LabelNamespaceName).
+		WithAggregation(view.LastValue()).
+		ForComponent("knative.dev/serving", "autoscaler").
+		Register()
+	if err != nil {
+		t.Fatal("Int64 Register() =", err)
+	}
+	floatM := NewFloat64Measure("builder_latencies", "Latencies", stats.UnitMilliseconds).
+		WithAggregation(view.Distribution(1, 10)).
+		MustRegister()
+	defer UnregisterResourceView(view.Find("desired_pods"), view.Find("builder_latencies"))
+
+	ctx, err := tag.New(context.Background(), tag.Upsert(tag.MustNewKey(metricskey.LabelNamespaceName), "ns"))
+	if err != nil {
+		t.Fatal("tag.New() =", err)
+	}
+	setCurMetricsConfig(&metricsConfig{})
+	defer setCurMetricsConfig(nil)
+	Record(ctx, intM.M(3))
+	Record(ctx, floatM.M(5))
+
+	metricstest.CheckLastValueData(t, "desired_pods", map[string]string{metricskey.LabelNamespaceName: "ns"}, 3)
+	metricstest.CheckDistributionCount(t, "builder_latencies", map[string]string{}, 1)
+
+	// The metric type of the component is used whatever the metrics config.
+	mpf := getMetricPrefixFunc("knative.dev/eventing/broker", "custom.googleapis.com/knative.dev/broker")
+	if got, want := mpf("desired_pods"), "knative.dev/serving/autoscaler"; got != want {
+		t.Errorf("Metric prefix of desired_pods = %q, want: %q", got, want)
+	}
+	if got, want := mpf("builder_latencies"), "custom.googleapis.com/knative.dev/broker"; got != want {
+		t.Errorf("Metric prefix of builder_latencies = %q, want: %q", got, want)
+	}
+	UnregisterResourceView(view.Find("desired_pods"))
+	if got, want := mpf("desired_pods"), "custom.googleapis.com/knative.dev/broker"; got != want {
+		t.Errorf("Metric prefix of unregistered desired_pods = %q, want: %q", got, want)
+	}
+}
+
+func TestMeasureBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder interface{ validate() error }
+	}{{
+		name:    "no aggregation",
+		builder: NewInt64Measure("no_aggregation", "", stats.UnitDimensionless),
+	}, {
+		name:    "no name",
+		builder: NewFloat64Measure("", "", stats.UnitDimensionless).WithAggregation(view.Count()),
+	}, {
+		name: "invalid tag",
+		builder: NewInt64Measure("invalid_tag", "", stats.UnitDimensionless).
+			WithTags("").
+			WithAggregation(view.Count()),
+	}, {
+		name: "unmapped metric type",
+		builder: NewFloat64Measure("desired_pod", "", stats.UnitDimensionless).
+			WithAggregation(view.LastValue()).
+			ForComponent("knative.dev/serving", "autoscaler"),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.builder.validate(); err == nil {
+				t.Error("validate() = nil, wanted an error")
+			}
+		})
+	}
+
+	if _, err := NewInt64Measure("no_aggregation", "", stats.UnitDimensionless).Register(); err == nil {
+		t.Error("Register() = nil, wanted an error without an aggregation")
+	}
+}
@@ -144,7 +144,7 @@ func measureView(m stats.Measure, agg *view.Aggregation) *view.View {
 		Name:        m.Name(),
 		Description: m.Description(),
 		Measure:     m,
-		Aggregation: agg,
+		Aggregation: withBuckets(m.Name(), agg),
 		TagKeys:     []tag.Key{tagName},
 	}
 }
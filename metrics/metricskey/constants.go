@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"go.opencensus.io/resource"
+	"go.opencensus.io/tag"
 )
 
 const (
@@ -53,11 +54,21 @@ const (
 	// LabelResponseTimeout is the label timeout.
 	LabelResponseTimeout = "response_timeout"
 
+	// LabelComponent is the label for the component reporting the metric.
+	LabelComponent = "component"
+
+	// LabelReconcileResult is the label for the outcome of a reconcile operation.
+	// For example, "success", "error" or "requeue".
+	LabelReconcileResult = "result"
+
 	// ValueUnknown is the default value if the field is unknown, e.g. project will be unknown if Knative
 	// is not running on GKE.
 	ValueUnknown = "unknown"
 )
 
+// ComponentTagKey tags the measurements with the component reporting them.
+var ComponentTagKey = tag.MustNewKey(LabelComponent)
+
 type resourceKey struct{}
 
 // WithResource associates the given monitoring Resource with the current
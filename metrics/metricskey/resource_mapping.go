@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricskey
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ResourceMapping describes the monitored resource a metric type is
+// promoted to when exported to Stackdriver.
+type ResourceMapping struct {
+	// Type is the monitored resource type, e.g. "knative_revision".
+	Type string
+	// Labels are the labels of the resource type. They are taken from the
+	// resource of the context, the tags of the metric or the platform
+	// metadata, in that order, and are unknown otherwise.
+	Labels sets.String
+}
+
+var (
+	resourceMappingsMux sync.RWMutex
+	// resourceMappings holds the mappings by metric type.
+	resourceMappings = map[string]ResourceMapping{}
+)
+
+func init() {
+	// TODO serving and eventing should register these themselves.
+	// See https://github.com/knative/pkg/issues/608
+	for _, item := range []struct {
+		metrics sets.String
+		mapping ResourceMapping
+	}{
+		{KnativeRevisionMetrics, ResourceMapping{ResourceTypeKnativeRevision, KnativeRevisionLabels}},
+		{KnativeTriggerMetrics, ResourceMapping{ResourceTypeKnativeTrigger, KnativeTriggerLabels}},
+		{KnativeBrokerMetrics, ResourceMapping{ResourceTypeKnativeBroker, KnativeBrokerLabels}},
+		{KnativeSourceMetrics, ResourceMapping{ResourceTypeKnativeSource, KnativeSourceLabels}},
+	} {
+		for metricType := range item.metrics {
+			resourceMappings[metricType] = item.mapping
+		}
+	}
+}
+
+// RegisterResourceMapping promotes the metrics of the given type, e.g.
+// "knative.dev/serving/activator/request_count", to the monitored resource
+// of the mapping, so that the repositories owning the metrics declare their
+// resource types themselves. It is meant to be called from init functions,
+// and replaces the mapping previously registered for the type, if any.
+func RegisterResourceMapping(metricType string, m ResourceMapping) {
+	resourceMappingsMux.Lock()
+	defer resourceMappingsMux.Unlock()
+	resourceMappings[metricType] = m
+}
+
+// UnregisterResourceMapping removes the mapping of the metric type.
+func UnregisterResourceMapping(metricType string) {
+	resourceMappingsMux.Lock()
+	defer resourceMappingsMux.Unlock()
+	delete(resourceMappings, metricType)
+}
+
+// GetResourceMapping returns the mapping registered for the metric type.
+func GetResourceMapping(metricType string) (ResourceMapping, bool) {
+	resourceMappingsMux.RLock()
+	defer resourceMappingsMux.RUnlock()
+	m, ok := resourceMappings[metricType]
+	return m, ok
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricskey_test
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+func TestResourceMapping(t *testing.T) {
+	m, ok := metricskey.GetResourceMapping("knative.dev/serving/autoscaler/desired_pods")
+	if !ok {
+		t.Fatal("GetResourceMapping() = false for a built-in serving metric")
+	}
+	if got, want := m.Type, metricskey.ResourceTypeKnativeRevision; got != want {
+		t.Errorf("Type = %q, want %q", got, want)
+	}
+
+	const metricType = "knative.dev/example/controller/reconcile_count"
+	if _, ok := metricskey.GetResourceMapping(metricType); ok {
+		t.Fatalf("GetResourceMapping(%q) = true before registration", metricType)
+	}
+	want := metricskey.ResourceMapping{
+		Type:   "knative_example",
+		Labels: sets.NewString(metricskey.LabelNamespaceName, "example_name"),
+	}
+	metricskey.RegisterResourceMapping(metricType, want)
+	got, ok := metricskey.GetResourceMapping(metricType)
+	if !ok {
+		t.Fatalf("GetResourceMapping(%q) = false after registration", metricType)
+	}
+	if got.Type != want.Type || !got.Labels.Equal(want.Labels) {
+		t.Errorf("GetResourceMapping(%q) = %v, want %v", metricType, got, want)
+	}
+	metricskey.UnregisterResourceMapping(metricType)
+	if _, ok := metricskey.GetResourceMapping(metricType); ok {
+		t.Errorf("GetResourceMapping(%q) = true after unregistration", metricType)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricstest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricexport"
+)
+
+// Exporter is an in-memory metricexport.Exporter, which keeps the last batch
+// of metrics exported, so tests can assert on what a backend would receive
+// without depending on the Meters the metrics were recorded in.
+type Exporter struct {
+	mu      sync.Mutex
+	metrics map[string][]Metric
+}
+
+var _ metricexport.Exporter = (*Exporter)(nil)
+
+// NewExporter creates an empty Exporter.
+func NewExporter() *Exporter {
+	return &Exporter{
+		metrics: make(map[string][]Metric),
+	}
+}
+
+// ExportMetrics implements metricexport.Exporter. Every call replaces the
+// metrics previously exported.
+func (e *Exporter) ExportMetrics(_ context.Context, metrics []*metricdata.Metric) error {
+	exported := make(map[string][]Metric, len(metrics))
+	for _, m := range metrics {
+		if len(m.TimeSeries) > 0 {
+			exported[m.Descriptor.Name] = append(exported[m.Descriptor.Name], NewMetric(m))
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.metrics = exported
+	return nil
+}
+
+// Export synchronously exports the metrics currently recorded in every Meter
+// to the Exporter.
+func (e *Exporter) Export() {
+	EnsureRecorded()
+	metricexport.NewReader().ReadAndExport(e)
+}
+
+// GetMetric returns the exported values of the named metric, one per
+// Resource.
+func (e *Exporter) GetMetric(name string) []Metric {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.metrics[name]
+}
+
+// AssertMetric exports the metrics currently recorded and verifies that the
+// metrics have the specified values. Unlike the package level AssertMetric,
+// it is enough for one of the Metrics exported with the name to match, so
+// setting the Resource checks metrics with the same name on several Meters.
+func (e *Exporter) AssertMetric(t *testing.T, values ...Metric) {
+	t.Helper()
+	e.Export()
+	for _, v := range values {
+		if !e.hasMetric(v) {
+			t.Errorf("Metric %v was not exported, got: %v", v, e.GetMetric(v.Name))
+		}
+	}
+}
+
+// AssertNoMetric exports the metrics currently recorded and verifies that
+// none of the named metrics have values.
+func (e *Exporter) AssertNoMetric(t *testing.T, names ...string) {
+	t.Helper()
+	e.Export()
+	for _, name := range names {
+		if m := e.GetMetric(name); len(m) != 0 {
+			t.Error("Found unexpected data for:", m)
+		}
+	}
+}
+
+func (e *Exporter) hasMetric(want Metric) bool {
+	for _, got := range e.GetMetric(want.Name) {
+		if cmp.Equal(want, got) {
+			return true
+		}
+	}
+	return false
+}
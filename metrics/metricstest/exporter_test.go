@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricstest
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestExporter(t *testing.T) {
+	count := stats.Int64("exported_count", "Test count metric", stats.UnitDimensionless)
+	tagKey := tag.MustNewKey("tag")
+	countView := &view.View{
+		Measure:     count,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{tagKey},
+	}
+	if err := view.Register(countView); err != nil {
+		t.Fatal("Register() =", err)
+	}
+	t.Cleanup(func() { view.Unregister(countView) })
+
+	// The same view, recorded for a Resource in a Meter of its own.
+	res := &resource.Resource{Type: "testing", Labels: map[string]string{"name": "thing"}}
+	meter := view.NewMeter()
+	meter.Start()
+	meter.SetResource(res)
+	t.Cleanup(meter.Stop)
+	if err := meter.Register(countView); err != nil {
+		t.Fatal("Register() =", err)
+	}
+
+	e := NewExporter()
+	e.AssertNoMetric(t, "exported_count")
+
+	ctx, err := tag.New(context.Background(), tag.Upsert(tagKey, "alpha"))
+	if err != nil {
+		t.Fatal("Unable to create context:", err)
+	}
+	stats.Record(ctx, count.M(5))
+	stats.Record(ctx, count.M(3))
+	meter.Record(tag.FromContext(ctx), []stats.Measurement{count.M(2)}, nil)
+
+	e.AssertMetric(t,
+		IntMetric("exported_count", 8, map[string]string{"tag": "alpha"}),
+		IntMetric("exported_count", 2, map[string]string{"tag": "alpha"}).WithResource(res),
+	)
+	if got := len(e.GetMetric("exported_count")); got != 2 {
+		t.Errorf("len(GetMetric()) = %d, want 2", got)
+	}
+	e.AssertNoMetric(t, "other")
+
+	// Every export replaces the previous one.
+	view.Unregister(countView)
+	meter.Unregister(countView)
+	e.AssertNoMetric(t, "exported_count")
+}
@@ -19,20 +19,25 @@ package metrics
 import (
 	"context"
 	"crypto/tls"
-	"fmt"
 	"path"
 
 	"contrib.go.opencensus.io/exporter/ocagent"
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
 	"go.opencensus.io/resource"
 	"go.opencensus.io/stats/view"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 )
 
 func newOpenCensusExporter(config *metricsConfig, logger *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
-	opts := []ocagent.ExporterOption{ocagent.WithServiceName(config.component)}
+	opts := []ocagent.ExporterOption{
+		ocagent.WithServiceName(config.component),
+		// The agent exporter drops the errors of its writes, so they are
+		// recorded from the stream it writes the metrics on.
+		ocagent.WithGRPCDialOption(grpc.WithStreamInterceptor(recordMetricsExport)),
+	}
 	if config.collectorAddress != "" {
 		opts = append(opts, ocagent.WithAddress(config.collectorAddress))
 	}
@@ -58,6 +63,42 @@ func newOpenCensusExporter(config *metricsConfig, logger *zap.SugaredLogger) (vi
 	return e, getFactory(e, opts), nil
 }
 
+// metricsExportMethod is the method of the stream the agent exporter
+// writes the metrics on.
+const metricsExportMethod = "/opencensus.proto.agent.metrics.v1.MetricsService/Export"
+
+// recordMetricsExport records the writes of metrics to the OpenCensus agent.
+func recordMetricsExport(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil || method != metricsExportMethod {
+		return s, err
+	}
+	return &metricsExportStream{ClientStream: s}, nil
+}
+
+// metricsExportStream records the requests sent with metrics.
+type metricsExportStream struct {
+	grpc.ClientStream
+}
+
+func (s *metricsExportStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	req, ok := m.(*agentmetricspb.ExportMetricsServiceRequest)
+	if !ok || len(req.Metrics) == 0 {
+		// The first request of a stream only identifies the node.
+		return err
+	}
+	recordExportAttempt(openCensus)
+	if err != nil {
+		var rows int64
+		for _, metric := range req.Metrics {
+			rows += int64(len(metric.Timeseries))
+		}
+		recordExportDropped(openCensus, rows)
+	}
+	return err
+}
+
 func getFactory(defaultExporter view.Exporter, stored []ocagent.ExporterOption) ResourceExporterFactory {
 	return func(r *resource.Resource) (view.Exporter, error) {
 		if r == nil || (r.Type == "" && len(r.Labels) == 0) {
@@ -72,25 +113,6 @@ func getFactory(defaultExporter view.Exporter, stored []ocagent.ExporterOption)
 	}
 }
 
-// getOpenCensusSecret attempts to locate a secret containing TLS credentials
-// for communicating with the OpenCensus Agent. To do this, it first looks
-// for a secret named "<component>-opencensus", then for a generic
-// "opencensus" secret.
-func getOpenCensusSecret(component string, lister SecretFetcher) (*corev1.Secret, error) {
-	if lister == nil {
-		return nil, fmt.Errorf("no secret lister provided for component %q; cannot use requireSecure=true", component)
-	}
-	secret, err := lister(component + "-opencensus")
-	if errors.IsNotFound(err) {
-		secret, err = lister("opencensus")
-	}
-	if err != nil {
-		return nil, fmt.Errorf("unable to fetch opencensus secret for %q, cannot use requireSecure=true: %+v", component, err)
-	}
-
-	return secret, nil
-}
-
 // getCredentials attempts to create a certificate containing TLS credentials
 // for communicating with the OpenCensus Agent.
 func getCredentials(component string, secret *corev1.Secret, logger *zap.SugaredLogger) credentials.TransportCredentials {
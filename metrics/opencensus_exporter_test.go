@@ -21,8 +21,11 @@ import (
 	"testing"
 
 	"contrib.go.opencensus.io/exporter/ocagent"
+	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
 	"github.com/google/go-cmp/cmp"
 	"go.opencensus.io/stats/view"
+	"google.golang.org/grpc"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -186,3 +189,41 @@ func GetServer(config *tls.Config) (net.Listener, chan error, error) {
 	}()
 	return server, shutdown, err
 }
+
+// sendStream is a stream failing its sends with err.
+type sendStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (s *sendStream) SendMsg(interface{}) error {
+	return s.err
+}
+
+func TestMetricsExportStream(t *testing.T) {
+	registerExporterViews(logtesting.TestLogger(t))
+	attempts := exporterMetricCount(t, exportAttemptsM.Name(), openCensus)
+	failures := exporterMetricCount(t, exportFailuresM.Name(), openCensus)
+
+	s := &metricsExportStream{ClientStream: &sendStream{}}
+	// The request identifying the node is not a write of metrics.
+	s.SendMsg(&agentmetricspb.ExportMetricsServiceRequest{})
+	s.SendMsg(&agentmetricspb.ExportMetricsServiceRequest{
+		Metrics: []*metricspb.Metric{{}},
+	})
+	s = &metricsExportStream{ClientStream: &sendStream{err: errors.NewBadRequest("failed")}}
+	if err := s.SendMsg(&agentmetricspb.ExportMetricsServiceRequest{
+		Metrics: []*metricspb.Metric{{
+			Timeseries: []*metricspb.TimeSeries{{}, {}},
+		}},
+	}); err == nil {
+		t.Error("SendMsg() = nil, want the error of the stream")
+	}
+
+	if got, want := exporterMetricCount(t, exportAttemptsM.Name(), openCensus)-attempts, int64(2); got != want {
+		t.Errorf("Export attempts = %d, want %d", got, want)
+	}
+	if got, want := exporterMetricCount(t, exportFailuresM.Name(), openCensus)-failures, int64(1); got != want {
+		t.Errorf("Export failures = %d, want %d", got, want)
+	}
+}
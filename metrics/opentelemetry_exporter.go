@@ -0,0 +1,338 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats/view"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// defaultOpenTelemetryAddress is the address of the OTLP/gRPC receiver
+	// of the OpenTelemetry Collector when none is configured.
+	defaultOpenTelemetryAddress = "localhost:4317"
+
+	// otlpExportTimeout bounds a single export to the Collector.
+	otlpExportTimeout = 10 * time.Second
+
+	// otlpInstrumentationScope is the instrumentation scope of the exported
+	// metrics.
+	otlpInstrumentationScope = "knative.dev/pkg/metrics"
+
+	// otlpCumulative is the temporality of the sums and histograms, as
+	// OpenCensus views hold cumulative values.
+	otlpCumulative = metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+)
+
+// openTelemetryExporter exports view data to an OpenTelemetry Collector over
+// OTLP/gRPC.
+type openTelemetryExporter struct {
+	prefix string
+	// resource is the OTLP resource the data is exported for.
+	resource *resourcepb.Resource
+	logger   *zap.SugaredLogger
+
+	// export sends a request to the Collector.
+	export func(*colmetricspb.ExportMetricsServiceRequest) error
+	// queue runs the exports. It is shared with the exporters of the
+	// resources, and stopped by the default one.
+	queue *exportQueue
+	// conn is the connection to the Collector, shared with the exporters of
+	// the resources, and closed by the default one.
+	conn *grpc.ClientConn
+}
+
+var _ view.Exporter = (*openTelemetryExporter)(nil)
+var _ flushable = (*openTelemetryExporter)(nil)
+var _ stoppable = (*openTelemetryExporter)(nil)
+
+// newOpenTelemetryExporter creates an exporter sending to the OTLP/gRPC
+// receiver of an OpenTelemetry Collector, which listens on `localhost:4317`
+// by default.
+func newOpenTelemetryExporter(config *metricsConfig, logger *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
+	address := config.collectorAddress
+	if address == "" {
+		address = defaultOpenTelemetryAddress
+	}
+	opts := []grpc.DialOption{}
+	if config.requireSecure {
+		creds := getCredentials(config.component, config.secret, logger)
+		if creds == nil {
+			return nil, nil, fmt.Errorf("no secret provided for component %q; cannot use requireSecure=true", config.component)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	// The connection is established in the background, so that exporting
+	// starts whether or not the Collector is up yet.
+	conn, err := grpc.Dial(address, opts...)
+	if err != nil {
+		logger.Errorw("Failed to create the OpenTelemetry exporter.", zap.Error(err))
+		return nil, nil, err
+	}
+
+	e := &openTelemetryExporter{
+		prefix:   path.Join(config.domain, config.component),
+		resource: otlpResource(config.component, nil),
+		logger:   logger,
+		export:   otlpExporter(conn, config.collectorHeaders),
+		queue:    newExportQueue(exportQueueSize),
+		conn:     conn,
+	}
+	logger.Infow("Created OpenTelemetry exporter with config:", zap.Any("config", *config))
+	view.RegisterExporter(e)
+	return e, e.forResource(config.component), nil
+}
+
+// otlpExporter returns a function sending requests over conn, with the
+// headers as gRPC metadata.
+func otlpExporter(conn *grpc.ClientConn, headers map[string]string) func(*colmetricspb.ExportMetricsServiceRequest) error {
+	client := colmetricspb.NewMetricsServiceClient(conn)
+	md := metadata.New(headers)
+	return func(req *colmetricspb.ExportMetricsServiceRequest) error {
+		ctx, cancel := context.WithTimeout(context.Background(), otlpExportTimeout)
+		defer cancel()
+		if len(md) > 0 {
+			ctx = metadata.NewOutgoingContext(ctx, md)
+		}
+		_, err := client.Export(ctx, req)
+		return err
+	}
+}
+
+// forResource returns a factory of exporters attaching the labels of the
+// resource to the data as resource attributes.
+func (e *openTelemetryExporter) forResource(component string) ResourceExporterFactory {
+	return func(r *resource.Resource) (view.Exporter, error) {
+		if r == nil || (r.Type == "" && len(r.Labels) == 0) {
+			return e, nil
+		}
+		return &openTelemetryExporter{
+			prefix:   e.prefix,
+			resource: otlpResource(component, r),
+			logger:   e.logger,
+			export:   e.export,
+			queue:    e.queue,
+		}, nil
+	}
+}
+
+// Flush implements flushable, waiting for the queued requests to be
+// exported.
+func (e *openTelemetryExporter) Flush() {
+	e.queue.flush()
+}
+
+// StopMetricsExporter implements stoppable, exporting the queued requests
+// and closing the connection to the Collector.
+func (e *openTelemetryExporter) StopMetricsExporter() {
+	e.queue.stop()
+	if e.conn != nil {
+		e.conn.Close()
+	}
+}
+
+// ExportView implements view.Exporter, queueing the request for export.
+func (e *openTelemetryExporter) ExportView(vd *view.Data) {
+	if len(vd.Rows) == 0 {
+		return
+	}
+	req := e.toRequest(vd)
+	if !e.queue.enqueue(func() {
+		var dropped int64
+		if err := e.export(req); err != nil {
+			e.logger.Errorw("Failed to export view "+vd.View.Name+" to the OpenTelemetry Collector", zap.Error(err))
+			dropped = int64(len(vd.Rows))
+		}
+		recordViewExport(openTelemetry, vd, dropped)
+	}) {
+		e.logger.Warn("Dropped view " + vd.View.Name + ": too many views are waiting to be exported to the OpenTelemetry Collector")
+		recordViewExport(openTelemetry, vd, int64(len(vd.Rows)))
+	}
+}
+
+// toRequest converts the view data into an OTLP export request.
+func (e *openTelemetryExporter) toRequest(vd *view.Data) *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: e.resource,
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: otlpInstrumentationScope},
+				Metrics: []*metricspb.Metric{e.toMetric(vd)},
+			}},
+		}},
+	}
+}
+
+// toMetric converts the rows of the view data into an OTLP metric: counts
+// and sums into cumulative sums, last values into gauges and distributions
+// into cumulative histograms.
+func (e *openTelemetryExporter) toMetric(vd *view.Data) *metricspb.Metric {
+	name := vd.View.Name
+	if e.prefix != "" {
+		name = e.prefix + "/" + name
+	}
+	m := &metricspb.Metric{
+		Name:        name,
+		Description: vd.View.Description,
+		Unit:        vd.View.Measure.Unit(),
+	}
+	start := uint64(vd.Start.UnixNano())
+	end := uint64(vd.End.UnixNano())
+
+	switch vd.View.Aggregation.Type {
+	case view.AggTypeCount, view.AggTypeSum:
+		sum := &metricspb.Sum{
+			AggregationTemporality: otlpCumulative,
+			// Counts only go up, while sums may go down.
+			IsMonotonic: vd.View.Aggregation.Type == view.AggTypeCount,
+		}
+		for _, row := range vd.Rows {
+			p := &metricspb.NumberDataPoint{
+				Attributes:        otlpAttributes(rowAttributes(row)),
+				StartTimeUnixNano: start,
+				TimeUnixNano:      end,
+			}
+			switch d := row.Data.(type) {
+			case *view.CountData:
+				p.Value = &metricspb.NumberDataPoint_AsInt{AsInt: d.Value}
+			case *view.SumData:
+				p.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: d.Value}
+			default:
+				continue
+			}
+			sum.DataPoints = append(sum.DataPoints, p)
+		}
+		m.Data = &metricspb.Metric_Sum{Sum: sum}
+	case view.AggTypeLastValue:
+		gauge := &metricspb.Gauge{}
+		for _, row := range vd.Rows {
+			d, ok := row.Data.(*view.LastValueData)
+			if !ok {
+				continue
+			}
+			gauge.DataPoints = append(gauge.DataPoints, &metricspb.NumberDataPoint{
+				Attributes:   otlpAttributes(rowAttributes(row)),
+				TimeUnixNano: end,
+				Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: d.Value},
+			})
+		}
+		m.Data = &metricspb.Metric_Gauge{Gauge: gauge}
+	case view.AggTypeDistribution:
+		hist := &metricspb.Histogram{AggregationTemporality: otlpCumulative}
+		for _, row := range vd.Rows {
+			d, ok := row.Data.(*view.DistributionData)
+			if !ok {
+				continue
+			}
+			sum := d.Sum()
+			p := &metricspb.HistogramDataPoint{
+				Attributes:        otlpAttributes(rowAttributes(row)),
+				StartTimeUnixNano: start,
+				TimeUnixNano:      end,
+				Count:             uint64(d.Count),
+				Sum:               &sum,
+				BucketCounts:      make([]uint64, len(d.CountPerBucket)),
+				ExplicitBounds:    vd.View.Aggregation.Buckets,
+			}
+			for i, c := range d.CountPerBucket {
+				p.BucketCounts[i] = uint64(c)
+			}
+			if d.Count > 0 {
+				min, max := d.Min, d.Max
+				p.Min, p.Max = &min, &max
+			}
+			hist.DataPoints = append(hist.DataPoints, p)
+		}
+		m.Data = &metricspb.Metric_Histogram{Histogram: hist}
+	}
+	return m
+}
+
+// otlpResource returns the OTLP resource of the component, with the type
+// and labels of r, if any, as attributes.
+func otlpResource(component string, r *resource.Resource) *resourcepb.Resource {
+	attrs := map[string]string{}
+	if component != "" {
+		attrs["service.name"] = component
+	}
+	if r != nil {
+		if r.Type != "" {
+			attrs["opencensus.resourcetype"] = r.Type
+		}
+		for k, v := range r.Labels {
+			attrs[k] = v
+		}
+	}
+	return &resourcepb.Resource{Attributes: otlpAttributes(attrs)}
+}
+
+// rowAttributes returns the tags of the row as attributes.
+func rowAttributes(row *view.Row) map[string]string {
+	attrs := make(map[string]string, len(row.Tags))
+	for _, t := range row.Tags {
+		attrs[t.Key.Name()] = t.Value
+	}
+	return attrs
+}
+
+// otlpAttributes converts the attributes into OTLP key values, sorted by
+// key.
+func otlpAttributes(attrs map[string]string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, &commonpb.KeyValue{
+			Key:   k,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: attrs[k]}},
+		})
+	}
+	return kvs
+}
+
+// parseHeaders parses a comma separated list of key=value pairs, the format
+// of the OTEL_EXPORTER_OTLP_HEADERS environment variable.
+func parseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("header %q is not of the form key=value", pair)
+		}
+		headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return headers, nil
+}
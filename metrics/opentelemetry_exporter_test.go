@@ -0,0 +1,318 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+// otlpExport is a request received by the test Collector.
+type otlpExport struct {
+	md  metadata.MD
+	req *colmetricspb.ExportMetricsServiceRequest
+}
+
+// testCollector is an OTLP metrics service answering all the requests with
+// code.
+type testCollector struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	code    codes.Code
+	exports chan otlpExport
+}
+
+func (c *testCollector) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	c.exports <- otlpExport{md: md, req: req}
+	if c.code != codes.OK {
+		return nil, status.Error(c.code, "export failed")
+	}
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+// startOTLPServer starts a Collector answering all the requests with code,
+// sending them on the returned channel.
+func startOTLPServer(t *testing.T, code codes.Code) (string, <-chan otlpExport) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Listen() =", err)
+	}
+	c := &testCollector{code: code, exports: make(chan otlpExport, 10)}
+	server := grpc.NewServer()
+	colmetricspb.RegisterMetricsServiceServer(server, c)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String(), c.exports
+}
+
+func stringKeyValue(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   k,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}},
+	}
+}
+
+func TestOpenTelemetryExporter(t *testing.T) {
+	address, exports := startOTLPServer(t, codes.OK)
+
+	e, f, err := newOpenTelemetryExporter(&metricsConfig{
+		domain:             "knative.dev/serving",
+		component:          testComponent,
+		backendDestination: openTelemetry,
+		collectorAddress:   address,
+		collectorHeaders:   map[string]string{"api-key": "secret"},
+	}, TestLogger(t))
+	if err != nil {
+		t.Fatal("newOpenTelemetryExporter() =", err)
+	}
+	view.UnregisterExporter(e)
+	defer e.(stoppable).StopMetricsExporter()
+
+	re, err := f(&resource.Resource{Type: "knative_revision", Labels: map[string]string{"namespace_name": "ns"}})
+	if err != nil {
+		t.Fatal("factory() =", err)
+	}
+	if re == e {
+		t.Error("factory() returned the default exporter for a resource")
+	}
+
+	key, err := tag.NewKey("route")
+	if err != nil {
+		t.Fatal("NewKey() =", err)
+	}
+	start, end := time.Unix(1600000000, 0), time.Unix(1600000060, 0)
+	re.ExportView(&view.Data{
+		View: &view.View{
+			Name:        "request_count",
+			Description: "The number of requests",
+			Measure:     stats.Int64("request_count", "Requests", stats.UnitDimensionless),
+			Aggregation: view.Count(),
+		},
+		Start: start,
+		End:   end,
+		Rows: []*view.Row{{
+			Tags: []tag.Tag{{Key: key, Value: "r1"}},
+			Data: &view.CountData{Value: 3},
+		}},
+	})
+	e.(flushable).Flush()
+
+	var got otlpExport
+	select {
+	case got = <-exports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the export")
+	}
+	if h := got.md.Get("api-key"); !cmp.Equal(h, []string{"secret"}) {
+		t.Errorf("api-key header = %v, want [secret]", h)
+	}
+
+	want := &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{
+					stringKeyValue("namespace_name", "ns"),
+					stringKeyValue("opencensus.resourcetype", "knative_revision"),
+					stringKeyValue("service.name", testComponent),
+				},
+			},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope: &commonpb.InstrumentationScope{Name: otlpInstrumentationScope},
+				Metrics: []*metricspb.Metric{{
+					Name:        "knative.dev/serving/" + testComponent + "/request_count",
+					Description: "The number of requests",
+					Unit:        stats.UnitDimensionless,
+					Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+						AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+						IsMonotonic:            true,
+						DataPoints: []*metricspb.NumberDataPoint{{
+							Attributes:        []*commonpb.KeyValue{stringKeyValue("route", "r1")},
+							StartTimeUnixNano: uint64(start.UnixNano()),
+							TimeUnixNano:      uint64(end.UnixNano()),
+							Value:             &metricspb.NumberDataPoint_AsInt{AsInt: 3},
+						}},
+					}},
+				}},
+			}},
+		}},
+	}
+	if !cmp.Equal(got.req, want, protocmp.Transform()) {
+		t.Error("Request (-want, +got):", cmp.Diff(want, got.req, protocmp.Transform()))
+	}
+}
+
+func TestOpenTelemetryMetrics(t *testing.T) {
+	start, end := time.Unix(1600000000, 0), time.Unix(1600000060, 0)
+	measure := stats.Float64("request_latencies", "Latency", stats.UnitMilliseconds)
+	float64Ptr := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name string
+		view *view.View
+		data view.AggregationData
+		want *metricspb.Metric
+	}{{
+		name: "sum",
+		view: &view.View{Name: "request_latencies", Measure: measure, Aggregation: view.Sum()},
+		data: &view.SumData{Value: 2.5},
+		want: &metricspb.Metric{
+			Name: testComponent + "/request_latencies",
+			Unit: stats.UnitMilliseconds,
+			Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints: []*metricspb.NumberDataPoint{{
+					StartTimeUnixNano: uint64(start.UnixNano()),
+					TimeUnixNano:      uint64(end.UnixNano()),
+					Value:             &metricspb.NumberDataPoint_AsDouble{AsDouble: 2.5},
+				}},
+			}},
+		},
+	}, {
+		name: "last value",
+		view: &view.View{Name: "request_latencies", Measure: measure, Aggregation: view.LastValue()},
+		data: &view.LastValueData{Value: 7},
+		want: &metricspb.Metric{
+			Name: testComponent + "/request_latencies",
+			Unit: stats.UnitMilliseconds,
+			Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+				DataPoints: []*metricspb.NumberDataPoint{{
+					TimeUnixNano: uint64(end.UnixNano()),
+					Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: 7},
+				}},
+			}},
+		},
+	}, {
+		name: "distribution",
+		view: &view.View{Name: "request_latencies", Measure: measure, Aggregation: view.Distribution(10, 100)},
+		data: &view.DistributionData{Count: 4, Min: 1, Max: 500, Mean: 130, CountPerBucket: []int64{2, 1, 1}},
+		want: &metricspb.Metric{
+			Name: testComponent + "/request_latencies",
+			Unit: stats.UnitMilliseconds,
+			Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints: []*metricspb.HistogramDataPoint{{
+					StartTimeUnixNano: uint64(start.UnixNano()),
+					TimeUnixNano:      uint64(end.UnixNano()),
+					Count:             4,
+					Sum:               float64Ptr(520),
+					BucketCounts:      []uint64{2, 1, 1},
+					ExplicitBounds:    []float64{10, 100},
+					Min:               float64Ptr(1),
+					Max:               float64Ptr(500),
+				}},
+			}},
+		},
+	}}
+
+	e := &openTelemetryExporter{prefix: testComponent}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := e.toMetric(&view.Data{
+				View:  test.view,
+				Start: start,
+				End:   end,
+				Rows:  []*view.Row{{Data: test.data}},
+			})
+			if !cmp.Equal(got, test.want, protocmp.Transform()) {
+				t.Error("Metric (-want, +got):", cmp.Diff(test.want, got, protocmp.Transform()))
+			}
+		})
+	}
+}
+
+func TestOpenTelemetryExportFailure(t *testing.T) {
+	address, exports := startOTLPServer(t, codes.Unavailable)
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal("Dial() =", err)
+	}
+	defer conn.Close()
+	if err := otlpExporter(conn, nil)(&colmetricspb.ExportMetricsServiceRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("export() = %v, want code %v", err, codes.Unavailable)
+	}
+	if got := <-exports; len(got.md.Get("api-key")) != 0 {
+		t.Errorf("Headers = %v, want none", got.md)
+	}
+}
+
+func TestOpenTelemetryExporterRequiresSecret(t *testing.T) {
+	if _, _, err := newOpenTelemetryExporter(&metricsConfig{
+		component:          testComponent,
+		backendDestination: openTelemetry,
+		requireSecure:      true,
+	}, TestLogger(t)); err == nil {
+		t.Error("newOpenTelemetryExporter() = nil, want an error without a secret")
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{{
+		name: "single",
+		in:   "api-key=secret",
+		want: map[string]string{"api-key": "secret"},
+	}, {
+		name: "multiple with spaces and padding",
+		in:   "api-key=a=b, tenant = knative",
+		want: map[string]string{"api-key": "a=b", "tenant": "knative"},
+	}, {
+		name:    "missing value",
+		in:      "api-key",
+		wantErr: true,
+	}, {
+		name:    "empty key",
+		in:      "=secret",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseHeaders(test.in)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseHeaders() = %v, wantErr %v", err, test.wantErr)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("parseHeaders() (-want, +got) = %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
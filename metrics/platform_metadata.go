@@ -0,0 +1,212 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+const (
+	// platformMetadataProviderKey selects the provider of the platform
+	// metadata the Stackdriver exporter labels the metrics with.
+	platformMetadataProviderKey = "metrics.platform-metadata-provider"
+
+	// The environment variables read by the "env" provider.
+	platformProjectEnvName  = "METRICS_PLATFORM_PROJECT"
+	platformLocationEnvName = "METRICS_PLATFORM_LOCATION"
+	platformClusterEnvName  = "METRICS_PLATFORM_CLUSTER"
+)
+
+// The built-in platform metadata providers.
+const (
+	gcpPlatform   = "gcp"
+	awsPlatform   = "aws"
+	azurePlatform = "azure"
+	envPlatform   = "env"
+)
+
+// PlatformMetadata describes the platform the process runs on. The values
+// which cannot be determined are metricskey.ValueUnknown.
+type PlatformMetadata struct {
+	// Project is the project, account or subscription the node belongs to.
+	Project string
+	// Location is the zone or region of the node.
+	Location string
+	// Cluster is the name of the Kubernetes cluster of the node.
+	Cluster string
+}
+
+// PlatformMetadataProvider looks up the metadata of the platform the process
+// runs on. It is called at most once per process, the result being cached.
+type PlatformMetadataProvider func() PlatformMetadata
+
+var (
+	// azureMetadataEndpoint is the address of the Azure instance metadata service.
+	azureMetadataEndpoint = "http://169.254.169.254"
+
+	platformMetadataMux       sync.Mutex
+	platformMetadataProviders = map[string]PlatformMetadataProvider{
+		gcpPlatform: func() PlatformMetadata {
+			gm := getGCPMetadataFunc()()
+			return PlatformMetadata{Project: gm.project, Location: gm.location, Cluster: gm.cluster}
+		},
+		awsPlatform: func() PlatformMetadata {
+			am := awsMetadataFunc()
+			return PlatformMetadata{Project: metricskey.ValueUnknown, Location: am.region, Cluster: am.cluster}
+		},
+		azurePlatform: retrieveAzureMetadata,
+		envPlatform:   platformMetadataFromEnv,
+	}
+	// platformMetadataCache holds the metadata already looked up, by provider.
+	platformMetadataCache = map[string]PlatformMetadata{}
+)
+
+// RegisterPlatformMetadataProvider makes a custom platform metadata provider
+// available under the given name, which config-observability selects through
+// metrics.platform-metadata-provider. It is meant to be called from init
+// functions, and panics if the name is empty or already registered.
+func RegisterPlatformMetadataProvider(name string, p PlatformMetadataProvider) {
+	name = strings.ToLower(name)
+	if name == "" || p == nil {
+		panic("metrics: RegisterPlatformMetadataProvider needs a name and a provider")
+	}
+
+	platformMetadataMux.Lock()
+	defer platformMetadataMux.Unlock()
+	if _, ok := platformMetadataProviders[name]; ok {
+		panic(fmt.Sprintf("metrics: platform metadata provider %q is already registered", name))
+	}
+	platformMetadataProviders[name] = p
+}
+
+// validatePlatformMetadataProvider checks that a provider is registered
+// under the name.
+func validatePlatformMetadataProvider(name string) error {
+	platformMetadataMux.Lock()
+	defer platformMetadataMux.Unlock()
+	if _, ok := platformMetadataProviders[strings.ToLower(name)]; !ok {
+		return fmt.Errorf("unsupported %s value %q", platformMetadataProviderKey, name)
+	}
+	return nil
+}
+
+// getPlatformMetadata returns the metadata of the named provider, which
+// defaults to GCP, looking it up on first use only.
+func getPlatformMetadata(name string) PlatformMetadata {
+	name = strings.ToLower(name)
+	if name == "" {
+		name = gcpPlatform
+	}
+
+	platformMetadataMux.Lock()
+	defer platformMetadataMux.Unlock()
+	if pm, ok := platformMetadataCache[name]; ok {
+		return pm
+	}
+	p, ok := platformMetadataProviders[name]
+	if !ok {
+		return PlatformMetadata{
+			Project:  metricskey.ValueUnknown,
+			Location: metricskey.ValueUnknown,
+			Cluster:  metricskey.ValueUnknown,
+		}
+	}
+	pm := p()
+	platformMetadataCache[name] = pm
+	return pm
+}
+
+// resetPlatformMetadataCache forgets the metadata looked up so far.
+func resetPlatformMetadataCache() {
+	platformMetadataMux.Lock()
+	defer platformMetadataMux.Unlock()
+	platformMetadataCache = map[string]PlatformMetadata{}
+}
+
+// platformMetadataFromEnv reads the metadata from environment variables,
+// e.g. set by the downward API or the deployment tooling.
+func platformMetadataFromEnv() PlatformMetadata {
+	pm := PlatformMetadata{
+		Project:  metricskey.ValueUnknown,
+		Location: metricskey.ValueUnknown,
+		Cluster:  metricskey.ValueUnknown,
+	}
+	for name, value := range map[string]*string{
+		platformProjectEnvName:  &pm.Project,
+		platformLocationEnvName: &pm.Location,
+		platformClusterEnvName:  &pm.Cluster,
+	} {
+		if v := os.Getenv(name); v != "" {
+			*value = v
+		}
+	}
+	return pm
+}
+
+// retrieveAzureMetadata reads the subscription and location of the node
+// from the Azure instance metadata service. The cluster is derived from the
+// "MC_<group>_<cluster>_<location>" resource group AKS creates for the nodes.
+func retrieveAzureMetadata() PlatformMetadata {
+	pm := PlatformMetadata{
+		Project:  metricskey.ValueUnknown,
+		Location: metricskey.ValueUnknown,
+		Cluster:  metricskey.ValueUnknown,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, azureMetadataEndpoint+"/metadata/instance/compute?api-version=2021-02-01", nil)
+	if err != nil {
+		return pm
+	}
+	req.Header.Set("Metadata", "true")
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Not on Azure, or the metadata service is unreachable.
+		return pm
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return pm
+	}
+
+	var compute struct {
+		SubscriptionID    string `json:"subscriptionId"`
+		Location          string `json:"location"`
+		ResourceGroupName string `json:"resourceGroupName"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&compute); err != nil {
+		return pm
+	}
+	if compute.SubscriptionID != "" {
+		pm.Project = compute.SubscriptionID
+	}
+	if compute.Location != "" {
+		pm.Location = compute.Location
+	}
+	if parts := strings.Split(compute.ResourceGroupName, "_"); len(parts) == 4 && strings.EqualFold(parts[0], "MC") {
+		pm.Cluster = parts[2]
+	}
+	return pm
+}
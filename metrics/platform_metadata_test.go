@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+func TestRetrieveAzureMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Path != "/metadata/instance/compute" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{
+			"subscriptionId": "8d10da13-8125-4ba9-a717-bf7490507b3d",
+			"location": "westeurope",
+			"resourceGroupName": "MC_prod-group_prod_westeurope"
+		}`))
+	}))
+	defer server.Close()
+
+	defer func(endpoint string) {
+		azureMetadataEndpoint = endpoint
+	}(azureMetadataEndpoint)
+	azureMetadataEndpoint = server.URL
+
+	want := PlatformMetadata{
+		Project:  "8d10da13-8125-4ba9-a717-bf7490507b3d",
+		Location: "westeurope",
+		Cluster:  "prod",
+	}
+	if got := retrieveAzureMetadata(); got != want {
+		t.Errorf("retrieveAzureMetadata() = %+v, want %+v", got, want)
+	}
+
+	// Off Azure everything is unknown.
+	server.Close()
+	want = PlatformMetadata{Project: metricskey.ValueUnknown, Location: metricskey.ValueUnknown, Cluster: metricskey.ValueUnknown}
+	if got := retrieveAzureMetadata(); got != want {
+		t.Errorf("retrieveAzureMetadata() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlatformMetadataFromEnv(t *testing.T) {
+	t.Setenv(platformProjectEnvName, "my-project")
+	t.Setenv(platformClusterEnvName, "my-cluster")
+
+	want := PlatformMetadata{Project: "my-project", Location: metricskey.ValueUnknown, Cluster: "my-cluster"}
+	if got := platformMetadataFromEnv(); got != want {
+		t.Errorf("platformMetadataFromEnv() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetPlatformMetadata(t *testing.T) {
+	t.Cleanup(resetPlatformMetadataCache)
+
+	calls := 0
+	RegisterPlatformMetadataProvider("Testing", func() PlatformMetadata {
+		calls++
+		return PlatformMetadata{Project: "project", Location: "location", Cluster: "cluster"}
+	})
+	t.Cleanup(func() {
+		platformMetadataMux.Lock()
+		defer platformMetadataMux.Unlock()
+		delete(platformMetadataProviders, "testing")
+	})
+
+	want := PlatformMetadata{Project: "project", Location: "location", Cluster: "cluster"}
+	for i := 0; i < 2; i++ {
+		if got := getPlatformMetadata("testing"); got != want {
+			t.Errorf("getPlatformMetadata() = %+v, want %+v", got, want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("The provider was called %d times, want once", calls)
+	}
+
+	if err := validatePlatformMetadataProvider("TESTING"); err != nil {
+		t.Error("validatePlatformMetadataProvider() =", err)
+	}
+	if err := validatePlatformMetadataProvider("unknown"); err == nil {
+		t.Error("validatePlatformMetadataProvider() = nil, wanted an error")
+	}
+
+	// GCP is the default.
+	want = PlatformMetadata{Project: testGcpMetadata.project, Location: testGcpMetadata.location, Cluster: testGcpMetadata.cluster}
+	if got := getPlatformMetadata(""); got != want {
+		t.Errorf("getPlatformMetadata() = %+v, want %+v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterPlatformMetadataProvider() did not panic for a registered name")
+		}
+	}()
+	RegisterPlatformMetadataProvider(gcpPlatform, func() PlatformMetadata { return PlatformMetadata{} })
+}
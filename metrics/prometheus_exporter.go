@@ -17,8 +17,10 @@ limitations under the License.
 package metrics
 
 import (
-	"fmt"
+	"crypto/tls"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 
 	prom "contrib.go.opencensus.io/exporter/prometheus"
@@ -42,7 +44,25 @@ func (emptyPromExporter) ExportView(viewData *view.Data) {
 }
 
 func newPrometheusExporter(config *metricsConfig, logger *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
-	e, err := prom.NewExporter(prom.Options{Namespace: config.component})
+	var tlsConfig *tls.Config
+	if config.prometheusTLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.prometheusTLSCertFile, config.prometheusTLSKeyFile)
+		if err != nil {
+			logger.Errorw("Failed to load the Prometheus exporter's TLS certificate.", zap.Error(err))
+			return nil, nil, err
+		}
+		tlsConfig = &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{cert},
+		}
+	}
+	e, err := prom.NewExporter(prom.Options{
+		Namespace: config.component,
+		OnError: func(err error) {
+			recordForBackend(prometheus, exportFailuresM.M(1))
+			logger.Errorw("Failed to collect the metrics for Prometheus", zap.Error(err))
+		},
+	})
 	if err != nil {
 		logger.Errorw("Failed to create the Prometheus exporter.", zap.Error(err))
 		return nil, nil, err
@@ -50,8 +70,12 @@ func newPrometheusExporter(config *metricsConfig, logger *zap.SugaredLogger) (vi
 	logger.Infof("Created Opencensus Prometheus exporter with config: %v. Start the server for Prometheus exporter.", config)
 	// Start the server for Prometheus scraping
 	go func() {
-		srv := startNewPromSrv(e, config.prometheusPort)
-		srv.ListenAndServe()
+		srv := startNewPromSrv(e, config.prometheusHost, config.prometheusPort, tlsConfig)
+		if tlsConfig != nil {
+			srv.ListenAndServeTLS("", "")
+		} else {
+			srv.ListenAndServe()
+		}
 	}()
 	return e,
 		func(r *resource.Resource) (view.Exporter, error) { return &emptyPromExporter{}, nil },
@@ -73,17 +97,22 @@ func resetCurPromSrv() {
 	}
 }
 
-func startNewPromSrv(e *prom.Exporter, port int) *http.Server {
+func startNewPromSrv(e *prom.Exporter, host string, port int, tlsConfig *tls.Config) *http.Server {
 	sm := http.NewServeMux()
-	sm.Handle("/metrics", e)
+	sm.Handle("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every scrape is an attempt to export the metrics.
+		recordExportAttempt(prometheus)
+		e.ServeHTTP(w, r)
+	}))
 	curPromSrvMux.Lock()
 	defer curPromSrvMux.Unlock()
 	if curPromSrv != nil {
 		curPromSrv.Close()
 	}
 	curPromSrv = &http.Server{
-		Addr:    fmt.Sprintf(":%v", port),
-		Handler: sm,
+		Addr:      net.JoinHostPort(host, strconv.Itoa(port)),
+		Handler:   sm,
+		TLSConfig: tlsConfig,
 	}
 	return curPromSrv
 }
@@ -13,9 +13,16 @@ limitations under the License.
 package metrics
 
 import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
+	prom "contrib.go.opencensus.io/exporter/prometheus"
+	"k8s.io/apimachinery/pkg/util/wait"
+
 	. "knative.dev/pkg/logging/testing"
 )
 
@@ -67,3 +74,75 @@ func expectPromSrv(t *testing.T, expectedAddr string) {
 		t.Errorf("metrics port addresses diff, got=%v, want=%v", got, want)
 	}
 }
+
+func TestNewPrometheusExporterTLS(t *testing.T) {
+	defer resetCurPromSrv()
+	config := &metricsConfig{
+		domain:                servingDomain,
+		component:             testComponent,
+		backendDestination:    prometheus,
+		prometheusHost:        "127.0.0.1",
+		prometheusPort:        19093,
+		prometheusTLSCertFile: filepath.Join("testdata", "server-cert.pem"),
+		prometheusTLSKeyFile:  filepath.Join("testdata", "server-key.pem"),
+	}
+	if _, _, err := newPrometheusExporter(config, TestLogger(t)); err != nil {
+		t.Fatal("newPrometheusExporter() =", err)
+	}
+	expectPromSrv(t, "127.0.0.1:19093")
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	var resp *http.Response
+	err := wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+		var err error
+		resp, err = client.Get("https://127.0.0.1:19093/metrics")
+		return err == nil, nil
+	})
+	if err != nil {
+		t.Fatal("Failed to scrape the metrics over HTTPS:", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if resp.TLS == nil {
+		t.Error("The metrics were not served over TLS")
+	}
+}
+
+func TestNewPrometheusExporterBadTLS(t *testing.T) {
+	config := &metricsConfig{
+		domain:                servingDomain,
+		component:             testComponent,
+		backendDestination:    prometheus,
+		prometheusPort:        19094,
+		prometheusTLSCertFile: filepath.Join("testdata", "missing-cert.pem"),
+		prometheusTLSKeyFile:  filepath.Join("testdata", "missing-key.pem"),
+	}
+	if _, _, err := newPrometheusExporter(config, TestLogger(t)); err == nil {
+		t.Error("newPrometheusExporter() = nil, wanted an error")
+	}
+}
+
+func TestPrometheusScrapeRecorded(t *testing.T) {
+	defer resetCurPromSrv()
+	registerExporterViews(TestLogger(t))
+	attempts := exporterMetricCount(t, exportAttemptsM.Name(), prometheus)
+
+	e, err := prom.NewExporter(prom.Options{Namespace: testComponent})
+	if err != nil {
+		t.Fatal("NewExporter() =", err)
+	}
+	srv := startNewPromSrv(e, "", 9092, nil)
+	rec := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Scrape status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if got, want := exporterMetricCount(t, exportAttemptsM.Name(), prometheus)-attempts, int64(1); got != want {
+		t.Errorf("Export attempts = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	prom "contrib.go.opencensus.io/exporter/prometheus"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+)
+
+// pushgatewayInstanceLabel groups the metrics pushed by each replica of the
+// job, when enabled, so that they do not replace each other's.
+const pushgatewayInstanceLabel = "instance"
+
+// pushgatewayExporter pushes the snapshot of the views the Prometheus
+// exporter would serve to a Prometheus Pushgateway, at every reporting
+// period and when flushed, for the components which do not live long
+// enough to be scraped. The metrics are pushed to the group of the job, or
+// to one group per pod when grouping by instance, which is left behind on
+// the Pushgateway when the pod goes away.
+type pushgatewayExporter struct {
+	pusher *push.Pusher
+	logger *zap.SugaredLogger
+
+	pushMux  sync.Mutex
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+}
+
+var _ view.Exporter = (*pushgatewayExporter)(nil)
+var _ flushable = (*pushgatewayExporter)(nil)
+var _ stoppable = (*pushgatewayExporter)(nil)
+
+func newPushgatewayExporter(config *metricsConfig, logger *zap.SugaredLogger) (view.Exporter, ResourceExporterFactory, error) {
+	// The Prometheus exporter registers its collector with the registry,
+	// which is gathered on every push.
+	registry := promclient.NewRegistry()
+	if _, err := prom.NewExporter(prom.Options{Namespace: config.component, Registry: registry}); err != nil {
+		logger.Errorw("Failed to create the Prometheus exporter.", zap.Error(err))
+		return nil, nil, err
+	}
+	pusher := push.New(config.pushgatewayURL, config.pushgatewayJob).Gatherer(registry)
+	if config.pushgatewayGroupByInstance {
+		host, err := os.Hostname()
+		if err != nil {
+			logger.Errorw("Failed to get the instance to group the pushed metrics by.", zap.Error(err))
+			return nil, nil, err
+		}
+		pusher = pusher.Grouping(pushgatewayInstanceLabel, host)
+	}
+	pe := &pushgatewayExporter{
+		pusher: pusher,
+		logger: logger,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go pe.run(config.reportingPeriod)
+	logger.Infof("Created Prometheus Pushgateway exporter for job %q at %q", config.pushgatewayJob, config.pushgatewayURL)
+	return pe,
+		func(r *resource.Resource) (view.Exporter, error) { return &emptyPromExporter{}, nil },
+		nil
+}
+
+// ExportView implements view.Exporter. The views are read on every push, so
+// this is just a signal to enrich the internal Meters with Resource
+// information.
+func (e *pushgatewayExporter) ExportView(*view.Data) {}
+
+// run pushes the metrics at every period, until the exporter is stopped.
+func (e *pushgatewayExporter) run(period time.Duration) {
+	defer close(e.doneCh)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.push()
+		case <-e.stopCh:
+			return
+		}
+	}
+}
+
+func (e *pushgatewayExporter) push() {
+	e.pushMux.Lock()
+	defer e.pushMux.Unlock()
+	recordExportAttempt(prometheusPushgateway)
+	if err := e.pusher.Push(); err != nil {
+		e.logger.Errorw("Failed to push the metrics to the Prometheus Pushgateway.", zap.Error(err))
+		// The metrics are pushed as a whole, so there are no rows to count.
+		recordExportDropped(prometheusPushgateway, 0)
+	}
+}
+
+// Flush implements flushable, pushing the metrics right away.
+func (e *pushgatewayExporter) Flush() {
+	e.push()
+}
+
+// StopMetricsExporter implements stoppable. It stops the periodic pushes,
+// after pushing the metrics one last time.
+func (e *pushgatewayExporter) StopMetricsExporter() {
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+		<-e.doneCh
+		e.push()
+	})
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestPushgatewayExporter(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		pushes []string
+		bodies [][]byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		pushes = append(pushes, r.Method+" "+r.URL.Path)
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	measure := stats.Int64("pushed_events", "Events", stats.UnitDimensionless)
+	v := &view.View{Name: "pushed_events", Measure: measure, Aggregation: view.Count()}
+	if err := view.Register(v); err != nil {
+		t.Fatal("Register() =", err)
+	}
+	defer view.Unregister(v)
+	stats.Record(context.Background(), measure.M(1))
+	// Recording is asynchronous, make sure the measurement is in the view
+	// before pushing it.
+	metricstest.EnsureRecorded()
+
+	e, f, err := newPushgatewayExporter(&metricsConfig{
+		component:          testComponent,
+		backendDestination: prometheusPushgateway,
+		pushgatewayURL:     srv.URL,
+		pushgatewayJob:     "adapter",
+		reportingPeriod:    time.Hour,
+	}, TestLogger(t))
+	if err != nil {
+		t.Fatal("newPushgatewayExporter() =", err)
+	}
+	if _, err := f(nil); err != nil {
+		t.Fatal("factory() =", err)
+	}
+	pe := e.(*pushgatewayExporter)
+
+	pe.Flush()
+	mu.Lock()
+	if len(pushes) != 1 {
+		t.Fatalf("Pushes after Flush() = %v, want one", pushes)
+	}
+	if got, want := pushes[0], "PUT /metrics/job/adapter"; got != want {
+		t.Errorf("Push = %q, want %q", got, want)
+	}
+	if !bytes.Contains(bodies[0], []byte(testComponent+"_pushed_events")) {
+		t.Errorf("Pushed metrics = %q, want %s_pushed_events", bodies[0], testComponent)
+	}
+	mu.Unlock()
+
+	// Stopping the exporter pushes the metrics one last time, only once.
+	pe.StopMetricsExporter()
+	pe.StopMetricsExporter()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pushes) != 2 {
+		t.Errorf("Pushes after StopMetricsExporter() = %v, want two", pushes)
+	}
+}
+
+func TestPushgatewayExporterGroupByInstance(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		pushes []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		pushes = append(pushes, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	e, _, err := newPushgatewayExporter(&metricsConfig{
+		component:                  testComponent,
+		backendDestination:         prometheusPushgateway,
+		pushgatewayURL:             srv.URL,
+		pushgatewayJob:             "adapter",
+		pushgatewayGroupByInstance: true,
+		reportingPeriod:            time.Hour,
+	}, TestLogger(t))
+	if err != nil {
+		t.Fatal("newPushgatewayExporter() =", err)
+	}
+	pe := e.(*pushgatewayExporter)
+	defer pe.StopMetricsExporter()
+
+	pe.Flush()
+	host, _ := os.Hostname()
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := pushes, []string{"PUT /metrics/job/adapter/" + pushgatewayInstanceLabel + "/" + host}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pushes = %v, want %v", got, want)
+	}
+}
+
+func TestPushgatewayConfig(t *testing.T) {
+	ops := ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{
+			BackendDestinationKey: string(prometheusPushgateway),
+		},
+	}
+	if _, err := createMetricsConfig(context.Background(), ops); err == nil {
+		t.Error("createMetricsConfig() = nil, wanted an error without a Pushgateway URL")
+	}
+
+	ops.ConfigMap[pushgatewayURLKey] = "pushgateway.monitoring:9091"
+	mc, err := createMetricsConfig(context.Background(), ops)
+	if err != nil {
+		t.Fatal("createMetricsConfig() =", err)
+	}
+	if got, want := mc.pushgatewayJob, testComponent; got != want {
+		t.Errorf("pushgatewayJob = %q, want %q", got, want)
+	}
+	if got, want := mc.reportingPeriod, 5*time.Second; got != want {
+		t.Errorf("reportingPeriod = %v, want %v", got, want)
+	}
+
+	setCurMetricsConfig(mc)
+	defer setCurMetricsConfig(nil)
+	newConfig := *mc
+	if isNewExporterRequired(&newConfig) {
+		t.Error("isNewExporterRequired() = true, wanted false for the same config")
+	}
+	newConfig.pushgatewayJob = "batch"
+	if !isNewExporterRequired(&newConfig) {
+		t.Error("isNewExporterRequired() = false, wanted true for a new job")
+	}
+	newConfig = *mc
+	newConfig.pushgatewayGroupByInstance = true
+	if !isNewExporterRequired(&newConfig) {
+		t.Error("isNewExporterRequired() = false, wanted true for grouping by instance")
+	}
+
+	ops.ConfigMap[pushgatewayGroupByInstanceKey] = "yes please"
+	if _, err := createMetricsConfig(context.Background(), ops); err == nil {
+		t.Error("createMetricsConfig() = nil, wanted an error for an invalid grouping")
+	}
+	ops.ConfigMap[pushgatewayGroupByInstanceKey] = "true"
+	if mc, err := createMetricsConfig(context.Background(), ops); err != nil {
+		t.Error("createMetricsConfig() =", err)
+	} else if !mc.pushgatewayGroupByInstance {
+		t.Error("pushgatewayGroupByInstance = false, wanted true")
+	}
+
+	ops.ConfigMap[pushgatewayJobKey] = "a/b"
+	if _, err := createMetricsConfig(context.Background(), ops); err == nil {
+		t.Error("createMetricsConfig() = nil, wanted an error for a job with a /")
+	}
+}
@@ -20,20 +20,71 @@ import (
 	"context"
 
 	"go.opencensus.io/stats"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
 )
 
 // TODO should be properly refactored and pieces should move to eventing and serving, as appropriate.
 // 	See https://github.com/knative/pkg/issues/608
 
-// Record stores the given Measurement from `ms` in the current metrics backend.
+// Recorder stores Measurements. The Recorder of the context, if any, is used
+// by Record and RecordBatch in place of the current metrics backend, e.g. to
+// enrich the tags before delegating to BackendRecorder, or to fake the
+// recording in tests.
+type Recorder interface {
+	Record(ctx context.Context, mss []stats.Measurement, ros ...stats.Options) error
+}
+
+// RecorderFunc is an adapter to use a function as a Recorder.
+type RecorderFunc func(ctx context.Context, mss []stats.Measurement, ros ...stats.Options) error
+
+// Record implements Recorder.
+func (f RecorderFunc) Record(ctx context.Context, mss []stats.Measurement, ros ...stats.Options) error {
+	return f(ctx, mss, ros...)
+}
+
+// BackendRecorder stores Measurements in the current metrics backend.
+var BackendRecorder Recorder = backendRecorder{}
+
+type backendRecorder struct{}
+
+// Record implements Recorder.
+func (backendRecorder) Record(ctx context.Context, mss []stats.Measurement, ros ...stats.Options) error {
+	return getCurMetricsConfig().record(ctx, mss, ros...)
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context making Record and RecordBatch use r.
+func WithRecorder(ctx context.Context, r Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// GetRecorder returns the Recorder of the context, BackendRecorder if none.
+func GetRecorder(ctx context.Context) Recorder {
+	if r, ok := ctx.Value(recorderKey{}).(Recorder); ok {
+		return r
+	}
+	return BackendRecorder
+}
+
+// Record stores the given Measurement from `ms` with the Recorder of the context,
+// the current metrics backend by default. Failures are logged with the logger of
+// the context.
 func Record(ctx context.Context, ms stats.Measurement, ros ...stats.Options) {
-	getCurMetricsConfig().record(ctx, []stats.Measurement{ms}, ros...)
+	if err := GetRecorder(ctx).Record(ctx, []stats.Measurement{ms}, ros...); err != nil {
+		logging.FromContext(ctx).Errorw("Failed to record the measurement", zap.String("measure", ms.Measure().Name()), zap.Error(err))
+	}
 }
 
-// RecordBatch stores the given Measurements from `mss` in the current metrics backend.
+// RecordBatch stores the given Measurements from `mss` with the Recorder of the context,
+// the current metrics backend by default. Failures are logged with the logger of
+// the context.
 // All metrics should be reported using the same Resource.
 func RecordBatch(ctx context.Context, mss ...stats.Measurement) {
-	getCurMetricsConfig().record(ctx, mss)
+	if err := GetRecorder(ctx).Record(ctx, mss); err != nil {
+		logging.FromContext(ctx).Errorw("Failed to record the measurements", zap.Error(err))
+	}
 }
 
 // Buckets125 generates an array of buckets with approximate powers-of-two
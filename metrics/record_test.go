@@ -18,10 +18,12 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path"
 	"testing"
 
+	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 
@@ -29,6 +31,9 @@ import (
 	"go.opencensus.io/resource"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 type cases struct {
@@ -112,6 +117,62 @@ func TestRecordBatch(t *testing.T) {
 	metricstest.CheckLastValueData(t, measurement2.Measure().Name(), map[string]string{}, 42)
 }
 
+func TestRecorderInContext(t *testing.T) {
+	measure := stats.Int64("recorded", "Recorded counter", stats.UnitNone)
+	tagKey := tag.MustNewKey("enriched")
+	v := &view.View{
+		Measure:     measure,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{tagKey},
+	}
+	view.Register(v)
+	t.Cleanup(func() { view.Unregister(v) })
+	setCurMetricsConfig(&metricsConfig{})
+
+	// A fake keeps the measurements away from the backend.
+	var got []stats.Measurement
+	ctx := WithRecorder(context.Background(), RecorderFunc(func(_ context.Context, mss []stats.Measurement, _ ...stats.Options) error {
+		got = append(got, mss...)
+		return nil
+	}))
+	Record(ctx, measure.M(1))
+	RecordBatch(ctx, measure.M(2), measure.M(3))
+	if len(got) != 3 || got[0].Value() != 1 || got[2].Value() != 3 {
+		t.Errorf("Recorded %v, want the 3 measurements", got)
+	}
+	metricstest.CheckStatsNotReported(t, "recorded")
+
+	// An enriching Recorder delegates to the backend.
+	ctx = WithRecorder(context.Background(), RecorderFunc(func(ctx context.Context, mss []stats.Measurement, ros ...stats.Options) error {
+		ctx, err := tag.New(ctx, tag.Upsert(tagKey, "yes"))
+		if err != nil {
+			return err
+		}
+		return BackendRecorder.Record(ctx, mss, ros...)
+	}))
+	Record(ctx, measure.M(4))
+	metricstest.CheckLastValueData(t, "recorded", map[string]string{"enriched": "yes"}, 4)
+
+	if r := GetRecorder(context.Background()); r != BackendRecorder {
+		t.Errorf("GetRecorder() = %v, want BackendRecorder", r)
+	}
+}
+
+func TestRecordLogsErrors(t *testing.T) {
+	measure := stats.Int64("failed", "Failed counter", stats.UnitNone)
+	core, logs := observer.New(zap.ErrorLevel)
+	ctx := logging.WithLogger(context.Background(), zap.New(core).Sugar())
+	ctx = WithRecorder(ctx, RecorderFunc(func(context.Context, []stats.Measurement, ...stats.Options) error {
+		return errors.New("boom")
+	}))
+
+	Record(ctx, measure.M(1))
+	RecordBatch(ctx, measure.M(2), measure.M(3))
+	if got := logs.FilterField(zap.Error(errors.New("boom"))).Len(); got != 2 {
+		t.Errorf("Logged %d errors, want 2: %v", got, logs.All())
+	}
+}
+
 func testRecord(t *testing.T, measure *stats.Int64Measure, shouldReportCases []cases) {
 	t.Helper()
 	ctx := context.Background()
@@ -226,3 +287,57 @@ func TestMeter(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordSource(t *testing.T) {
+	defer SetFakeGCPMetadata(FakeGCPMetadata{
+		Project:  "test-project",
+		Location: "us-west1",
+		Cluster:  "test-cluster",
+	})()
+
+	measure := stats.Int64("event_count", "Number of events sent", stats.UnitDimensionless)
+	nameKey := tag.MustNewKey(metricskey.LabelName)
+	resourceGroupKey := tag.MustNewKey(metricskey.LabelResourceGroup)
+	eventTypeKey := tag.MustNewKey(metricskey.LabelEventType)
+	v := &view.View{
+		Measure:     measure,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{NamespaceTagKey, nameKey, resourceGroupKey, eventTypeKey},
+	}
+	RegisterResourceView(v)
+	t.Cleanup(func() { UnregisterResourceView(v) })
+
+	mc := metricsConfig{
+		isStackdriverBackend:        true,
+		stackdriverMetricTypePrefix: "knative.dev/eventing/source",
+	}
+	mc.recorder = sdCustomMetricsRecorder(mc, false)
+	setCurMetricsConfig(&mc)
+	t.Cleanup(func() { setCurMetricsConfig(nil) })
+
+	ctx, err := tag.New(context.Background(),
+		tag.Insert(NamespaceTagKey, "testns"),
+		tag.Insert(nameKey, "testsource"),
+		tag.Insert(resourceGroupKey, "pingsources.sources.knative.dev"),
+		tag.Insert(eventTypeKey, "dev.knative.event"))
+	if err != nil {
+		t.Fatal("tag.New() =", err)
+	}
+	Record(ctx, measure.M(3))
+
+	// The source labels are promoted to a knative_source resource, and only
+	// the remaining tags stay on the metric.
+	meter := meterExporterForResource(&resource.Resource{
+		Type: metricskey.ResourceTypeKnativeSource,
+		Labels: map[string]string{
+			metricskey.LabelProject:       "test-project",
+			metricskey.LabelLocation:      "us-west1",
+			metricskey.LabelClusterName:   "test-cluster",
+			metricskey.LabelNamespaceName: "testns",
+			metricskey.LabelName:          "testsource",
+			metricskey.LabelResourceGroup: "pingsources.sources.knative.dev",
+		},
+	}).m
+	metricstest.CheckLastValueDataWithMeter(t, measure.Name(),
+		map[string]string{metricskey.LabelEventType: "dev.knative.event"}, 3, meter)
+}
@@ -65,6 +65,14 @@ var allMeters = meters{
 // register the view across all Resources tracked by the system, rather than
 // simply the default view.
 func RegisterResourceView(views ...*view.View) error {
+	if err := registerResourceView(views...); err != nil {
+		return err
+	}
+	precreateSDDescriptors(views)
+	return nil
+}
+
+func registerResourceView(views ...*view.View) error {
 	var err error
 	allMeters.lock.Lock()
 	defer allMeters.lock.Unlock()
@@ -80,10 +88,27 @@ func RegisterResourceView(views ...*view.View) error {
 	if err != nil {
 		return err
 	}
-	resourceViews.views = append(resourceViews.views, views...)
+	for _, v := range views {
+		// Registering a view again is a no-op for the meters, since they
+		// accept the same definition under the same name.
+		if !hasView(resourceViews.views, viewName(v)) {
+			resourceViews.views = append(resourceViews.views, v)
+		}
+	}
+	setHashedKeys(resourceViews.views)
 	return nil
 }
 
+// hasView returns whether one of the views has the given name.
+func hasView(views []*view.View, name string) bool {
+	for _, v := range views {
+		if viewName(v) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // UnregisterResourceView is similar to view.Unregiste(), except that it will
 // unregister the view across all Resources tracked byt he system, rather than
 // simply the default view.
@@ -99,11 +124,7 @@ func UnregisterResourceView(views ...*view.View) {
 		// the caller might not have the same view pointer that was registered.
 		// Use Meter.Find() to find the view with a matching name.
 		for _, v := range views {
-			name := v.Name
-			if v.Name == "" {
-				name = v.Measure.Name()
-			}
-			if v := meter.m.Find(name); v != nil {
+			if v := meter.m.Find(viewName(v)); v != nil {
 				meter.m.Unregister(v)
 			}
 		}
@@ -113,7 +134,7 @@ func UnregisterResourceView(views ...*view.View) {
 	for _, view := range resourceViews.views {
 		toRemove := false
 		for _, viewToRemove := range views {
-			if view == viewToRemove {
+			if view == viewToRemove || viewName(view) == viewName(viewToRemove) {
 				toRemove = true
 				break
 			}
@@ -124,6 +145,21 @@ func UnregisterResourceView(views ...*view.View) {
 		}
 	}
 	resourceViews.views = resourceViews.views[:j]
+	setHashedKeys(resourceViews.views)
+
+	componentPrefixes.Lock()
+	defer componentPrefixes.Unlock()
+	for _, v := range views {
+		delete(componentPrefixes.m, viewName(v))
+	}
+}
+
+// viewName is the name of the view, which defaults to the name of its measure.
+func viewName(v *view.View) string {
+	if v.Name == "" {
+		return v.Measure.Name()
+	}
+	return v.Name
 }
 
 func setFactory(f ResourceExporterFactory) error {
@@ -144,6 +180,11 @@ func setFactory(f ResourceExporterFactory) error {
 			retErr = err
 			continue // Keep trying to clean up remaining Meters.
 		}
+		// Flush the old exporter before swapping it out, so the data it
+		// buffered is not lost with it.
+		if meter.e != e {
+			flushGivenExporter(meter.e)
+		}
 		meter.m.UnregisterExporter(meter.e)
 		meter.m.RegisterExporter(e)
 		meter.e = e
@@ -291,12 +332,17 @@ func copyViews(views []*view.View) []*view.View {
 	viewsCopy := make([]*view.View, 0, len(resourceViews.views))
 	for _, v := range views {
 		c := *v
-		c.TagKeys = make([]tag.Key, len(v.TagKeys))
-		copy(c.TagKeys, v.TagKeys)
-		agg := *v.Aggregation
-		c.Aggregation = &agg
-		c.Aggregation.Buckets = make([]float64, len(v.Aggregation.Buckets))
-		copy(c.Aggregation.Buckets, v.Aggregation.Buckets)
+		c.TagKeys = filterTagKeys(v.TagKeys)
+		if v.Aggregation.Type == view.AggTypeDistribution {
+			// A distribution's data is bound to its aggregation, so make a new one.
+			buckets := bucketsFor(v.Measure.Name(), v.Aggregation.Buckets)
+			c.Aggregation = view.Distribution(append([]float64(nil), buckets...)...)
+		} else {
+			agg := *v.Aggregation
+			c.Aggregation = &agg
+			c.Aggregation.Buckets = make([]float64, len(v.Aggregation.Buckets))
+			copy(c.Aggregation.Buckets, v.Aggregation.Buckets)
+		}
 		viewsCopy = append(viewsCopy, &c)
 	}
 	return viewsCopy
@@ -37,6 +37,7 @@ import (
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"go.uber.org/zap"
 
 	emptypb "github.com/golang/protobuf/ptypes/empty"
 	"github.com/google/go-cmp/cmp"
@@ -98,11 +99,12 @@ func TestOptionForResource(t *testing.T) {
 }
 
 type testExporter struct {
-	id string
+	id      string
+	flushes int
 }
 
 func (fe *testExporter) ExportView(vd *view.Data) {}
-func (fe *testExporter) Flush()                   {}
+func (fe *testExporter) Flush()                   { fe.flushes++ }
 func TestSetFactory(t *testing.T) {
 	fakeFactory := func(rr *resource.Resource) (view.Exporter, error) {
 		if rr == nil {
@@ -142,6 +144,17 @@ func TestSetFactory(t *testing.T) {
 	if e.id != "456" {
 		t.Error("Expect id to be 456, instead got", e.id)
 	}
+
+	// Swapping the factory flushes the exporters being replaced.
+	setFactory(func(rr *resource.Resource) (view.Exporter, error) {
+		return &testExporter{id: "new"}, nil
+	})
+	if e.flushes != 1 {
+		t.Errorf("Replaced exporter was flushed %d times, want 1", e.flushes)
+	}
+	if got := meterExporterForResource(&resource456).e.(*testExporter).id; got != "new" {
+		t.Errorf("Exporter id = %q, want %q", got, "new")
+	}
 }
 
 func TestResourceAsString(t *testing.T) {
@@ -205,6 +218,25 @@ func initSdFake(sdFake *stackDriverFake) error {
 	return nil
 }
 
+// withoutExporterMetrics removes the lines of the metrics about exporting
+// from the Prometheus output, which depend on the tests run before.
+func withoutExporterMetrics(body string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(body, "\n") {
+		keep := true
+		for name := range exporterMetricNames {
+			if strings.Contains(line, "_"+name) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
 func sortMetrics() cmp.Option {
 	return cmp.Transformer("Sort", func(in []metricExtract) []string {
 		out := make([]string, 0, len(in))
@@ -321,7 +353,7 @@ testComponent_resource_global_export_count 2
 testComponent_testing_value{project="p1",revision="r1"} 0
 testComponent_testing_value{project="p1",revision="r2"} 1
 `
-			if diff := cmp.Diff(want, string(body)); diff != "" {
+			if diff := cmp.Diff(want, withoutExporterMetrics(string(body))); diff != "" {
 				t.Errorf("Unexpected prometheus output (-want +got):\n%s", diff)
 			}
 		},
@@ -388,6 +420,9 @@ testComponent_testing_value{project="p1",revision="r2"} 1
 			records := []metricExtract{}
 			for record := range sdFake.published {
 				for _, ts := range record.TimeSeries {
+					if strings.HasPrefix(ts.Metric.Type, internalMetricsDomain) {
+						continue
+					}
 					name := ts.Metric.Type[len("custom.googleapis.com/"):]
 					records = append(records, metricExtract{
 						Name:   name,
@@ -555,7 +590,9 @@ func TestStackDriverExports(t *testing.T) {
 			if err := initSdFake(&sdFake); err != nil {
 				t.Error("Init stackdriver failed", err)
 			}
-			if err := UpdateExporter(context.Background(), eo, logtesting.TestLogger(t)); err != nil {
+			// The exporter outlives the test and reports the uploads failing
+			// once the fake is stopped, so it must not log to the test.
+			if err := UpdateExporter(context.Background(), eo, zap.NewNop().Sugar()); err != nil {
 				t.Error("UpdateExporter failed", err)
 			}
 
@@ -590,6 +627,9 @@ func TestStackDriverExports(t *testing.T) {
 				select {
 				case record := <-sdFake.published:
 					for _, ts := range record.TimeSeries {
+						if strings.HasPrefix(ts.Metric.Type, internalMetricsDomain) {
+							continue
+						}
 						extracted := metricExtract{
 							Name:   ts.Metric.Type,
 							Labels: ts.Resource.Labels,
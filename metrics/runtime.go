@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+var (
+	// processStart approximates the time the process started.
+	processStart = time.Now()
+
+	goroutinesM = stats.Int64(
+		"go_goroutines",
+		"The number of goroutines that currently exist.",
+		stats.UnitDimensionless)
+	gcPauseM = stats.Float64(
+		"go_gc_pause_seconds",
+		"The duration of the stop-the-world pauses of the garbage collector.",
+		stats.UnitSeconds)
+	uptimeM = stats.Float64(
+		"process_uptime_seconds",
+		"The time since the process started.",
+		stats.UnitSeconds)
+
+	runtimeViews = []*view.View{{
+		Description: goroutinesM.Description(),
+		Measure:     goroutinesM,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{metricskey.ComponentTagKey},
+	}, {
+		Description: gcPauseM.Description(),
+		Measure:     gcPauseM,
+		Aggregation: view.Distribution(Buckets125(0.00001, 1)...),
+		TagKeys:     []tag.Key{metricskey.ComponentTagKey},
+	}, {
+		Description: uptimeM.Description(),
+		Measure:     uptimeM,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{metricskey.ComponentTagKey},
+	}}
+)
+
+// RegisterRuntimeViews registers the views of the goroutine count, garbage
+// collection pauses and uptime of the process, and records them every
+// period, tagged with the given component, until ctx is done. They are
+// exported like any other metric, through the configured backend. The heap
+// is reported by the MemStatsProvider, see NewMemStatsAll.
+func RegisterRuntimeViews(ctx context.Context, component string, period time.Duration) error {
+	if err := RegisterResourceView(runtimeViews...); err != nil {
+		return err
+	}
+	ctx, err := tag.New(ctx, tag.Upsert(metricskey.ComponentTagKey, component))
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		var rr runtimeRecorder
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			rr.record(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// runtimeRecorder records the runtime metrics, keeping track of the garbage
+// collections whose pauses were recorded.
+type runtimeRecorder struct {
+	numGC uint32
+}
+
+func (rr *runtimeRecorder) record(ctx context.Context) {
+	ms := runtime.MemStats{}
+	runtime.ReadMemStats(&ms)
+
+	mss := []stats.Measurement{
+		goroutinesM.M(int64(runtime.NumGoroutine())),
+		uptimeM.M(time.Since(processStart).Seconds()),
+	}
+	// The runtime keeps the pauses of the last 256 collections.
+	from := rr.numGC
+	if ms.NumGC-from > uint32(len(ms.PauseNs)) {
+		from = ms.NumGC - uint32(len(ms.PauseNs))
+	}
+	for i := from; i < ms.NumGC; i++ {
+		pause := time.Duration(ms.PauseNs[i%uint32(len(ms.PauseNs))])
+		mss = append(mss, gcPauseM.M(pause.Seconds()))
+	}
+	rr.numGC = ms.NumGC
+
+	RecordBatch(ctx, mss...)
+}
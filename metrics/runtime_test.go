@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestRuntimeRecorder(t *testing.T) {
+	InitForTesting()
+	if err := RegisterResourceView(runtimeViews...); err != nil {
+		t.Fatal("RegisterResourceView() =", err)
+	}
+	t.Cleanup(func() { UnregisterResourceView(runtimeViews...) })
+
+	ctx, err := tag.New(context.Background(), tag.Upsert(metricskey.ComponentTagKey, testComponent))
+	if err != nil {
+		t.Fatal("tag.New() =", err)
+	}
+	wantTags := map[string]string{"component": testComponent}
+
+	var rr runtimeRecorder
+	rr.record(ctx)
+	if got := metricstest.GetLastValueData(t, "go_goroutines", wantTags); got < 1 {
+		t.Errorf("go_goroutines = %v, want at least 1", got)
+	}
+	if got := metricstest.GetLastValueData(t, "process_uptime_seconds", wantTags); got <= 0 {
+		t.Errorf("process_uptime_seconds = %v, want a positive value", got)
+	}
+	before := gcPauses(t)
+
+	// Only the pauses of the collections since the last recording are recorded.
+	runtime.GC()
+	runtime.GC()
+	rr.record(ctx)
+	if got, want := gcPauses(t)-before, int64(2); got < want {
+		t.Errorf("Recorded %d pauses, want at least %d", got, want)
+	}
+	before = gcPauses(t)
+	rr.record(ctx)
+	// A collection may have happened meanwhile.
+	if got := gcPauses(t) - before; got > 1 {
+		t.Errorf("Recorded %d pauses again, want the new ones only", got)
+	}
+}
+
+func gcPauses(t *testing.T) int64 {
+	t.Helper()
+	rows, err := view.RetrieveData("go_gc_pause_seconds")
+	if err != nil {
+		t.Fatal("RetrieveData() =", err)
+	}
+	if len(rows) == 0 {
+		return 0
+	}
+	return rows[0].Data.(*view.DistributionData).Count
+}
+
+func TestRegisterRuntimeViews(t *testing.T) {
+	InitForTesting()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := RegisterRuntimeViews(ctx, testComponent, 10*time.Millisecond); err != nil {
+		t.Fatal("RegisterRuntimeViews() =", err)
+	}
+	t.Cleanup(func() { UnregisterResourceView(runtimeViews...) })
+
+	// The metrics are recorded right away, tagged with the component.
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if rows, err := view.RetrieveData("process_uptime_seconds"); err == nil && len(rows) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	metricstest.CheckStatsReported(t, "go_goroutines", "process_uptime_seconds")
+	if got := metricstest.GetLastValueData(t, "go_goroutines", map[string]string{"component": testComponent}); got < 1 {
+		t.Errorf("go_goroutines = %v, want at least 1", got)
+	}
+}
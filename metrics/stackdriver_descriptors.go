@@ -0,0 +1,158 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricexport"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// stackdriverPrecreateDescriptorsKey enables creating the metric descriptors
+// of the registered views when the Stackdriver exporter starts, rather than
+// on the first write of each metric.
+const stackdriverPrecreateDescriptorsKey = "metrics.stackdriver-precreate-descriptors"
+
+// precreateDescriptors creates the descriptors of the views which were not
+// created by the exporter yet. The Stackdriver exporter creates the
+// descriptor of a metric before writing its time series, and caches that it
+// did, so it is given the metrics of the views without time series and
+// flushed right away. The exporter uploads in the background and reports
+// failures through its OnError hook only, so the descriptors are remembered
+// as created only when no error was reported during the flush; otherwise
+// they are tried again the next time.
+func (e *pollOnlySDExporter) precreateDescriptors(views []*view.View) {
+	me, ok := e.internalExporter.(metricexport.Exporter)
+	if !ok {
+		return
+	}
+
+	e.descriptorsMux.Lock()
+	defer e.descriptorsMux.Unlock()
+	if e.descriptors == nil {
+		e.descriptors = sets.NewString()
+	}
+	ms := make([]*metricdata.Metric, 0, len(views))
+	for _, v := range views {
+		m := descriptorMetric(v)
+		if m == nil || e.descriptors.Has(m.Descriptor.Name) {
+			continue
+		}
+		ms = append(ms, m)
+	}
+	if len(ms) == 0 {
+		return
+	}
+	errs := atomic.LoadUint64(&e.exportErrors)
+	if err := me.ExportMetrics(context.Background(), ms); err != nil {
+		if e.logger != nil {
+			e.logger.Warnw("Failed to create the Stackdriver metric descriptors", zap.Error(err))
+		}
+		return
+	}
+	if f, ok := e.internalExporter.(flushable); ok {
+		f.Flush()
+	}
+	if atomic.LoadUint64(&e.exportErrors) != errs {
+		// The error may be about other metrics exported meanwhile, but
+		// creating the descriptors again is harmless.
+		if e.logger != nil {
+			e.logger.Warn("Could not confirm the Stackdriver metric descriptors were created, they will be created again")
+		}
+		return
+	}
+	for _, m := range ms {
+		e.descriptors.Insert(m.Descriptor.Name)
+	}
+}
+
+// precreateSDDescriptors creates the descriptors of the views newly
+// registered with the current Stackdriver exporter, if it precreates them.
+// Views are usually registered from init functions, so the descriptors are
+// created in the background.
+func precreateSDDescriptors(views []*view.View) {
+	if e, ok := getCurMetricsExporter().(*pollOnlySDExporter); ok && e.precreate {
+		go e.precreateDescriptors(views)
+	}
+}
+
+// registeredViews returns the views registered by RegisterResourceView.
+func registeredViews() []*view.View {
+	resourceViews.lock.Lock()
+	defer resourceViews.lock.Unlock()
+	return append([]*view.View(nil), resourceViews.views...)
+}
+
+// descriptorMetric returns a metric without time series, with the
+// descriptor OpenCensus gives to the metrics read from the view, or nil if
+// the view cannot be read as a metric.
+func descriptorMetric(v *view.View) *metricdata.Metric {
+	if v.Measure == nil || v.Aggregation == nil {
+		return nil
+	}
+	name := viewName(v)
+	_, isInt := v.Measure.(*stats.Int64Measure)
+
+	var typ metricdata.Type
+	unit := metricdata.UnitDimensionless
+	switch v.Aggregation.Type {
+	case view.AggTypeCount:
+		typ = metricdata.TypeCumulativeInt64
+	case view.AggTypeSum:
+		typ = metricdata.TypeCumulativeFloat64
+		if isInt {
+			typ = metricdata.TypeCumulativeInt64
+		}
+	case view.AggTypeDistribution:
+		typ = metricdata.TypeCumulativeDistribution
+	case view.AggTypeLastValue:
+		typ = metricdata.TypeGaugeFloat64
+		if isInt {
+			typ = metricdata.TypeGaugeInt64
+		}
+	default:
+		return nil
+	}
+	if v.Aggregation.Type != view.AggTypeCount {
+		switch v.Measure.Unit() {
+		case stats.UnitMilliseconds:
+			unit = metricdata.UnitMilliseconds
+		case stats.UnitBytes:
+			unit = metricdata.UnitBytes
+		}
+	}
+
+	labelKeys := make([]metricdata.LabelKey, 0, len(v.TagKeys))
+	for _, k := range v.TagKeys {
+		labelKeys = append(labelKeys, metricdata.LabelKey{Key: k.Name()})
+	}
+	return &metricdata.Metric{
+		Descriptor: metricdata.Descriptor{
+			Name:        name,
+			Description: v.Description,
+			Unit:        unit,
+			Type:        typ,
+			LabelKeys:   labelKeys,
+		},
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricexport"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// descriptorExporter records the names of the metrics it exports, and
+// whether it was flushed since. When flushed, it reports failures through
+// onError, if set.
+type descriptorExporter struct {
+	mu      sync.Mutex
+	names   []string
+	flushed bool
+	onError func(error)
+}
+
+func (e *descriptorExporter) ExportView(*view.Data) {}
+
+func (e *descriptorExporter) ExportMetrics(_ context.Context, metrics []*metricdata.Metric) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, m := range metrics {
+		e.names = append(e.names, m.Descriptor.Name)
+	}
+	e.flushed = false
+	return nil
+}
+
+func (e *descriptorExporter) Flush() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.flushed = true
+	if e.onError != nil {
+		e.onError(errors.New("descriptor creation failed"))
+	}
+}
+
+func (e *descriptorExporter) exported() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.names...)
+}
+
+func TestDescriptorMetric(t *testing.T) {
+	intMeasure := stats.Int64("precreated_requests", "Requests", stats.UnitDimensionless)
+	floatMeasure := stats.Float64("precreated_latency", "Latency", stats.UnitMilliseconds)
+	key := tag.MustNewKey("response_code")
+
+	tests := []struct {
+		name string
+		view *view.View
+		want metricdata.Descriptor
+	}{{
+		name: "count",
+		view: &view.View{Name: "request_count", Description: "Count", Measure: floatMeasure, Aggregation: view.Count(), TagKeys: []tag.Key{key}},
+		want: metricdata.Descriptor{
+			Name:        "request_count",
+			Description: "Count",
+			Unit:        metricdata.UnitDimensionless,
+			Type:        metricdata.TypeCumulativeInt64,
+			LabelKeys:   []metricdata.LabelKey{{Key: "response_code"}},
+		},
+	}, {
+		name: "sum named after the measure",
+		view: &view.View{Measure: intMeasure, Aggregation: view.Sum()},
+		want: metricdata.Descriptor{
+			Name:      "precreated_requests",
+			Unit:      metricdata.UnitDimensionless,
+			Type:      metricdata.TypeCumulativeInt64,
+			LabelKeys: []metricdata.LabelKey{},
+		},
+	}, {
+		name: "distribution",
+		view: &view.View{Name: "request_latencies", Measure: floatMeasure, Aggregation: view.Distribution(1, 10)},
+		want: metricdata.Descriptor{
+			Name:      "request_latencies",
+			Unit:      metricdata.UnitMilliseconds,
+			Type:      metricdata.TypeCumulativeDistribution,
+			LabelKeys: []metricdata.LabelKey{},
+		},
+	}, {
+		name: "last value",
+		view: &view.View{Name: "latency", Measure: floatMeasure, Aggregation: view.LastValue()},
+		want: metricdata.Descriptor{
+			Name:      "latency",
+			Unit:      metricdata.UnitMilliseconds,
+			Type:      metricdata.TypeGaugeFloat64,
+			LabelKeys: []metricdata.LabelKey{},
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := descriptorMetric(test.view)
+			if m == nil {
+				t.Fatal("descriptorMetric() = nil")
+			}
+			if len(m.TimeSeries) != 0 {
+				t.Errorf("TimeSeries = %v, want none", m.TimeSeries)
+			}
+			if diff := cmp.Diff(test.want, m.Descriptor); diff != "" {
+				t.Error("Descriptor (-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestPrecreateDescriptors(t *testing.T) {
+	measure := stats.Int64("precreated_requests", "Requests", stats.UnitDimensionless)
+	countView := &view.View{Name: "request_count", Measure: measure, Aggregation: view.Count()}
+	sumView := &view.View{Name: "request_sum", Measure: measure, Aggregation: view.Sum()}
+	lastView := &view.View{Name: "request_last", Measure: measure, Aggregation: view.LastValue()}
+
+	de := &descriptorExporter{}
+	e := &pollOnlySDExporter{internalExporter: de, precreate: true}
+
+	e.precreateDescriptors([]*view.View{countView, sumView})
+	if got, want := de.names, []string{"request_count", "request_sum"}; !cmp.Equal(got, want) {
+		t.Errorf("Created descriptors = %v, want %v", got, want)
+	}
+	if !de.flushed {
+		t.Error("The exporter was not flushed after creating the descriptors")
+	}
+
+	// The descriptors already created are not created again.
+	e.precreateDescriptors([]*view.View{sumView, lastView})
+	if got, want := de.names, []string{"request_count", "request_sum", "request_last"}; !cmp.Equal(got, want) {
+		t.Errorf("Created descriptors = %v, want %v", got, want)
+	}
+}
+
+func TestPrecreateDescriptorsUnconfirmed(t *testing.T) {
+	measure := stats.Int64("precreated_requests", "Requests", stats.UnitDimensionless)
+	countView := &view.View{Name: "request_count", Measure: measure, Aggregation: view.Count()}
+
+	de := &descriptorExporter{}
+	e := &pollOnlySDExporter{internalExporter: de, precreate: true}
+	de.onError = e.onError
+
+	// The exporter reported an error during the flush, so the descriptor
+	// is created again the next time.
+	e.precreateDescriptors([]*view.View{countView})
+	de.onError = nil
+	e.precreateDescriptors([]*view.View{countView})
+	e.precreateDescriptors([]*view.View{countView})
+	if got, want := de.names, []string{"request_count", "request_count"}; !cmp.Equal(got, want) {
+		t.Errorf("Created descriptors = %v, want %v", got, want)
+	}
+}
+
+func TestPrecreateSDDescriptorsInBackground(t *testing.T) {
+	measure := stats.Int64("precreated_requests", "Requests", stats.UnitDimensionless)
+	countView := &view.View{Name: "request_count", Measure: measure, Aggregation: view.Count()}
+
+	de := &descriptorExporter{}
+	setCurMetricsExporter(&pollOnlySDExporter{internalExporter: de, precreate: true})
+	defer setCurMetricsExporter(nil)
+
+	precreateSDDescriptors([]*view.View{countView})
+	if err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return len(de.exported()) == 1, nil
+	}); err != nil {
+		t.Errorf("Created descriptors = %v, want [request_count]", de.exported())
+	}
+}
+
+func TestSDProjectRouterRoutesDescriptorsToAllProjects(t *testing.T) {
+	a, b := &fakeProjectExporter{}, &fakeProjectExporter{}
+	r := &sdProjectRouter{
+		defaultExporter: a,
+		byNamespace:     map[string]metricexport.Exporter{"tenant": b},
+		exporters:       []metricexport.Exporter{a, b},
+	}
+	m := descriptorMetric(&view.View{Name: "request_count", Measure: stats.Int64("requests", "", ""), Aggregation: view.Count()})
+	routed := r.route(m)
+	if len(routed) != 2 || routed[a] != m || routed[b] != m {
+		t.Errorf("route() = %v, want the metric for both projects", routed)
+	}
+}
@@ -21,17 +21,21 @@ import (
 	"fmt"
 	"path"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	sd "contrib.go.opencensus.io/exporter/stackdriver"
+	"go.opencensus.io/metric/metricexport"
 	"go.opencensus.io/resource"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	"go.uber.org/zap"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/system"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,6 +45,9 @@ import (
 
 const (
 	customMetricTypePrefix = "custom.googleapis.com"
+	// externalMetricTypePrefix is the other domain Stackdriver accepts
+	// user-defined metrics under.
+	externalMetricTypePrefix = "external.googleapis.com"
 	// defaultCustomMetricSubDomain is the default subdomain to use for unsupported metrics by monitored resource types.
 	// See: https://cloud.google.com/monitoring/api/ref_v3/rest/v3/projects.metricDescriptors#MetricDescriptor
 	defaultCustomMetricSubDomain = "knative.dev"
@@ -58,8 +65,10 @@ var (
 	// gcpMetadataFunc is the function used to fetch GCP metadata.
 	// In product usage, this is always set to function retrieveGCPMetadata.
 	// In unit tests this is set to a fake one to avoid calling GCP metadata
-	// service.
+	// service. It is guarded by gcpMetadataMux, since the exporters may look
+	// the metadata up concurrently with tests swapping the function.
 	gcpMetadataFunc func() *gcpMetadata
+	gcpMetadataMux  sync.RWMutex
 
 	// newStackdriverExporterFunc is the function used to create new stackdriver
 	// exporter.
@@ -85,20 +94,11 @@ var (
 	// Consuming packages must do explicitly enable this by calling SetStackdriverSecretLocation.
 	useStackdriverSecretEnabled = false
 
-	// metricToResourceLabels provides a quick lookup from a custom metric name to the set of tags
-	// which should be promoted to Stackdriver Resource labels via opencensus resources.
-	metricToResourceLabels = map[string]*resourceTemplate{}
-
 	// A variable for testing to reduce the size (number of metrics) buffered before
 	// Stackdriver will send a bundled metric report. Only applies if non-zero.
 	TestOverrideBundleCount = 0
 )
 
-type resourceTemplate struct {
-	Type      string
-	LabelKeys sets.String
-}
-
 // SetStackdriverSecretLocation sets the name and namespace of the Secret that can be used to authenticate with Stackdriver.
 // The Secret is only used if both:
 // 1. This function has been explicitly called to set the name and namespace
@@ -111,37 +111,61 @@ func SetStackdriverSecretLocation(name string, namespace string) {
 	useStackdriverSecretEnabled = true
 }
 
+// getGCPMetadataFunc returns the function used to fetch GCP metadata.
+func getGCPMetadataFunc() func() *gcpMetadata {
+	gcpMetadataMux.RLock()
+	defer gcpMetadataMux.RUnlock()
+	return gcpMetadataFunc
+}
+
+// setGCPMetadataFunc sets the function used to fetch GCP metadata, and
+// returns the previous one.
+func setGCPMetadataFunc(f func() *gcpMetadata) func() *gcpMetadata {
+	gcpMetadataMux.Lock()
+	defer gcpMetadataMux.Unlock()
+	prev := gcpMetadataFunc
+	gcpMetadataFunc = f
+	return prev
+}
+
 func init() {
 	// Set gcpMetadataFunc to call GCP metadata service.
 	gcpMetadataFunc = retrieveGCPMetadata
 	newStackdriverExporterFunc = newOpencensusSDExporter
 
 	kubeclientInitErr = nil
-
-	metricsToTemplates := []struct {
-		metrics  sets.String
-		template resourceTemplate
-	}{
-		{metricskey.KnativeRevisionMetrics, resourceTemplate{metricskey.ResourceTypeKnativeRevision, metricskey.KnativeRevisionLabels}},
-		{metricskey.KnativeTriggerMetrics, resourceTemplate{metricskey.ResourceTypeKnativeTrigger, metricskey.KnativeTriggerLabels}},
-		{metricskey.KnativeBrokerMetrics, resourceTemplate{metricskey.ResourceTypeKnativeBroker, metricskey.KnativeBrokerLabels}},
-		{metricskey.KnativeSourceMetrics, resourceTemplate{metricskey.ResourceTypeKnativeSource, metricskey.KnativeSourceLabels}},
-	}
-
-	for _, item := range metricsToTemplates {
-		t := item.template
-		for k := range item.metrics {
-			metricToResourceLabels[k] = &t
-		}
-	}
 }
 
 type pollOnlySDExporter struct {
 	internalExporter view.Exporter
+	retries          *sdRetryQueue
+	logger           *zap.SugaredLogger
+
+	// precreate is true when the metric descriptors of the views are
+	// created as soon as the views are registered, see precreateDescriptors.
+	precreate bool
+	// descriptorsMux serializes the precreation of the descriptors.
+	descriptorsMux sync.Mutex
+	// descriptors are the names of the metrics whose descriptors were
+	// created by precreateDescriptors.
+	descriptors sets.String
+	// exportErrors counts the errors the Stackdriver exporter reported
+	// through its OnError hook.
+	exportErrors uint64
+}
+
+// onError is the OnError hook of the Stackdriver exporter, which reports
+// the errors of the uploads it runs in the background.
+func (e *pollOnlySDExporter) onError(err error) {
+	atomic.AddUint64(&e.exportErrors, 1)
+	if e.logger != nil {
+		e.logger.Errorw("Failed to export to Stackdriver", zap.Error(err))
+	}
 }
 
 var _ (view.Exporter) = (*pollOnlySDExporter)(nil)
 var _ (flushable) = (*pollOnlySDExporter)(nil)
+var _ (drainable) = (*pollOnlySDExporter)(nil)
 
 func (e *pollOnlySDExporter) ExportView(viewData *view.Data) {
 	// Stackdriver will run an internal loop to bundle stats, so ignore this.
@@ -153,6 +177,16 @@ func (e *pollOnlySDExporter) Flush() {
 			f.Flush()
 		}
 	}
+	if e.retries != nil {
+		e.retries.Flush()
+	}
+}
+
+func (e *pollOnlySDExporter) Drain() {
+	// Read the metrics the internal loop did not get to yet.
+	if me, ok := e.internalExporter.(metricexport.Exporter); ok {
+		metricexport.NewReader().ReadAndExport(me)
+	}
 }
 
 func (e *pollOnlySDExporter) StopMetricsExporter() {
@@ -161,6 +195,9 @@ func (e *pollOnlySDExporter) StopMetricsExporter() {
 			f.StopMetricsExporter()
 		}
 	}
+	if e.retries != nil {
+		e.retries.Stop()
+	}
 }
 
 func newOpencensusSDExporter(o sd.Options) (view.Exporter, error) {
@@ -183,9 +220,18 @@ func newStackdriverExporter(config *metricsConfig, logger *zap.SugaredLogger) (v
 	if err != nil {
 		logger.Warnw("Issue configuring Stackdriver exporter client options, no additional client options will be used: ", zap.Error(err))
 	}
-
+	// Buffer the writes failing while Stackdriver is unreachable, to retry them.
+	retries := newSDRetryQueue(logger)
+	retries.start()
+	co = append(co, option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(retries.intercept)))
+
+	pe := &pollOnlySDExporter{
+		retries:   retries,
+		logger:    logger,
+		precreate: config.stackdriverPrecreateDescriptors,
+	}
 	// Automatically fall back on Google application default credentials
-	e, err := newStackdriverExporterFunc(sd.Options{
+	o := sd.Options{
 		ProjectID:               gm.project,
 		Location:                gm.location,
 		MonitoringClientOptions: co,
@@ -195,14 +241,28 @@ func newStackdriverExporter(config *metricsConfig, logger *zap.SugaredLogger) (v
 		DefaultMonitoringLabels: &sd.Labels{},
 		Timeout:                 stackdriverAPITimeout,
 		BundleCountThreshold:    TestOverrideBundleCount,
-	})
+		OnError:                 pe.onError,
+	}
+	var e view.Exporter
+	if len(config.stackdriverProjects) > 0 {
+		e, err = newSDProjectRouter(o, config.stackdriverProjects)
+	} else {
+		e, err = newStackdriverExporterFunc(o)
+	}
 	if err != nil {
 		logger.Errorw("Failed to create the Stackdriver exporter: ", zap.Error(err))
+		retries.Stop()
 		return nil, nil, err
 	}
 	logger.Info("Created Opencensus Stackdriver exporter with config ", config)
+	pe.internalExporter = e
+	if pe.precreate {
+		// This runs while the exporter is being swapped, so the descriptors
+		// are created in the background to not block recording.
+		go pe.precreateDescriptors(registeredViews())
+	}
 	// We have to return a ResourceExporterFactory here to enable tracking resources, even though we always poll for them.
-	return &pollOnlySDExporter{e},
+	return pe,
 		func(r *resource.Resource) (view.Exporter, error) { return &pollOnlySDExporter{}, nil },
 		nil
 }
@@ -217,16 +277,19 @@ func sdCustomMetricsRecorder(mc metricsConfig, allowCustomMetrics bool) func(con
 	return func(ctx context.Context, mss []stats.Measurement, ros ...stats.Options) error {
 		// Some metrics may be promoted to known Stackdriver schemas, so we may
 		// end up multiple Resources recorded for a single `RecordBatch` call.
-		metricsByResource := map[*resourceTemplate][]stats.Measurement{}
+		// They are grouped by resource type, empty for the custom metrics.
+		metricsByResource := map[string][]stats.Measurement{}
+		mappings := map[string]metricskey.ResourceMapping{}
 
 		for _, m := range mss {
-			metricType := path.Join(mc.stackdriverMetricTypePrefix, m.Measure().Name())
-			t, ok := metricToResourceLabels[metricType]
+			metricType := path.Join(componentMetricTypePrefix(m.Measure().Name(), mc.stackdriverMetricTypePrefix), m.Measure().Name())
+			mapping, ok := metricskey.GetResourceMapping(metricType)
 			if ok || allowCustomMetrics {
-				if metricsByResource[t] == nil {
-					metricsByResource[t] = make([]stats.Measurement, 0, len(mss))
+				if metricsByResource[mapping.Type] == nil {
+					metricsByResource[mapping.Type] = make([]stats.Measurement, 0, len(mss))
 				}
-				metricsByResource[t] = append(metricsByResource[t], m)
+				metricsByResource[mapping.Type] = append(metricsByResource[mapping.Type], m)
+				mappings[mapping.Type] = mapping
 			}
 		}
 
@@ -236,16 +299,17 @@ func sdCustomMetricsRecorder(mc metricsConfig, allowCustomMetrics bool) func(con
 			baseLabels = baseResource.Labels
 		}
 		tagMap := tag.FromContext(ctx)
-		for templ, ms := range metricsByResource {
+		for resourceType, ms := range metricsByResource {
 			sdResource := baseResource
 			sdCtx := ctx
-			if templ != nil {
+			if resourceType != "" {
+				mapping := mappings[resourceType]
 				sdResource = &resource.Resource{
-					Type:   templ.Type,
+					Type:   resourceType,
 					Labels: map[string]string{},
 				}
-				tagMutations := make([]tag.Mutator, 0, len(templ.LabelKeys))
-				for k := range templ.LabelKeys {
+				tagMutations := make([]tag.Mutator, 0, len(mapping.Labels))
+				for k := range mapping.Labels {
 					if v, ok := baseLabels[k]; ok {
 						sdResource.Labels[k] = v
 						continue
@@ -300,14 +364,23 @@ func getStackdriverExporterClientOptions(config *metricsConfig) ([]option.Client
 			return co, err
 		}
 	}
+	if config.stackdriverClientConfig.CredentialsFile != "" {
+		co = append(co, option.WithCredentialsFile(config.stackdriverClientConfig.CredentialsFile))
+	}
 	return co, nil
 }
 
 // getMergedGCPMetadata returns GCP metadata required to export metrics
-// to Stackdriver. Values can come from the GCE metadata server or the config.
+// to Stackdriver. Values can come from the platform metadata provider, the
+// GCE metadata server by default, or the config.
 //  Values explicitly set in the config take the highest precedent.
 func getMergedGCPMetadata(config *metricsConfig) *gcpMetadata {
-	gm := gcpMetadataFunc()
+	pm := getPlatformMetadata(config.platformMetadataProvider)
+	gm := &gcpMetadata{
+		project:  pm.Project,
+		location: pm.Location,
+		cluster:  pm.Cluster,
+	}
 	if config.stackdriverClientConfig.ProjectID != "" {
 		gm.project = config.stackdriverClientConfig.ProjectID
 	}
@@ -325,45 +398,51 @@ func getMergedGCPMetadata(config *metricsConfig) *gcpMetadata {
 
 func getMetricPrefixFunc(metricTypePrefix, customMetricTypePrefix string) func(name string) string {
 	return func(name string) string {
-		metricType := path.Join(metricTypePrefix, name)
-		inServing := metricskey.KnativeRevisionMetrics.Has(metricType)
-		inEventing := metricskey.KnativeBrokerMetrics.Has(metricType) ||
-			metricskey.KnativeTriggerMetrics.Has(metricType) ||
-			metricskey.KnativeSourceMetrics.Has(metricType)
-		if inServing || inEventing {
-			return metricTypePrefix
+		if exporterMetricNames.Has(name) {
+			return internalMetricsDomain
 		}
-		// Unsupported metric by knative_revision, use custom domain.
+		prefix := componentMetricTypePrefix(name, metricTypePrefix)
+		if _, ok := metricskey.GetResourceMapping(path.Join(prefix, name)); ok {
+			return prefix
+		}
+		// Not promoted to a monitored resource, use custom domain.
 		return customMetricTypePrefix
 	}
 }
 
 // getStackdriverSecret returns the Kubernetes Secret specified in the given config.
-// SetStackdriverSecretLocation must have been called by calling package for this to work.
+// Unless the config names the Secret, SetStackdriverSecretLocation must have been
+// called by calling package for this to work.
 // TODO(anniefu): Update exporter if Secret changes (https://github.com/knative/pkg/issues/842)
-func getStackdriverSecret(ctx context.Context, secretFetcher SecretFetcher) (*corev1.Secret, error) {
+func getStackdriverSecret(ctx context.Context, scc *StackdriverClientConfig, secretFetcher SecretFetcher) (*corev1.Secret, error) {
 	stackdriverMtx.RLock()
 	defer stackdriverMtx.RUnlock()
 
-	if !useStackdriverSecretEnabled {
+	name, namespace := secretName, secretNamespace
+	if scc.SecretName != "" {
+		name, namespace = scc.SecretName, scc.SecretNamespace
+		if namespace == "" {
+			namespace = system.Namespace()
+		}
+	} else if !useStackdriverSecretEnabled {
 		return nil, nil
 	}
 
 	var secErr error
 	var sec *corev1.Secret
 	if secretFetcher != nil {
-		sec, secErr = secretFetcher(fmt.Sprintf("%s/%s", secretNamespace, secretName))
+		sec, secErr = secretFetcher(fmt.Sprintf("%s/%s", namespace, name))
 	} else {
 		// This else-block can be removed once UpdateExporterFromConfigMap is fully deprecated in favor of ConfigMapWatcher
 		if err := ensureKubeclient(); err != nil {
 			return nil, err
 		}
 
-		sec, secErr = kubeclient.CoreV1().Secrets(secretNamespace).Get(ctx, secretName, metav1.GetOptions{})
+		sec, secErr = kubeclient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
 	}
 
 	if secErr != nil {
-		return nil, fmt.Errorf("error getting Secret [%v] in namespace [%v]: %v", secretName, secretNamespace, secErr)
+		return nil, fmt.Errorf("error getting Secret [%v] in namespace [%v]: %v", name, namespace, secErr)
 	}
 
 	return sec, nil
@@ -34,6 +34,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	. "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/system"
 )
 
 // TODO UTs should move to eventing and serving, as appropriate.
@@ -78,7 +79,13 @@ type metricExtractor struct {
 }
 
 func (me *metricExtractor) ExportMetrics(ctx context.Context, data []*metricdata.Metric) error {
-	me.data = data
+	// The metrics about exporting depend on the tests run before.
+	me.data = make([]*metricdata.Metric, 0, len(data))
+	for _, m := range data {
+		if !exporterMetricNames.Has(m.Descriptor.Name) {
+			me.data = append(me.data, m)
+		}
+	}
 	return nil
 }
 
@@ -523,7 +530,7 @@ func TestSetStackdriverSecretLocation(t *testing.T) {
 	// Sanity checks
 	assertStringsEqual(t, "DefaultSecretName", secretName, StackdriverSecretNameDefault)
 	assertStringsEqual(t, "DefaultSecretNamespace", secretNamespace, StackdriverSecretNamespaceDefault)
-	sec, err := getStackdriverSecret(ctx, secretFetcher)
+	sec, err := getStackdriverSecret(ctx, &StackdriverClientConfig{}, secretFetcher)
 	if err != nil {
 		t.Error("Got unexpected error when getting secret:", err)
 	}
@@ -533,7 +540,7 @@ func TestSetStackdriverSecretLocation(t *testing.T) {
 
 	// Once SetStackdriverSecretLocation has been called, attempts to get the secret should complete.
 	SetStackdriverSecretLocation(testName, testNamespace)
-	sec, err = getStackdriverSecret(ctx, secretFetcher)
+	sec, err = getStackdriverSecret(ctx, &StackdriverClientConfig{}, secretFetcher)
 	if err != nil {
 		t.Error("Got unexpected error when getting secret:", err)
 	}
@@ -543,3 +550,84 @@ func TestSetStackdriverSecretLocation(t *testing.T) {
 	assertStringsEqual(t, "secretName", secretName, testName)
 	assertStringsEqual(t, "secretNamespace", secretNamespace, testNamespace)
 }
+
+func TestStackdriverSecretFromConfig(t *testing.T) {
+	// The Secret named in the config is used without SetStackdriverSecretLocation.
+	useStackdriverSecretEnabled = false
+	var got string
+	secretFetcher := func(name string) (*corev1.Secret, error) {
+		got = name
+		return &corev1.Secret{Data: map[string][]byte{secretDataFieldKey: []byte("{}")}}, nil
+	}
+
+	ctx := context.Background()
+	scc := &StackdriverClientConfig{UseSecret: true, SecretName: "sd-key", SecretNamespace: "monitoring"}
+	sec, err := getStackdriverSecret(ctx, scc, secretFetcher)
+	if err != nil {
+		t.Fatal("getStackdriverSecret() =", err)
+	}
+	if sec == nil {
+		t.Error("getStackdriverSecret() = nil, wanted the configured Secret")
+	}
+	assertStringsEqual(t, "fetched Secret", "monitoring/sd-key", got)
+
+	// The namespace defaults to the system namespace.
+	scc.SecretNamespace = ""
+	if _, err := getStackdriverSecret(ctx, scc, secretFetcher); err != nil {
+		t.Fatal("getStackdriverSecret() =", err)
+	}
+	assertStringsEqual(t, "fetched Secret", system.Namespace()+"/sd-key", got)
+
+	co, err := getStackdriverExporterClientOptions(&metricsConfig{
+		stackdriverClientConfig: *scc,
+		secret:                  sec,
+	})
+	if err != nil || len(co) != 1 {
+		t.Errorf("getStackdriverExporterClientOptions() = %v, %v, wanted a single option", co, err)
+	}
+}
+
+func TestStackdriverCredentialsFile(t *testing.T) {
+	co, err := getStackdriverExporterClientOptions(&metricsConfig{
+		stackdriverClientConfig: StackdriverClientConfig{CredentialsFile: "/var/secrets/key.json"},
+	})
+	if err != nil || len(co) != 1 {
+		t.Errorf("getStackdriverExporterClientOptions() = %v, %v, wanted a single option", co, err)
+	}
+
+	if _, err := createMetricsConfig(context.Background(), ExporterOptions{
+		Domain:    servingDomain,
+		Component: testComponent,
+		ConfigMap: map[string]string{
+			BackendDestinationKey:         string(stackdriver),
+			stackdriverCredentialsFileKey: "/var/secrets/key.json",
+			stackdriverSecretNameKey:      "sd-key",
+		},
+		Secrets: fakeSecretList().Get,
+	}); err == nil {
+		t.Error("createMetricsConfig() = nil, wanted an error for both a credentials file and a Secret")
+	}
+}
+
+func TestSetFakeGCPMetadata(t *testing.T) {
+	prev := getGCPMetadataFunc()
+	restore := SetFakeGCPMetadata(FakeGCPMetadata{
+		Project:  "fake-project",
+		Location: "fake-location",
+		Cluster:  "fake-cluster",
+	})
+
+	want := &gcpMetadata{
+		project:  "fake-project",
+		location: "fake-location",
+		cluster:  "fake-cluster",
+	}
+	if got := getMergedGCPMetadata(&metricsConfig{}); *got != *want {
+		t.Errorf("getMergedGCPMetadata() = %+v, want: %+v", got, want)
+	}
+
+	restore()
+	if got, want := getGCPMetadataFunc()(), prev(); *got != *want {
+		t.Errorf("After restore, gcpMetadataFunc() = %+v, want: %+v", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sd "contrib.go.opencensus.io/exporter/stackdriver"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricexport"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/multierr"
+
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+// stackdriverProjectKeyPrefix prefixes the keys routing the metrics of a
+// namespace to a GCP project, e.g. "metrics.stackdriver-project.tenant-a: project-a".
+const stackdriverProjectKeyPrefix = "metrics.stackdriver-project."
+
+// newSDProjectExporterFunc is the function used to create the exporter of
+// a project the metrics are routed to. Unlike newStackdriverExporterFunc,
+// the exporter is not started, since the router reads the metrics for it.
+// In unit tests this is set to a fake one to avoid calling actual Google API
+// service.
+var newSDProjectExporterFunc = func(o sd.Options) (metricexport.Exporter, error) {
+	return sd.NewExporter(o)
+}
+
+// parseStackdriverProjects returns the GCP projects to route the metrics of
+// a namespace to, by namespace.
+func parseStackdriverProjects(m map[string]string) (map[string]string, error) {
+	var projects map[string]string
+	for k, v := range m {
+		if !strings.HasPrefix(k, stackdriverProjectKeyPrefix) {
+			continue
+		}
+		ns := strings.TrimPrefix(k, stackdriverProjectKeyPrefix)
+		if ns == "" || v == "" {
+			return nil, fmt.Errorf("invalid %s value %q", k, v)
+		}
+		if projects == nil {
+			projects = make(map[string]string)
+		}
+		projects[ns] = v
+	}
+	return projects, nil
+}
+
+// sdProjectRouter reads the metrics of every Meter and exports them to the
+// project of their namespace, with one Stackdriver exporter per project.
+// The namespace is the namespace_name label of the resource the metric was
+// promoted to or, failing that, of the time series. Metrics of the other
+// namespaces go to the default project.
+type sdProjectRouter struct {
+	defaultExporter metricexport.Exporter
+	byNamespace     map[string]metricexport.Exporter
+	exporters       []metricexport.Exporter
+	reader          *metricexport.IntervalReader
+}
+
+var _ view.Exporter = (*sdProjectRouter)(nil)
+var _ flushable = (*sdProjectRouter)(nil)
+var _ stoppable = (*sdProjectRouter)(nil)
+
+// newSDProjectRouter creates the exporters of the default project of o and
+// of the projects of the namespaces, and starts reading the metrics.
+func newSDProjectRouter(o sd.Options, projects map[string]string) (*sdProjectRouter, error) {
+	r := &sdProjectRouter{
+		byNamespace: make(map[string]metricexport.Exporter, len(projects)),
+	}
+	byProject := make(map[string]metricexport.Exporter, len(projects)+1)
+	exporterFor := func(project string) (metricexport.Exporter, error) {
+		if e, ok := byProject[project]; ok {
+			return e, nil
+		}
+		po := o
+		po.ProjectID = project
+		e, err := newSDProjectExporterFunc(po)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the Stackdriver exporter of project %q: %w", project, err)
+		}
+		byProject[project] = e
+		r.exporters = append(r.exporters, e)
+		return e, nil
+	}
+
+	var err error
+	if r.defaultExporter, err = exporterFor(o.ProjectID); err != nil {
+		return nil, err
+	}
+	for ns, project := range projects {
+		if r.byNamespace[ns], err = exporterFor(project); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.reader, err = metricexport.NewIntervalReader(metricexport.NewReader(), r); err != nil {
+		return nil, err
+	}
+	if o.ReportingInterval > 0 {
+		r.reader.ReportingInterval = o.ReportingInterval
+	}
+	if err := r.reader.Start(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ExportView implements view.Exporter. The metrics are read by the router's
+// own interval reader, so ignore this.
+func (r *sdProjectRouter) ExportView(*view.Data) {}
+
+// ExportMetrics implements metricexport.Exporter.
+func (r *sdProjectRouter) ExportMetrics(ctx context.Context, metrics []*metricdata.Metric) error {
+	batches := make(map[metricexport.Exporter][]*metricdata.Metric, len(r.exporters))
+	for _, m := range metrics {
+		for e, routed := range r.route(m) {
+			batches[e] = append(batches[e], routed)
+		}
+	}
+	var errs error
+	for e, ms := range batches {
+		errs = multierr.Append(errs, e.ExportMetrics(ctx, ms))
+	}
+	return errs
+}
+
+// route splits the time series of the metric by the exporter of their namespace.
+// A metric without time series only describes its descriptor, see
+// precreateDescriptors, so it goes to every project.
+func (r *sdProjectRouter) route(m *metricdata.Metric) map[metricexport.Exporter]*metricdata.Metric {
+	if len(m.TimeSeries) == 0 {
+		routed := make(map[metricexport.Exporter]*metricdata.Metric, len(r.exporters))
+		for _, e := range r.exporters {
+			routed[e] = m
+		}
+		return routed
+	}
+	if m.Resource != nil {
+		if ns, ok := m.Resource.Labels[metricskey.LabelNamespaceName]; ok {
+			return map[metricexport.Exporter]*metricdata.Metric{r.exporterFor(ns): m}
+		}
+	}
+	idx := -1
+	for i, k := range m.Descriptor.LabelKeys {
+		if k.Key == metricskey.LabelNamespaceName {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return map[metricexport.Exporter]*metricdata.Metric{r.defaultExporter: m}
+	}
+
+	series := make(map[metricexport.Exporter][]*metricdata.TimeSeries, 1)
+	for _, ts := range m.TimeSeries {
+		e := r.defaultExporter
+		if idx < len(ts.LabelValues) && ts.LabelValues[idx].Present {
+			e = r.exporterFor(ts.LabelValues[idx].Value)
+		}
+		series[e] = append(series[e], ts)
+	}
+	routed := make(map[metricexport.Exporter]*metricdata.Metric, len(series))
+	for e, ts := range series {
+		if len(series) == 1 {
+			// No need to copy a metric routed as a whole.
+			routed[e] = m
+			continue
+		}
+		routed[e] = &metricdata.Metric{
+			Descriptor: m.Descriptor,
+			Resource:   m.Resource,
+			TimeSeries: ts,
+		}
+	}
+	return routed
+}
+
+func (r *sdProjectRouter) exporterFor(namespace string) metricexport.Exporter {
+	if e, ok := r.byNamespace[namespace]; ok {
+		return e
+	}
+	return r.defaultExporter
+}
+
+// Flush implements flushable.
+func (r *sdProjectRouter) Flush() {
+	for _, e := range r.exporters {
+		if f, ok := e.(flushable); ok {
+			f.Flush()
+		}
+	}
+}
+
+// StopMetricsExporter implements stoppable.
+func (r *sdProjectRouter) StopMetricsExporter() {
+	r.reader.Stop()
+	r.Flush()
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	sd "contrib.go.opencensus.io/exporter/stackdriver"
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricexport"
+	"go.opencensus.io/resource"
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+// fakeProjectExporter records the time series exported to its project.
+type fakeProjectExporter struct {
+	mu     sync.Mutex
+	series []string
+}
+
+func newFakeProjectExporter(sd.Options) (metricexport.Exporter, error) {
+	return &fakeProjectExporter{}, nil
+}
+
+func (e *fakeProjectExporter) ExportMetrics(_ context.Context, metrics []*metricdata.Metric) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, m := range metrics {
+		for _, ts := range m.TimeSeries {
+			name := m.Descriptor.Name
+			for _, v := range ts.LabelValues {
+				name += "/" + v.Value
+			}
+			e.series = append(e.series, name)
+		}
+	}
+	return nil
+}
+
+func (e *fakeProjectExporter) exported() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	sort.Strings(e.series)
+	return e.series
+}
+
+func withFakeProjectExporters(t *testing.T) map[string]*fakeProjectExporter {
+	exporters := map[string]*fakeProjectExporter{}
+	old := newSDProjectExporterFunc
+	newSDProjectExporterFunc = func(o sd.Options) (metricexport.Exporter, error) {
+		e := &fakeProjectExporter{}
+		exporters[o.ProjectID] = e
+		return e, nil
+	}
+	t.Cleanup(func() { newSDProjectExporterFunc = old })
+	return exporters
+}
+
+func TestParseStackdriverProjects(t *testing.T) {
+	got, err := parseStackdriverProjects(map[string]string{
+		stackdriverProjectIDKey:                  "default",
+		stackdriverProjectKeyPrefix + "tenant-a": "project-a",
+		stackdriverProjectKeyPrefix + "tenant-b": "project-b",
+	})
+	if err != nil {
+		t.Fatal("parseStackdriverProjects() =", err)
+	}
+	want := map[string]string{"tenant-a": "project-a", "tenant-b": "project-b"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("parseStackdriverProjects() = %v, want: %v", got, want)
+	}
+
+	if got, err := parseStackdriverProjects(map[string]string{stackdriverProjectIDKey: "default"}); err != nil || got != nil {
+		t.Errorf("parseStackdriverProjects() = %v, %v, want: nil, nil", got, err)
+	}
+	if _, err := parseStackdriverProjects(map[string]string{stackdriverProjectKeyPrefix: "project"}); err == nil {
+		t.Error("parseStackdriverProjects() = nil, wanted an error for a missing namespace")
+	}
+}
+
+func TestSDProjectRouter(t *testing.T) {
+	exporters := withFakeProjectExporters(t)
+	r, err := newSDProjectRouter(sd.Options{ProjectID: "default", ReportingInterval: time.Hour}, map[string]string{
+		"tenant-a": "project-a",
+		"tenant-b": "project-b",
+		"tenant-c": "project-a",
+	})
+	if err != nil {
+		t.Fatal("newSDProjectRouter() =", err)
+	}
+	t.Cleanup(r.StopMetricsExporter)
+
+	if got, want := len(exporters), 3; got != want {
+		t.Fatalf("Created %d exporters, want: %d", got, want)
+	}
+
+	series := func(values ...string) []*metricdata.TimeSeries {
+		ts := make([]*metricdata.TimeSeries, 0, len(values))
+		for _, v := range values {
+			ts = append(ts, &metricdata.TimeSeries{
+				LabelValues: []metricdata.LabelValue{metricdata.NewLabelValue(v)},
+			})
+		}
+		return ts
+	}
+	metrics := []*metricdata.Metric{{
+		// Promoted to a resource holding the namespace.
+		Descriptor: metricdata.Descriptor{
+			Name:      "promoted",
+			LabelKeys: []metricdata.LabelKey{{Key: "response_code"}},
+		},
+		Resource: &resource.Resource{
+			Type:   metricskey.ResourceTypeKnativeRevision,
+			Labels: map[string]string{metricskey.LabelNamespaceName: "tenant-b"},
+		},
+		TimeSeries: series("200"),
+	}, {
+		// Split by the namespace of the time series.
+		Descriptor: metricdata.Descriptor{
+			Name:      "labeled",
+			LabelKeys: []metricdata.LabelKey{{Key: metricskey.LabelNamespaceName}},
+		},
+		TimeSeries: series("tenant-a", "tenant-b", "tenant-c", "other"),
+	}, {
+		// Without a namespace at all.
+		Descriptor: metricdata.Descriptor{
+			Name:      "unlabeled",
+			LabelKeys: []metricdata.LabelKey{{Key: "component"}},
+		},
+		TimeSeries: series("controller"),
+	}}
+	if err := r.ExportMetrics(context.Background(), metrics); err != nil {
+		t.Fatal("ExportMetrics() =", err)
+	}
+
+	want := map[string][]string{
+		"default":   {"labeled/other", "unlabeled/controller"},
+		"project-a": {"labeled/tenant-a", "labeled/tenant-c"},
+		"project-b": {"labeled/tenant-b", "promoted/200"},
+	}
+	for project, e := range exporters {
+		if diff := cmp.Diff(want[project], e.exported()); diff != "" {
+			t.Errorf("Exported to %s (-want +got): %s", project, diff)
+		}
+	}
+}
+
+func TestNewStackdriverExporterWithProjects(t *testing.T) {
+	exporters := withFakeProjectExporters(t)
+	e, _, err := newStackdriverExporter(&metricsConfig{
+		domain:             servingDomain,
+		component:          testComponent,
+		backendDestination: stackdriver,
+		reportingPeriod:    time.Hour,
+		stackdriverClientConfig: StackdriverClientConfig{
+			ProjectID: "default",
+		},
+		stackdriverProjects: map[string]string{"tenant-a": "project-a"},
+	}, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal("newStackdriverExporter() =", err)
+	}
+	defer e.(stoppable).StopMetricsExporter()
+
+	if _, ok := e.(*pollOnlySDExporter).internalExporter.(*sdProjectRouter); !ok {
+		t.Errorf("internalExporter = %T, want a *sdProjectRouter", e.(*pollOnlySDExporter).internalExporter)
+	}
+	if _, ok := exporters["project-a"]; !ok {
+		t.Error("No exporter was created for project-a")
+	}
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"go.uber.org/zap"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// sdRetryQueueSize bounds the number of time series writes buffered for retry.
+	sdRetryQueueSize = 100
+	// sdRetryAttempts is how many times a buffered write is retried before it is dropped.
+	sdRetryAttempts = 5
+	// sdRetryBaseDelay is the delay before the first retry, doubled for each further one.
+	sdRetryBaseDelay = time.Second
+	// sdRetryMaxDelay caps the delay between retries.
+	sdRetryMaxDelay = 30 * time.Second
+)
+
+// sdRetryCall is a time series write waiting to be retried.
+type sdRetryCall struct {
+	method   string
+	req      interface{}
+	reply    interface{}
+	cc       *grpc.ClientConn
+	invoker  grpc.UnaryInvoker
+	opts     []grpc.CallOption
+	attempts int
+	next     time.Time
+}
+
+// sdRetryQueue buffers the time series writes Stackdriver failed with a
+// transient error and retries them in the background with exponential
+// backoff, so that short outages don't lose data. Writes are dropped, and
+// counted in metrics_export_failures, once they ran out of attempts or the
+// buffer overflows. Stackdriver rejects points older than the last point
+// written to a series, so the buffered points of a series are discarded as
+// soon as a newer point of that series is written.
+type sdRetryQueue struct {
+	size      int
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	timeout   time.Duration
+	logger    *zap.SugaredLogger
+
+	mu      sync.Mutex
+	pending []*sdRetryCall
+
+	wake     chan struct{}
+	started  bool
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newSDRetryQueue returns a retry queue, whose background retries begin
+// with start.
+func newSDRetryQueue(logger *zap.SugaredLogger) *sdRetryQueue {
+	registerExporterViews(logger)
+	return &sdRetryQueue{
+		size:      sdRetryQueueSize,
+		attempts:  sdRetryAttempts,
+		baseDelay: sdRetryBaseDelay,
+		maxDelay:  sdRetryMaxDelay,
+		timeout:   stackdriverAPITimeout,
+		logger:    logger,
+		wake:      make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+func (q *sdRetryQueue) start() {
+	q.started = true
+	go q.run()
+}
+
+// intercept is a grpc.UnaryClientInterceptor buffering the time series
+// writes that failed transiently. The error is still returned, so that the
+// exporter reports it.
+func (q *sdRetryQueue) intercept(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if !strings.HasSuffix(method, "/CreateTimeSeries") {
+		return err
+	}
+	recordExportAttempt(stackdriver)
+	c := &sdRetryCall{method: method, req: req, reply: reply, cc: cc, invoker: invoker, opts: opts}
+	switch {
+	case err == nil:
+		q.supersede(req)
+	case isTransientError(err):
+		q.add(c)
+	default:
+		q.dropped(err.Error(), c)
+	}
+	return err
+}
+
+func (q *sdRetryQueue) add(c *sdRetryCall) {
+	c.attempts = 1
+	c.next = time.Now().Add(q.delay(c.attempts))
+
+	q.mu.Lock()
+	if len(q.pending) >= q.size {
+		// Drop the oldest write to make room.
+		q.dropped("the retry buffer is full", q.pending[0])
+		q.pending = q.pending[1:]
+	}
+	q.pending = append(q.pending, c)
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// delay returns how long to wait before the given attempt.
+func (q *sdRetryQueue) delay(attempts int) time.Duration {
+	d := q.baseDelay
+	for i := 1; i < attempts && d < q.maxDelay; i++ {
+		d *= 2
+	}
+	if d > q.maxDelay {
+		d = q.maxDelay
+	}
+	return d
+}
+
+func (q *sdRetryQueue) run() {
+	defer close(q.done)
+	timer := time.NewTimer(q.maxDelay)
+	defer timer.Stop()
+	for {
+		q.retry(false)
+
+		wait := q.maxDelay
+		q.mu.Lock()
+		for _, c := range q.pending {
+			if d := time.Until(c.next); d < wait {
+				wait = d
+			}
+		}
+		q.mu.Unlock()
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-q.stopCh:
+			return
+		case <-q.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// retry reissues the pending writes which are due, or all of them when
+// all is set.
+func (q *sdRetryQueue) retry(all bool) {
+	now := time.Now()
+	q.mu.Lock()
+	var due []*sdRetryCall
+	j := 0
+	for _, c := range q.pending {
+		if all || !c.next.After(now) {
+			due = append(due, c)
+		} else {
+			q.pending[j] = c
+			j++
+		}
+	}
+	q.pending = q.pending[:j]
+	q.mu.Unlock()
+
+	for _, c := range due {
+		ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+		err := c.invoker(ctx, c.method, c.req, c.reply, c.cc, c.opts...)
+		cancel()
+		recordExportAttempt(stackdriver)
+		switch {
+		case err == nil:
+			q.supersede(c.req)
+			continue
+		case !isTransientError(err):
+			q.dropped(err.Error(), c)
+			continue
+		case c.attempts >= q.attempts:
+			q.dropped("out of attempts: "+err.Error(), c)
+			continue
+		}
+		c.attempts++
+		c.next = time.Now().Add(q.delay(c.attempts))
+		q.mu.Lock()
+		if len(q.pending) >= q.size {
+			q.mu.Unlock()
+			q.dropped("the retry buffer is full", c)
+			continue
+		}
+		q.pending = append(q.pending, c)
+		q.mu.Unlock()
+	}
+}
+
+func (q *sdRetryQueue) dropped(reason string, c *sdRetryCall) {
+	q.logger.Warn("Dropping a Stackdriver metrics write: ", reason)
+	var rows int64
+	if req, ok := c.req.(*monitoringpb.CreateTimeSeriesRequest); ok {
+		rows = int64(len(req.TimeSeries))
+	}
+	recordExportDropped(stackdriver, rows)
+}
+
+// Flush retries every pending write once.
+func (q *sdRetryQueue) Flush() {
+	q.retry(true)
+}
+
+// Stop ends the background retries. Pending writes are discarded.
+func (q *sdRetryQueue) Stop() {
+	q.stopOnce.Do(func() {
+		close(q.stopCh)
+	})
+	if q.started {
+		<-q.done
+	}
+}
+
+// supersede discards the buffered points which are not newer than the
+// points of the same series in the given successful write, and the buffered
+// writes left without points.
+func (q *sdRetryQueue) supersede(req interface{}) {
+	written, ok := req.(*monitoringpb.CreateTimeSeriesRequest)
+	if !ok {
+		return
+	}
+	ends := make(map[string]*timestamp.Timestamp, len(written.TimeSeries))
+	for _, ts := range written.TimeSeries {
+		ends[seriesKey(written.Name, ts)] = pointEnd(ts)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j := 0
+	for _, c := range q.pending {
+		pr, ok := c.req.(*monitoringpb.CreateTimeSeriesRequest)
+		if !ok {
+			q.pending[j] = c
+			j++
+			continue
+		}
+		kept := make([]*monitoringpb.TimeSeries, 0, len(pr.TimeSeries))
+		for _, ts := range pr.TimeSeries {
+			if end, ok := ends[seriesKey(pr.Name, ts)]; !ok || after(pointEnd(ts), end) {
+				kept = append(kept, ts)
+			}
+		}
+		if len(kept) == 0 {
+			q.logger.Debug("Discarding a Stackdriver metrics write superseded by a newer one")
+			continue
+		}
+		if len(kept) < len(pr.TimeSeries) {
+			c.req = &monitoringpb.CreateTimeSeriesRequest{Name: pr.Name, TimeSeries: kept}
+		}
+		q.pending[j] = c
+		j++
+	}
+	q.pending = q.pending[:j]
+}
+
+// seriesKey identifies the time series of the given project.
+func seriesKey(project string, ts *monitoringpb.TimeSeries) string {
+	// fmt prints the labels sorted by key.
+	return fmt.Sprint(project, ts.GetMetric().GetType(), ts.GetMetric().GetLabels(),
+		ts.GetResource().GetType(), ts.GetResource().GetLabels())
+}
+
+// pointEnd returns the end of the interval of the last point of the series.
+func pointEnd(ts *monitoringpb.TimeSeries) *timestamp.Timestamp {
+	if len(ts.Points) == 0 {
+		return nil
+	}
+	return ts.Points[len(ts.Points)-1].GetInterval().GetEndTime()
+}
+
+// after returns whether a is strictly later than b.
+func after(a, b *timestamp.Timestamp) bool {
+	if a.GetSeconds() != b.GetSeconds() {
+		return a.GetSeconds() > b.GetSeconds()
+	}
+	return a.GetNanos() > b.GetNanos()
+}
+
+// isTransientError returns whether the RPC failed in a way worth retrying.
+// Writes which may have reached Stackdriver, e.g. that timed out, are not
+// retried, as writing their points twice fails.
+func isTransientError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
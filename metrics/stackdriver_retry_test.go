@@ -0,0 +1,245 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/go-cmp/cmp"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoredrespb "google.golang.org/genproto/googleapis/api/monitoredres"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+const createTimeSeries = "/google.monitoring.v3.MetricService/CreateTimeSeries"
+
+// fakeInvoker fails the first failures calls with err.
+type fakeInvoker struct {
+	mu       sync.Mutex
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *fakeInvoker) invoke(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *fakeInvoker) getCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func exportFailures(t *testing.T) int64 {
+	t.Helper()
+	return exporterMetricCount(t, exportFailuresM.Name(), stackdriver)
+}
+
+func testRetryQueue(t *testing.T) *sdRetryQueue {
+	q := newSDRetryQueue(TestLogger(t))
+	q.baseDelay = time.Millisecond
+	q.maxDelay = 10 * time.Millisecond
+	q.start()
+	t.Cleanup(q.Stop)
+	return q
+}
+
+func TestSDRetryQueueRetries(t *testing.T) {
+	q := testRetryQueue(t)
+	f := &fakeInvoker{err: status.Error(codes.Unavailable, "down"), failures: 3}
+
+	if err := q.intercept(context.Background(), createTimeSeries, "req", nil, nil, f.invoke); err == nil {
+		t.Error("intercept() = nil, wanted the error of the write")
+	}
+	// The write is retried until Stackdriver accepts it.
+	deadline := time.Now().Add(5 * time.Second)
+	for f.getCalls() < 4 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got, want := f.getCalls(), 4; got != want {
+		t.Errorf("Calls = %d, want %d", got, want)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) != 0 {
+		t.Errorf("Pending = %d, want none", len(q.pending))
+	}
+}
+
+func TestSDRetryQueueDrops(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		err     error
+		pending int
+	}{{
+		name:    "unavailable",
+		method:  createTimeSeries,
+		err:     status.Error(codes.Unavailable, "down"),
+		pending: 1,
+	}, {
+		name:    "resource exhausted",
+		method:  createTimeSeries,
+		err:     status.Error(codes.ResourceExhausted, "quota"),
+		pending: 1,
+	}, {
+		name:   "deadline exceeded",
+		method: createTimeSeries,
+		err:    status.Error(codes.DeadlineExceeded, "slow"),
+	}, {
+		name:   "permanent",
+		method: createTimeSeries,
+		err:    status.Error(codes.InvalidArgument, "bad"),
+	}, {
+		name:   "not a status",
+		method: createTimeSeries,
+		err:    errors.New("boom"),
+	}, {
+		name:   "other method",
+		method: "/google.monitoring.v3.MetricService/CreateMetricDescriptor",
+		err:    status.Error(codes.Unavailable, "down"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := newSDRetryQueue(TestLogger(t))
+			// Only retry on Flush.
+			q.baseDelay, q.maxDelay = time.Hour, time.Hour
+
+			f := &fakeInvoker{err: test.err, failures: 100}
+			q.intercept(context.Background(), test.method, "req", nil, nil, f.invoke)
+			q.mu.Lock()
+			got := len(q.pending)
+			q.mu.Unlock()
+			if got != test.pending {
+				t.Errorf("Pending = %d, want %d", got, test.pending)
+			}
+		})
+	}
+}
+
+func TestSDRetryQueueFailures(t *testing.T) {
+	q := newSDRetryQueue(TestLogger(t))
+	q.baseDelay, q.maxDelay = time.Hour, time.Hour
+	q.attempts = 2
+	q.size = 2
+
+	before := exportFailures(t)
+	f := &fakeInvoker{err: status.Error(codes.Unavailable, "down"), failures: 100}
+	for i := 0; i < 3; i++ {
+		q.intercept(context.Background(), createTimeSeries, i, nil, nil, f.invoke)
+	}
+	// The buffer overflowed.
+	if got, want := exportFailures(t)-before, int64(1); got != want {
+		t.Errorf("Export failures = %d, want %d", got, want)
+	}
+
+	// Flushing uses up the last attempt of the buffered writes.
+	q.Flush()
+	q.Flush()
+	if got, want := exportFailures(t)-before, int64(3); got != want {
+		t.Errorf("Export failures = %d, want %d", got, want)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) != 0 {
+		t.Errorf("Pending = %d, want none", len(q.pending))
+	}
+}
+
+func timeSeriesRequest(end int64, names ...string) *monitoringpb.CreateTimeSeriesRequest {
+	req := &monitoringpb.CreateTimeSeriesRequest{Name: "projects/test"}
+	for _, name := range names {
+		req.TimeSeries = append(req.TimeSeries, &monitoringpb.TimeSeries{
+			Metric:   &metricpb.Metric{Type: "custom.googleapis.com/" + name, Labels: map[string]string{"a": "b"}},
+			Resource: &monitoredrespb.MonitoredResource{Type: "global"},
+			Points: []*monitoringpb.Point{{
+				Interval: &monitoringpb.TimeInterval{EndTime: &timestamp.Timestamp{Seconds: end}},
+			}},
+		})
+	}
+	return req
+}
+
+func TestSDRetryQueueSupersedes(t *testing.T) {
+	q := newSDRetryQueue(TestLogger(t))
+	q.baseDelay, q.maxDelay = time.Hour, time.Hour
+
+	down := &fakeInvoker{err: status.Error(codes.Unavailable, "down"), failures: 100}
+	q.intercept(context.Background(), createTimeSeries, timeSeriesRequest(10, "requests", "latency"), nil, nil, down.invoke)
+	q.intercept(context.Background(), createTimeSeries, timeSeriesRequest(10, "errors"), nil, nil, down.invoke)
+
+	// A newer point of requests was written, an older one of errors.
+	up := &fakeInvoker{}
+	q.intercept(context.Background(), createTimeSeries, timeSeriesRequest(20, "requests"), nil, nil, up.invoke)
+	q.intercept(context.Background(), createTimeSeries, timeSeriesRequest(5, "errors"), nil, nil, up.invoke)
+
+	if got, want := pendingSeries(q), []string{"custom.googleapis.com/latency", "custom.googleapis.com/errors"}; !cmp.Equal(got, want) {
+		t.Errorf("Pending series = %v, want %v", got, want)
+	}
+
+	// Once all its series are superseded, the write is discarded.
+	q.intercept(context.Background(), createTimeSeries, timeSeriesRequest(20, "latency"), nil, nil, up.invoke)
+	if got, want := pendingSeries(q), []string{"custom.googleapis.com/errors"}; !cmp.Equal(got, want) {
+		t.Errorf("Pending series = %v, want %v", got, want)
+	}
+}
+
+// pendingSeries returns the metric types of the buffered time series.
+func pendingSeries(q *sdRetryQueue) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var types []string
+	for _, c := range q.pending {
+		for _, ts := range c.req.(*monitoringpb.CreateTimeSeriesRequest).TimeSeries {
+			types = append(types, ts.Metric.Type)
+		}
+	}
+	return types
+}
+
+func TestSDRetryDelay(t *testing.T) {
+	q := &sdRetryQueue{baseDelay: time.Second, maxDelay: 30 * time.Second}
+	for attempts, want := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		4: 8 * time.Second,
+		6: 30 * time.Second,
+		9: 30 * time.Second,
+	} {
+		if got := q.delay(attempts); got != want {
+			t.Errorf("delay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
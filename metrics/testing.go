@@ -24,3 +24,29 @@ func InitForTesting() {
 		domain:             "test",
 	})
 }
+
+// FakeGCPMetadata holds the GCP metadata to report in unit tests, in place
+// of the values from the GCE metadata server.
+type FakeGCPMetadata struct {
+	Project  string
+	Location string
+	Cluster  string
+}
+
+// SetFakeGCPMetadata makes the Stackdriver exporter use the given metadata
+// instead of querying the GCE metadata server. It returns a function that
+// restores the previous behavior.
+func SetFakeGCPMetadata(md FakeGCPMetadata) (restore func()) {
+	prev := setGCPMetadataFunc(func() *gcpMetadata {
+		return &gcpMetadata{
+			project:  md.Project,
+			location: md.Location,
+			cluster:  md.Cluster,
+		}
+	})
+	resetPlatformMetadataCache()
+	return func() {
+		setGCPMetadataFunc(prev)
+		resetPlatformMetadataCache()
+	}
+}
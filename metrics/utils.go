@@ -19,6 +19,7 @@ package metrics
 import (
 	"strconv"
 
+	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 )
 
@@ -52,3 +53,31 @@ func MaybeInsertStringTag(key tag.Key, value string, cond bool) tag.Mutator {
 	}
 	return tag.Insert(key, "")
 }
+
+// namedValue is a value of a metric exported by a push based backend.
+type namedValue struct {
+	name  string
+	value float64
+}
+
+// aggregationValues flattens the aggregated data of a view row into values
+// of the named metric, for backends without a notion of distributions.
+// Distributions are flattened into their count, average, minimum and maximum.
+func aggregationValues(name string, data view.AggregationData) []namedValue {
+	switch data := data.(type) {
+	case *view.CountData:
+		return []namedValue{{name, float64(data.Value)}}
+	case *view.SumData:
+		return []namedValue{{name, data.Value}}
+	case *view.LastValueData:
+		return []namedValue{{name, data.Value}}
+	case *view.DistributionData:
+		return []namedValue{
+			{name + ".count", float64(data.Count)},
+			{name + ".avg", data.Mean},
+			{name + ".min", data.Min},
+			{name + ".max", data.Max},
+		}
+	}
+	return nil
+}
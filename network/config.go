@@ -0,0 +1,281 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	cm "knative.dev/pkg/configmap"
+)
+
+const (
+	// ConfigName is the name of the configmap with the network settings.
+	ConfigName = "config-network"
+
+	// DomainTemplateKey is the key of the Go template used to build the
+	// cluster-local hostname of a service, see DomainTemplateValues.
+	DomainTemplateKey = "domain-template"
+
+	// HTTPProtocolKey is the key of the HTTP protocol preferred when
+	// talking to in-cluster destinations.
+	HTTPProtocolKey = "http-protocol"
+
+	// DefaultExternalSchemeKey is the key of the scheme used for URLs
+	// that don't specify one.
+	DefaultExternalSchemeKey = "default-external-scheme"
+
+	// MaxIdleConnsKey, MaxIdleConnsPerHostKey, MaxConnsPerHostKey,
+	// IdleConnTimeoutKey, TCPKeepAliveKey and DisableCompressionKey are
+	// the keys of the TransportOptions of the event delivery transport.
+	MaxIdleConnsKey        = "max-idle-conns"
+	MaxIdleConnsPerHostKey = "max-idle-conns-per-host"
+	MaxConnsPerHostKey     = "max-conns-per-host"
+	IdleConnTimeoutKey     = "idle-conn-timeout"
+	TCPKeepAliveKey        = "tcp-keepalive"
+	DisableCompressionKey  = "disable-compression"
+
+	// DefaultDomainTemplate is the default value of domain-template.
+	DefaultDomainTemplate = "{{.Name}}.{{.Namespace}}.svc.{{.Domain}}"
+)
+
+// HTTPProtocol is the HTTP protocol preferred for in-cluster traffic.
+type HTTPProtocol string
+
+const (
+	// HTTP1 is used to speak HTTP/1.1 to in-cluster destinations.
+	HTTP1 HTTPProtocol = "http1"
+	// H2C is used to speak HTTP/2 without TLS to in-cluster destinations.
+	H2C HTTPProtocol = "h2c"
+)
+
+// defaultDomainTemplate is the parsed DefaultDomainTemplate, shared by the
+// default Configs since templates are safe for concurrent use.
+var defaultDomainTemplate = template.Must(template.New("domain-template").Parse(DefaultDomainTemplate))
+
+// DomainTemplateValues are the values the domain-template is executed with.
+type DomainTemplateValues struct {
+	Name      string
+	Namespace string
+	// Domain is the cluster domain name, see GetClusterDomainName.
+	Domain string
+}
+
+// Config holds the settings of the config-network configmap.
+type Config struct {
+	// DomainTemplate is the Go template used to build service hostnames.
+	DomainTemplate string
+
+	// HTTPProtocol is the protocol preferred for in-cluster traffic.
+	HTTPProtocol HTTPProtocol
+
+	// DefaultExternalScheme is the scheme of URLs that don't specify one.
+	DefaultExternalScheme string
+
+	// Transport tunes the transport used for event delivery,
+	// see NewTransport.
+	Transport TransportOptions
+
+	domainTemplate *template.Template
+}
+
+// DefaultConfig returns the Config used when config-network is absent.
+func DefaultConfig() *Config {
+	return &Config{
+		DomainTemplate:        DefaultDomainTemplate,
+		HTTPProtocol:          HTTP1,
+		DefaultExternalScheme: "http",
+		Transport:             DefaultTransportOptions(),
+		domainTemplate:        defaultDomainTemplate,
+	}
+}
+
+// NewConfigFromMap returns a Config given a map corresponding to a ConfigMap.
+func NewConfigFromMap(data map[string]string) (*Config, error) {
+	nc := DefaultConfig()
+
+	if err := cm.Parse(cm.WithoutExample(data),
+		cm.AsString(DomainTemplateKey, &nc.DomainTemplate),
+		cm.AsString(DefaultExternalSchemeKey, &nc.DefaultExternalScheme),
+		cm.AsInt(MaxIdleConnsKey, &nc.Transport.MaxIdleConns),
+		cm.AsInt(MaxIdleConnsPerHostKey, &nc.Transport.MaxIdleConnsPerHost),
+		cm.AsInt(MaxConnsPerHostKey, &nc.Transport.MaxConnsPerHost),
+		cm.AsDuration(IdleConnTimeoutKey, &nc.Transport.IdleConnTimeout),
+		cm.AsDuration(TCPKeepAliveKey, &nc.Transport.KeepAlive),
+		cm.AsBool(DisableCompressionKey, &nc.Transport.DisableCompression),
+	); err != nil {
+		return nil, err
+	}
+
+	if t := nc.Transport; t.MaxIdleConns < 0 || t.MaxIdleConnsPerHost < 0 || t.MaxConnsPerHost < 0 ||
+		t.IdleConnTimeout < 0 || t.KeepAlive < 0 {
+		return nil, fmt.Errorf("%s, %s, %s, %s and %s must not be negative",
+			MaxIdleConnsKey, MaxIdleConnsPerHostKey, MaxConnsPerHostKey, IdleConnTimeoutKey, TCPKeepAliveKey)
+	}
+
+	if p, ok := data[HTTPProtocolKey]; ok {
+		switch hp := HTTPProtocol(strings.ToLower(p)); hp {
+		case HTTP1, H2C:
+			nc.HTTPProtocol = hp
+		default:
+			return nil, fmt.Errorf("%s = %q must be one of %q or %q", HTTPProtocolKey, p, HTTP1, H2C)
+		}
+	}
+
+	switch nc.DefaultExternalScheme = strings.ToLower(nc.DefaultExternalScheme); nc.DefaultExternalScheme {
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("%s = %q must be either http or https", DefaultExternalSchemeKey, nc.DefaultExternalScheme)
+	}
+
+	t, err := template.New("domain-template").Parse(nc.DomainTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", DomainTemplateKey, err)
+	}
+	nc.domainTemplate = t
+	// Make sure the template produces a valid hostname.
+	if _, err := nc.GetServiceHostname("name", "namespace"); err != nil {
+		return nil, err
+	}
+	return nc, nil
+}
+
+// NewConfigFromConfigMap returns a Config for the given configmap.
+func NewConfigFromConfigMap(config *corev1.ConfigMap) (*Config, error) {
+	return NewConfigFromMap(config.Data)
+}
+
+// GetServiceHostname returns the cluster-local hostname of the
+// service, built with the DomainTemplate.
+func (c *Config) GetServiceHostname(name, namespace string) (string, error) {
+	if c.DomainTemplate == DefaultDomainTemplate {
+		// Skip executing the template in the common case.
+		return GetServiceHostname(name, namespace), nil
+	}
+	t := c.domainTemplate
+	if t == nil {
+		// The Config was not built by NewConfigFromMap.
+		var err error
+		if t, err = template.New("domain-template").Parse(c.DomainTemplate); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", DomainTemplateKey, err)
+		}
+	}
+	buf := bytes.Buffer{}
+	if err := t.Execute(&buf, DomainTemplateValues{
+		Name:      name,
+		Namespace: namespace,
+		Domain:    GetClusterDomainName(),
+	}); err != nil {
+		return "", fmt.Errorf("failed to execute %s: %w", DomainTemplateKey, err)
+	}
+	host := buf.String()
+	if u, err := url.Parse("http://" + host); err != nil || u.Host != host || host == "" {
+		return "", fmt.Errorf("%s produced an invalid hostname %q", DomainTemplateKey, host)
+	}
+	return host, nil
+}
+
+// NewTransport returns a transport for event delivery speaking the
+// HTTPProtocol. The Transport options only tune the HTTP/1.1 transport.
+func (c *Config) NewTransport() http.RoundTripper {
+	if c.HTTPProtocol == H2C {
+		return NewH2CTransport()
+	}
+	return NewTunedTransport(c.Transport)
+}
+
+// GetServiceURL returns the cluster-local URL of the service, built
+// with the DomainTemplate and the DefaultExternalScheme.
+func (c *Config) GetServiceURL(name, namespace string) (*url.URL, error) {
+	host, err := c.GetServiceHostname(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	scheme := c.DefaultExternalScheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	return &url.URL{
+		Scheme: scheme,
+		Host:   host,
+		Path:   "/",
+	}, nil
+}
+
+type cfgKey struct{}
+
+// ToContext attaches the Config to the context.
+func ToContext(ctx context.Context, c *Config) context.Context {
+	return context.WithValue(ctx, cfgKey{}, c)
+}
+
+// FromContext returns the Config attached to the context, or nil.
+func FromContext(ctx context.Context) *Config {
+	c, _ := ctx.Value(cfgKey{}).(*Config)
+	return c
+}
+
+// FromContextOrDefaults is like FromContext, but falls back
+// to DefaultConfig when no Config is attached to the context.
+func FromContextOrDefaults(ctx context.Context) *Config {
+	if c := FromContext(ctx); c != nil {
+		return c
+	}
+	return DefaultConfig()
+}
+
+// Store is a typed wrapper around configmap.UntypedStore
+// to handle the config-network configmap. It is a reconciler.ConfigStore,
+// so passing it as the controller.Options ConfigStore attaches the Config
+// to the context of every reconcile, where the resolver picks it up.
+type Store struct {
+	*cm.UntypedStore
+}
+
+// NewStore creates a new Store watching config-network.
+func NewStore(logger cm.Logger, onAfterStore ...func(name string, value interface{})) *Store {
+	return &Store{
+		UntypedStore: cm.NewUntypedStore(
+			"network",
+			logger,
+			cm.Constructors{
+				ConfigName: NewConfigFromConfigMap,
+			},
+			onAfterStore...,
+		),
+	}
+}
+
+// ToContext attaches the current Config to the context.
+func (s *Store) ToContext(ctx context.Context) context.Context {
+	return ToContext(ctx, s.Load())
+}
+
+// Load returns the current Config, or DefaultConfig
+// if config-network has not been seen yet.
+func (s *Store) Load() *Config {
+	if c, ok := s.UntypedLoad(ConfigName).(*Config); ok && c != nil {
+		return c
+	}
+	return DefaultConfig()
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/configmap"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestNewConfigFromMap(t *testing.T) {
+	domain := GetClusterDomainName()
+
+	tests := []struct {
+		name     string
+		data     map[string]string
+		wantErr  bool
+		wantHost string
+		wantProt HTTPProtocol
+		wantSch  string
+		wantURL  string
+	}{{
+		name:     "defaults",
+		data:     map[string]string{},
+		wantHost: "name.namespace.svc." + domain,
+		wantProt: HTTP1,
+		wantSch:  "http",
+		wantURL:  "http://name.namespace.svc." + domain + "/",
+	}, {
+		name: "all set",
+		data: map[string]string{
+			DomainTemplateKey:        "{{.Name}}-{{.Namespace}}.{{.Domain}}",
+			HTTPProtocolKey:          "H2C",
+			DefaultExternalSchemeKey: "https",
+		},
+		wantHost: "name-namespace." + domain,
+		wantProt: H2C,
+		wantSch:  "https",
+		wantURL:  "https://name-namespace." + domain + "/",
+	}, {
+		name:    "bad protocol",
+		data:    map[string]string{HTTPProtocolKey: "spdy"},
+		wantErr: true,
+	}, {
+		name:    "bad scheme",
+		data:    map[string]string{DefaultExternalSchemeKey: "ftp"},
+		wantErr: true,
+	}, {
+		name:    "unparseable template",
+		data:    map[string]string{DomainTemplateKey: "{{.Name"},
+		wantErr: true,
+	}, {
+		name:    "unknown template field",
+		data:    map[string]string{DomainTemplateKey: "{{.Cluster}}"},
+		wantErr: true,
+	}, {
+		name:    "template produces a path",
+		data:    map[string]string{DomainTemplateKey: "{{.Name}}/{{.Namespace}}"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := NewConfigFromMap(test.data)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewConfigFromMap() = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			host, err := c.GetServiceHostname("name", "namespace")
+			if err != nil {
+				t.Fatal("GetServiceHostname() =", err)
+			}
+			if host != test.wantHost {
+				t.Errorf("GetServiceHostname() = %s, want %s", host, test.wantHost)
+			}
+			if c.HTTPProtocol != test.wantProt {
+				t.Errorf("HTTPProtocol = %s, want %s", c.HTTPProtocol, test.wantProt)
+			}
+			if c.DefaultExternalScheme != test.wantSch {
+				t.Errorf("DefaultExternalScheme = %s, want %s", c.DefaultExternalScheme, test.wantSch)
+			}
+			u, err := c.GetServiceURL("name", "namespace")
+			if err != nil {
+				t.Fatal("GetServiceURL() =", err)
+			}
+			if got := u.String(); got != test.wantURL {
+				t.Errorf("GetServiceURL() = %s, want %s", got, test.wantURL)
+			}
+		})
+	}
+}
+
+func TestNewConfigTransport(t *testing.T) {
+	c, err := NewConfigFromMap(map[string]string{
+		MaxIdleConnsPerHostKey: "1000",
+		MaxConnsPerHostKey:     "2000",
+		IdleConnTimeoutKey:     "2m",
+		TCPKeepAliveKey:        "15s",
+		DisableCompressionKey:  "true",
+	})
+	if err != nil {
+		t.Fatal("NewConfigFromMap() =", err)
+	}
+	want := TransportOptions{
+		MaxIdleConns:        DefaultTransportOptions().MaxIdleConns,
+		MaxIdleConnsPerHost: 1000,
+		MaxConnsPerHost:     2000,
+		IdleConnTimeout:     2 * time.Minute,
+		KeepAlive:           15 * time.Second,
+		DisableCompression:  true,
+	}
+	if c.Transport != want {
+		t.Errorf("Transport = %#v, want %#v", c.Transport, want)
+	}
+	if tr, ok := c.NewTransport().(*http.Transport); !ok || tr.MaxConnsPerHost != 2000 {
+		t.Errorf("NewTransport() = %#v, want an HTTP/1.1 transport with MaxConnsPerHost = 2000", c.NewTransport())
+	}
+
+	c, err = NewConfigFromMap(map[string]string{HTTPProtocolKey: string(H2C)})
+	if err != nil {
+		t.Fatal("NewConfigFromMap() =", err)
+	}
+	if tr, ok := c.NewTransport().(*http2.Transport); !ok || !tr.AllowHTTP {
+		t.Errorf("NewTransport() = %#v, want an h2c transport", c.NewTransport())
+	}
+
+	for _, data := range []map[string]string{
+		{MaxIdleConnsKey: "many"},
+		{MaxConnsPerHostKey: "-1"},
+		{IdleConnTimeoutKey: "-1s"},
+	} {
+		if _, err := NewConfigFromMap(data); err == nil {
+			t.Errorf("NewConfigFromMap(%v) = nil, wanted an error", data)
+		}
+	}
+}
+
+func TestConfigWithoutTemplate(t *testing.T) {
+	c := &Config{DomainTemplate: "{{.Name}}.{{.Domain}}"}
+	u, err := c.GetServiceURL("sink", "ns")
+	if err != nil {
+		t.Fatal("GetServiceURL() =", err)
+	}
+	if got, want := u.String(), "http://sink."+GetClusterDomainName()+"/"; got != want {
+		t.Errorf("GetServiceURL() = %s, want %s", got, want)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(logtesting.TestLogger(t))
+
+	ctx := store.ToContext(context.Background())
+	if got, want := FromContext(ctx), DefaultConfig(); got.DomainTemplate != want.DomainTemplate {
+		t.Errorf("FromContext() = %v, want the defaults", got)
+	}
+
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigName},
+		Data:       map[string]string{HTTPProtocolKey: string(H2C)},
+	})
+	ctx = store.ToContext(context.Background())
+	if got, want := FromContext(ctx).HTTPProtocol, H2C; got != want {
+		t.Errorf("HTTPProtocol = %s, want %s", got, want)
+	}
+
+	if got := FromContextOrDefaults(context.Background()); got == nil {
+		t.Error("FromContextOrDefaults() = nil")
+	}
+
+	// Make sure the store can be used with a watcher.
+	store.WatchConfigs(configmap.NewStaticWatcher(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigName},
+	}))
+}
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -28,6 +29,11 @@ import (
 const (
 	resolverFileName  = "/etc/resolv.conf"
 	defaultDomainName = "cluster.local"
+
+	// ClusterDomainEnvKey is the name of the environment variable that
+	// can be used to override the cluster domain name discovered from
+	// /etc/resolv.conf.
+	ClusterDomainEnvKey = "CLUSTER_DOMAIN"
 )
 
 var (
@@ -35,25 +41,47 @@ var (
 	once       sync.Once
 )
 
-// GetServiceHostname returns the fully qualified service hostname
+// GetServiceHostname returns the fully qualified service hostname, built
+// with the DefaultDomainTemplate.
+// Use Config.GetServiceHostname to follow the domain-template of config-network.
 func GetServiceHostname(name, namespace string) string {
 	return fmt.Sprintf("%s.%s.svc.%s", name, namespace, GetClusterDomainName())
 }
 
+// GetServiceURL returns the cluster-local http URL of the service, built
+// with the DefaultDomainTemplate.
+// Use Config.GetServiceURL to follow the settings of config-network.
+func GetServiceURL(name, namespace string) *url.URL {
+	return &url.URL{
+		Scheme: "http",
+		Host:   GetServiceHostname(name, namespace),
+		Path:   "/",
+	}
+}
+
 // GetClusterDomainName returns cluster's domain name or an error
 // Closes issue: https://github.com/knative/eventing/issues/714
+// The domain name is discovered once, from the CLUSTER_DOMAIN environment
+// variable if set, and from /etc/resolv.conf otherwise.
 func GetClusterDomainName() string {
 	once.Do(func() {
-		f, err := os.Open(resolverFileName)
-		if err != nil {
-			return
-		}
-		defer f.Close()
-		domainName = getClusterDomainName(f)
+		domainName = loadClusterDomainName(resolverFileName)
 	})
 	return domainName
 }
 
+func loadClusterDomainName(resolvConf string) string {
+	if d := strings.TrimSuffix(os.Getenv(ClusterDomainEnvKey), "."); d != "" {
+		return d
+	}
+	f, err := os.Open(resolvConf)
+	if err != nil {
+		return defaultDomainName
+	}
+	defer f.Close()
+	return getClusterDomainName(f)
+}
+
 func getClusterDomainName(r io.Reader) string {
 	for scanner := bufio.NewScanner(r); scanner.Scan(); {
 		elements := strings.Split(scanner.Text(), " ")
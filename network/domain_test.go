@@ -17,6 +17,9 @@ limitations under the License.
 package network
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -70,3 +73,35 @@ options ndots:5
 		}
 	}
 }
+
+func TestLoadClusterDomainName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resolv")
+	if err != nil {
+		t.Fatal("TempDir() =", err)
+	}
+	defer os.RemoveAll(dir)
+	resolvConf := filepath.Join(dir, "resolv.conf")
+	if err := ioutil.WriteFile(resolvConf, []byte("search default.svc.abc.com svc.abc.com\n"), 0644); err != nil {
+		t.Fatal("WriteFile() =", err)
+	}
+
+	if got, want := loadClusterDomainName(resolvConf), "abc.com"; got != want {
+		t.Errorf("loadClusterDomainName() = %s, want %s", got, want)
+	}
+	if got, want := loadClusterDomainName(filepath.Join(dir, "missing")), defaultDomainName; got != want {
+		t.Errorf("loadClusterDomainName() = %s, want %s", got, want)
+	}
+
+	os.Setenv(ClusterDomainEnvKey, "xyz.org.")
+	defer os.Unsetenv(ClusterDomainEnvKey)
+	if got, want := loadClusterDomainName(resolvConf), "xyz.org"; got != want {
+		t.Errorf("loadClusterDomainName() = %s, want %s", got, want)
+	}
+}
+
+func TestGetServiceURL(t *testing.T) {
+	want := "http://sink.ns.svc." + GetClusterDomainName() + "/"
+	if got := GetServiceURL("sink", "ns").String(); got != want {
+		t.Errorf("GetServiceURL() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// ErrorClass tells whether a failed delivery is worth retrying.
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown is used for errors that could not be classified.
+	ErrorClassUnknown ErrorClass = iota
+	// ErrorClassRetriable is used for errors that may go away on retry,
+	// e.g. a refused connection or a 503.
+	ErrorClassRetriable
+	// ErrorClassNonRetriable is used for errors that will not go away
+	// on retry, e.g. a 400 or a certificate signed by an unknown authority.
+	ErrorClassNonRetriable
+)
+
+// String implements fmt.Stringer.
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassRetriable:
+		return "Retriable"
+	case ErrorClassNonRetriable:
+		return "NonRetriable"
+	default:
+		return "Unknown"
+	}
+}
+
+// DeliveryError is a classified error of a failed delivery. Reason is a
+// short CamelCase string suitable for a condition reason.
+type DeliveryError struct {
+	Class  ErrorClass
+	Reason string
+	// StatusCode is the status of the response, or 0 if
+	// the delivery failed before a response was received.
+	StatusCode int
+	Err        error
+}
+
+// Error implements error.
+func (e *DeliveryError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+	}
+	return e.Reason
+}
+
+// Unwrap returns the underlying error.
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Retriable returns whether the delivery should be retried.
+func (e *DeliveryError) Retriable() bool {
+	return e.Class == ErrorClassRetriable
+}
+
+// ClassifyError classifies an error returned by an http.Client or
+// http.RoundTripper. It returns nil for a nil error, and the error
+// itself when it is already a *DeliveryError.
+func ClassifyError(err error) *DeliveryError {
+	if err == nil {
+		return nil
+	}
+	var de *DeliveryError
+	if errors.As(err, &de) {
+		return de
+	}
+
+	class, reason := classify(err)
+	return &DeliveryError{Class: class, Reason: reason, Err: err}
+}
+
+func classify(err error) (ErrorClass, string) {
+	var (
+		dnsErr  *net.DNSError
+		netErr  net.Error
+		authErr x509.UnknownAuthorityError
+		hostErr x509.HostnameError
+		certErr x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorClassNonRetriable, "Canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassRetriable, "Timeout"
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsNotFound {
+			return ErrorClassNonRetriable, "HostNotFound"
+		}
+		return ErrorClassRetriable, "DNSError"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorClassRetriable, "ConnectionRefused"
+	case errors.Is(err, syscall.ECONNRESET):
+		return ErrorClassRetriable, "ConnectionReset"
+	case errors.As(err, &authErr), errors.As(err, &hostErr), errors.As(err, &certErr):
+		return ErrorClassNonRetriable, "CertificateError"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassRetriable, "Timeout"
+	}
+	return ErrorClassUnknown, "Unknown"
+}
+
+// ClassifyStatusCode classifies the status code of a response.
+// Successful responses are ErrorClassUnknown, since they are no error.
+func ClassifyStatusCode(code int) ErrorClass {
+	switch {
+	case code == http.StatusRequestTimeout,
+		code == http.StatusTooManyRequests,
+		code >= 500 && code != http.StatusNotImplemented && code != http.StatusHTTPVersionNotSupported:
+		return ErrorClassRetriable
+	case code >= 400:
+		return ErrorClassNonRetriable
+	}
+	return ErrorClassUnknown
+}
+
+// ClassifyResponse classifies the result of a delivery. It returns nil
+// when the delivery succeeded with a 2xx response.
+func ClassifyResponse(resp *http.Response, err error) *DeliveryError {
+	if err != nil {
+		return ClassifyError(err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	reason := "UnexpectedStatus"
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		reason = "TooManyRequests"
+	case resp.StatusCode == http.StatusRequestTimeout:
+		reason = "RequestTimeout"
+	case resp.StatusCode >= 500:
+		reason = "ServerError"
+	case resp.StatusCode >= 400:
+		reason = "ClientError"
+	}
+	return &DeliveryError{
+		Class:      ClassifyStatusCode(resp.StatusCode),
+		Reason:     reason,
+		StatusCode: resp.StatusCode,
+		Err:        fmt.Errorf("unexpected response status %d", resp.StatusCode),
+	}
+}
+
+// IsRetriable returns whether the delivery that failed with err
+// should be retried.
+func IsRetriable(err error) bool {
+	de := ClassifyError(err)
+	return de != nil && de.Retriable()
+}
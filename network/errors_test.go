@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	urlErr := func(err error) error {
+		return &url.Error{Op: "Post", URL: "http://sink.ns.svc.cluster.local", Err: err}
+	}
+	opErr := func(err error) error {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantClass  ErrorClass
+		wantReason string
+	}{{
+		name:       "connection refused",
+		err:        urlErr(opErr(syscall.ECONNREFUSED)),
+		wantClass:  ErrorClassRetriable,
+		wantReason: "ConnectionRefused",
+	}, {
+		name:       "connection reset",
+		err:        urlErr(opErr(syscall.ECONNRESET)),
+		wantClass:  ErrorClassRetriable,
+		wantReason: "ConnectionReset",
+	}, {
+		name:       "host not found",
+		err:        urlErr(&net.OpError{Op: "dial", Err: &net.DNSError{Name: "sink", IsNotFound: true}}),
+		wantClass:  ErrorClassNonRetriable,
+		wantReason: "HostNotFound",
+	}, {
+		name:       "dns failure",
+		err:        urlErr(&net.DNSError{Name: "sink", IsTemporary: true}),
+		wantClass:  ErrorClassRetriable,
+		wantReason: "DNSError",
+	}, {
+		name:       "deadline",
+		err:        urlErr(context.DeadlineExceeded),
+		wantClass:  ErrorClassRetriable,
+		wantReason: "Timeout",
+	}, {
+		name:       "net timeout",
+		err:        urlErr(timeoutError{}),
+		wantClass:  ErrorClassRetriable,
+		wantReason: "Timeout",
+	}, {
+		name:       "canceled",
+		err:        urlErr(context.Canceled),
+		wantClass:  ErrorClassNonRetriable,
+		wantReason: "Canceled",
+	}, {
+		name:       "unknown authority",
+		err:        urlErr(x509.UnknownAuthorityError{}),
+		wantClass:  ErrorClassNonRetriable,
+		wantReason: "CertificateError",
+	}, {
+		name:       "unknown",
+		err:        errors.New("boom"),
+		wantClass:  ErrorClassUnknown,
+		wantReason: "Unknown",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ClassifyError(test.err)
+			if got.Class != test.wantClass || got.Reason != test.wantReason {
+				t.Errorf("ClassifyError() = %v/%s, want %v/%s", got.Class, got.Reason, test.wantClass, test.wantReason)
+			}
+			if !errors.Is(got, test.err) {
+				t.Error("ClassifyError() does not wrap the original error")
+			}
+			if got, want := IsRetriable(test.err), test.wantClass == ErrorClassRetriable; got != want {
+				t.Errorf("IsRetriable() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	if got := ClassifyError(nil); got != nil {
+		t.Errorf("ClassifyError(nil) = %v, want nil", got)
+	}
+	de := &DeliveryError{Class: ErrorClassNonRetriable, Reason: "Custom"}
+	if got := ClassifyError(fmt.Errorf("wrapped: %w", de)); got != de {
+		t.Errorf("ClassifyError() = %v, want %v", got, de)
+	}
+}
+
+func TestClassifyResponse(t *testing.T) {
+	tests := []struct {
+		code       int
+		wantClass  ErrorClass
+		wantReason string
+	}{{
+		code:       http.StatusBadRequest,
+		wantClass:  ErrorClassNonRetriable,
+		wantReason: "ClientError",
+	}, {
+		code:       http.StatusNotFound,
+		wantClass:  ErrorClassNonRetriable,
+		wantReason: "ClientError",
+	}, {
+		code:       http.StatusRequestTimeout,
+		wantClass:  ErrorClassRetriable,
+		wantReason: "RequestTimeout",
+	}, {
+		code:       http.StatusTooManyRequests,
+		wantClass:  ErrorClassRetriable,
+		wantReason: "TooManyRequests",
+	}, {
+		code:       http.StatusServiceUnavailable,
+		wantClass:  ErrorClassRetriable,
+		wantReason: "ServerError",
+	}, {
+		code:       http.StatusNotImplemented,
+		wantClass:  ErrorClassNonRetriable,
+		wantReason: "ServerError",
+	}, {
+		code:       http.StatusFound,
+		wantClass:  ErrorClassUnknown,
+		wantReason: "UnexpectedStatus",
+	}}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.code), func(t *testing.T) {
+			got := ClassifyResponse(&http.Response{StatusCode: test.code}, nil)
+			if got.Class != test.wantClass || got.Reason != test.wantReason || got.StatusCode != test.code {
+				t.Errorf("ClassifyResponse() = %v/%s/%d, want %v/%s/%d",
+					got.Class, got.Reason, got.StatusCode, test.wantClass, test.wantReason, test.code)
+			}
+		})
+	}
+
+	if got := ClassifyResponse(&http.Response{StatusCode: http.StatusAccepted}, nil); got != nil {
+		t.Errorf("ClassifyResponse(202) = %v, want nil", got)
+	}
+	if got := ClassifyResponse(nil, syscall.ECONNREFUSED); !got.Retriable() {
+		t.Errorf("ClassifyResponse(ECONNREFUSED) = %v, want retriable", got)
+	}
+}
@@ -26,11 +26,28 @@ import (
 	"golang.org/x/net/http2/h2c"
 )
 
+const (
+	// h2cReadIdleTimeout is how long an h2c connection may go without
+	// receiving frames before a health check ping is sent. This allows
+	// long-lived streams to detect dead peers instead of hanging.
+	h2cReadIdleTimeout = 30 * time.Second
+
+	// h2cPingTimeout is how long to wait for a health check ping to be
+	// answered before the connection is closed.
+	h2cPingTimeout = 15 * time.Second
+
+	// h2cServerIdleTimeout is how long an h2c connection without any
+	// open streams is kept by the server before it is closed.
+	h2cServerIdleTimeout = 5 * time.Minute
+)
+
 // NewServer returns a new HTTP Server with HTTP2 handler.
 func NewServer(addr string, h http.Handler) *http.Server {
 	h1s := &http.Server{
-		Addr:    addr,
-		Handler: h2c.NewHandler(h, &http2.Server{}),
+		Addr: addr,
+		Handler: h2c.NewHandler(h, &http2.Server{
+			IdleTimeout: h2cServerIdleTimeout,
+		}),
 	}
 
 	return h1s
@@ -40,9 +57,13 @@ func NewServer(addr string, h http.Handler) *http.Server {
 // That transport will reroute all HTTPS traffic to HTTP. This is
 // to explicitly allow h2c (http2 without TLS) transport.
 // See https://github.com/golang/go/issues/14141 for more details.
+// Idle connections are health checked with pings, so that long-lived
+// streams notice when the peer went away.
 func NewH2CTransport() http.RoundTripper {
 	return &http2.Transport{
-		AllowHTTP: true,
+		AllowHTTP:       true,
+		ReadIdleTimeout: h2cReadIdleTimeout,
+		PingTimeout:     h2cPingTimeout,
 		DialTLS: func(netw, addr string, cfg *tls.Config) (net.Conn, error) {
 			d := &net.Dialer{
 				Timeout:   DefaultConnTimeout,
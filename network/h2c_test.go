@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestH2CRoundTrip(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Listen() =", err)
+	}
+	s := NewServer(l.Addr().String(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+	}))
+	go s.Serve(l)
+	defer s.Close()
+
+	c := &http.Client{Transport: NewH2CTransport()}
+	resp, err := c.Get("http://" + l.Addr().String())
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	defer resp.Body.Close()
+
+	if got, want := resp.Proto, "HTTP/2.0"; got != want {
+		t.Errorf("Response proto = %q, want %q", got, want)
+	}
+	if got, want := resp.Header.Get("X-Proto"), "HTTP/2.0"; got != want {
+		t.Errorf("Request proto seen by server = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"knative.dev/pkg/network"
+)
+
+// ProbeHandler wraps an inner http.Handler so that kubelet and network
+// probes are answered before they reach the inner handler. Kubelet probes
+// are answered with "200 OK" while Ready (if set) returns true, and with
+// "503 not ready" otherwise. Network probes must carry a 'K-Network-Hash'
+// header, which is echoed back so that the prober can tell which version
+// of the networking configuration answered.
+type ProbeHandler struct {
+	// Inner is the http.Handler to which we delegate actual requests.
+	Inner http.Handler
+
+	// Ready reports whether kubelet probes should succeed.
+	// A nil Ready means always ready.
+	Ready func() bool
+}
+
+// Ensure ProbeHandler implements http.Handler
+var _ http.Handler = (*ProbeHandler)(nil)
+
+// ServeHTTP implements http.Handler
+func (h *ProbeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case network.IsKubeletProbe(r):
+		if h.Ready != nil && !h.Ready() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case network.IsProbe(r):
+		if ph := r.Header.Get(network.ProbeHeaderName); ph != network.ProbeHeaderValue {
+			http.Error(w, fmt.Sprintf("unexpected probe header value: %q", ph), http.StatusBadRequest)
+			return
+		}
+		hash := r.Header.Get(network.HashHeaderName)
+		if hash == "" {
+			http.Error(w, "probe hash header is missing", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(network.HashHeaderName, hash)
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		h.Inner.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/pkg/network"
+)
+
+func TestProbeHandler(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		name       string
+		ready      func() bool
+		headers    map[string]string
+		wantStatus int
+		wantHash   string
+	}{{
+		name:       "user request",
+		wantStatus: http.StatusTeapot,
+	}, {
+		name:       "kubelet probe",
+		headers:    map[string]string{network.UserAgentKey: network.KubeProbeUAPrefix + "1.17"},
+		wantStatus: http.StatusOK,
+	}, {
+		name:       "kubelet probe while not ready",
+		ready:      func() bool { return false },
+		headers:    map[string]string{network.KubeletProbeHeaderName: "queue"},
+		wantStatus: http.StatusServiceUnavailable,
+	}, {
+		name: "network probe",
+		headers: map[string]string{
+			network.ProbeHeaderName: network.ProbeHeaderValue,
+			network.HashHeaderName:  "deadbeef",
+		},
+		wantStatus: http.StatusOK,
+		wantHash:   "deadbeef",
+	}, {
+		name:       "network probe without hash",
+		headers:    map[string]string{network.ProbeHeaderName: network.ProbeHeaderValue},
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name: "network probe with unexpected value",
+		headers: map[string]string{
+			network.ProbeHeaderName: "activator",
+			network.HashHeaderName:  "deadbeef",
+		},
+		wantStatus: http.StatusBadRequest,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := &ProbeHandler{Inner: inner, Ready: test.ready}
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, req)
+
+			if got, want := resp.Code, test.wantStatus; got != want {
+				t.Errorf("Status = %d, want %d", got, want)
+			}
+			if got, want := resp.Header().Get(network.HashHeaderName), test.wantHash; got != want {
+				t.Errorf("Hash header = %q, want %q", got, want)
+			}
+		})
+	}
+}
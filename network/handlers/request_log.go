@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging"
+)
+
+// RequestIDHeader is the header carrying the ID of a request, which is
+// generated when the client doesn't provide one.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestLogger wraps an inner http.Handler to attach a request-scoped
+// logger to the context of each request, tagged with the request ID,
+// method, path and remote address, and optionally to emit an access log
+// entry with the status and latency of each request.
+type RequestLogger struct {
+	// Inner is the http.Handler to which we delegate actual requests.
+	Inner http.Handler
+
+	// Logger is the logger the request-scoped loggers derive from. When
+	// nil, the logger of the request context is used.
+	Logger *zap.SugaredLogger
+
+	// AccessLog enables the access log entries.
+	AccessLog bool
+}
+
+// Ensure RequestLogger implements http.Handler
+var _ http.Handler = (*RequestLogger)(nil)
+
+// ServeHTTP implements http.Handler
+func (h *RequestLogger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = uuid.New().String()
+	}
+	w.Header().Set(RequestIDHeader, id)
+
+	logger := h.Logger
+	if logger == nil {
+		logger = logging.FromContext(r.Context())
+	}
+	logger = logger.With(
+		zap.String("requestId", id),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.String("remote", r.RemoteAddr))
+	r = r.WithContext(logging.WithLogger(r.Context(), logger))
+
+	if !h.AccessLog {
+		h.Inner.ServeHTTP(w, r)
+		return
+	}
+
+	start := time.Now()
+	rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.Inner.ServeHTTP(rw, r)
+	logger.Infow("Request served",
+		zap.Int("status", rw.status),
+		zap.Int64("bytes", rw.bytes),
+		zap.Duration("latency", time.Since(start)))
+}
+
+// statusRecorder is an http.ResponseWriter recording the status and size
+// of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher, for streaming responses.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, for websockets.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.ResponseWriter.(http.Hijacker); ok {
+		r.status = http.StatusSwitchingProtocols
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("wrapped ResponseWriter doesn't implement http.Hijacker")
+}
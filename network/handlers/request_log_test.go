@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"knative.dev/pkg/logging"
+)
+
+func TestRequestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	h := &RequestLogger{
+		Inner: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logging.FromContext(r.Context()).Info("handling")
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("short and stout"))
+		}),
+		AccessLog: true,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/brew", nil)
+	req.Header.Set(RequestIDHeader, "abc")
+	req = req.WithContext(logging.WithLogger(context.Background(), zap.New(core).Sugar()))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got, want := rec.Header().Get(RequestIDHeader), "abc"; got != want {
+		t.Errorf("%s = %q, want %q", RequestIDHeader, got, want)
+	}
+	entries := logs.All()
+	if got, want := len(entries), 2; got != want {
+		t.Fatalf("len(entries) = %d, want %d", got, want)
+	}
+	handling := entries[0].ContextMap()
+	for k, want := range map[string]interface{}{
+		"requestId": "abc",
+		"method":    http.MethodGet,
+		"path":      "/brew",
+	} {
+		if handling[k] != want {
+			t.Errorf("%s = %v, want %v", k, handling[k], want)
+		}
+	}
+	access := entries[1].ContextMap()
+	if got, want := access["status"], int64(http.StatusTeapot); got != want {
+		t.Errorf("status = %v, want %v", got, want)
+	}
+	if got, want := access["bytes"], int64(len("short and stout")); got != want {
+		t.Errorf("bytes = %v, want %v", got, want)
+	}
+}
+
+func TestRequestLoggerGeneratesID(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	h := &RequestLogger{
+		Inner:  http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		Logger: zap.New(core).Sugar(),
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Header().Get(RequestIDHeader) == "" {
+		t.Errorf("%s was not generated", RequestIDHeader)
+	}
+	if got := logs.Len(); got != 0 {
+		t.Errorf("Got %d entries without AccessLog, want none", got)
+	}
+}
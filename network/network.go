@@ -54,6 +54,14 @@ const (
 	// included in request metrics.
 	ProbeHeaderName = "K-Network-Probe"
 
+	// ProbeHeaderValue is the value used in 'K-Network-Probe'
+	ProbeHeaderValue = "probe"
+
+	// HashHeaderName is the name of an internal header that network probes
+	// carry, so that the prober can tell which version of the networking
+	// configuration answered the probe.
+	HashHeaderName = "K-Network-Hash"
+
 	// Since K8s 1.8, prober requests have
 	//   User-Agent = "kube-probe/{major-version}.{minor-version}".
 	KubeProbeUAPrefix = "kube-probe/"
@@ -68,3 +76,9 @@ func IsKubeletProbe(r *http.Request) bool {
 	return strings.HasPrefix(r.Header.Get("User-Agent"), KubeProbeUAPrefix) ||
 		r.Header.Get(KubeletProbeHeaderName) != ""
 }
+
+// IsProbe returns true if the request is a network probe, as
+// opposed to a request that should be handled by the user.
+func IsProbe(r *http.Request) bool {
+	return r.Header.Get(ProbeHeaderName) != ""
+}
@@ -42,3 +42,17 @@ func TestIsKubeletProbe(t *testing.T) {
 		t.Error("kubelet probe but not counted as such")
 	}
 }
+
+func TestIsProbe(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if err != nil {
+		t.Fatal("Error building request:", err)
+	}
+	if IsProbe(req) {
+		t.Error("Not a network probe but counted as such")
+	}
+	req.Header.Set(ProbeHeaderName, ProbeHeaderValue)
+	if !IsProbe(req) {
+		t.Error("Network probe but not counted as such")
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/network"
 )
 
 // Preparer is a way for the caller to modify the HTTP request before it goes out.
@@ -60,6 +61,27 @@ func WithPath(path string) Preparer {
 	}
 }
 
+// AsNetworkProbe marks the probe request as a network probe carrying the
+// given hash, see network.ProbeHeaderName and network.HashHeaderName.
+func AsNetworkProbe(hash string) Preparer {
+	return func(r *http.Request) *http.Request {
+		r.Header.Set(network.ProbeHeaderName, network.ProbeHeaderValue)
+		r.Header.Set(network.HashHeaderName, hash)
+		return r
+	}
+}
+
+// AsKubeletProbe marks the probe request as a kubelet probe,
+// see network.KubeletProbeHeaderName.
+func AsKubeletProbe() Preparer {
+	return WithHeader(network.KubeletProbeHeaderName, "probe")
+}
+
+// ExpectsHash validates that the probe response echoed the given hash.
+func ExpectsHash(hash string) Verifier {
+	return ExpectsHeader(network.HashHeaderName, hash)
+}
+
 // ExpectsBody validates that the body of the probe response matches the provided string.
 func ExpectsBody(body string) Verifier {
 	return func(r *http.Response, b []byte) (bool, error) {
@@ -26,6 +26,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/wait"
 	"knative.dev/pkg/network"
+	"knative.dev/pkg/network/handlers"
 )
 
 const (
@@ -373,6 +374,41 @@ func TestExpectsHeaderOption(t *testing.T) {
 	}
 }
 
+func TestNetworkProbe(t *testing.T) {
+	ts := httptest.NewServer(&handlers.ProbeHandler{
+		Inner: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Probe reached the inner handler")
+		}),
+	})
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		options []interface{}
+		success bool
+	}{{
+		name:    "matching hash",
+		options: []interface{}{AsNetworkProbe("abc"), ExpectsHash("abc"), ExpectsStatusCodes([]int{http.StatusOK})},
+		success: true,
+	}, {
+		name:    "stale hash",
+		options: []interface{}{AsNetworkProbe("abc"), ExpectsHash("def")},
+	}, {
+		name:    "kubelet probe",
+		options: []interface{}{AsKubeletProbe(), ExpectsStatusCodes([]int{http.StatusOK})},
+		success: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, err := Do(context.Background(), network.AutoTransport, ts.URL, test.options...)
+			if ok != test.success {
+				t.Errorf("unexpected probe result: want: %v, got: %v (err: %v)", test.success, ok, err)
+			}
+		})
+	}
+}
+
 func (m *Manager) len() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
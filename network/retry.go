@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	// DefaultRetryBackoff is the backoff used by RetryTransport
+	// when none is configured.
+	DefaultRetryBackoff = wait.Backoff{
+		Duration: 100 * time.Millisecond,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    5,
+	}
+
+	// DefaultRetryableStatusCodes are the response codes that
+	// RetryTransport retries when none are configured.
+	DefaultRetryableStatusCodes = sets.NewInt(
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	)
+
+	idempotentMethods = sets.NewString(
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodOptions,
+		http.MethodTrace,
+		http.MethodPut,
+		http.MethodDelete,
+	)
+)
+
+// RetryTransport wraps an inner http.RoundTripper to retry requests that
+// failed with a transient connection error, as classified by IsRetriable,
+// or with one of RetryableStatusCodes.
+// Only idempotent requests are retried. Like net/http, requests of other
+// methods (e.g. POST) are treated as idempotent when they carry an
+// "Idempotency-Key" or "X-Idempotency-Key" header. Requests with a body
+// are only retried when their GetBody is set, which http.NewRequest does
+// for in-memory bodies.
+type RetryTransport struct {
+	// Inner is the http.RoundTripper the requests are delegated to.
+	// Defaults to AutoTransport.
+	Inner http.RoundTripper
+
+	// Backoff controls the delay between attempts. Its Steps are the
+	// maximum number of retries. Defaults to DefaultRetryBackoff.
+	Backoff wait.Backoff
+
+	// Budget, if positive, caps the total time spent on a request,
+	// retries included. No retry is attempted that would exceed it.
+	Budget time.Duration
+
+	// RetryableStatusCodes are the response codes that are retried.
+	// Defaults to DefaultRetryableStatusCodes.
+	RetryableStatusCodes sets.Int
+}
+
+// Ensure RetryTransport implements http.RoundTripper
+var _ http.RoundTripper = (*RetryTransport)(nil)
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	inner := t.Inner
+	if inner == nil {
+		inner = AutoTransport
+	}
+	if !isRetriable(r) {
+		return inner.RoundTrip(r)
+	}
+
+	bo := t.Backoff
+	if bo.Steps == 0 {
+		bo = DefaultRetryBackoff
+	}
+	start := time.Now()
+	req := r
+	for {
+		resp, err := inner.RoundTrip(req)
+		if !t.shouldRetry(r.Context(), resp, err) || bo.Steps < 1 {
+			return resp, err
+		}
+		delay := bo.Step()
+		if t.Budget > 0 && time.Since(start)+delay > t.Budget {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body so that the connection can be reused.
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+
+		if req, err = rewind(r); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *RetryTransport) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// Only retry the transient connection errors, e.g. a refused
+		// connection, and not once the caller gave up on the request.
+		return ctx.Err() == nil && IsRetriable(err)
+	}
+	codes := t.RetryableStatusCodes
+	if codes == nil {
+		codes = DefaultRetryableStatusCodes
+	}
+	return codes.Has(resp.StatusCode)
+}
+
+// isRetriable returns whether the request is idempotent and can be replayed.
+func isRetriable(r *http.Request) bool {
+	if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		return false
+	}
+	if idempotentMethods.Has(r.Method) || r.Method == "" {
+		return true
+	}
+	_, hasKey := r.Header["Idempotency-Key"]
+	_, hasXKey := r.Header["X-Idempotency-Key"]
+	return hasKey || hasXKey
+}
+
+// rewind returns a copy of the request with a fresh body, ready to be resent.
+func rewind(r *http.Request) (*http.Request, error) {
+	req := r.Clone(r.Context())
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	return req, nil
+}
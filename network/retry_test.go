@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var errTest = &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+func TestRetryTransport(t *testing.T) {
+	backoff := wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3}
+
+	tests := []struct {
+		name         string
+		method       string
+		header       http.Header
+		body         string
+		transport    RetryTransport
+		failures     int32
+		wantStatus   int
+		wantAttempts int32
+	}{{
+		name:         "succeeds first time",
+		method:       http.MethodGet,
+		wantStatus:   http.StatusOK,
+		wantAttempts: 1,
+	}, {
+		name:         "succeeds after retries",
+		method:       http.MethodGet,
+		failures:     2,
+		wantStatus:   http.StatusOK,
+		wantAttempts: 3,
+	}, {
+		name:         "gives up after steps",
+		method:       http.MethodGet,
+		failures:     10,
+		wantStatus:   http.StatusServiceUnavailable,
+		wantAttempts: 4,
+	}, {
+		name:         "post is not retried",
+		method:       http.MethodPost,
+		body:         "hello",
+		failures:     2,
+		wantStatus:   http.StatusServiceUnavailable,
+		wantAttempts: 1,
+	}, {
+		name:         "post with idempotency key is retried",
+		method:       http.MethodPost,
+		header:       http.Header{"Idempotency-Key": []string{"abc"}},
+		body:         "hello",
+		failures:     2,
+		wantStatus:   http.StatusOK,
+		wantAttempts: 3,
+	}, {
+		name:         "status not retryable",
+		method:       http.MethodGet,
+		transport:    RetryTransport{RetryableStatusCodes: sets.NewInt(http.StatusBadGateway)},
+		failures:     2,
+		wantStatus:   http.StatusServiceUnavailable,
+		wantAttempts: 1,
+	}, {
+		name:   "budget exhausted",
+		method: http.MethodGet,
+		transport: RetryTransport{
+			Backoff: wait.Backoff{Duration: time.Hour, Steps: 3},
+			Budget:  time.Second,
+		},
+		failures:     2,
+		wantStatus:   http.StatusServiceUnavailable,
+		wantAttempts: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				if b, _ := ioutil.ReadAll(r.Body); string(b) != test.body {
+					t.Errorf("Attempt %d got body %q, want %q", n, b, test.body)
+				}
+				if n <= test.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer ts.Close()
+
+			rt := test.transport
+			if rt.Backoff.Steps == 0 {
+				rt.Backoff = backoff
+			}
+			req, err := http.NewRequest(test.method, ts.URL, bytes.NewBufferString(test.body))
+			if err != nil {
+				t.Fatal("NewRequest() =", err)
+			}
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal("RoundTrip() =", err)
+			}
+			resp.Body.Close()
+			if got, want := resp.StatusCode, test.wantStatus; got != want {
+				t.Errorf("StatusCode = %d, want %d", got, want)
+			}
+			if got, want := atomic.LoadInt32(&attempts), test.wantAttempts; got != want {
+				t.Errorf("Attempts = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestRetryTransportConnectionError(t *testing.T) {
+	var attempts int32
+	rt := &RetryTransport{
+		Inner: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				return nil, errTest
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		Backoff: wait.Backoff{Duration: time.Millisecond, Steps: 5},
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal("RoundTrip() =", err)
+	}
+	if got, want := atomic.LoadInt32(&attempts), int32(3); got != want {
+		t.Errorf("Attempts = %d, want %d", got, want)
+	}
+}
+
+func TestRetryTransportPermanentError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{{
+		name: "unknown authority",
+		err:  &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}},
+	}, {
+		name: "host not found",
+		err:  &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}},
+	}, {
+		name: "unclassified",
+		err:  errors.New("malformed HTTP response"),
+	}, {
+		name: "canceled",
+		err:  context.Canceled,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int32
+			rt := &RetryTransport{
+				Inner: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					atomic.AddInt32(&attempts, 1)
+					return nil, test.err
+				}),
+				Backoff: wait.Backoff{Duration: time.Millisecond, Steps: 5},
+			}
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			if _, err := rt.RoundTrip(req); !errors.Is(err, test.err) {
+				t.Errorf("RoundTrip() = %v, want: %v", err, test.err)
+			}
+			if got, want := atomic.LoadInt32(&attempts), int32(1); got != want {
+				t.Errorf("Attempts = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rt := &RetryTransport{
+		Inner: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			cancel()
+			return nil, errTest
+		}),
+		Backoff: wait.Backoff{Duration: time.Hour, Steps: 5},
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	if _, err := rt.RoundTrip(req); err == nil {
+		t.Error("RoundTrip() = nil, wanted an error")
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultTLSClientTimeout is the timeout of the clients returned by
+	// NewTLSClient when TLSClientOptions.Timeout is not set.
+	DefaultTLSClientTimeout = 30 * time.Second
+)
+
+// TLSClientOptions holds the optional settings of NewTLSClient.
+type TLSClientOptions struct {
+	// Timeout is the overall timeout of a request made by the client.
+	// Defaults to DefaultTLSClientTimeout.
+	Timeout time.Duration
+
+	// MaxIdleConns and MaxIdleConnsPerHost control the connection
+	// pooling of the transport. They default to those of
+	// DefaultTransportOptions.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+}
+
+// NewTLSTransport returns an http.Transport that trusts the certificate
+// authorities in the PEM encoded caPEM, in addition to the system ones.
+func NewTLSTransport(caPEM []byte, opts TLSClientOptions) (*http.Transport, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if len(caPEM) > 0 && !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("failed to parse any certificate from the CA bundle")
+	}
+
+	transport := NewTunedTransport(TransportOptions{
+		MaxIdleConns:        opts.MaxIdleConns,
+		MaxIdleConnsPerHost: opts.MaxIdleConnsPerHost,
+	})
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    pool,
+	}
+	return transport, nil
+}
+
+// NewTLSClient returns an http.Client for delivering to destinations
+// serving certificates signed by the authorities in caPEM, e.g. the
+// CACerts of a Destination.
+func NewTLSClient(caPEM []byte, opts TLSClientOptions) (*http.Client, error) {
+	transport, err := NewTLSTransport(caPEM, opts)
+	if err != nil {
+		return nil, err
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTLSClientTimeout
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewTLSClient(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	c, err := NewTLSClient(caPEM, TLSClientOptions{})
+	if err != nil {
+		t.Fatal("NewTLSClient() =", err)
+	}
+	if got, want := c.Timeout, DefaultTLSClientTimeout; got != want {
+		t.Errorf("Timeout = %v, want %v", got, want)
+	}
+	resp, err := c.Get(ts.URL)
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	resp.Body.Close()
+
+	// Without the CA bundle the server is not trusted.
+	c, err = NewTLSClient(nil, TLSClientOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatal("NewTLSClient() =", err)
+	}
+	if got, want := c.Timeout, time.Second; got != want {
+		t.Errorf("Timeout = %v, want %v", got, want)
+	}
+	if _, err := c.Get(ts.URL); err == nil {
+		t.Error("Get() = nil, wanted an error for an untrusted server")
+	} else if got := ClassifyError(err); got.Reason != "CertificateError" {
+		t.Errorf("ClassifyError() = %v, want a CertificateError", got)
+	}
+}
+
+func TestNewTLSClientInvalidBundle(t *testing.T) {
+	if _, err := NewTLSClient([]byte("not a certificate"), TLSClientOptions{}); err == nil {
+		t.Error("NewTLSClient() = nil, wanted an error")
+	}
+}
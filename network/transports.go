@@ -61,15 +61,25 @@ var DialWithBackOff = NewBackoffDialer(backOffTemplate)
 // exponentially increasing dial timeouts. In addition it sleeps with random jitter
 // between tries.
 func NewBackoffDialer(backoffConfig wait.Backoff) func(context.Context, string, string) (net.Conn, error) {
+	return newBackoffDialer(backoffConfig, 5*time.Second)
+}
+
+// newBackoffDialer is like NewBackoffDialer, but the dialed connections send
+// TCP keep-alive probes with the given period.
+func newBackoffDialer(backoffConfig wait.Backoff, keepAlive time.Duration) func(context.Context, string, string) (net.Conn, error) {
 	return func(ctx context.Context, network, address string) (net.Conn, error) {
-		return dialBackOffHelper(ctx, network, address, backoffConfig, sleepTO)
+		return dialBackOff(ctx, network, address, backoffConfig, sleepTO, keepAlive)
 	}
 }
 
 func dialBackOffHelper(ctx context.Context, network, address string, bo wait.Backoff, sleep time.Duration) (net.Conn, error) {
+	return dialBackOff(ctx, network, address, bo, sleep, 5*time.Second)
+}
+
+func dialBackOff(ctx context.Context, network, address string, bo wait.Backoff, sleep, keepAlive time.Duration) (net.Conn, error) {
 	dialer := &net.Dialer{
 		Timeout:   bo.Duration, // Initial duration.
-		KeepAlive: 5 * time.Second,
+		KeepAlive: keepAlive,
 		DualStack: true,
 	}
 	start := time.Now()
@@ -102,6 +112,64 @@ func newHTTPTransport(disableKeepAlives bool, maxIdle, maxIdlePerHost int) http.
 	return transport
 }
 
+// TransportOptions tune the http.Transport returned by NewTunedTransport.
+// Zero values leave the settings of DefaultTransportOptions in place.
+type TransportOptions struct {
+	// MaxIdleConns is the maximum number of idle connections kept
+	// across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections
+	// kept per host. This is what limits connection churn when fanning
+	// out to few hosts, so it should be close to the expected concurrency.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost, if positive, limits the number of connections
+	// per host, including those in use.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept.
+	IdleConnTimeout time.Duration
+	// KeepAlive is the period of the TCP keep-alive probes.
+	KeepAlive time.Duration
+	// DisableCompression disables the transparent gzip compression,
+	// which only costs CPU for events that are already small.
+	DisableCompression bool
+}
+
+// DefaultTransportOptions returns the options used for event fan-out
+// when none are configured.
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		MaxIdleConns:        1000,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+	}
+}
+
+// NewTunedTransport creates an http.Transport tuned for delivering many
+// concurrent requests, e.g. event fan-out, with the given options.
+func NewTunedTransport(opts TransportOptions) *http.Transport {
+	def := DefaultTransportOptions()
+	if opts.MaxIdleConns <= 0 {
+		opts.MaxIdleConns = def.MaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost <= 0 {
+		opts.MaxIdleConnsPerHost = def.MaxIdleConnsPerHost
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = def.IdleConnTimeout
+	}
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = def.KeepAlive
+	}
+
+	transport := newHTTPTransport(false /*disable keep-alives*/, opts.MaxIdleConns, opts.MaxIdleConnsPerHost).(*http.Transport)
+	transport.DialContext = newBackoffDialer(backOffTemplate, opts.KeepAlive)
+	transport.MaxConnsPerHost = opts.MaxConnsPerHost
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+	transport.DisableCompression = opts.DisableCompression
+	return transport
+}
+
 // NewProberTransport creates a RoundTripper that is useful for probing,
 // since it will not cache connections.
 func NewProberTransport() http.RoundTripper {
@@ -22,6 +22,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -100,3 +101,32 @@ func TestDialWithBackoff(t *testing.T) {
 	}
 	c.Close()
 }
+
+func TestNewTunedTransport(t *testing.T) {
+	tr := NewTunedTransport(TransportOptions{})
+	def := DefaultTransportOptions()
+	if tr.MaxIdleConns != def.MaxIdleConns || tr.MaxIdleConnsPerHost != def.MaxIdleConnsPerHost ||
+		tr.IdleConnTimeout != def.IdleConnTimeout || tr.DisableCompression {
+		t.Errorf("NewTunedTransport() did not apply the defaults: %#v", tr)
+	}
+
+	tr = NewTunedTransport(TransportOptions{
+		MaxIdleConns:        5000,
+		MaxIdleConnsPerHost: 500,
+		MaxConnsPerHost:     1000,
+		IdleConnTimeout:     time.Minute,
+		DisableCompression:  true,
+	})
+	if tr.MaxIdleConns != 5000 || tr.MaxIdleConnsPerHost != 500 || tr.MaxConnsPerHost != 1000 ||
+		tr.IdleConnTimeout != time.Minute || !tr.DisableCompression {
+		t.Errorf("NewTunedTransport() did not apply the options: %#v", tr)
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+	resp, err := (&http.Client{Transport: tr}).Get(s.URL)
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	resp.Body.Close()
+}
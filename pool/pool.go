@@ -18,6 +18,7 @@ package pool
 
 import (
 	"context"
+	"errors"
 	"sync"
 )
 
@@ -115,3 +116,35 @@ func (i *impl) Wait() error {
 
 	return i.result
 }
+
+// ForEach calls f for every index in [0, n), running at most workers calls
+// at a time, and returns the first error returned by f. Once f returned an
+// error, or ctx is canceled, the indices that did not start yet are skipped
+// and the context passed to the running calls is canceled.
+// workers must be at least 1; no more than n workers are started.
+func ForEach(ctx context.Context, workers, n int, f func(ctx context.Context, i int) error) error {
+	if workers < 1 {
+		return errors.New("pool: ForEach needs at least one worker")
+	}
+	if n < 1 {
+		return ctx.Err()
+	}
+	if workers > n {
+		workers = n
+	}
+	parent := ctx
+	p, ctx := NewWithContext(parent, workers, workers)
+	for idx := 0; idx < n && ctx.Err() == nil; idx++ {
+		i := idx
+		p.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return f(ctx, i)
+		})
+	}
+	if err := p.Wait(); err != nil {
+		return err
+	}
+	return parent.Err()
+}
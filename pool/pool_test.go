@@ -19,6 +19,7 @@ package pool
 import (
 	"context"
 	"errors"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -244,3 +245,104 @@ func TestErrorCancelsContext(t *testing.T) {
 		t.Fatalf("pool.Wait() = %v, want: %v", err, want)
 	}
 }
+
+func TestForEach(t *testing.T) {
+	var (
+		active, max int32
+		seen        = make([]int32, 100)
+	)
+	err := ForEach(context.Background(), 5, len(seen), func(ctx context.Context, i int) error {
+		na := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if na <= m || atomic.CompareAndSwapInt32(&max, m, na) {
+				break
+			}
+		}
+		atomic.AddInt32(&seen[i], 1)
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatal("ForEach() =", err)
+	}
+	if got := atomic.LoadInt32(&max); got > 5 {
+		t.Errorf("max active = %d, wanted at most 5", got)
+	}
+	for i, n := range seen {
+		if n != 1 {
+			t.Errorf("f(%d) was called %d times, wanted once", i, n)
+		}
+	}
+}
+
+func TestForEachStopsOnError(t *testing.T) {
+	want := errors.New("this is what I expect")
+	var calls int32
+	err := ForEach(context.Background(), 1, 100, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&calls, 1)
+		if i == 3 {
+			return want
+		}
+		return nil
+	})
+	if err != want {
+		t.Errorf("ForEach() = %v, wanted %v", err, want)
+	}
+	// With a single worker, the work queued before the error skips f.
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Errorf("f was called %d times, wanted 4", got)
+	}
+}
+
+func TestForEachCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ForEach(ctx, 3, 10, func(context.Context, int) error {
+		t.Error("f was called with a canceled context")
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("ForEach() = %v, wanted %v", err, context.Canceled)
+	}
+}
+
+func TestForEachInvalidWorkers(t *testing.T) {
+	for _, workers := range []int{0, -1} {
+		err := ForEach(context.Background(), workers, 10, func(context.Context, int) error {
+			t.Error("f was called without workers")
+			return nil
+		})
+		if err == nil {
+			t.Errorf("ForEach(workers=%d) = nil, wanted an error", workers)
+		}
+	}
+}
+
+func TestForEachMoreWorkersThanItems(t *testing.T) {
+	const n = 3
+	before := runtime.NumGoroutine()
+	var (
+		calls int32
+		wg    sync.WaitGroup
+	)
+	wg.Add(n)
+	err := ForEach(context.Background(), 1000, n, func(context.Context, int) error {
+		atomic.AddInt32(&calls, 1)
+		// Wait for all the calls to run concurrently, so that all the
+		// workers are running when we count the goroutines.
+		wg.Done()
+		wg.Wait()
+		if got := runtime.NumGoroutine() - before; got > 100 {
+			t.Errorf("Started %d goroutines for %d items", got, n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("ForEach() =", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != n {
+		t.Errorf("calls = %d, wanted %d", got, n)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/pprof"
+	"os"
 	"strconv"
 
 	"go.uber.org/atomic"
@@ -31,6 +32,10 @@ const (
 	// ProfilingPort specifies the port where profiling data is available when profiling is enabled
 	ProfilingPort = 8008
 
+	// ProfilingPortEnv is the name of the environment variable that
+	// can be used to serve profiling data on another port than ProfilingPort.
+	ProfilingPortEnv = "PROFILING_PORT"
+
 	// profilingKey is the name of the key in config-observability config map
 	// that indicates whether profiling is enabled
 	profilingKey = "profiling.enable"
@@ -64,6 +69,8 @@ func NewHandler(logger *zap.SugaredLogger, enableProfiling bool) *Handler {
 	}
 }
 
+// ServeHTTP implements http.Handler, serving the profiling data
+// if profiling is enabled and a 404 otherwise.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if h.enabled.Load() {
 		h.handler.ServeHTTP(w, r)
@@ -72,6 +79,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ReadProfilingFlag returns whether profiling is enabled in the
+// given config-observability data. It defaults to false.
 func ReadProfilingFlag(config map[string]string) (bool, error) {
 	profiling, ok := config[profilingKey]
 	if !ok {
@@ -98,10 +107,15 @@ func (h *Handler) UpdateFromConfigMap(configMap *corev1.ConfigMap) {
 	}
 }
 
-// NewServer creates a new http server that exposes profiling data on the default profiling port
+// NewServer creates a new http server that exposes profiling data on the default profiling port,
+// or the one in the PROFILING_PORT environment variable if set.
 func NewServer(handler http.Handler) *http.Server {
+	port := strconv.Itoa(ProfilingPort)
+	if p := os.Getenv(ProfilingPortEnv); p != "" {
+		port = p
+	}
 	return &http.Server{
-		Addr:    ":" + strconv.Itoa(ProfilingPort),
+		Addr:    ":" + port,
 		Handler: handler,
 	}
 }
@@ -19,6 +19,7 @@ package profiling
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	"go.uber.org/zap"
@@ -101,3 +102,15 @@ func TestUpdateFromConfigMap(t *testing.T) {
 		})
 	}
 }
+
+func TestNewServer(t *testing.T) {
+	if got, want := NewServer(nil).Addr, ":8008"; got != want {
+		t.Errorf("Addr = %s, want %s", got, want)
+	}
+
+	os.Setenv(ProfilingPortEnv, "18008")
+	defer os.Unsetenv(ProfilingPortEnv)
+	if got, want := NewServer(nil).Addr, ":18008"; got != want {
+		t.Errorf("Addr = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ptr
+
+import "time"
+
+// Int32Value is a helper for reading optional fields of API types
+// that are *int32. It returns 0 for nil.
+func Int32Value(p *int32) int32 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// Int64Value is a helper for reading optional fields of API types
+// that are *int64. It returns 0 for nil.
+func Int64Value(p *int64) int64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// Float32Value is a helper for reading optional fields of API types
+// that are *float32. It returns 0 for nil.
+func Float32Value(p *float32) float32 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// Float64Value is a helper for reading optional fields of API types
+// that are *float64. It returns 0 for nil.
+func Float64Value(p *float64) float64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// BoolValue is a helper for reading optional fields of API types
+// that are *bool. It returns false for nil.
+func BoolValue(p *bool) bool {
+	if p == nil {
+		return false
+	}
+	return *p
+}
+
+// StringValue is a helper for reading optional fields of API types
+// that are *string. It returns the empty string for nil.
+func StringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+// DurationValue is a helper for reading optional fields of API types
+// that are *time.Duration. It returns 0 for nil.
+func DurationValue(p *time.Duration) time.Duration {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// TimeValue is a helper for reading optional fields of API types
+// that are *time.Time. It returns the zero time for nil.
+func TimeValue(p *time.Time) time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	return *p
+}
+
+// Int32Equal returns whether the optional fields a and b are either
+// both unset, or both set to the same value.
+func Int32Equal(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Int64Equal returns whether the optional fields a and b are either
+// both unset, or both set to the same value.
+func Int64Equal(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Float32Equal returns whether the optional fields a and b are either
+// both unset, or both set to the same value.
+func Float32Equal(a, b *float32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Float64Equal returns whether the optional fields a and b are either
+// both unset, or both set to the same value.
+func Float64Equal(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// BoolEqual returns whether the optional fields a and b are either
+// both unset, or both set to the same value.
+func BoolEqual(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// StringEqual returns whether the optional fields a and b are either
+// both unset, or both set to the same value.
+func StringEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// DurationEqual returns whether the optional fields a and b are either
+// both unset, or both set to the same value.
+func DurationEqual(a, b *time.Duration) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// TimeEqual returns whether the optional fields a and b are either
+// both unset, or both set to the same value.
+func TimeEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
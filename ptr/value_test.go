@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ptr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValues(t *testing.T) {
+	now := time.Now()
+
+	if got := Int32Value(nil); got != 0 {
+		t.Errorf("Int32Value(nil) = %v, wanted 0", got)
+	}
+	if got := Int32Value(Int32(55)); got != 55 {
+		t.Errorf("Int32Value() = %v, wanted 55", got)
+	}
+	if got := Int64Value(Int64(55)); got != 55 {
+		t.Errorf("Int64Value() = %v, wanted 55", got)
+	}
+	if got := Float32Value(Float32(1.25)); got != 1.25 {
+		t.Errorf("Float32Value() = %v, wanted 1.25", got)
+	}
+	if got := Float64Value(nil); got != 0 {
+		t.Errorf("Float64Value(nil) = %v, wanted 0", got)
+	}
+	if got := BoolValue(nil); got {
+		t.Errorf("BoolValue(nil) = %v, wanted false", got)
+	}
+	if got := StringValue(String("foo")); got != "foo" {
+		t.Errorf("StringValue() = %q, wanted foo", got)
+	}
+	if got := StringValue(nil); got != "" {
+		t.Errorf("StringValue(nil) = %q, wanted empty", got)
+	}
+	if got := DurationValue(Duration(time.Second)); got != time.Second {
+		t.Errorf("DurationValue() = %v, wanted 1s", got)
+	}
+	if got := TimeValue(Time(now)); !got.Equal(now) {
+		t.Errorf("TimeValue() = %v, wanted %v", got, now)
+	}
+	if got := TimeValue(nil); !got.IsZero() {
+		t.Errorf("TimeValue(nil) = %v, wanted the zero time", got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"both nil", Int32Equal(nil, nil), true},
+		{"one nil", Int32Equal(Int32(1), nil), false},
+		{"other nil", Int32Equal(nil, Int32(1)), false},
+		{"same value", Int32Equal(Int32(1), Int32(1)), true},
+		{"different value", Int32Equal(Int32(1), Int32(2)), false},
+		{"int64", Int64Equal(Int64(1), Int64(1)), true},
+		{"float32", Float32Equal(Float32(1), Float32(2)), false},
+		{"float64", Float64Equal(Float64(1), Float64(1)), true},
+		{"bool", BoolEqual(Bool(false), nil), false},
+		{"string", StringEqual(String("a"), String("a")), true},
+		{"duration", DurationEqual(Duration(time.Second), Duration(time.Minute)), false},
+		{"time in other zone", TimeEqual(Time(time.Unix(0, 0).UTC()), Time(time.Unix(0, 0).In(time.FixedZone("x", 3600)))), true},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
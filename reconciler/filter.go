@@ -16,12 +16,14 @@ limitations under the License.
 
 package reconciler
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"knative.dev/pkg/kmeta"
+)
 
 // AnnotationFilterFunc creates a FilterFunc only accepting objects with given annotation key and value
 func AnnotationFilterFunc(key, value string, allowUnset bool) func(interface{}) bool {
 	return func(obj interface{}) bool {
-		if mo, ok := obj.(metav1.Object); ok {
+		if mo, ok := kmeta.DeletionHandlingObject(obj); ok {
 			return mapHasOrDefault(mo.GetAnnotations(), key, value, allowUnset)
 		}
 		return false
@@ -31,7 +33,7 @@ func AnnotationFilterFunc(key, value string, allowUnset bool) func(interface{})
 // LabelExistsFilterFunc creates a FilterFunc only accepting objects which have a given label.
 func LabelExistsFilterFunc(label string) func(obj interface{}) bool {
 	return func(obj interface{}) bool {
-		if mo, ok := obj.(metav1.Object); ok {
+		if mo, ok := kmeta.DeletionHandlingObject(obj); ok {
 			labels := mo.GetLabels()
 			_, ok := labels[label]
 			return ok
@@ -43,7 +45,7 @@ func LabelExistsFilterFunc(label string) func(obj interface{}) bool {
 // LabelFilterFunc creates a FilterFunc only accepting objects where a label is set to a specific value.
 func LabelFilterFunc(label, value string, allowUnset bool) func(interface{}) bool {
 	return func(obj interface{}) bool {
-		if mo, ok := obj.(metav1.Object); ok {
+		if mo, ok := kmeta.DeletionHandlingObject(obj); ok {
 			return mapHasOrDefault(mo.GetLabels(), label, value, allowUnset)
 		}
 		return false
@@ -53,7 +55,7 @@ func LabelFilterFunc(label, value string, allowUnset bool) func(interface{}) boo
 // NameFilterFunc creates a FilterFunc only accepting objects with the given name.
 func NameFilterFunc(name string) func(interface{}) bool {
 	return func(obj interface{}) bool {
-		if mo, ok := obj.(metav1.Object); ok {
+		if mo, ok := kmeta.DeletionHandlingObject(obj); ok {
 			return mo.GetName() == name
 		}
 		return false
@@ -63,7 +65,7 @@ func NameFilterFunc(name string) func(interface{}) bool {
 // NamespaceFilterFunc creates a FilterFunc only accepting objects in the given namespace.
 func NamespaceFilterFunc(namespace string) func(interface{}) bool {
 	return func(obj interface{}) bool {
-		if mo, ok := obj.(metav1.Object); ok {
+		if mo, ok := kmeta.DeletionHandlingObject(obj); ok {
 			return mo.GetNamespace() == namespace
 		}
 		return false
@@ -89,6 +91,18 @@ func ChainFilterFuncs(funcs ...func(interface{}) bool) func(interface{}) bool {
 	}
 }
 
+// ChainFilterFuncsOr creates a FilterFunc which performs an OR of the passed FilterFuncs.
+func ChainFilterFuncsOr(funcs ...func(interface{}) bool) func(interface{}) bool {
+	return func(obj interface{}) bool {
+		for _, f := range funcs {
+			if f(obj) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // mapHasOrDefault returns true if the map has the key and its value is equal to value.
 // If the key is not found, it returns defaultValue.
 func mapHasOrDefault(m map[string]string, key string, value string, defaultValue bool) bool {
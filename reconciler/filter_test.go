@@ -21,6 +21,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 const (
@@ -293,6 +294,76 @@ func TestChainFilterFuncs(t *testing.T) {
 	}
 }
 
+func TestChainFilterFuncsOr(t *testing.T) {
+	tc := []struct {
+		name  string
+		chain []bool
+		want  bool
+	}{{
+		name: "empty",
+		want: false,
+	}, {
+		name:  "single true",
+		chain: []bool{true},
+		want:  true,
+	}, {
+		name:  "single false",
+		chain: []bool{false},
+		want:  false,
+	}, {
+		name:  "second true",
+		chain: []bool{false, true},
+		want:  true,
+	}, {
+		name:  "multi false",
+		chain: []bool{false, false},
+		want:  false,
+	}}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			filters := make([]func(interface{}) bool, len(test.chain))
+			for i, chainVal := range test.chain {
+				chainVal := chainVal
+				filters[i] = func(interface{}) bool {
+					return chainVal
+				}
+			}
+			filter := ChainFilterFuncsOr(filters...)
+			got := filter(nil)
+			if got != test.want {
+				t.Errorf("ChainFilterFuncsOr() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFiltersSeeThroughTombstones(t *testing.T) {
+	tombstone := cache.DeletedFinalStateUnknown{
+		Key: namespaceToFilter + "/" + nameToFilter,
+		Obj: pod(namespaceToFilter, nameToFilter,
+			map[string]string{keyToFilter: valueToFilter},
+			map[string]string{keyToFilter: valueToFilter}),
+	}
+
+	filters := map[string]func(interface{}) bool{
+		"AnnotationFilterFunc":  AnnotationFilterFunc(keyToFilter, valueToFilter, false),
+		"LabelExistsFilterFunc": LabelExistsFilterFunc(keyToFilter),
+		"LabelFilterFunc":       LabelFilterFunc(keyToFilter, valueToFilter, false),
+		"NameFilterFunc":        NameFilterFunc(nameToFilter),
+		"NamespaceFilterFunc":   NamespaceFilterFunc(namespaceToFilter),
+	}
+	for name, filter := range filters {
+		if !filter(tombstone) {
+			t.Errorf("%s(tombstone) = false, want true", name)
+		}
+	}
+
+	if NameFilterFunc(nameToFilter)(cache.DeletedFinalStateUnknown{Obj: "not an object"}) {
+		t.Error("NameFilterFunc(tombstone of non-object) = true, want false")
+	}
+}
+
 func TestNotFilter(t *testing.T) {
 	odd := func(o interface{}) bool {
 		// Return true if odd.
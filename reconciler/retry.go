@@ -16,10 +16,13 @@ limitations under the License.
 package reconciler
 
 import (
+	"encoding/json"
 	"strings"
 
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/util/retry"
+
+	"knative.dev/pkg/apis/duck"
 )
 
 // RetryUpdateConflicts retries the inner function if it returns conflict errors.
@@ -28,6 +31,31 @@ func RetryUpdateConflicts(updater func(int) error) error {
 	return RetryErrors(updater, apierrs.IsConflict)
 }
 
+// RetryUpdateConflictsOrPatch behaves like RetryUpdateConflicts, but if the
+// updater still conflicts once the retries are exhausted, it falls back on the
+// patcher, which is expected to apply the same change as a JSON merge patch
+// (see StatusMergePatch). Merge patches carry no resourceVersion precondition,
+// so they land where the read-modify-write cycle of an Update keeps losing the
+// race, e.g. for statuses that are updated very frequently.
+func RetryUpdateConflictsOrPatch(updater func(int) error, patcher func() error) error {
+	err := RetryUpdateConflicts(updater)
+	if apierrs.IsConflict(err) {
+		return patcher()
+	}
+	return err
+}
+
+// StatusMergePatch returns the JSON merge patch that turns the status before
+// into the status after, nested under the "status" key, so that it can be
+// applied to the status subresource of the object owning them.
+func StatusMergePatch(before, after interface{}) ([]byte, error) {
+	patch, err := duck.CreateMergePatch(before, after)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]json.RawMessage{"status": patch})
+}
+
 // RetryErrors retries the inner function if it returns matching errors.
 func RetryErrors(updater func(int) error, fns ...func(error) bool) error {
 	attempts := 0
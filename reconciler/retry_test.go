@@ -79,6 +79,83 @@ func TestRetryUpdateConflicts(t *testing.T) {
 	}
 }
 
+func TestRetryUpdateConflictsOrPatch(t *testing.T) {
+	errAny := errors.New("foo")
+	errConflict := apierrs.NewConflict(v1.Resource("foo"), "bar", errAny)
+
+	tests := []struct {
+		name         string
+		returns      []error
+		patchErr     error
+		want         error
+		wantAttempts int
+		wantPatched  bool
+	}{{
+		name:         "all good",
+		returns:      []error{nil},
+		wantAttempts: 1,
+	}, {
+		name:         "no fallback on non-conflict error",
+		returns:      []error{errAny},
+		want:         errAny,
+		wantAttempts: 1,
+	}, {
+		name:         "eventually succeed",
+		returns:      []error{errConflict, nil},
+		wantAttempts: 2,
+	}, {
+		name:         "fall back on patch after persistent conflicts",
+		returns:      []error{errConflict, errConflict, errConflict, errConflict, errConflict},
+		wantAttempts: 5,
+		wantPatched:  true,
+	}, {
+		name:         "patch fails",
+		returns:      []error{errConflict, errConflict, errConflict, errConflict, errConflict},
+		patchErr:     errAny,
+		want:         errAny,
+		wantAttempts: 5,
+		wantPatched:  true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts, patched := 0, false
+			got := RetryUpdateConflictsOrPatch(func(i int) error {
+				attempts++
+				return test.returns[i]
+			}, func() error {
+				patched = true
+				return test.patchErr
+			})
+
+			if got != test.want {
+				t.Errorf("RetryUpdateConflictsOrPatch() = %v, want %v", got, test.want)
+			}
+			if attempts != test.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, test.wantAttempts)
+			}
+			if patched != test.wantPatched {
+				t.Errorf("patched = %v, want %v", patched, test.wantPatched)
+			}
+		})
+	}
+}
+
+func TestStatusMergePatch(t *testing.T) {
+	type status struct {
+		Replicas int32  `json:"replicas,omitempty"`
+		Selector string `json:"selector,omitempty"`
+	}
+
+	got, err := StatusMergePatch(status{Replicas: 1, Selector: "a=b"}, status{Replicas: 3, Selector: "a=b"})
+	if err != nil {
+		t.Fatal("StatusMergePatch() =", err)
+	}
+	if want := `{"status":{"replicas":3}}`; string(got) != want {
+		t.Errorf("StatusMergePatch() = %s, want %s", got, want)
+	}
+}
+
 func TestRetryTestErrors(t *testing.T) {
 	errAny := errors.New("foo")
 	errGKE := errors.New(`Operation cannot be fulfilled on resourcequotas "gke-resource-quotas": StorageError: invalid object, Code: 4, Key: /registry/resourcequotas/serving-tests/gke-resource-quotas, ResourceVersion: 0, AdditionalErrorMsg: Precondition failed: UID in precondition: 7aaedbdf-caa8-41e7-94cb-f8c053038e86, UID in object meta:`)
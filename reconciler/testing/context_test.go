@@ -0,0 +1,42 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"knative.dev/pkg/injection"
+)
+
+func TestSetupFakeContext(t *testing.T) {
+	ctx, cancel, _ := SetupFakeContextWithCancel(t, func(ctx context.Context) context.Context {
+		return injection.WithNamespaceScope(ctx, "ns")
+	})
+	defer cancel()
+
+	if got, want := injection.GetNamespaceScope(ctx), "ns"; got != want {
+		t.Errorf("GetNamespaceScope() = %q, wanted %q", got, want)
+	}
+	if injection.GetConfig(ctx) == nil {
+		t.Error("GetConfig() = nil, wanted the fake config")
+	}
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("Context was not canceled")
+	}
+}
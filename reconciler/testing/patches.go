@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/types"
+	clientgotesting "k8s.io/client-go/testing"
+)
+
+// NewPatch returns a PatchActionImpl for the resource namespace/name
+// carrying the given patch of the given type.
+func NewPatch(namespace, name string, pt types.PatchType, patch []byte) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Namespace = namespace
+	action.Name = name
+	action.PatchType = pt
+	action.Patch = patch
+	return action
+}
+
+// NewStatusPatch is like NewPatch, but the resulting action targets
+// the `status` subresource, as expected by TableRow.WantStatusPatches.
+func NewStatusPatch(namespace, name string, pt types.PatchType, patch []byte) clientgotesting.PatchActionImpl {
+	action := NewPatch(namespace, name, pt, patch)
+	action.Subresource = "status"
+	return action
+}
+
+// PatchFinalizers returns the merge patch the generated reconcilers issue
+// to set the finalizers of namespace/name to the given list at the given
+// resource version. Passing no finalizers yields the patch that clears them.
+func PatchFinalizers(namespace, name, resourceVersion string, finalizers ...string) clientgotesting.PatchActionImpl {
+	if finalizers == nil {
+		finalizers = []string{}
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": resourceVersion,
+		},
+	})
+	return NewPatch(namespace, name, types.MergePatchType, patch)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPatchFinalizers(t *testing.T) {
+	tests := []struct {
+		name       string
+		finalizers []string
+		want       string
+	}{{
+		name:       "add",
+		finalizers: []string{"foo.knative.dev"},
+		want:       `{"metadata":{"finalizers":["foo.knative.dev"],"resourceVersion":"v1"}}`,
+	}, {
+		name: "clear",
+		want: `{"metadata":{"finalizers":[],"resourceVersion":"v1"}}`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := PatchFinalizers("ns", "name", "v1", test.finalizers...)
+			if got, want := string(got.GetPatch()), test.want; got != want {
+				t.Errorf("Patch = %s, want: %s", got, want)
+			}
+			if got, want := got.GetPatchType(), types.MergePatchType; got != want {
+				t.Errorf("PatchType = %s, want: %s", got, want)
+			}
+			if got.GetNamespace() != "ns" || got.GetName() != "name" {
+				t.Errorf("Patch targets %s/%s, want: ns/name", got.GetNamespace(), got.GetName())
+			}
+			if got.GetSubresource() != "" {
+				t.Errorf("Subresource = %q, want none", got.GetSubresource())
+			}
+		})
+	}
+}
+
+func TestNewStatusPatch(t *testing.T) {
+	got := NewStatusPatch("ns", "name", types.MergePatchType, []byte(`{}`))
+	if got, want := got.GetSubresource(), "status"; got != want {
+		t.Errorf("Subresource = %q, want: %q", got, want)
+	}
+}
+
+func TestPatchDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     string
+		got      string
+		wantDiff bool
+	}{{
+		name: "identical",
+		want: `{"a":1}`,
+		got:  `{"a":1}`,
+	}, {
+		name:     "different json",
+		want:     `{"a":1,"b":{"c":"d"}}`,
+		got:      `{"a":1,"b":{"c":"e"}}`,
+		wantDiff: true,
+	}, {
+		name:     "different key order",
+		want:     `{"a":1,"b":2}`,
+		got:      `{"b":2,"a":1}`,
+		wantDiff: true,
+	}, {
+		name:     "not json",
+		want:     `foo`,
+		got:      `bar`,
+		wantDiff: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := patchDiff([]byte(test.want), []byte(test.got)); (diff != "") != test.wantDiff {
+				t.Errorf("patchDiff() = %q, wantDiff: %v", diff, test.wantDiff)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package testing
 
 import (
 	"context"
+	"encoding/json"
 	"path"
 	"reflect"
 	"strings"
@@ -77,6 +78,10 @@ type TableRow struct {
 	// WantPatches holds the ordered list of Patch calls we expect during reconciliation.
 	WantPatches []clientgotesting.PatchActionImpl
 
+	// WantStatusPatches holds the ordered list of Patch calls, with `status` subresource set,
+	// that we expect during reconciliation.
+	WantStatusPatches []clientgotesting.PatchActionImpl
+
 	// WantEvents holds the ordered list of events we expect during reconciliation.
 	WantEvents []string
 
@@ -88,6 +93,10 @@ type TableRow struct {
 	// in the same namespace with its child resources.
 	SkipNamespaceValidation bool
 
+	// PreConditions allows custom setup to be made right before reconciliation,
+	// e.g. registering the metric views PostConditions assert on.
+	PreConditions []func(*testing.T, *TableRow)
+
 	// PostConditions allows custom assertions to be made after reconciliation
 	PostConditions []func(*testing.T, *TableRow)
 
@@ -156,6 +165,10 @@ func (r *TableRow) Test(t *testing.T, factory Factory) {
 		ctx = logging.WithLogger(ctx, l)
 	}
 
+	for _, setup := range r.PreConditions {
+		setup(t, r)
+	}
+
 	// Run the Reconcile we're testing.
 	if err := c.Reconcile(ctx, r.Key); (err != nil) != r.WantErr {
 		t.Errorf("Reconcile() error = %v, WantErr %v", err, r.WantErr)
@@ -314,29 +327,20 @@ func (r *TableRow) Test(t *testing.T, factory Factory) {
 		}
 	}
 
-	for i, want := range r.WantPatches {
-		if i >= len(actions.Patches) {
-			t.Errorf("Missing patch: %#v; raw: %s", want, string(want.GetPatch()))
-			continue
-		}
+	patches := filterPatchesWithSubresource("", actions.Patches)
+	r.checkPatches(t, "patch", r.WantPatches, patches, expectedNamespace)
 
-		got := actions.Patches[i]
-		if got.GetName() != want.GetName() {
-			t.Errorf("Unexpected patch[%d]: %#v", i, got)
-		}
-		if (!r.SkipNamespaceValidation && got.GetNamespace() != expectedNamespace) &&
-			(!r.SkipNamespaceValidation && got.GetResource().GroupResource().Resource != "namespaces" &&
-				got.GetName() != expectedNamespace) {
-			t.Errorf("Unexpected patch[%d]: %#v", i, got)
-		}
-		if got, want := string(got.GetPatch()), string(want.GetPatch()); got != want {
-			t.Errorf("Unexpected patch(-want, +got):\n%s", cmp.Diff(want, got))
-		}
-	}
-	if got, want := len(actions.Patches), len(r.WantPatches); got > want {
-		for _, extra := range actions.Patches[want:] {
-			t.Errorf("Extra patch: %#v; raw: %s", extra, string(extra.GetPatch()))
+	statusPatches := filterPatchesWithSubresource("status", actions.Patches)
+	r.checkPatches(t, "status patch", r.WantStatusPatches, statusPatches, expectedNamespace)
+
+	if len(patches)+len(statusPatches) != len(actions.Patches) {
+		var unexpected []clientgotesting.PatchAction
+		for _, patch := range actions.Patches {
+			if patch.GetSubresource() != "status" && patch.GetSubresource() != "" {
+				unexpected = append(unexpected, patch)
+			}
 		}
+		t.Errorf("Unexpected subresource patches occurred %#v", unexpected)
 	}
 
 	gotEvents := eventList.Events()
@@ -361,6 +365,73 @@ func (r *TableRow) Test(t *testing.T, factory Factory) {
 	}
 }
 
+// checkPatches compares the recorded patches against the expected ones,
+// reporting missing, extra and mismatched patches. The kind is used to
+// distinguish between patch flavors in the error messages.
+func (r *TableRow) checkPatches(t *testing.T, kind string,
+	wantPatches []clientgotesting.PatchActionImpl, gotPatches []clientgotesting.PatchAction,
+	expectedNamespace string) {
+	t.Helper()
+	for i, want := range wantPatches {
+		if i >= len(gotPatches) {
+			t.Errorf("Missing %s: %#v; raw: %s", kind, want, string(want.GetPatch()))
+			continue
+		}
+
+		got := gotPatches[i]
+		if got.GetName() != want.GetName() {
+			t.Errorf("Unexpected %s[%d]: %#v", kind, i, got)
+		}
+		if (!r.SkipNamespaceValidation && got.GetNamespace() != expectedNamespace) &&
+			(!r.SkipNamespaceValidation && got.GetResource().GroupResource().Resource != "namespaces" &&
+				got.GetName() != expectedNamespace) {
+			t.Errorf("Unexpected %s[%d]: %#v", kind, i, got)
+		}
+		if want.GetPatchType() != "" && got.GetPatchType() != want.GetPatchType() {
+			t.Errorf("Unexpected %s[%d] type = %s, want: %s", kind, i, got.GetPatchType(), want.GetPatchType())
+		}
+		if diff := patchDiff(want.GetPatch(), got.GetPatch()); diff != "" {
+			t.Errorf("Unexpected %s(-want, +got):\n%s", kind, diff)
+		}
+	}
+	if got, want := len(gotPatches), len(wantPatches); got > want {
+		for _, extra := range gotPatches[want:] {
+			t.Errorf("Extra %s: %#v; raw: %s", kind, extra, string(extra.GetPatch()))
+		}
+	}
+}
+
+// patchDiff returns a human readable diff between the two patches, or
+// the empty string if they are the same. Patches are compared byte for
+// byte, and if they differ and both are valid JSON, the diff is computed
+// over the decoded documents, so that it points at the offending field
+// rather than at an offset in a long single line string.
+func patchDiff(want, got []byte) string {
+	if string(want) == string(got) {
+		return ""
+	}
+	var wantDoc, gotDoc interface{}
+	if json.Unmarshal(want, &wantDoc) != nil || json.Unmarshal(got, &gotDoc) != nil {
+		return cmp.Diff(string(want), string(got))
+	}
+	if diff := cmp.Diff(wantDoc, gotDoc); diff != "" {
+		return diff
+	}
+	// Semantically equal, but serialized differently (e.g. key order).
+	return cmp.Diff(string(want), string(got))
+}
+
+func filterPatchesWithSubresource(
+	subresource string,
+	actions []clientgotesting.PatchAction) (result []clientgotesting.PatchAction) {
+	for _, action := range actions {
+		if action.GetSubresource() == subresource {
+			result = append(result, action)
+		}
+	}
+	return
+}
+
 func filterUpdatesWithSubresource(
 	subresource string,
 	actions []clientgotesting.UpdateAction) (result []clientgotesting.UpdateAction) {
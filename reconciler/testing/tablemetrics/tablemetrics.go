@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tablemetrics holds the PreConditions and PostConditions asserting
+// on the metrics recorded by a row of a reconciler table test. They live
+// apart from reconciler/testing so that the table tests not asserting on
+// metrics don't depend on the exporters.
+package tablemetrics
+
+import (
+	"testing"
+
+	"go.opencensus.io/stats/view"
+
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricstest"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+// WithViews returns a PreCondition registering the given views afresh, so
+// that only the data recorded by the row is seen.
+func WithViews(views ...*view.View) func(*testing.T, *rtesting.TableRow) {
+	return func(t *testing.T, _ *rtesting.TableRow) {
+		// Unregistering drops any data previously recorded for these views.
+		view.Unregister(views...)
+		if err := view.Register(views...); err != nil {
+			t.Fatal("Failed to register metric views:", err)
+		}
+	}
+}
+
+// WithGCPMetadata returns a PreCondition reporting the given metadata in
+// place of querying the GCE metadata server, until the end of the test.
+func WithGCPMetadata(md metrics.FakeGCPMetadata) func(*testing.T, *rtesting.TableRow) {
+	return func(t *testing.T, _ *rtesting.TableRow) {
+		t.Cleanup(metrics.SetFakeGCPMetadata(md))
+	}
+}
+
+// WantMetrics returns a PostCondition asserting that the given metrics have
+// been recorded during reconciliation.
+func WantMetrics(want ...metricstest.Metric) func(*testing.T, *rtesting.TableRow) {
+	return func(t *testing.T, _ *rtesting.TableRow) {
+		metricstest.AssertMetric(t, want...)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tablemetrics
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/metrics/metricstest"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+var (
+	tableTestCount = stats.Int64("table_test_reconciles", "Reconciles in the table test", stats.UnitDimensionless)
+	tableTestKey   = tag.MustNewKey("key")
+	tableTestView  = &view.View{
+		Description: tableTestCount.Description(),
+		Measure:     tableTestCount,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{tableTestKey},
+	}
+)
+
+type countingReconciler struct{}
+
+func (countingReconciler) Reconcile(ctx context.Context, key string) error {
+	ctx, err := tag.New(ctx, tag.Insert(tableTestKey, key))
+	if err != nil {
+		return err
+	}
+	stats.Record(ctx, tableTestCount.M(1))
+	return nil
+}
+
+func TestTableWantMetrics(t *testing.T) {
+	factory := func(*testing.T, *rtesting.TableRow) (controller.Reconciler, rtesting.ActionRecorderList, rtesting.EventList) {
+		return countingReconciler{}, nil, rtesting.EventList{Recorder: record.NewFakeRecorder(10)}
+	}
+
+	// Both rows expect a single reconcile, since the views are registered
+	// afresh for every row.
+	rtesting.TableTest{{
+		Name:          "first",
+		Key:           "ns/first",
+		PreConditions: []func(*testing.T, *rtesting.TableRow){WithViews(tableTestView)},
+		PostConditions: []func(*testing.T, *rtesting.TableRow){WantMetrics(
+			metricstest.IntMetric("table_test_reconciles", 1, map[string]string{"key": "ns/first"}),
+		)},
+	}, {
+		Name:          "second",
+		Key:           "ns/second",
+		PreConditions: []func(*testing.T, *rtesting.TableRow){WithViews(tableTestView)},
+		PostConditions: []func(*testing.T, *rtesting.TableRow){WantMetrics(
+			metricstest.IntMetric("table_test_reconciles", 1, map[string]string{"key": "ns/second"}),
+		)},
+	}}.Test(t, factory)
+}
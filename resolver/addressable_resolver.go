@@ -155,12 +155,11 @@ func (r *URIResolver) URIFromObjectReference(ctx context.Context, ref *corev1.Ob
 	// Callable interface.
 	// TODO(spencer-p,n3wscott) Verify that the service actually exists in K8s.
 	if ref.APIVersion == "v1" && ref.Kind == "Service" {
-		url := &apis.URL{
-			Scheme: "http",
-			Host:   ServiceHostName(ref.Name, ref.Namespace),
-			Path:   "/",
+		url, err := network.FromContextOrDefaults(ctx).GetServiceURL(ref.Name, ref.Namespace)
+		if err != nil {
+			return nil, apierrs.NewBadRequest(err.Error())
 		}
-		return url, nil
+		return (*apis.URL)(url), nil
 	}
 
 	_, lister, err := r.informerFactory.Get(ctx, gvr)
@@ -191,6 +190,15 @@ func (r *URIResolver) URIFromObjectReference(ctx context.Context, ref *corev1.Ob
 }
 
 // ServiceHostName resolves the hostname for a Kubernetes Service.
+//
+// Deprecated: Use ServiceHostNameFromContext, which follows the
+// domain-template of config-network.
 func ServiceHostName(serviceName, namespace string) string {
-	return fmt.Sprintf("%s.%s.svc.%s", serviceName, namespace, network.GetClusterDomainName())
+	return network.GetServiceHostname(serviceName, namespace)
+}
+
+// ServiceHostNameFromContext resolves the hostname for a Kubernetes Service
+// with the domain template of the config-network of the context.
+func ServiceHostNameFromContext(ctx context.Context, serviceName, namespace string) (string, error) {
+	return network.FromContextOrDefaults(ctx).GetServiceHostname(serviceName, namespace)
 }
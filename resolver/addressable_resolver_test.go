@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -33,6 +34,9 @@ import (
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/client/injection/ducks/duck/v1/addressable"
 	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/network"
+	"knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
 )
 
@@ -583,6 +587,42 @@ func TestURIFromObjectReferenceErrors(t *testing.T) {
 	}
 }
 
+func TestServiceURIFollowsNetworkConfig(t *testing.T) {
+	ctx, _ := fakedynamicclient.With(context.Background(), scheme.Scheme)
+	ctx = addressable.WithDuck(ctx)
+	r := resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+
+	// The Store is what a controller passes as its ConfigStore.
+	var store reconciler.ConfigStore = network.NewStore(logtesting.TestLogger(t))
+	store.(*network.Store).OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: network.ConfigName},
+		Data: map[string]string{
+			network.DomainTemplateKey:        "{{.Name}}.{{.Namespace}}.example.com",
+			network.DefaultExternalSchemeKey: "https",
+		},
+	})
+	ctx = store.ToContext(ctx)
+
+	uri, err := r.URIFromDestinationV1(ctx, duckv1.Destination{Ref: getK8SServiceRef()}, getAddressable())
+	if err != nil {
+		t.Fatal("URIFromDestinationV1() =", err)
+	}
+	if got, want := uri.String(), "https://testsink.testnamespace.example.com/"; got != want {
+		t.Errorf("URIFromDestinationV1() = %s, want %s", got, want)
+	}
+
+	host, err := resolver.ServiceHostNameFromContext(ctx, "testsink", "testnamespace")
+	if err != nil {
+		t.Fatal("ServiceHostNameFromContext() =", err)
+	}
+	if got, want := host, "testsink.testnamespace.example.com"; got != want {
+		t.Errorf("ServiceHostNameFromContext() = %s, want %s", got, want)
+	}
+	if got, want := resolver.ServiceHostName("testsink", "testnamespace"), network.GetServiceHostname("testsink", "testnamespace"); got != want {
+		t.Errorf("ServiceHostName() = %s, want %s", got, want)
+	}
+}
+
 func getAddressable() *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
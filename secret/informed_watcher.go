@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/informers/internalinterfaces"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewInformedWatcherFromFactory watches a Kubernetes namespace for Secret changes.
+func NewInformedWatcherFromFactory(sif informers.SharedInformerFactory, namespace string) *InformedWatcher {
+	return &InformedWatcher{
+		sif:      sif,
+		informer: sif.Core().V1().Secrets(),
+		ManualWatcher: ManualWatcher{
+			Namespace: namespace,
+		},
+		defaults: make(map[string]*corev1.Secret),
+	}
+}
+
+// NewInformedWatcher watches a Kubernetes namespace for Secret changes.
+// Optional label requirements allow restricting the list of Secret objects
+// that is tracked by the underlying Informer.
+func NewInformedWatcher(kc kubernetes.Interface, namespace string, lr ...labels.Requirement) *InformedWatcher {
+	return NewInformedWatcherFromFactory(informers.NewSharedInformerFactoryWithOptions(
+		kc,
+		0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(addLabelRequirementsToListOptions(lr)),
+	), namespace)
+}
+
+// addLabelRequirementsToListOptions returns a function which injects label
+// requirements to existing metav1.ListOptions.
+func addLabelRequirementsToListOptions(lr []labels.Requirement) internalinterfaces.TweakListOptionsFunc {
+	if len(lr) == 0 {
+		return nil
+	}
+
+	return func(lo *metav1.ListOptions) {
+		sel, err := labels.Parse(lo.LabelSelector)
+		if err != nil {
+			panic(fmt.Errorf("could not parse label selector %q: %w", lo.LabelSelector, err))
+		}
+		lo.LabelSelector = sel.Add(lr...).String()
+	}
+}
+
+// InformedWatcher provides an informer-based implementation of Watcher.
+type InformedWatcher struct {
+	sif      informers.SharedInformerFactory
+	informer corev1informers.SecretInformer
+	started  bool
+
+	// defaults are the default Secrets to use if the real ones do not exist or are deleted.
+	defaults map[string]*corev1.Secret
+
+	// Embedding this struct allows us to reuse the logic
+	// of registering and notifying observers. This simplifies the
+	// InformedWatcher to just setting up the Kubernetes informer.
+	ManualWatcher
+}
+
+// Asserts that InformedWatcher implements Watcher.
+var _ Watcher = (*InformedWatcher)(nil)
+
+// Asserts that InformedWatcher implements DefaultingWatcher.
+var _ DefaultingWatcher = (*InformedWatcher)(nil)
+
+// WatchWithDefault implements DefaultingWatcher.
+func (i *InformedWatcher) WatchWithDefault(s corev1.Secret, o ...Observer) {
+	i.m.Lock()
+	started := i.started
+	i.m.Unlock()
+	if started {
+		panic("cannot WatchWithDefault after the InformedWatcher has started")
+	}
+
+	i.defaults[s.Name] = &s
+	i.Watch(s.Name, o...)
+}
+
+// Start implements Watcher.
+func (i *InformedWatcher) Start(stopCh <-chan struct{}) error {
+	// Pretend that all the defaulted Secrets were just created. This is done before we start
+	// the informer to ensure that if a defaulted Secret does exist, then the real value is
+	// processed after the default one.
+	for k := range i.observers {
+		if def, ok := i.defaults[k]; ok {
+			// OnChange ignores Secrets from other namespaces.
+			if def.Namespace != i.Namespace {
+				return fmt.Errorf("default Secret %q has namespace %q, want %q", k, def.Namespace, i.Namespace)
+			}
+			i.addSecretEvent(def)
+		}
+	}
+
+	if err := i.registerCallbackAndStartInformer(stopCh); err != nil {
+		return err
+	}
+
+	// Wait until it has been synced (WITHOUT holding the mutex, so callbacks happen)
+	if ok := cache.WaitForCacheSync(stopCh, i.informer.Informer().HasSynced); !ok {
+		return errors.New("error waiting for Secret informer to sync")
+	}
+
+	return i.checkObservedResourcesExist()
+}
+
+func (i *InformedWatcher) registerCallbackAndStartInformer(stopCh <-chan struct{}) error {
+	i.m.Lock()
+	defer i.m.Unlock()
+	if i.started {
+		return errors.New("watcher already started")
+	}
+	i.started = true
+
+	i.informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    i.addSecretEvent,
+		UpdateFunc: i.updateSecretEvent,
+		DeleteFunc: i.deleteSecretEvent,
+	})
+
+	// Start the shared informer factory (non-blocking).
+	i.sif.Start(stopCh)
+	return nil
+}
+
+func (i *InformedWatcher) checkObservedResourcesExist() error {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	// Check that all objects with Observers exist in our informers.
+	for k := range i.observers {
+		if _, err := i.informer.Lister().Secrets(i.Namespace).Get(k); err != nil {
+			if _, ok := i.defaults[k]; ok && k8serrors.IsNotFound(err) {
+				// It is defaulted, so it is OK that it doesn't exist.
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *InformedWatcher) addSecretEvent(obj interface{}) {
+	i.OnChange(obj.(*corev1.Secret))
+}
+
+func (i *InformedWatcher) updateSecretEvent(o, n interface{}) {
+	// Ignore updates that are idempotent, e.g. periodic resyncs.
+	if equality.Semantic.DeepEqual(o, n) {
+		return
+	}
+	i.OnChange(n.(*corev1.Secret))
+}
+
+func (i *InformedWatcher) deleteSecretEvent(obj interface{}) {
+	// The informer may have missed the deletion, in which case it hands us
+	// the last state it knew of, wrapped in a tombstone.
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	if def, ok := i.defaults[secret.Name]; ok {
+		i.OnChange(def)
+	}
+	// If there is no default value, then don't do anything.
+}
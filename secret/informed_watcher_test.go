@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package secret
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type recorder struct {
+	mu   sync.Mutex
+	seen []string
+}
+
+// observe records the value of the "key" entry of the observed Secrets.
+func (r *recorder) observe(s *corev1.Secret) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen = append(r.seen, string(s.Data["key"]))
+}
+
+func (r *recorder) values() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.seen...)
+}
+
+func (r *recorder) waitFor(t *testing.T, n int) []string {
+	t.Helper()
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(r.values()) >= n, nil
+	}); err != nil {
+		t.Fatalf("Observed %v, wanted %d values", r.values(), n)
+	}
+	return r.values()
+}
+
+func newSecret(name, value string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Data: map[string][]byte{"key": []byte(value)},
+	}
+}
+
+func TestInformedWatcher(t *testing.T) {
+	foo := newSecret("foo", "from k8s")
+	kc := fakekubeclientset.NewSimpleClientset(foo, newSecret("bar", "other"))
+	w := NewInformedWatcher(kc, "default")
+
+	r := &recorder{}
+	w.Watch("foo", r.observe)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err != nil {
+		t.Fatal("Start() =", err)
+	}
+	if got := r.waitFor(t, 1); got[0] != "from k8s" {
+		t.Errorf("Observed %v, wanted the initial state first", got)
+	}
+
+	updated := foo.DeepCopy()
+	updated.Data["key"] = []byte("updated")
+	if _, err := kc.CoreV1().Secrets("default").Update(context.Background(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Update() =", err)
+	}
+	if got := r.waitFor(t, 2); got[1] != "updated" {
+		t.Errorf("Observed %v, wanted the update", got)
+	}
+}
+
+func TestInformedWatcherMissing(t *testing.T) {
+	w := NewInformedWatcher(fakekubeclientset.NewSimpleClientset(), "default")
+	w.Watch("foo", func(*corev1.Secret) {})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err == nil {
+		t.Error("Start() = nil, wanted an error for the missing Secret")
+	}
+}
+
+func TestInformedWatcherDefault(t *testing.T) {
+	kc := fakekubeclientset.NewSimpleClientset()
+	w := NewInformedWatcher(kc, "default")
+
+	r := &recorder{}
+	w.WatchWithDefault(*newSecret("foo", "default"), r.observe)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err != nil {
+		t.Fatal("Start() =", err)
+	}
+
+	foo := newSecret("foo", "from k8s")
+	if _, err := kc.CoreV1().Secrets("default").Create(context.Background(), foo, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Create() =", err)
+	}
+	r.waitFor(t, 2)
+	if err := kc.CoreV1().Secrets("default").Delete(context.Background(), "foo", metav1.DeleteOptions{}); err != nil {
+		t.Fatal("Delete() =", err)
+	}
+
+	// The default is observed at start, then the real Secret, and the
+	// default again once it is deleted.
+	got := r.waitFor(t, 3)
+	want := []string{"default", "from k8s", "default"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Observed %v, want %v", got, want)
+		}
+	}
+}
+
+func TestInformedWatcherDefaultWithoutNamespace(t *testing.T) {
+	w := NewInformedWatcher(fakekubeclientset.NewSimpleClientset(), "default")
+	def := newSecret("foo", "default")
+	def.Namespace = ""
+	w.WatchWithDefault(*def, func(*corev1.Secret) {})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err == nil {
+		t.Error("Start() = nil, wanted an error for the default without a namespace")
+	}
+}
+
+func TestInformedWatcherLabelRequirements(t *testing.T) {
+	labeled := newSecret("labeled", "from k8s")
+	labeled.Labels = map[string]string{"knative.dev/watched": "true"}
+	kc := fakekubeclientset.NewSimpleClientset(labeled, newSecret("unlabeled", "other"))
+	req, err := labels.NewRequirement("knative.dev/watched", selection.Exists, nil)
+	if err != nil {
+		t.Fatal("NewRequirement() =", err)
+	}
+
+	w := NewInformedWatcher(kc, "default", *req)
+	r := &recorder{}
+	w.Watch("labeled", r.observe)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err != nil {
+		t.Fatal("Start() =", err)
+	}
+	if got := r.waitFor(t, 1); got[0] != "from k8s" {
+		t.Errorf("Observed %v, wanted the labeled Secret", got)
+	}
+
+	// The Secrets without the label are filtered out.
+	w = NewInformedWatcher(kc, "default", *req)
+	w.Watch("unlabeled", func(*corev1.Secret) {})
+	if err := w.Start(stopCh); err == nil {
+		t.Error("Start() = nil, wanted an error for the filtered out Secret")
+	}
+}
+
+func TestWatchWithDefaultAfterStart(t *testing.T) {
+	w := NewInformedWatcher(fakekubeclientset.NewSimpleClientset(), "default")
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err != nil {
+		t.Fatal("Start() =", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("WatchWithDefault after Start did not panic")
+		}
+	}()
+	w.WatchWithDefault(*newSecret("foo", "default"), func(*corev1.Secret) {})
+}
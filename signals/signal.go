@@ -24,33 +24,62 @@ import (
 	"time"
 )
 
-var onlyOneSignalHandler = make(chan struct{})
+var (
+	onlyOneSignalHandler = make(chan struct{})
+
+	// exit is overridden in tests.
+	exit = os.Exit
+)
 
 // SetupSignalHandler registered for SIGTERM and SIGINT. A stop channel is returned
 // which is closed on one of these signals. If a second signal is caught, the program
 // is terminated with exit code 1.
 func SetupSignalHandler() (stopCh <-chan struct{}) {
+	return SetupSignalHandlerWithDrain(0)
+}
+
+// SetupSignalHandlerWithDrain is like SetupSignalHandler, but additionally
+// terminates the program with exit code 1 if it did not exit within drain
+// after the stop channel was closed, so that a shutdown can't hang forever.
+// A drain of zero waits for a second signal only.
+func SetupSignalHandlerWithDrain(drain time.Duration) (stopCh <-chan struct{}) {
 	close(onlyOneSignalHandler) // panics when called twice
 
 	stop := make(chan struct{})
 	c := make(chan os.Signal, 2)
 	signal.Notify(c, shutdownSignals...)
-	go func() {
-		<-c
-		close(stop)
-		<-c
-		os.Exit(1) // second signal. Exit directly.
-	}()
+	go handleSignals(c, stop, drain)
 
 	return stop
 }
 
+func handleSignals(c <-chan os.Signal, stop chan struct{}, drain time.Duration) {
+	<-c
+	close(stop)
+
+	var drained <-chan time.Time
+	if drain > 0 {
+		drained = time.After(drain)
+	}
+	select {
+	case <-c: // second signal. Exit directly.
+	case <-drained: // took too long to drain.
+	}
+	exit(1)
+}
+
 // NewContext creates a new context with SetupSignalHandler()
 // as our Done() channel.
 func NewContext() context.Context {
 	return &signalContext{stopCh: SetupSignalHandler()}
 }
 
+// NewContextWithDrain creates a new context with
+// SetupSignalHandlerWithDrain(drain) as our Done() channel.
+func NewContextWithDrain(drain time.Duration) context.Context {
+	return &signalContext{stopCh: SetupSignalHandlerWithDrain(drain)}
+}
+
 type signalContext struct {
 	stopCh <-chan struct{}
 }
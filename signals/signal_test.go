@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signals
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSignals(t *testing.T) {
+	tests := []struct {
+		name         string
+		drain        time.Duration
+		secondSignal bool
+	}{{
+		name:         "second signal",
+		secondSignal: true,
+	}, {
+		name:         "second signal before drain",
+		drain:        time.Hour,
+		secondSignal: true,
+	}, {
+		name:  "drain elapsed",
+		drain: 10 * time.Millisecond,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exited := make(chan int, 1)
+			exit = func(code int) { exited <- code }
+			defer func() { exit = os.Exit }()
+
+			c := make(chan os.Signal, 2)
+			stop := make(chan struct{})
+			go handleSignals(c, stop, test.drain)
+
+			c <- syscall.SIGTERM
+			select {
+			case <-stop:
+			case <-time.After(5 * time.Second):
+				t.Fatal("The stop channel was not closed on the first signal")
+			}
+			if test.secondSignal {
+				c <- syscall.SIGTERM
+			}
+			select {
+			case code := <-exited:
+				if code != 1 {
+					t.Errorf("Exit code = %d, want 1", code)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("The program did not exit")
+			}
+		})
+	}
+}
+
+func TestHandleSignalsWaitsWithoutDrain(t *testing.T) {
+	exited := make(chan int, 1)
+	exit = func(code int) { exited <- code }
+	defer func() { exit = os.Exit }()
+
+	c := make(chan os.Signal, 2)
+	stop := make(chan struct{})
+	go handleSignals(c, stop, 0)
+	c <- syscall.SIGTERM
+	<-stop
+
+	select {
+	case <-exited:
+		t.Error("The program exited without a second signal")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
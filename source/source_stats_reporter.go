@@ -51,6 +51,24 @@ var (
 	responseTimeout        = tag.MustNewKey(metricskey.LabelResponseTimeout)
 )
 
+// eventCountView is the view of eventCountM, by source and response.
+var eventCountView = &view.View{
+	Description: eventCountM.Description(),
+	Measure:     eventCountM,
+	Aggregation: view.Count(),
+	TagKeys: []tag.Key{
+		namespaceKey,
+		eventSourceKey,
+		eventTypeKey,
+		sourceNameKey,
+		sourceResourceGroupKey,
+		responseCodeKey,
+		responseCodeClassKey,
+		responseError,
+		responseTimeout,
+	},
+}
+
 type ReportArgs struct {
 	Namespace     string
 	EventType     string
@@ -113,26 +131,9 @@ func (r *reporter) generateTag(args *ReportArgs, responseCode int) (context.Cont
 }
 
 func register() {
-	tagKeys := []tag.Key{
-		namespaceKey,
-		eventSourceKey,
-		eventTypeKey,
-		sourceNameKey,
-		sourceResourceGroupKey,
-		responseCodeKey,
-		responseCodeClassKey,
-		responseError,
-		responseTimeout}
-
-	// Create view to see our measurements.
-	if err := view.Register(
-		&view.View{
-			Description: eventCountM.Description(),
-			Measure:     eventCountM,
-			Aggregation: view.Count(),
-			TagKeys:     tagKeys,
-		},
-	); err != nil {
+	// Register the view across all resources, so that the Stackdriver exporter
+	// can report it against the knative_source resource.
+	if err := metrics.RegisterResourceView(eventCountView); err != nil {
 		panic(err)
 	}
 }
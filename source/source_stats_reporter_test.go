@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"testing"
 
+	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 	_ "knative.dev/pkg/metrics/testing"
@@ -74,6 +75,6 @@ func setup() {
 
 func resetMetrics() {
 	// OpenCensus metrics carry global state that need to be reset between unit tests.
-	metricstest.Unregister("event_count")
+	metrics.UnregisterResourceView(eventCountView)
 	register()
 }
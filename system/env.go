@@ -22,7 +22,12 @@ import (
 )
 
 const (
-	NamespaceEnvKey     = "SYSTEM_NAMESPACE"
+	// NamespaceEnvKey is the environment variable that holds the
+	// namespace returned by Namespace.
+	NamespaceEnvKey = "SYSTEM_NAMESPACE"
+
+	// ResourceLabelEnvKey is the environment variable that holds the
+	// label key returned by ResourceLabel.
 	ResourceLabelEnvKey = "SYSTEM_RESOURCE_LABEL"
 )
 
@@ -18,6 +18,7 @@ package testing
 
 import (
 	"os"
+	gotesting "testing"
 
 	"knative.dev/pkg/system"
 )
@@ -28,3 +29,27 @@ func init() {
 	}
 	os.Setenv(system.NamespaceEnvKey, "knative-testing")
 }
+
+// SetNamespace makes system.Namespace return ns for the duration of the test,
+// restoring the previous value when the test completes.
+func SetNamespace(t gotesting.TB, ns string) {
+	setEnv(t, system.NamespaceEnvKey, ns)
+}
+
+// SetResourceLabel makes system.ResourceLabel return label for the duration
+// of the test, restoring the previous value when the test completes.
+func SetResourceLabel(t gotesting.TB, label string) {
+	setEnv(t, system.ResourceLabelEnvKey, label)
+}
+
+func setEnv(t gotesting.TB, key, value string) {
+	prev, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	gotesting "testing"
+
+	"knative.dev/pkg/system"
+)
+
+func TestSetNamespace(t *gotesting.T) {
+	if got, want := system.Namespace(), "knative-testing"; got != want {
+		t.Errorf("Namespace() = %s, want %s", got, want)
+	}
+
+	t.Run("override", func(t *gotesting.T) {
+		SetNamespace(t, "knative-other")
+		SetResourceLabel(t, "example.com/config")
+		if got, want := system.Namespace(), "knative-other"; got != want {
+			t.Errorf("Namespace() = %s, want %s", got, want)
+		}
+		if got, want := system.ResourceLabel(), "example.com/config"; got != want {
+			t.Errorf("ResourceLabel() = %s, want %s", got, want)
+		}
+	})
+
+	if got, want := system.Namespace(), "knative-testing"; got != want {
+		t.Errorf("Namespace() after the override = %s, want %s", got, want)
+	}
+	if got := system.ResourceLabel(); got != "" {
+		t.Errorf("ResourceLabel() after the override = %s, want empty", got)
+	}
+}
@@ -22,6 +22,7 @@ package test
 import (
 	"os"
 	"os/signal"
+	"sync"
 
 	"knative.dev/pkg/test/logging"
 )
@@ -38,3 +39,39 @@ func CleanupOnInterrupt(cleanup func(), logf logging.FormatLogger) {
 		}
 	}()
 }
+
+// Cleaner tracks the resources created by a test, and cleans them up in
+// reverse order of creation when the test ends or is interrupted.
+type Cleaner struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// NewCleaner returns a Cleaner running its cleanups when t finishes or the
+// test is interrupted, whichever comes first.
+func NewCleaner(t T, logf logging.FormatLogger) *Cleaner {
+	c := &Cleaner{}
+	t.Cleanup(c.Run)
+	CleanupOnInterrupt(c.Run, logf)
+	return c
+}
+
+// Add tracks a function cleaning up a resource.
+func (c *Cleaner) Add(f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fns = append(c.fns, f)
+}
+
+// Run runs the tracked cleanups, most recently added first. Each cleanup
+// runs at most once, even if Run is called again.
+func (c *Cleaner) Run() {
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		fns[i]()
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCleaner(t *testing.T) {
+	var got []int
+	t.Run("sub", func(t *testing.T) {
+		c := NewCleaner(t, t.Logf)
+		for i := 0; i < 3; i++ {
+			i := i
+			c.Add(func() { got = append(got, i) })
+		}
+	})
+
+	if want := []int{2, 1, 0}; !cmp.Equal(got, want) {
+		t.Error("Cleanups (-want, +got):", cmp.Diff(want, got))
+	}
+}
+
+func TestCleanerRunOnce(t *testing.T) {
+	calls := 0
+	c := &Cleaner{}
+	c.Add(func() { calls++ })
+	c.Run()
+	c.Run()
+	if calls != 1 {
+		t.Errorf("Cleanup ran %d times, want 1", calls)
+	}
+}
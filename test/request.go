@@ -51,6 +51,14 @@ func WithHeader(header http.Header) RequestOption {
 	}
 }
 
+// WithHost overrides the Host header of the request, for reaching a
+// virtual host through an endpoint that does not resolve it.
+func WithHost(host string) RequestOption {
+	return func(r *http.Request) {
+		r.Host = host
+	}
+}
+
 // Retrying modifies a ResponseChecker to retry certain response codes.
 func Retrying(rc spoof.ResponseChecker, codes ...int) spoof.ResponseChecker {
 	return func(resp *spoof.Response) (bool, error) {
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"knative.dev/pkg/test/spoof"
+)
+
+func TestRequestOptions(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://10.0.0.1", nil)
+	if err != nil {
+		t.Fatal("NewRequest() =", err)
+	}
+	WithHost("hello.default.example.com")(req)
+	WithHeader(http.Header{"Foo": []string{"bar"}})(req)
+
+	if got, want := req.Host, "hello.default.example.com"; got != want {
+		t.Errorf("Host = %q, want %q", got, want)
+	}
+	if got, want := req.Header.Get("Foo"), "bar"; got != want {
+		t.Errorf("Header[Foo] = %q, want %q", got, want)
+	}
+}
+
+func TestResponseCheckers(t *testing.T) {
+	tests := []struct {
+		name     string
+		checker  spoof.ResponseChecker
+		resp     *spoof.Response
+		wantDone bool
+		wantErr  bool
+	}{{
+		name:     "ok",
+		checker:  IsStatusOK,
+		resp:     &spoof.Response{StatusCode: http.StatusOK},
+		wantDone: true,
+	}, {
+		name:     "not ok",
+		checker:  IsStatusOK,
+		resp:     &spoof.Response{StatusCode: http.StatusNotFound},
+		wantDone: true,
+		wantErr:  true,
+	}, {
+		name:    "retrying",
+		checker: Retrying(IsStatusOK, http.StatusNotFound),
+		resp:    &spoof.Response{StatusCode: http.StatusNotFound},
+	}, {
+		name:     "matches body",
+		checker:  MatchesAllOf(IsStatusOK, MatchesBody("hello")),
+		resp:     &spoof.Response{StatusCode: http.StatusOK, Body: []byte("hello world")},
+		wantDone: true,
+	}, {
+		name:     "wrong body",
+		checker:  MatchesBody("hello"),
+		resp:     &spoof.Response{StatusCode: http.StatusOK, Body: []byte("bye")},
+		wantDone: true,
+		wantErr:  true,
+	}, {
+		name:    "eventually matches body",
+		checker: EventuallyMatchesBody("hello"),
+		resp:    &spoof.Response{StatusCode: http.StatusOK, Body: []byte("bye")},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			done, err := test.checker(test.resp)
+			if done != test.wantDone {
+				t.Errorf("done = %v, want %v", done, test.wantDone)
+			}
+			if (err != nil) != test.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spoof
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPollUntilReady(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(r.Host))
+	}))
+	defer server.Close()
+
+	sc := &SpoofingClient{
+		Client:          server.Client(),
+		RequestInterval: 10 * time.Millisecond,
+		RequestTimeout:  5 * time.Second,
+		Logf:            t.Logf,
+	}
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal("NewRequest() =", err)
+	}
+	req.Host = "hello.default.example.com"
+
+	resp, err := sc.Poll(req, func(resp *Response) (bool, error) {
+		return resp.StatusCode == http.StatusOK, nil
+	})
+	if err != nil {
+		t.Fatal("Poll() =", err)
+	}
+	if got, want := string(resp.Body), "hello.default.example.com"; got != want {
+		t.Errorf("Body = %q, want %q", got, want)
+	}
+	if got, want := atomic.LoadInt32(&calls), int32(3); got != want {
+		t.Errorf("Requests = %d, want %d", got, want)
+	}
+}
+
+func TestPollTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sc := &SpoofingClient{
+		Client:          server.Client(),
+		RequestInterval: 10 * time.Millisecond,
+		RequestTimeout:  50 * time.Millisecond,
+		Logf:            t.Logf,
+	}
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal("NewRequest() =", err)
+	}
+
+	resp, err := sc.Poll(req, func(resp *Response) (bool, error) {
+		return resp.StatusCode == http.StatusOK, nil
+	})
+	if err == nil {
+		t.Fatal("Poll() = nil, wanted an error")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Poll() = %v, wanted the last response", resp)
+	}
+}
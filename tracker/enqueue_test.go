@@ -23,6 +23,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 
@@ -657,6 +658,89 @@ func TestHappyPathsInexact(t *testing.T) {
 	}
 }
 
+func TestTrackKind(t *testing.T) {
+	calls := 0
+	f := func(key types.NamespacedName) {
+		calls++
+	}
+
+	trk := New(f, 100*time.Millisecond)
+
+	secret := func(ns, name string, labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "Secret",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ns,
+				Name:      name,
+				Labels:    labels,
+			},
+		}
+	}
+	ref := ReferenceForKind(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, "ns")
+
+	watcher := &Resource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "reffer.knative.dev/v1alpha1",
+			Kind:       "Thing2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "watcher",
+		},
+	}
+
+	if err := trk.TrackReference(ref, watcher); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+	// New registrations should result in an immediate callback.
+	if got, want := calls, 1; got != want {
+		t.Fatalf("TrackReference() = %v, wanted %v", got, want)
+	}
+
+	// Any Secret in the namespace triggers the callback, labeled or not.
+	trk.OnChanged(secret("ns", "foo", nil))
+	trk.OnChanged(secret("ns", "bar", map[string]string{"a": "b"}))
+	if got, want := calls, 3; got != want {
+		t.Fatalf("OnChanged() = %v, wanted %v", got, want)
+	}
+
+	// Secrets in other namespaces are ignored.
+	trk.OnChanged(secret("other", "foo", nil))
+	if got, want := calls, 3; got != want {
+		t.Fatalf("OnChanged() = %v, wanted %v", got, want)
+	}
+
+	// Once the watcher is gone, so is the inexact entry.
+	trk.OnDeletedObserver(watcher)
+	if _, stillThere := trk.(*impl).inexact[Reference{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Namespace:  ref.Namespace,
+	}]; stillThere {
+		t.Error("Watcher was deleted, but inexact for the kind is still there")
+	}
+	trk.OnChanged(secret("ns", "foo", nil))
+	if got, want := calls, 3; got != want {
+		t.Fatalf("OnChanged() = %v, wanted %v", got, want)
+	}
+
+	// Expired leases are cleaned up when the tracked kind changes.
+	if err := trk.TrackReference(ref, watcher); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+	time.Sleep(101 * time.Millisecond)
+	trk.OnChanged(secret("ns", "foo", nil))
+	if got, want := calls, 4; got != want {
+		t.Fatalf("OnChanged() = %v, wanted %v", got, want)
+	}
+	if got := len(trk.(*impl).inexact); got != 0 {
+		t.Errorf("len(inexact) = %d, wanted 0 after the lease expired", got)
+	}
+}
+
 func TestHappyPathsByBoth(t *testing.T) {
 	calls := 0
 	f := func(key types.NamespacedName) {
@@ -51,10 +51,25 @@ type Reference struct {
 
 	// Selector of the referents.
 	// Mutually exclusive with Name.
+	// An empty selector matches every object of the referenced kind
+	// within the namespace.
 	// +optional
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
+// ReferenceForKind returns a Reference to every object of the given
+// GroupVersionKind within the given namespace, regardless of their
+// names or labels.
+func ReferenceForKind(gvk schema.GroupVersionKind, namespace string) Reference {
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return Reference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  namespace,
+		Selector:   &metav1.LabelSelector{},
+	}
+}
+
 // Interface defines the interface through which an object can register
 // that it is tracking another object by reference.
 type Interface interface {
@@ -231,3 +231,18 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestReferenceForKind(t *testing.T) {
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	ref := ReferenceForKind(gvk, "default")
+
+	if got := ref.GroupVersionKind(); got != gvk {
+		t.Errorf("GroupVersionKind() = %v, want: %v", got, gvk)
+	}
+	if got, want := ref.APIVersion, "v1"; got != want {
+		t.Errorf("APIVersion = %q, want: %q", got, want)
+	}
+	if err := ref.Validate(context.Background()); err != nil {
+		t.Error("Validate() =", err)
+	}
+}
@@ -47,12 +47,7 @@ func getMinimumVersion() string {
 // A Kubernetes discovery client can be passed in as the versioner
 // like `CheckMinimumVersion(kubeClient.Discovery())`.
 func CheckMinimumVersion(versioner discovery.ServerVersionInterface) error {
-	v, err := versioner.ServerVersion()
-	if err != nil {
-		return err
-	}
-
-	currentVersion, err := semver.Make(normalizeVersion(v.GitVersion))
+	currentVersion, err := serverVersion(versioner)
 	if err != nil {
 		return err
 	}
@@ -70,6 +65,44 @@ func CheckMinimumVersion(versioner discovery.ServerVersionInterface) error {
 	return nil
 }
 
+// CheckFeatureVersion checks if the currently installed version of
+// Kubernetes is at least minimum, which the named feature requires, e.g.
+// `CheckFeatureVersion(kubeClient.Discovery(), "CRD conversion webhooks", "v1.16.0")`.
+// Returns an error naming the feature if its not.
+func CheckFeatureVersion(versioner discovery.ServerVersionInterface, feature, minimum string) error {
+	currentVersion, err := serverVersion(versioner)
+	if err != nil {
+		return err
+	}
+	minimumVersion, err := semver.Make(normalizeVersion(minimum))
+	if err != nil {
+		return err
+	}
+
+	if currentVersion.LT(minimumVersion) {
+		return fmt.Errorf("kubernetes version %q does not support %s, which needs at least %q",
+			currentVersion, feature, minimumVersion)
+	}
+	return nil
+}
+
+// serverVersion returns the version of the Kubernetes API server.
+// Pre-release suffixes, like those of managed offerings (e.g. "-gke.1"),
+// are dropped so that they don't rank below the release they are built on.
+func serverVersion(versioner discovery.ServerVersionInterface) (semver.Version, error) {
+	v, err := versioner.ServerVersion()
+	if err != nil {
+		return semver.Version{}, err
+	}
+
+	currentVersion, err := semver.Make(normalizeVersion(v.GitVersion))
+	if err != nil {
+		return semver.Version{}, err
+	}
+	currentVersion.Pre = nil
+	return currentVersion, nil
+}
+
 func normalizeVersion(v string) string {
 	if strings.HasPrefix(v, "v") {
 		// No need to account for unicode widths.
@@ -51,6 +51,9 @@ func TestVersionCheck(t *testing.T) {
 	}, {
 		name:          "same version with build",
 		actualVersion: &testVersioner{version: "v1.16.0+k3s.1"},
+	}, {
+		name:          "same version with pre-release",
+		actualVersion: &testVersioner{version: "v1.16.0-gke.1"},
 	}, {
 		name:          "smaller version",
 		actualVersion: &testVersioner{version: "v1.14.3"},
@@ -90,3 +93,44 @@ func TestVersionCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestFeatureVersionCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		actualVersion *testVersioner
+		minimum       string
+		wantError     bool
+	}{{
+		name:          "greater version",
+		actualVersion: &testVersioner{version: "v1.18.3"},
+		minimum:       "v1.17.0",
+	}, {
+		name:          "same version with pre-release",
+		actualVersion: &testVersioner{version: "v1.17.0-eks.2"},
+		minimum:       "v1.17.0",
+	}, {
+		name:          "smaller version",
+		actualVersion: &testVersioner{version: "v1.16.8"},
+		minimum:       "v1.17.0",
+		wantError:     true,
+	}, {
+		name:          "unparseable minimum",
+		actualVersion: &testVersioner{version: "v1.16.8"},
+		minimum:       "latest",
+		wantError:     true,
+	}, {
+		name:          "error while fetching",
+		actualVersion: &testVersioner{err: errors.New("random error")},
+		minimum:       "v1.17.0",
+		wantError:     true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckFeatureVersion(test.actualVersion, "a feature", test.minimum)
+			if (err != nil) != test.wantError {
+				t.Errorf("CheckFeatureVersion() = %v, wantError %v", err, test.wantError)
+			}
+		})
+	}
+}
@@ -196,10 +196,7 @@ func (ac *reconciler) validate(ctx context.Context, req *admissionv1.AdmissionRe
 
 	if constructor, ok := ac.constructors[newObj.Name]; ok {
 		// Only validate example data if this is a configMap we know about.
-		exampleData, hasExampleData := newObj.Data[configmap.ExampleKey]
-		exampleChecksum, hasExampleChecksumAnnotation := newObj.Annotations[configmap.ExampleChecksumAnnotation]
-		if hasExampleData && hasExampleChecksumAnnotation &&
-			exampleChecksum != configmap.Checksum(exampleData) {
+		if configmap.ExampleModified(&newObj) {
 			return fmt.Errorf(
 				"the update modifies a key in %q which is probably not what you want. Instead, copy the respective setting to the top-level of the ConfigMap, directly below %q",
 				configmap.ExampleKey, "data")
@@ -117,7 +117,7 @@ func RegisterMetrics() {
 		resourceNamespaceKey,
 		admissionAllowedKey}
 
-	if err := view.Register(
+	if err := metrics.RegisterResourceView(
 		&view.View{
 			Description: requestCountM.Description(),
 			Measure:     requestCountM,
@@ -37,6 +37,10 @@ var (
 	// but no connection is already created.
 	ErrConnectionNotEstablished = errors.New("connection has not yet been established")
 
+	// ErrSendBufferFull is returned by the buffered send methods when
+	// the message can neither be sent nor buffered.
+	ErrSendBufferFull = errors.New("send buffer is full")
+
 	// errShuttingDown is returned internally once the shutdown signal has been sent.
 	errShuttingDown = errors.New("shutdown in progress")
 
@@ -85,6 +89,18 @@ type ManagedConnection struct {
 
 	// Used for the exponential backoff when connecting
 	connectionBackoff wait.Backoff
+
+	// Messages that could not be sent by the buffered send methods,
+	// oldest first, to be flushed once the connection is (re)established.
+	// At most sendBufferSize messages are kept.
+	sendBufferLock sync.Mutex
+	sendBuffer     []bufferedMessage
+	sendBufferSize int
+}
+
+type bufferedMessage struct {
+	messageType int
+	body        []byte
 }
 
 // NewDurableSendingConnection creates a new websocket connection
@@ -114,6 +130,18 @@ func NewDurableSendingConnectionGuaranteed(target string, duration time.Duration
 	}
 }
 
+// NewDurableBufferedSendingConnection creates a new websocket connection
+// that can only send messages to the endpoint it connects to. Messages
+// sent through SendBuffered or SendRawBuffered while the connection is
+// down are kept, up to bufferSize of them, and sent in order once the
+// connection is reestablished.
+//
+// The connection will continuously be kept alive and reconnected
+// in case of a loss of connectivity.
+func NewDurableBufferedSendingConnection(target string, bufferSize int, logger *zap.SugaredLogger) *ManagedConnection {
+	return newDurableConnection(target, nil, bufferSize, logger)
+}
+
 // NewDurableConnection creates a new websocket connection, that
 // passes incoming messages to the given message channel. It can also
 // send messages to the endpoint it connects to.
@@ -127,6 +155,10 @@ func NewDurableSendingConnectionGuaranteed(target string, duration time.Duration
 // go func() {conn.Shutdown(); close(messageChan)}
 // go func() {for range messageChan {}}
 func NewDurableConnection(target string, messageChan chan []byte, logger *zap.SugaredLogger) *ManagedConnection {
+	return newDurableConnection(target, messageChan, 0, logger)
+}
+
+func newDurableConnection(target string, messageChan chan []byte, bufferSize int, logger *zap.SugaredLogger) *ManagedConnection {
 	websocketConnectionFactory := func() (rawConnection, error) {
 		dialer := &websocket.Dialer{
 			// This needs to be relatively short to avoid the connection getting blackholed for a long time
@@ -141,6 +173,7 @@ func NewDurableConnection(target string, messageChan chan []byte, logger *zap.Su
 	}
 
 	c := newConnection(websocketConnectionFactory, messageChan)
+	c.sendBufferSize = bufferSize
 
 	// Keep the connection alive asynchronously and reconnect on
 	// connection failure.
@@ -157,6 +190,9 @@ func NewDurableConnection(target string, messageChan chan []byte, logger *zap.Su
 					continue
 				}
 				logger.Debug("Connected to ", target)
+				if err := c.flushSendBuffer(); err != nil {
+					logger.Errorw("Failed to send the buffered messages to "+target, zap.Error(err))
+				}
 				if err := c.keepalive(); err != nil {
 					logger.With(zap.Error(err)).Errorf("Connection to %s broke down, reconnecting...", target)
 				}
@@ -343,6 +379,58 @@ func (c *ManagedConnection) SendRaw(messageType int, msg []byte) error {
 	return c.write(messageType, msg)
 }
 
+// SendBuffered is like Send, but buffers the message to be sent once the
+// connection is (re)established if it can't be sent right away. It returns
+// ErrSendBufferFull if the buffer has no room left for the message.
+func (c *ManagedConnection) SendBuffered(msg interface{}) error {
+	var b bytes.Buffer
+	enc := gob.NewEncoder(&b)
+	if err := enc.Encode(msg); err != nil {
+		return err
+	}
+
+	return c.SendRawBuffered(websocket.BinaryMessage, b.Bytes())
+}
+
+// SendRawBuffered is like SendRaw, but buffers the message to be sent once
+// the connection is (re)established if it can't be sent right away.
+// It returns ErrSendBufferFull if the buffer has no room left for the message.
+func (c *ManagedConnection) SendRawBuffered(messageType int, msg []byte) error {
+	c.sendBufferLock.Lock()
+	defer c.sendBufferLock.Unlock()
+
+	// Messages buffered before have to go out first to keep the order.
+	if c.flushSendBufferLocked() == nil && c.write(messageType, msg) == nil {
+		return nil
+	}
+	if len(c.sendBuffer) >= c.sendBufferSize {
+		return ErrSendBufferFull
+	}
+	// The caller may reuse msg once we return.
+	body := append([]byte(nil), msg...)
+	c.sendBuffer = append(c.sendBuffer, bufferedMessage{messageType: messageType, body: body})
+	return nil
+}
+
+// flushSendBuffer sends the buffered messages, oldest first.
+func (c *ManagedConnection) flushSendBuffer() error {
+	c.sendBufferLock.Lock()
+	defer c.sendBufferLock.Unlock()
+	return c.flushSendBufferLocked()
+}
+
+func (c *ManagedConnection) flushSendBufferLocked() error {
+	for len(c.sendBuffer) > 0 {
+		m := c.sendBuffer[0]
+		if err := c.write(m.messageType, m.body); err != nil {
+			return err
+		}
+		c.sendBuffer[0] = bufferedMessage{}
+		c.sendBuffer = c.sendBuffer[1:]
+	}
+	return nil
+}
+
 // Shutdown closes the websocket connection.
 func (c *ManagedConnection) Shutdown() error {
 	c.closeOnce.Do(func() {
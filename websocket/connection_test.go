@@ -209,6 +209,72 @@ func TestSendRawMessage(t *testing.T) {
 	}
 }
 
+// recordingConnection records the messages written to it.
+type recordingConnection struct {
+	inspectableConnection
+	written []string
+}
+
+func (c *recordingConnection) WriteMessage(messageType int, data []byte) error {
+	c.written = append(c.written, string(data))
+	return nil
+}
+
+func TestSendRawBuffered(t *testing.T) {
+	spy := &recordingConnection{}
+	conn := newConnection(staticConnFactory(spy), nil)
+	conn.sendBufferSize = 2
+
+	// Buffered messages don't alias the caller's slice.
+	msg := make([]byte, 1)
+	for _, c := range []byte{'a', 'b'} {
+		msg[0] = c
+		if got := conn.SendRawBuffered(websocket.TextMessage, msg); got != nil {
+			t.Fatalf("SendRawBuffered(%s) = %v, wanted nil", msg, got)
+		}
+	}
+	msg[0] = 'x'
+	if got := conn.SendRawBuffered(websocket.TextMessage, []byte("c")); got != ErrSendBufferFull {
+		t.Fatalf("SendRawBuffered(c) = %v, wanted %v", got, ErrSendBufferFull)
+	}
+
+	conn.connect()
+	if got := conn.SendRawBuffered(websocket.TextMessage, []byte("d")); got != nil {
+		t.Fatalf("SendRawBuffered(d) = %v, wanted nil", got)
+	}
+	if got, want := strings.Join(spy.written, ","), "a,b,d"; got != want {
+		t.Errorf("Written messages = %s, want %s", got, want)
+	}
+}
+
+func TestSendBufferedFlushedOnConnect(t *testing.T) {
+	spy := &recordingConnection{}
+	conn := newConnection(staticConnFactory(spy), nil)
+	conn.sendBufferSize = 1
+
+	if got := conn.SendBuffered("test"); got != nil {
+		t.Fatalf("SendBuffered() = %v, wanted nil", got)
+	}
+	if len(spy.written) != 0 {
+		t.Fatalf("Expected nothing to be written before connecting, got %d messages", len(spy.written))
+	}
+
+	conn.connect()
+	if got := conn.flushSendBuffer(); got != nil {
+		t.Fatalf("flushSendBuffer() = %v, wanted nil", got)
+	}
+	if len(spy.written) != 1 {
+		t.Errorf("Expected the buffered message to be written, got %d messages", len(spy.written))
+	}
+}
+
+func TestSendRawBufferedWithoutBuffer(t *testing.T) {
+	conn := newConnection(nil, nil)
+	if got := conn.SendRawBuffered(websocket.TextMessage, []byte("test")); got != ErrSendBufferFull {
+		t.Errorf("SendRawBuffered() = %v, wanted %v", got, ErrSendBufferFull)
+	}
+}
+
 func TestReceiveMessage(t *testing.T) {
 	testMessage := "testmessage"
 